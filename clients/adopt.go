@@ -0,0 +1,109 @@
+package clients
+
+import (
+	"cmp"
+	"fmt"
+
+	"github.com/jrandolf/mcpr/config"
+)
+
+// EntryServer turns a server entry of a client config, as returned by
+// ServerEntries, back into the server it describes. It reads every shape
+// mcpr writes: command strings and OpenCode's command lists, Zed's nested
+// commands, Continue's transports, and the clients' names for urls, headers
+// and env vars. Windows "cmd /c" wrappers are removed, since mcpr adds them
+// back when syncing.
+func EntryServer(name string, entry any) (config.MCPServer, error) {
+	m, ok := entry.(map[string]any)
+	if !ok {
+		return config.MCPServer{}, fmt.Errorf("entry %q is not an object", name)
+	}
+	// Continue nests the transport
+	if transport, ok := m["transport"].(map[string]any); ok {
+		m = transport
+	}
+
+	server := config.MCPServer{Name: name}
+	if disabled, ok := m["disabled"].(bool); ok {
+		server.Disabled = disabled
+	}
+	if enabled, ok := m["enabled"].(bool); ok && !enabled {
+		server.Disabled = true
+	}
+
+	if u := cmp.Or(entryString(m["url"]), entryString(m["serverUrl"]), entryString(m["httpUrl"])); u != "" {
+		server.Type = "http"
+		server.URL = u
+		server.Headers = entryStringMap(m["headers"])
+		if server.Headers == nil {
+			server.Headers = entryStringMap(m["http_headers"])
+		}
+		return server, nil
+	}
+
+	server.Type = "stdio"
+	switch command := m["command"].(type) {
+	case string:
+		server.Command = command
+		server.Args = entryStrings(m["args"])
+	case []any:
+		// OpenCode lists the command with its args
+		if args := entryStrings(command); len(args) > 0 {
+			server.Command = args[0]
+			server.Args = args[1:]
+		}
+	case map[string]any:
+		// Zed nests the command with its args and env
+		server.Command = entryString(command["path"])
+		server.Args = entryStrings(command["args"])
+		server.Env = entryStringMap(command["env"])
+	}
+	if server.Command == "" {
+		return config.MCPServer{}, fmt.Errorf("entry %q has neither a command nor a url", name)
+	}
+	if env := entryStringMap(m["env"]); env != nil {
+		server.Env = env
+	}
+	if env := entryStringMap(m["environment"]); env != nil {
+		server.Env = env
+	}
+	server.EnvFile = entryString(m["envFile"])
+	if len(server.Args) == 0 {
+		server.Args = nil
+	}
+	return unshimCommand(server), nil
+}
+
+// entryString returns v if it is a string, and "" otherwise
+func entryString(v any) string {
+	s, _ := v.(string)
+	return s
+}
+
+// entryStrings returns the strings in the list v
+func entryStrings(v any) []string {
+	list, _ := v.([]any)
+	var result []string
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// entryStringMap returns the string values of the object v, or nil if it
+// has none
+func entryStringMap(v any) map[string]string {
+	m, _ := v.(map[string]any)
+	var result map[string]string
+	for key, value := range m {
+		if s, ok := value.(string); ok {
+			if result == nil {
+				result = make(map[string]string, len(m))
+			}
+			result[key] = s
+		}
+	}
+	return result
+}
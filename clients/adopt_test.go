@@ -0,0 +1,58 @@
+package clients
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jrandolf/mcpr/config"
+)
+
+func TestEntryServer_RoundTrip(t *testing.T) {
+	servers := []config.MCPServer{
+		{Name: "fs", Type: "stdio", Command: "npx", Args: []string{"-y", "fs"}, Env: map[string]string{"ROOT": "/tmp"}},
+		{Name: "api", Type: "http", URL: "https://example.com/mcp", Headers: map[string]string{"X-Team": "core"}},
+	}
+	for _, name := range ListClientNames() {
+		client, _ := GetClient(name)
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.json")
+			if name == "codex" {
+				path = filepath.Join(filepath.Dir(path), "config.toml")
+			}
+			t.Setenv(client.EnvOverride(false), path)
+			staged, err := client.Stage(servers, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			entries := client.ParseServerEntries(path, staged.Data)
+			for _, want := range servers {
+				entry, ok := entries[want.Name]
+				if !ok {
+					if want.Type == "http" && client.StdioOnly {
+						continue
+					}
+					t.Fatalf("expected an entry for %s, got %v", want.Name, entries)
+				}
+				got, err := EntryServer(want.Name, entry)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("expected %+v, got %+v", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestEntryServer_Invalid(t *testing.T) {
+	for name, entry := range map[string]any{
+		"list":  []any{"npx"},
+		"empty": map[string]any{"args": []any{"-y"}},
+	} {
+		if _, err := EntryServer(name, entry); err == nil {
+			t.Errorf("expected %s entry to fail", name)
+		}
+	}
+}
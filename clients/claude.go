@@ -1,9 +1,7 @@
 package clients
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
 	"path/filepath"
 	"runtime"
 
@@ -17,50 +15,62 @@ var (
 	getClaudeCodeLocalPath     = getClaudeCodeLocalPathImpl
 )
 
-func init() {
-	RegisterClient(&Client{
+// newClaudeDesktopClient returns the Claude Desktop client
+func newClaudeDesktopClient() *Client {
+	return &Client{
 		Name:          "claude-desktop",
 		DisplayName:   "Claude Desktop",
 		GlobalPath:    func() (string, error) { return getClaudeDesktopConfigPath() },
 		LocalPath:     nil,
 		SupportsLocal: false,
 		SyncFunc:      syncToMCPConfig,
-	})
+		// Claude Desktop only reads remote servers from its connectors UI
+		StdioOnly: true,
+		Schema:    embeddedSchema("claude-desktop"),
+	}
+}
 
-	RegisterClient(&Client{
+// newClaudeCodeClient returns the Claude Code client
+func newClaudeCodeClient() *Client {
+	return &Client{
 		Name:          "claude-code",
 		DisplayName:   "Claude Code",
 		GlobalPath:    func() (string, error) { return getClaudeCodeConfigPath() },
 		LocalPath:     func() (string, error) { return getClaudeCodeLocalPath() },
 		SupportsLocal: true,
 		SyncFunc:      syncToClaudeCode,
-	})
+	}
 }
 
 func getClaudeDesktopConfigPathImpl() (string, error) {
-	home, err := os.UserHomeDir()
+	home, err := userHomeDir()
 	if err != nil {
 		return "", err
 	}
 
-	switch runtime.GOOS {
+	switch currentOS() {
 	case "darwin":
 		return filepath.Join(home, "Library", "Application Support", "Claude", "claude_desktop_config.json"), nil
 	case "windows":
-		appData := os.Getenv("APPDATA")
+		appData := getenv("APPDATA")
 		if appData == "" {
 			appData = filepath.Join(home, "AppData", "Roaming")
 		}
 		return filepath.Join(appData, "Claude", "claude_desktop_config.json"), nil
 	case "linux":
-		return filepath.Join(home, ".config", "Claude", "claude_desktop_config.json"), nil
+		return filepath.Join(xdgConfigHome(home), "Claude", "claude_desktop_config.json"), nil
 	default:
 		return "", fmt.Errorf("unsupported operating system: %s", runtime.GOOS)
 	}
 }
 
 func getClaudeCodeConfigPathImpl() (string, error) {
-	home, err := os.UserHomeDir()
+	// Claude Code keeps its config in $CLAUDE_CONFIG_DIR when set
+	if dir := getenv("CLAUDE_CONFIG_DIR"); dir != "" {
+		return filepath.Join(dir, ".claude.json"), nil
+	}
+
+	home, err := userHomeDir()
 	if err != nil {
 		return "", err
 	}
@@ -69,7 +79,7 @@ func getClaudeCodeConfigPathImpl() (string, error) {
 }
 
 func getClaudeCodeLocalPathImpl() (string, error) {
-	cwd, err := os.Getwd()
+	cwd, err := workingDir()
 	if err != nil {
 		return "", err
 	}
@@ -77,41 +87,16 @@ func getClaudeCodeLocalPathImpl() (string, error) {
 }
 
 func syncToClaudeCode(servers []config.MCPServer, path string) error {
-	var settings map[string]any
-	data, err := os.ReadFile(path)
-	if os.IsNotExist(err) {
-		settings = make(map[string]any)
-	} else if err != nil {
-		return fmt.Errorf("failed to read config: %w", err)
-	} else {
-		if err := json.Unmarshal(data, &settings); err != nil {
-			return fmt.Errorf("failed to parse config: %w", err)
-		}
-	}
+	return syncToSettingsWithEntries(servers, path, "mcpServers", claudeCodeEntry)
+}
 
-	mcpServers := make(map[string]any)
-	for _, server := range servers {
-		entry := make(map[string]any)
-		if server.Type == "http" {
-			entry["type"] = "http"
-			entry["url"] = server.URL
-			if len(server.Headers) > 0 {
-				entry["headers"] = server.Headers
-			}
-		} else {
-			entry["type"] = "stdio"
-			entry["command"] = server.Command
-			if len(server.Args) > 0 {
-				entry["args"] = server.Args
-			}
-			if len(server.Env) > 0 {
-				entry["env"] = server.Env
-			}
-		}
-		mcpServers[server.Name] = entry
+// claudeCodeEntry builds a Claude Code entry, which names its transport type
+func claudeCodeEntry(server config.MCPServer) map[string]any {
+	entry := settingsEntry(server)
+	if server.Type == "http" {
+		entry["type"] = "http"
+	} else {
+		entry["type"] = "stdio"
 	}
-
-	settings["mcpServers"] = mcpServers
-
-	return saveSettingsFile(path, settings)
+	return entry
 }
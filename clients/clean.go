@@ -0,0 +1,156 @@
+package clients
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jrandolf/mcpr/config"
+)
+
+// ServersBlockKey returns the top-level key holding the client's server
+// entries
+func (c *Client) ServersBlockKey() string {
+	if c.ServersKey != "" {
+		return c.ServersKey
+	}
+	return "mcpServers"
+}
+
+// EmptyConfig reports why the client config at path is an empty leftover of
+// mcpr: a zero-byte file from a failed write, or a servers block with no
+// servers in it. It returns "" for configs that aren't, including missing
+// and unparseable ones.
+func (c *Client) EmptyConfig(path string) (string, error) {
+	data, err := os.ReadFile(longPath(path))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if len(data) == 0 {
+		return "zero-byte file", nil
+	}
+
+	var doc map[string]any
+	if err := config.Unmarshal(data, config.FormatForPath(path), &doc); err != nil {
+		return "", nil
+	}
+	servers, ok := doc[c.ServersBlockKey()]
+	if !ok || !isEmptyBlock(servers) {
+		return "", nil
+	}
+	// Rewriting TOML would drop its comments, so only files holding
+	// nothing else are cleaned
+	if config.FormatForPath(path) == config.FormatTOML && len(doc) > 1 {
+		return "", nil
+	}
+	return fmt.Sprintf("empty %s block", c.ServersBlockKey()), nil
+}
+
+// CleanConfig removes the empty servers block from the client config at
+// path, removing the file if nothing else is left in it, and reports
+// whether the file was removed
+func (c *Client) CleanConfig(path string) (bool, error) {
+	data, err := os.ReadFile(longPath(path))
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	doc := make(map[string]any)
+	if len(data) > 0 {
+		if err := config.Unmarshal(data, config.FormatForPath(path), &doc); err != nil {
+			return false, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		if servers, ok := doc[c.ServersBlockKey()]; ok && isEmptyBlock(servers) {
+			delete(doc, c.ServersBlockKey())
+		}
+	}
+
+	if len(doc) == 0 {
+		if err := os.Remove(longPath(path)); err != nil {
+			return false, fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+		return true, nil
+	}
+	if err := writeDocument(longPath(path), emitterForPath(path, jsoncEmitter), doc, data, false); err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
+// isEmptyBlock reports whether a servers block holds no servers
+func isEmptyBlock(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case map[string]any:
+		return len(v) == 0
+	case []any:
+		return len(v) == 0
+	default:
+		return false
+	}
+}
+
+// LocalPathIn returns the client's local config path for the project in dir
+// rather than the current directory
+func (c *Client) LocalPathIn(dir string) (string, error) {
+	path, err := c.Path(true)
+	if err != nil {
+		return "", err
+	}
+	cwd, err := workingDir()
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(cwd, path)
+	if err != nil || !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("local config of %s is %s, outside the project directory", c.DisplayName, path)
+	}
+	return filepath.Join(dir, rel), nil
+}
+
+// StaleSnapshots returns the snapshots past their retention: those beyond
+// the newest snapshotLimit of a client config, and the whole snapshot
+// directory of a config that wasn't synced within maxAge
+func StaleSnapshots(maxAge time.Duration) ([]string, error) {
+	state, err := config.StateDir()
+	if err != nil {
+		return nil, err
+	}
+	root := filepath.Join(state, "snapshots")
+	entries, err := os.ReadDir(root)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshots: %w", err)
+	}
+
+	var stale []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		dir := filepath.Join(root, entry.Name())
+		snapshots, err := listSnapshots(dir)
+		if err != nil {
+			return nil, err
+		}
+		if len(snapshots) == 0 {
+			stale = append(stale, dir)
+			continue
+		}
+		if info, err := os.Stat(snapshots[len(snapshots)-1]); err == nil && time.Since(info.ModTime()) > maxAge {
+			stale = append(stale, dir)
+			continue
+		}
+		if len(snapshots) > snapshotLimit {
+			stale = append(stale, snapshots[:len(snapshots)-snapshotLimit]...)
+		}
+	}
+	return stale, nil
+}
@@ -0,0 +1,118 @@
+package clients
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEmptyConfigAndClean(t *testing.T) {
+	cursor, _ := GetClient("cursor")
+	zed, _ := GetClient("zed")
+	dir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		client  *Client
+		content string
+		reason  string
+		removed bool
+		left    string
+	}{
+		{name: "zero-byte", client: cursor, content: "", reason: "zero-byte file", removed: true},
+		{name: "only empty block", client: cursor, content: `{"mcpServers": {}}`, reason: "empty mcpServers block", removed: true},
+		{name: "empty block with settings", client: zed, content: "{\n  // theme\n  \"theme\": \"dark\",\n  \"context_servers\": {}\n}", reason: "empty context_servers block", left: "// theme"},
+		{name: "servers", client: cursor, content: `{"mcpServers": {"a": {}}}`},
+		{name: "unparseable", client: cursor, content: `{broken`},
+		{name: "no block", client: cursor, content: `{"other": 1}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, strings.ReplaceAll(tt.name, " ", "-")+".json")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			reason, err := tt.client.EmptyConfig(path)
+			if err != nil || reason != tt.reason {
+				t.Fatalf("expected reason %q, got %q, %v", tt.reason, reason, err)
+			}
+			if reason == "" {
+				return
+			}
+
+			removed, err := tt.client.CleanConfig(path)
+			if err != nil || removed != tt.removed {
+				t.Fatalf("expected removed=%v, got %v, %v", tt.removed, removed, err)
+			}
+			if removed {
+				if _, err := os.Stat(path); !os.IsNotExist(err) {
+					t.Error("expected file to be removed")
+				}
+				return
+			}
+			data, _ := os.ReadFile(path)
+			if strings.Contains(string(data), tt.client.ServersBlockKey()) || !strings.Contains(string(data), tt.left) {
+				t.Errorf("expected empty block to be dropped and %q kept, got:\n%s", tt.left, data)
+			}
+		})
+	}
+
+	if reason, err := cursor.EmptyConfig(filepath.Join(dir, "missing.json")); err != nil || reason != "" {
+		t.Errorf("expected missing config not to be reported, got %q, %v", reason, err)
+	}
+}
+
+func TestLocalPathIn(t *testing.T) {
+	client, _ := GetClient("cursor")
+	dir := t.TempDir()
+	path, err := client.LocalPathIn(dir)
+	if err != nil || path != filepath.Join(dir, ".cursor", "mcp.json") {
+		t.Errorf("expected .cursor/mcp.json in %s, got %q, %v", dir, path, err)
+	}
+
+	t.Setenv(client.EnvOverride(true), filepath.Join(t.TempDir(), "mcp.json"))
+	if _, err := client.LocalPathIn(dir); err == nil {
+		t.Error("expected an overridden local path not to be relocated")
+	}
+}
+
+func TestStaleSnapshots(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	client, _ := GetClient("cursor")
+
+	// Recent config with more snapshots than are kept
+	recent := filepath.Join(t.TempDir(), "mcp.json")
+	recentDir, _ := client.snapshotDir(recent)
+	os.MkdirAll(recentDir, 0o700)
+	for i := range snapshotLimit + 1 {
+		os.WriteFile(filepath.Join(recentDir, "2025010"+string(rune('0'+i))), nil, 0o600)
+	}
+
+	// Config not synced for long
+	old := filepath.Join(t.TempDir(), "mcp.json")
+	os.WriteFile(old, []byte(`{"mcpServers":{}}`), 0644)
+	if err := client.Snapshot(old); err != nil {
+		t.Fatal(err)
+	}
+	oldDir, _ := client.snapshotDir(old)
+	snapshots, _ := listSnapshots(oldDir)
+	past := time.Now().Add(-48 * time.Hour)
+	os.Chtimes(snapshots[0], past, past)
+
+	stale, err := StaleSnapshots(24 * time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]bool{filepath.Join(recentDir, "20250100"): true, oldDir: true}
+	if len(stale) != len(want) {
+		t.Fatalf("expected %d stale entries, got %v", len(want), stale)
+	}
+	for _, path := range stale {
+		if !want[path] {
+			t.Errorf("unexpected stale entry %s", path)
+		}
+	}
+}
@@ -1,7 +1,9 @@
 package clients
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -194,75 +196,6 @@ func TestMCPClientConfig(t *testing.T) {
 	}
 }
 
-func TestSaveMCPConfig(t *testing.T) {
-	tempDir, err := os.MkdirTemp("", "mcpr-test-*")
-	if err != nil {
-		t.Fatalf("failed to create temp dir: %v", err)
-	}
-	defer os.RemoveAll(tempDir)
-
-	configPath := filepath.Join(tempDir, "config.json")
-
-	cfg := &MCPClientConfig{
-		MCPServers: map[string]MCPServerEntry{
-			"test-server": {
-				Command: "npx",
-				Args:    []string{"-y", "test"},
-				Env:     map[string]string{"KEY": "value"},
-			},
-		},
-	}
-
-	err = saveMCPConfig(configPath, cfg)
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
-	}
-
-	// Verify file was created
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		t.Fatal("config file was not created")
-	}
-
-	// Verify content
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		t.Fatalf("failed to read config file: %v", err)
-	}
-
-	var loadedCfg MCPClientConfig
-	err = json.Unmarshal(data, &loadedCfg)
-	if err != nil {
-		t.Fatalf("failed to parse config file: %v", err)
-	}
-
-	if len(loadedCfg.MCPServers) != 1 {
-		t.Errorf("expected 1 server, got %d", len(loadedCfg.MCPServers))
-	}
-}
-
-func TestSaveMCPConfig_CreatesDirectory(t *testing.T) {
-	tempDir, err := os.MkdirTemp("", "mcpr-test-*")
-	if err != nil {
-		t.Fatalf("failed to create temp dir: %v", err)
-	}
-	defer os.RemoveAll(tempDir)
-
-	configPath := filepath.Join(tempDir, "nested", "dir", "config.json")
-
-	cfg := &MCPClientConfig{
-		MCPServers: map[string]MCPServerEntry{},
-	}
-
-	err = saveMCPConfig(configPath, cfg)
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
-	}
-
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		t.Fatal("config file was not created")
-	}
-}
-
 func TestSyncToMCPConfig(t *testing.T) {
 	tempDir, err := os.MkdirTemp("", "mcpr-test-*")
 	if err != nil {
@@ -704,6 +637,53 @@ func TestClientSync_Local(t *testing.T) {
 	}
 }
 
+func TestClientSyncContext_Canceled(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "mcp.json")
+	originalFunc := getCursorConfigPath
+	getCursorConfigPath = func() (string, error) { return configPath, nil }
+	defer func() { getCursorConfigPath = originalFunc }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	client, _ := GetClient("cursor")
+	servers := []config.MCPServer{{Name: "fs", Type: "stdio", Command: "npx"}}
+	if _, err := client.SyncContext(ctx, servers, false); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if _, err := os.Stat(configPath); !os.IsNotExist(err) {
+		t.Error("expected config not to be written")
+	}
+}
+
+func TestResolveServer(t *testing.T) {
+	originalFunc := getWorkingDir
+	getWorkingDir = func() (string, error) { return "/work/project", nil }
+	defer func() { getWorkingDir = originalFunc }()
+
+	envFile := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(envFile, []byte("TOKEN=secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	server, err := ResolveServer(config.MCPServer{
+		Name:    "fs",
+		Type:    "stdio",
+		Command: "npx",
+		Args:    []string{"${workspaceFolder}/data"},
+		EnvFile: envFile,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if server.Args[0] != "/work/project/data" {
+		t.Errorf("expected workspace placeholder to be expanded, got %q", server.Args[0])
+	}
+	if server.Env["TOKEN"] != "secret" || server.EnvFile != "" {
+		t.Errorf("expected env file to be inlined, got env %v, envFile %q", server.Env, server.EnvFile)
+	}
+}
+
 func TestClientSync_LocalNotSupported(t *testing.T) {
 	client, _ := GetClient("claude-desktop")
 	servers := []config.MCPServer{
@@ -1092,6 +1072,262 @@ func TestKiloCodeLocalPath(t *testing.T) {
 	}
 }
 
+func TestSyncToGemini_Description(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "mcpr-test-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	configPath := filepath.Join(tempDir, "settings.json")
+
+	servers := []config.MCPServer{
+		{Name: "documented", Type: "stdio", Command: "npx", Description: "Reads project files"},
+		{Name: "plain", Type: "http", URL: "https://example.com/mcp"},
+	}
+
+	if err := syncToGemini(servers, configPath); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+
+	var cfg map[string]map[string]map[string]any
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+
+	if got := cfg["mcpServers"]["documented"]["description"]; got != "Reads project files" {
+		t.Errorf("expected description 'Reads project files', got %v", got)
+	}
+	if _, ok := cfg["mcpServers"]["plain"]["description"]; ok {
+		t.Error("expected no description for server without one")
+	}
+}
+
+func TestSyncToGemini_TrustAndTimeout(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "settings.json")
+
+	servers := []config.MCPServer{
+		{Name: "trusted", Type: "stdio", Command: "npx", Trust: true, Timeout: 30},
+		{Name: "plain", Type: "stdio", Command: "npx"},
+	}
+	if err := syncToGemini(servers, configPath); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, _ := os.ReadFile(configPath)
+	var cfg map[string]map[string]map[string]any
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+
+	trusted := cfg["mcpServers"]["trusted"]
+	if trusted["trust"] != true {
+		t.Errorf("expected trust true, got %v", trusted["trust"])
+	}
+	if trusted["timeout"] != float64(30000) {
+		t.Errorf("expected timeout 30000ms, got %v", trusted["timeout"])
+	}
+	plain := cfg["mcpServers"]["plain"]
+	if _, ok := plain["trust"]; ok {
+		t.Error("expected no trust field for untrusted server")
+	}
+	if _, ok := plain["timeout"]; ok {
+		t.Error("expected no timeout field without a timeout")
+	}
+}
+
+func TestGeminiExtensionServers(t *testing.T) {
+	dir := t.TempDir()
+	extDir := filepath.Join(dir, "github")
+	os.MkdirAll(extDir, 0o755)
+	os.WriteFile(filepath.Join(extDir, "gemini-extension.json"), []byte(`{
+  "name": "github-ext",
+  "mcpServers": {"github": {"command": "gh-mcp"}}
+}`), 0o644)
+
+	provided := geminiExtensionServers(dir)
+	if provided["github"] != "github-ext" {
+		t.Errorf("expected github to be provided by github-ext, got %v", provided)
+	}
+
+	if len(geminiExtensionServers(filepath.Join(dir, "missing"))) != 0 {
+		t.Error("expected no servers for missing extensions directory")
+	}
+}
+
+func TestSyncToCline_PreservesClientFields(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "cline_mcp_settings.json")
+	existing := `{
+  "mcpServers": {
+    "fs": {"command": "old", "args": ["stale"], "autoApprove": ["read_file"], "alwaysAllow": ["list"], "timeout": 120},
+    "removed": {"command": "gone"}
+  },
+  "theme": "dark"
+}`
+	os.WriteFile(configPath, []byte(existing), 0o644)
+
+	servers := []config.MCPServer{
+		{Name: "fs", Type: "stdio", Command: "npx", AlwaysAllow: []string{"read_file", "write_file"}},
+		{Name: "slow", Type: "stdio", Command: "slow", Timeout: 300, Disabled: true},
+	}
+	if err := syncToCline(servers, configPath); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, _ := os.ReadFile(configPath)
+	var cfg map[string]any
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+	if cfg["theme"] != "dark" {
+		t.Error("expected other settings to be preserved")
+	}
+
+	mcpServers := cfg["mcpServers"].(map[string]any)
+	if _, ok := mcpServers["removed"]; ok {
+		t.Error("expected unsynced server to be removed")
+	}
+
+	fs := mcpServers["fs"].(map[string]any)
+	if fs["command"] != "npx" {
+		t.Errorf("expected command to be replaced, got %v", fs["command"])
+	}
+	if _, ok := fs["args"]; ok {
+		t.Error("expected stale args to be dropped")
+	}
+	if fs["timeout"] != float64(120) {
+		t.Errorf("expected existing timeout to be kept, got %v", fs["timeout"])
+	}
+	if allow, _ := fs["alwaysAllow"].([]any); len(allow) != 2 {
+		t.Errorf("expected alwaysAllow from mcpr, got %v", fs["alwaysAllow"])
+	}
+	if approve, _ := fs["autoApprove"].([]any); len(approve) != 1 {
+		t.Errorf("expected unknown fields to be kept, got %v", fs["autoApprove"])
+	}
+
+	slow := mcpServers["slow"].(map[string]any)
+	if slow["disabled"] != true || slow["timeout"] != float64(300) {
+		t.Errorf("expected disabled and timeout, got %v", slow)
+	}
+}
+
+func TestClientSync_SkipsDisabledServers(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "mcp.json")
+	originalFunc := getCursorConfigPath
+	getCursorConfigPath = func() (string, error) { return configPath, nil }
+	defer func() { getCursorConfigPath = originalFunc }()
+
+	client, _ := GetClient("cursor")
+	servers := []config.MCPServer{
+		{Name: "on", Type: "stdio", Command: "npx"},
+		{Name: "off", Type: "stdio", Command: "npx", Disabled: true},
+	}
+	if _, err := client.Sync(servers, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, _ := os.ReadFile(configPath)
+	var cfg MCPClientConfig
+	json.Unmarshal(data, &cfg)
+	if _, ok := cfg.MCPServers["off"]; ok {
+		t.Error("expected disabled server to be left out")
+	}
+	if _, ok := cfg.MCPServers["on"]; !ok {
+		t.Error("expected enabled server to be synced")
+	}
+}
+
+func TestClientSync_StdioOnlySkipsHTTP(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "claude_desktop_config.json")
+	originalFunc := getClaudeDesktopConfigPath
+	getClaudeDesktopConfigPath = func() (string, error) { return configPath, nil }
+	defer func() { getClaudeDesktopConfigPath = originalFunc }()
+
+	client, _ := GetClient("claude-desktop")
+	servers := []config.MCPServer{
+		{Name: "local", Type: "stdio", Command: "npx"},
+		{Name: "remote", Type: "http", URL: "https://example.com/mcp"},
+	}
+	if _, err := client.Sync(servers, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, _ := os.ReadFile(configPath)
+	var cfg MCPClientConfig
+	json.Unmarshal(data, &cfg)
+	if _, ok := cfg.MCPServers["remote"]; ok {
+		t.Error("expected http server to be skipped")
+	}
+	if _, ok := cfg.MCPServers["local"]; !ok {
+		t.Error("expected stdio server to be synced")
+	}
+}
+
+func TestSyncToZencoder_PreservesSettings(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "mcp_settings.json")
+	os.WriteFile(configPath, []byte(`{
+  "mcpServers": {"fs": {"command": "old", "zencoderAgent": "coder"}},
+  "version": 2
+}`), 0o644)
+
+	servers := []config.MCPServer{{Name: "fs", Type: "stdio", Command: "npx", Args: []string{"-y", "fs"}}}
+	if err := syncToZencoder(servers, configPath); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, _ := os.ReadFile(configPath)
+	var cfg map[string]any
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+	if cfg["version"] != float64(2) {
+		t.Error("expected extension settings to be preserved")
+	}
+	fs := cfg["mcpServers"].(map[string]any)["fs"].(map[string]any)
+	if fs["command"] != "npx" {
+		t.Errorf("expected command to be replaced, got %v", fs["command"])
+	}
+	if fs["zencoderAgent"] != "coder" {
+		t.Errorf("expected extension-specific entry field to be kept, got %v", fs["zencoderAgent"])
+	}
+}
+
+func TestSyncToKiloCode_PreservesSettings(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "mcp_settings.json")
+	os.WriteFile(configPath, []byte(`{
+  "mcpServers": {"fs": {"command": "old", "alwaysAllow": ["read_file"], "disabled": true}}
+}`), 0o644)
+
+	servers := []config.MCPServer{
+		{Name: "fs", Type: "stdio", Command: "npx"},
+		{Name: "api", Type: "http", URL: "https://example.com/mcp", Timeout: 90},
+	}
+	if err := syncToKiloCode(servers, configPath); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, _ := os.ReadFile(configPath)
+	var cfg map[string]map[string]map[string]any
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+	fs := cfg["mcpServers"]["fs"]
+	if fs["command"] != "npx" || fs["disabled"] != true {
+		t.Errorf("expected command replaced and disabled kept, got %v", fs)
+	}
+	if allow, _ := fs["alwaysAllow"].([]any); len(allow) != 1 {
+		t.Errorf("expected alwaysAllow to be kept, got %v", fs["alwaysAllow"])
+	}
+	if cfg["mcpServers"]["api"]["timeout"] != float64(90) {
+		t.Errorf("expected timeout 90, got %v", cfg["mcpServers"]["api"]["timeout"])
+	}
+}
+
 func TestSyncToCodex(t *testing.T) {
 	tempDir, err := os.MkdirTemp("", "mcpr-test-*")
 	if err != nil {
@@ -1250,6 +1486,45 @@ func TestSyncIdempotency_MCPConfig(t *testing.T) {
 	}
 }
 
+func TestSyncRoundTrip_MCPConfig(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	servers := []config.MCPServer{
+		{
+			Name:    "server-a",
+			Type:    "stdio",
+			Command: "cmd-a",
+			Args:    []string{"arg1", "arg2"},
+			Env:     map[string]string{"KEY_Z": "val_z", "KEY_A": "val_a"},
+		},
+		{
+			Name:    "server-b",
+			Type:    "http",
+			URL:     "https://example.com/mcp",
+			Headers: map[string]string{"X-B": "b", "X-A": "a"},
+		},
+	}
+	if err := syncToMCPConfig(servers, configPath); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+
+	importer, err := GetImporter("mcp-get")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, imported, err := importer.Import(configPath)
+	if err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	if len(imported) != len(servers) {
+		t.Fatalf("expected %d servers back, got %d", len(servers), len(imported))
+	}
+	for i, server := range servers {
+		if !server.Equal(imported[i]) {
+			t.Errorf("expected %s to read back as synced:\nsynced:   %+v\nimported: %+v", server.Name, server, imported[i])
+		}
+	}
+}
+
 func TestSyncIdempotency_Codex(t *testing.T) {
 	tempDir, err := os.MkdirTemp("", "mcpr-test-*")
 	if err != nil {
@@ -1649,3 +1924,41 @@ func TestSyncIdempotency_OpenCode(t *testing.T) {
 		t.Errorf("OpenCode sync is not idempotent:\nFirst:\n%s\n\nSecond:\n%s", firstContent, secondContent)
 	}
 }
+
+func TestCreateConfig(t *testing.T) {
+	for _, name := range ListClientNames() {
+		client, _ := GetClient(name)
+		if client.SyncFunc == nil {
+			continue
+		}
+		t.Run(name, func(t *testing.T) {
+			path, _ := client.ConfigPath()
+			path = filepath.Join(t.TempDir(), filepath.Base(path))
+
+			created, err := client.CreateConfig(path)
+			if err != nil || !created {
+				t.Fatalf("expected the config to be created, got %v, %v", created, err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var doc map[string]any
+			if err := config.Unmarshal(data, config.FormatForPath(path), &doc); err != nil {
+				t.Errorf("expected a valid config, got %v:\n%s", err, data)
+			}
+			if servers := client.ParseServerEntries(path, data); len(servers) != 0 {
+				t.Errorf("expected no servers, got %v", servers)
+			}
+
+			// An existing config is left alone
+			os.WriteFile(path, []byte("edited"), 0o644)
+			if created, err := client.CreateConfig(path); err != nil || created {
+				t.Errorf("expected the existing config to be kept, got %v, %v", created, err)
+			}
+			if data, _ := os.ReadFile(path); string(data) != "edited" {
+				t.Errorf("expected the existing config to be untouched, got %q", data)
+			}
+		})
+	}
+}
@@ -2,9 +2,10 @@ package clients
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 	"runtime"
+
+	"github.com/jrandolf/mcpr/config"
 )
 
 // Path functions as variables for testing
@@ -12,35 +13,60 @@ var (
 	getClineConfigPath = getClineConfigPathImpl
 )
 
-func init() {
-	RegisterClient(&Client{
+// newClineClient returns the Cline client
+func newClineClient() *Client {
+	return &Client{
 		Name:          "cline",
 		DisplayName:   "Cline",
 		GlobalPath:    func() (string, error) { return getClineConfigPath() },
 		LocalPath:     nil,
 		SupportsLocal: false,
-		SyncFunc:      syncToMCPConfig,
-	})
+		SyncFunc:      syncToCline,
+		// Cline has its own per-server disabled toggle
+		SupportsDisabled: true,
+	}
 }
 
 func getClineConfigPathImpl() (string, error) {
-	home, err := os.UserHomeDir()
+	home, err := userHomeDir()
 	if err != nil {
 		return "", err
 	}
 
-	switch runtime.GOOS {
+	switch currentOS() {
 	case "darwin":
 		return filepath.Join(home, "Library", "Application Support", "Code", "User", "globalStorage", "saoudrizwan.claude-dev", "settings", "cline_mcp_settings.json"), nil
 	case "windows":
-		appData := os.Getenv("APPDATA")
+		appData := getenv("APPDATA")
 		if appData == "" {
 			appData = filepath.Join(home, "AppData", "Roaming")
 		}
 		return filepath.Join(appData, "Code", "User", "globalStorage", "saoudrizwan.claude-dev", "settings", "cline_mcp_settings.json"), nil
 	case "linux":
-		return filepath.Join(home, ".config", "Code", "User", "globalStorage", "saoudrizwan.claude-dev", "settings", "cline_mcp_settings.json"), nil
+		return filepath.Join(xdgConfigHome(home), "Code", "User", "globalStorage", "saoudrizwan.claude-dev", "settings", "cline_mcp_settings.json"), nil
 	default:
 		return "", fmt.Errorf("unsupported operating system: %s", runtime.GOOS)
 	}
 }
+
+// syncToCline syncs servers to Cline's settings, which also accept disabled,
+// a timeout in seconds and alwaysAllow per server. Values mcpr doesn't set,
+// such as tools approved in Cline's UI, are kept from the existing entry.
+func syncToCline(servers []config.MCPServer, path string) error {
+	return syncPreservingEntries(servers, path, "mcpServers", clineEntry)
+}
+
+// clineEntry builds a Cline-style server entry
+func clineEntry(server config.MCPServer) map[string]any {
+	entry := settingsEntry(server)
+	if server.Disabled {
+		entry["disabled"] = true
+	}
+	if server.Timeout > 0 {
+		entry["timeout"] = server.Timeout
+	}
+	if len(server.AlwaysAllow) > 0 {
+		entry["alwaysAllow"] = server.AlwaysAllow
+	}
+	return entry
+}
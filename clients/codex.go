@@ -2,9 +2,10 @@ package clients
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
+	"slices"
 	"sort"
+	"strings"
 
 	"github.com/jrandolf/mcpr/config"
 )
@@ -14,22 +15,24 @@ var (
 	getCodexConfigPath = getCodexConfigPathImpl
 )
 
-func init() {
-	RegisterClient(&Client{
+// newCodexClient returns the Codex (OpenAI) client
+func newCodexClient() *Client {
+	return &Client{
 		Name:          "codex",
 		DisplayName:   "Codex (OpenAI)",
 		GlobalPath:    func() (string, error) { return getCodexConfigPath() },
 		LocalPath:     nil,
 		SupportsLocal: false,
 		SyncFunc:      syncToCodex,
-	})
+		ServersKey:    "mcp_servers",
+	}
 }
 
 func getCodexConfigPathImpl() (string, error) {
 	// Check CODEX_HOME env var first
-	codexHome := os.Getenv("CODEX_HOME")
+	codexHome := getenv("CODEX_HOME")
 	if codexHome == "" {
-		home, err := os.UserHomeDir()
+		home, err := userHomeDir()
 		if err != nil {
 			return "", err
 		}
@@ -39,22 +42,103 @@ func getCodexConfigPathImpl() (string, error) {
 }
 
 func syncToCodex(servers []config.MCPServer, path string) error {
-	var existingContent string
-	data, err := os.ReadFile(path)
-	if os.IsNotExist(err) {
-		existingContent = ""
-	} else if err != nil {
-		return fmt.Errorf("failed to read config: %w", err)
-	} else {
-		existingContent = string(data)
-	}
-
-	// Parse existing content and remove existing [mcp_servers.*] sections
-	lines := tomlSplitLines(existingContent)
+	return syncFile(path, codexEmitter{}, config.HasSecrets(servers), func(doc map[string]any) {
+		doc["mcp_servers"] = serverEntries(servers, codexEntry)
+	})
+}
+
+// codexEntry builds a Codex mcp_servers table for a server
+func codexEntry(server config.MCPServer) map[string]any {
+	if server.Type == "http" {
+		entry := map[string]any{
+			"url": server.URL,
+		}
+		if len(server.Headers) > 0 {
+			entry["http_headers"] = server.Headers
+		}
+		return entry
+	}
+
+	entry := map[string]any{
+		"command": server.Command,
+	}
+	if len(server.Args) > 0 {
+		entry["args"] = server.Args
+	}
+	if len(server.Env) > 0 {
+		entry["env"] = server.Env
+	}
+	return entry
+}
+
+// codexKeyOrder is the order known keys are written in a server's table;
+// other keys follow alphabetically
+var codexKeyOrder = []string{"command", "url", "args", "env", "http_headers"}
+
+// codexEmitter edits Codex's config.toml in place: the [mcp_servers.*]
+// tables are regenerated from the document, and the rest of the file,
+// comments included, is kept as written
+type codexEmitter struct{}
+
+// Decode parses the file without its [mcp_servers.*] tables. They are
+// regenerated on every sync, and skipping them keeps syncing large server
+// sets fast.
+func (codexEmitter) Decode(data []byte) (map[string]any, error) {
+	return tomlEmitter.Decode([]byte(tomlJoinLines(withoutMCPServers(string(data)))))
+}
+
+func (codexEmitter) Encode(doc map[string]any, original []byte) ([]byte, error) {
+	filteredLines := withoutMCPServers(string(original))
+
+	// Build new MCP servers sections
+	servers, _ := doc["mcp_servers"].(map[string]any)
+	names := make([]string, 0, len(servers))
+	for name := range servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var mcpSections []string
+	for _, name := range names {
+		entry, ok := servers[name].(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("server %q is not a table", name)
+		}
+		section := fmt.Sprintf("[mcp_servers.%s]\n", name)
+		for _, key := range codexKeys(entry) {
+			section += fmt.Sprintf("%s = %s\n", key, tomlInlineValue(entry[key]))
+		}
+		mcpSections = append(mcpSections, section)
+	}
+
+	// Combine filtered content with new MCP sections
+	result := tomlJoinLines(filteredLines)
+	if len(mcpSections) > 0 {
+		// Exactly one blank line goes before the sections, so syncing again
+		// doesn't change the file
+		result = strings.TrimRight(result, "\r\n")
+		if result != "" {
+			result += "\n\n"
+		}
+	}
+
+	// Large configs have many sections, so they are joined in one buffer
+	var buf strings.Builder
+	buf.WriteString(result)
+	for _, section := range mcpSections {
+		buf.WriteString(section)
+		buf.WriteString("\n")
+	}
+	return []byte(buf.String()), nil
+}
+
+// withoutMCPServers splits TOML content into lines, leaving out the
+// [mcp_servers.*] sections
+func withoutMCPServers(content string) []string {
 	var filteredLines []string
 	inMcpSection := false
 
-	for _, line := range lines {
+	for _, line := range tomlSplitLines(content) {
 		trimmed := tomlTrimWhitespace(line)
 		if tomlHasPrefix(trimmed, "[mcp_servers.") {
 			inMcpSection = true
@@ -67,81 +151,64 @@ func syncToCodex(servers []config.MCPServer, path string) error {
 			filteredLines = append(filteredLines, line)
 		}
 	}
+	return filteredLines
+}
 
-	// Build new MCP servers sections
-	var mcpSections []string
-	for _, server := range servers {
-		if server.Type == "http" {
-			section := fmt.Sprintf("[mcp_servers.%s]\nurl = %q\n", server.Name, server.URL)
-			if len(server.Headers) > 0 {
-				section += "http_headers = { "
-				headerKeys := make([]string, 0, len(server.Headers))
-				for k := range server.Headers {
-					headerKeys = append(headerKeys, k)
-				}
-				sort.Strings(headerKeys)
-				for i, k := range headerKeys {
-					if i > 0 {
-						section += ", "
-					}
-					section += fmt.Sprintf("%q = %q", k, server.Headers[k])
-				}
-				section += " }\n"
-			}
-			mcpSections = append(mcpSections, section)
-		} else {
-			section := fmt.Sprintf("[mcp_servers.%s]\ncommand = %q\n", server.Name, server.Command)
-			if len(server.Args) > 0 {
-				section += "args = ["
-				for i, arg := range server.Args {
-					if i > 0 {
-						section += ", "
-					}
-					section += fmt.Sprintf("%q", arg)
-				}
-				section += "]\n"
-			}
-			if len(server.Env) > 0 {
-				section += "env = { "
-				envKeys := make([]string, 0, len(server.Env))
-				for k := range server.Env {
-					envKeys = append(envKeys, k)
-				}
-				sort.Strings(envKeys)
-				for i, k := range envKeys {
-					if i > 0 {
-						section += ", "
-					}
-					section += fmt.Sprintf("%q = %q", k, server.Env[k])
-				}
-				section += " }\n"
-			}
-			mcpSections = append(mcpSections, section)
+// codexKeys returns an entry's keys in the order they are written
+func codexKeys(entry map[string]any) []string {
+	var keys, rest []string
+	for _, key := range codexKeyOrder {
+		if _, ok := entry[key]; ok {
+			keys = append(keys, key)
 		}
 	}
-
-	// Combine filtered content with new MCP sections
-	result := tomlJoinLines(filteredLines)
-	if len(mcpSections) > 0 {
-		if result != "" && !tomlHasSuffix(result, "\n\n") {
-			if tomlHasSuffix(result, "\n") {
-				result += "\n"
-			} else {
-				result += "\n\n"
-			}
-		}
-		for _, section := range mcpSections {
-			result += section + "\n"
+	for key := range entry {
+		if !slices.Contains(codexKeyOrder, key) {
+			rest = append(rest, key)
 		}
 	}
+	sort.Strings(rest)
+	return append(keys, rest...)
+}
 
-	// Ensure directory exists
-	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0o755); err != nil {
-		return fmt.Errorf("failed to create config directory: %w", err)
+// tomlInlineValue formats a value as a TOML inline value. Arrays and tables
+// are written on one line, tables with their keys sorted.
+func tomlInlineValue(v any) string {
+	switch v := v.(type) {
+	case string:
+		return fmt.Sprintf("%q", v)
+	case []string:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = tomlInlineValue(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = tomlInlineValue(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case map[string]string:
+		table := make(map[string]any, len(v))
+		for k, val := range v {
+			table[k] = val
+		}
+		return tomlInlineValue(table)
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		pairs := make([]string, len(keys))
+		for i, k := range keys {
+			pairs[i] = fmt.Sprintf("%q = %s", k, tomlInlineValue(v[k]))
+		}
+		return "{ " + strings.Join(pairs, ", ") + " }"
+	default:
+		return fmt.Sprint(v)
 	}
-
-	return os.WriteFile(path, []byte(result), 0o644)
 }
 
 // TOML helper functions
@@ -165,17 +232,7 @@ func tomlSplitLines(s string) []string {
 }
 
 func tomlJoinLines(lines []string) string {
-	if len(lines) == 0 {
-		return ""
-	}
-	result := ""
-	for i, line := range lines {
-		if i > 0 {
-			result += "\n"
-		}
-		result += line
-	}
-	return result
+	return strings.Join(lines, "\n")
 }
 
 func tomlTrimWhitespace(s string) string {
@@ -1,10 +1,12 @@
 package clients
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
+	"maps"
 	"os"
-	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/jrandolf/mcpr/config"
 )
@@ -17,6 +19,34 @@ type Client struct {
 	LocalPath     func() (string, error) // nil if no local config supported
 	SupportsLocal bool
 	SyncFunc      func(servers []config.MCPServer, path string) error
+
+	// SupportsDisabled is set for clients with a per-server disabled flag;
+	// disabled servers are left out of other clients entirely
+	SupportsDisabled bool
+
+	// SupportsEnvFile is set for clients that read a server's envFile
+	// themselves; for others the file is inlined into env at sync time
+	SupportsEnvFile bool
+
+	// NativeVariables maps sync placeholders the client expands itself to
+	// its own syntax, e.g. "workspaceFolder" -> "${workspaceFolder}"
+	NativeVariables map[string]string
+
+	// StdioOnly is set for clients that can only launch local servers;
+	// http servers are skipped with a warning instead of being written
+	StdioOnly bool
+
+	// ServersKey is the top-level key holding the client's server entries,
+	// "mcpServers" when empty
+	ServersKey string
+
+	// Schema is the JSON Schema the client's config is checked against
+	// after a sync; nil skips the check
+	Schema []byte
+
+	// SchemaURL is where the client publishes the JSON Schema of its config,
+	// which FetchSchema downloads to check against instead of Schema
+	SchemaURL string
 }
 
 // MCPClientConfig represents the MCP configuration format used by clients
@@ -29,171 +59,244 @@ type MCPServerEntry struct {
 	Command string            `json:"command,omitempty"`
 	Args    []string          `json:"args,omitempty"`
 	Env     map[string]string `json:"env,omitempty"`
+	EnvFile string            `json:"envFile,omitempty"`
 	URL     string            `json:"url,omitempty"`
 	Headers map[string]string `json:"headers,omitempty"`
 }
 
-// clientRegistry holds all registered clients
-var clientRegistry = make(map[string]*Client)
-
-// RegisterClient adds a client to the registry
-func RegisterClient(client *Client) {
-	clientRegistry[client.Name] = client
-}
-
-// GetClients returns all supported MCP clients
-func GetClients() map[string]*Client {
-	return clientRegistry
-}
-
-// GetClient returns a specific client by name
-func GetClient(name string) (*Client, error) {
-	client, ok := clientRegistry[name]
-	if !ok {
-		return nil, fmt.Errorf("unknown client: %s", name)
+// ResolveServer returns server the way mcpr launches it when it connects
+// to a server itself: platform overrides and templates are applied,
+// placeholders are expanded for the current directory, env files are
+// inlined and the sandbox is applied
+func ResolveServer(server config.MCPServer) (config.MCPServer, error) {
+	self := &Client{Name: "mcpr", DisplayName: "mcpr"}
+	servers, err := self.expandVariables(applyPlatforms([]config.MCPServer{server}), true)
+	if err != nil {
+		return config.MCPServer{}, err
 	}
-	return client, nil
-}
-
-// ListClientNames returns all supported client names
-func ListClientNames() []string {
-	names := make([]string, 0, len(clientRegistry))
-	for name := range clientRegistry {
-		names = append(names, name)
+	servers, err = self.resolveEnvFiles(servers)
+	if err != nil {
+		return config.MCPServer{}, err
 	}
-	return names
+	servers = sandboxCommands(servers)
+	if len(servers) == 0 {
+		return config.MCPServer{}, fmt.Errorf("server %q is sandboxed, which isn't supported on %s", server.Name, goos)
+	}
+	return servers[0], nil
 }
 
 // Sync synchronizes MCP servers to the client, replacing the existing config
 func (c *Client) Sync(servers []config.MCPServer, local bool) (string, error) {
-	var path string
-	var err error
+	return c.SyncContext(context.Background(), servers, local)
+}
 
-	if local {
-		if !c.SupportsLocal {
-			return "", fmt.Errorf("%s does not support local config", c.DisplayName)
-		}
-		path, err = c.LocalPath()
-	} else {
-		path, err = c.GlobalPath()
+// SyncContext is like Sync but gives up before the client config is written
+// if ctx is canceled
+func (c *Client) SyncContext(ctx context.Context, servers []config.MCPServer, local bool) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
 	}
 
+	path, err := c.Path(local)
+	if err != nil {
+		return "", err
+	}
+	servers, err = c.prepareServers(servers, local)
 	if err != nil {
 		return "", err
 	}
 
-	if err := c.SyncFunc(servers, path); err != nil {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if err := c.SyncFunc(servers, longPath(path)); err != nil {
 		return "", err
 	}
 
 	return path, nil
 }
 
-// ConfigPath returns the global config path for display
-func (c *Client) ConfigPath() (string, error) {
-	return c.GlobalPath()
+// prepareServers turns servers into what is written to the client's config
+func (c *Client) prepareServers(servers []config.MCPServer, local bool) ([]config.MCPServer, error) {
+	servers = applyPlatforms(servers)
+	if !c.SupportsDisabled {
+		servers = enabledServers(servers)
+	}
+	if c.StdioOnly {
+		servers = c.stdioServers(servers)
+	}
+	servers, err := bridgeSockets(servers)
+	if err != nil {
+		return nil, err
+	}
+	servers = sandboxCommands(servers)
+	servers, err = c.expandVariables(servers, local)
+	if err != nil {
+		return nil, err
+	}
+	servers, err = c.resolveEnvFiles(servers)
+	if err != nil {
+		return nil, err
+	}
+	return shimCommands(servers), nil
 }
 
-// syncToMCPConfig syncs servers to a standard MCP config file (replaces entirely)
-func syncToMCPConfig(servers []config.MCPServer, path string) error {
-	cfg := &MCPClientConfig{
-		MCPServers: make(map[string]MCPServerEntry),
+// EnvOverride returns the environment variable that overrides the client's
+// global or local config path, e.g. MCPR_CLAUDE_DESKTOP_CONFIG or
+// MCPR_CURSOR_LOCAL_CONFIG
+func (c *Client) EnvOverride(local bool) string {
+	name := "MCPR_" + strings.ToUpper(strings.ReplaceAll(c.Name, "-", "_"))
+	if local {
+		name += "_LOCAL"
 	}
+	return name + "_CONFIG"
+}
 
-	for _, server := range servers {
-		entry := MCPServerEntry{}
-		if server.Type == "http" {
-			entry.URL = server.URL
-			entry.Headers = server.Headers
-		} else {
-			entry.Command = server.Command
-			entry.Args = server.Args
-			entry.Env = server.Env
-		}
-		cfg.MCPServers[server.Name] = entry
+// Path returns the client's global or local config path. The path can be
+// overridden with the client's EnvOverride variable.
+func (c *Client) Path(local bool) (string, error) {
+	if local && !c.SupportsLocal {
+		return "", fmt.Errorf("%s does not support local config", c.DisplayName)
 	}
 
-	return saveMCPConfig(path, cfg)
+	if override := getenv(c.EnvOverride(local)); override != "" {
+		return override, nil
+	}
+	if local {
+		return c.LocalPath()
+	}
+	return c.GlobalPath()
 }
 
-// syncToSettingsWithKey syncs servers to a settings file with a specific key (preserves other settings)
-func syncToSettingsWithKey(servers []config.MCPServer, path string, key string) error {
-	var settings map[string]any
-	data, err := os.ReadFile(path)
-	if os.IsNotExist(err) {
-		settings = make(map[string]any)
-	} else if err != nil {
-		return fmt.Errorf("failed to read config: %w", err)
-	} else {
-		if err := json.Unmarshal(data, &settings); err != nil {
-			return fmt.Errorf("failed to parse config: %w", err)
-		}
-	}
+// ConfigPath returns the global config path for display
+func (c *Client) ConfigPath() (string, error) {
+	return c.Path(false)
+}
 
-	mcpServers := make(map[string]any)
-	for _, server := range servers {
-		var entry map[string]any
-		if server.Type == "http" {
-			entry = map[string]any{
-				"url": server.URL,
-			}
-			if len(server.Headers) > 0 {
-				entry["headers"] = server.Headers
-			}
-		} else {
-			entry = map[string]any{
-				"command": server.Command,
-			}
-			if len(server.Args) > 0 {
-				entry["args"] = server.Args
-			}
-			if len(server.Env) > 0 {
-				entry["env"] = server.Env
-			}
-		}
-		mcpServers[server.Name] = entry
+// CreateConfig writes a config holding no servers, in the client's format,
+// to path unless a file is already there, and reports whether it did
+func (c *Client) CreateConfig(path string) (bool, error) {
+	if _, err := os.Stat(longPath(path)); err == nil {
+		return false, nil
+	} else if !os.IsNotExist(err) {
+		return false, fmt.Errorf("failed to check %s: %w", path, err)
+	}
+	if err := c.SyncFunc(nil, longPath(path)); err != nil {
+		return false, err
 	}
+	return true, nil
+}
 
-	settings[key] = mcpServers
+// syncToMCPConfig syncs servers to a standard MCP config file (replaces entirely)
+func syncToMCPConfig(servers []config.MCPServer, path string) error {
+	doc := map[string]any{
+		"mcpServers": serverEntries(servers, settingsEntry),
+	}
+	return replaceFile(path, jsonEmitter, doc, config.HasSecrets(servers))
+}
 
-	return saveSettingsFile(path, settings)
+// syncToSettingsWithKey syncs servers to a settings file with a specific key (preserves other settings)
+func syncToSettingsWithKey(servers []config.MCPServer, path string, key string) error {
+	return syncToSettingsWithEntries(servers, path, key, settingsEntry)
 }
 
-// syncToSettingsWithMcpServers syncs servers to a settings file with mcpServers key
-func syncToSettingsWithMcpServers(servers []config.MCPServer, path string) error {
-	return syncToSettingsWithKey(servers, path, "mcpServers")
+// syncToSettingsWithEntries syncs servers to a settings file with a specific
+// key, building each server's entry with entryFunc (preserves other settings)
+func syncToSettingsWithEntries(servers []config.MCPServer, path string, key string, entryFunc func(config.MCPServer) map[string]any) error {
+	return syncFile(path, jsonEmitter, config.HasSecrets(servers), func(doc map[string]any) {
+		doc[key] = serverEntries(servers, entryFunc)
+	})
 }
 
-// saveSettingsFile saves a settings map to disk
-func saveSettingsFile(path string, settings map[string]any) error {
-	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0o755); err != nil {
-		return fmt.Errorf("failed to create config directory: %w", err)
+// serverEntries builds each server's entry with entryFunc, keyed by name
+func serverEntries(servers []config.MCPServer, entryFunc func(config.MCPServer) map[string]any) map[string]any {
+	entries := make(map[string]any, len(servers))
+	for _, server := range servers {
+		entries[server.Name] = entryFunc(server)
 	}
+	return entries
+}
 
-	outData, err := json.MarshalIndent(settings, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal config: %w", err)
+// settingsEntry builds the standard command/url entry for a server
+func settingsEntry(server config.MCPServer) map[string]any {
+	var entry map[string]any
+	if server.Type == "http" {
+		entry = map[string]any{
+			"url": server.URL,
+		}
+		if len(server.Headers) > 0 {
+			entry["headers"] = server.Headers
+		}
+	} else {
+		entry = map[string]any{
+			"command": server.Command,
+		}
+		if len(server.Args) > 0 {
+			entry["args"] = server.Args
+		}
+		if len(server.Env) > 0 {
+			entry["env"] = server.Env
+		}
+		if server.EnvFile != "" {
+			entry["envFile"] = server.EnvFile
+		}
 	}
+	return entry
+}
 
-	return os.WriteFile(path, outData, 0o644)
+// syncPreservingEntries syncs servers to a settings file with a specific key.
+// Fields the client stores in an existing entry of the same name (e.g. tool
+// approvals) are kept; only the transport fields are replaced with
+// entryFunc's. Other settings are preserved, and servers that aren't synced
+// are removed.
+func syncPreservingEntries(servers []config.MCPServer, path string, key string, entryFunc func(config.MCPServer) map[string]any) error {
+	return syncFile(path, jsonEmitter, config.HasSecrets(servers), func(doc map[string]any) {
+		existing, _ := doc[key].(map[string]any)
+		doc[key] = serverEntries(servers, func(server config.MCPServer) map[string]any {
+			entry := make(map[string]any)
+			if old, ok := existing[server.Name].(map[string]any); ok {
+				for k, v := range old {
+					if !slices.Contains(transportFields, k) {
+						entry[k] = v
+					}
+				}
+			}
+			maps.Copy(entry, entryFunc(server))
+			return entry
+		})
+	})
 }
 
-// saveMCPConfig saves the MCP config to disk
-func saveMCPConfig(path string, cfg *MCPClientConfig) error {
-	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0o755); err != nil {
-		return fmt.Errorf("failed to create config directory: %w", err)
-	}
+// transportFields are the entry fields that describe how a server is started
+// or reached, replaced on every sync
+var transportFields = []string{"type", "transportType", "command", "args", "env", "envFile", "url", "headers"}
 
-	data, err := json.MarshalIndent(cfg, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal config: %w", err)
+// enabledServers returns the servers that aren't disabled
+func enabledServers(servers []config.MCPServer) []config.MCPServer {
+	enabled := make([]config.MCPServer, 0, len(servers))
+	for _, server := range servers {
+		if !server.Disabled {
+			enabled = append(enabled, server)
+		}
 	}
+	return enabled
+}
 
-	if err := os.WriteFile(path, data, 0o644); err != nil {
-		return fmt.Errorf("failed to write config: %w", err)
+// stdioServers returns the servers the client can launch itself, warning about
+// each http server that is left out
+func (c *Client) stdioServers(servers []config.MCPServer) []config.MCPServer {
+	stdio := make([]config.MCPServer, 0, len(servers))
+	for _, server := range servers {
+		if server.Type == "http" {
+			fmt.Fprintf(os.Stderr, "Warning: skipping http server %q, %s only supports stdio servers\n", server.Name, c.DisplayName)
+			continue
+		}
+		stdio = append(stdio, server)
 	}
+	return stdio
+}
 
-	return nil
+// syncToSettingsWithMcpServers syncs servers to a settings file with mcpServers key
+func syncToSettingsWithMcpServers(servers []config.MCPServer, path string) error {
+	return syncToSettingsWithKey(servers, path, "mcpServers")
 }
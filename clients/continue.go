@@ -1,9 +1,6 @@
 package clients
 
 import (
-	"encoding/json"
-	"fmt"
-	"os"
 	"path/filepath"
 
 	"github.com/jrandolf/mcpr/config"
@@ -14,19 +11,20 @@ var (
 	getContinueConfigPath = getContinueConfigPathImpl
 )
 
-func init() {
-	RegisterClient(&Client{
+// newContinueClient returns the Continue client
+func newContinueClient() *Client {
+	return &Client{
 		Name:          "continue",
 		DisplayName:   "Continue",
 		GlobalPath:    func() (string, error) { return getContinueConfigPath() },
 		LocalPath:     nil,
 		SupportsLocal: false,
 		SyncFunc:      syncToContinue,
-	})
+	}
 }
 
 func getContinueConfigPathImpl() (string, error) {
-	home, err := os.UserHomeDir()
+	home, err := userHomeDir()
 	if err != nil {
 		return "", err
 	}
@@ -35,18 +33,6 @@ func getContinueConfigPathImpl() (string, error) {
 }
 
 func syncToContinue(servers []config.MCPServer, path string) error {
-	var settings map[string]any
-	data, err := os.ReadFile(path)
-	if os.IsNotExist(err) {
-		settings = make(map[string]any)
-	} else if err != nil {
-		return fmt.Errorf("failed to read config: %w", err)
-	} else {
-		if err := json.Unmarshal(data, &settings); err != nil {
-			return fmt.Errorf("failed to parse config: %w", err)
-		}
-	}
-
 	// Continue uses "mcpServers" array with transport config
 	mcpServers := make([]map[string]any, 0, len(servers))
 	for _, server := range servers {
@@ -78,7 +64,9 @@ func syncToContinue(servers []config.MCPServer, path string) error {
 		})
 	}
 
-	settings["mcpServers"] = mcpServers
-
-	return saveSettingsFile(path, settings)
+	// Continue also reads config.yaml, so the path's extension picks the format
+	emitter := emitterForPath(path, jsonEmitter)
+	return syncFile(path, emitter, config.HasSecrets(servers), func(doc map[string]any) {
+		doc["mcpServers"] = mcpServers
+	})
 }
@@ -1,7 +1,6 @@
 package clients
 
 import (
-	"os"
 	"path/filepath"
 )
 
@@ -11,19 +10,31 @@ var (
 	getCursorLocalPath  = getCursorLocalPathImpl
 )
 
-func init() {
-	RegisterClient(&Client{
+// newCursorClient returns the Cursor client
+func newCursorClient() *Client {
+	return &Client{
 		Name:          "cursor",
 		DisplayName:   "Cursor",
 		GlobalPath:    func() (string, error) { return getCursorConfigPath() },
 		LocalPath:     func() (string, error) { return getCursorLocalPath() },
 		SupportsLocal: true,
 		SyncFunc:      syncToMCPConfig,
-	})
+		// Cursor reads envFile itself
+		SupportsEnvFile: true,
+		NativeVariables: map[string]string{
+			VarHome:            "${userHome}",
+			VarWorkspaceFolder: "${workspaceFolder}",
+			VarProjectRoot:     "${workspaceFolder}",
+		},
+	}
 }
 
 func getCursorConfigPathImpl() (string, error) {
-	home, err := os.UserHomeDir()
+	if dir := getenv("CURSOR_CONFIG_DIR"); dir != "" {
+		return filepath.Join(dir, "mcp.json"), nil
+	}
+
+	home, err := userHomeDir()
 	if err != nil {
 		return "", err
 	}
@@ -31,7 +42,7 @@ func getCursorConfigPathImpl() (string, error) {
 }
 
 func getCursorLocalPathImpl() (string, error) {
-	cwd, err := os.Getwd()
+	cwd, err := workingDir()
 	if err != nil {
 		return "", err
 	}
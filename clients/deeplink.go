@@ -0,0 +1,81 @@
+package clients
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/jrandolf/mcpr/config"
+)
+
+// cursorDeeplinkBase is where Cursor's one-click MCP install links point
+const cursorDeeplinkBase = "cursor://anysphere.cursor-deeplink/mcp/install"
+
+// installLinkers build a one-click install for a server's entry, by client
+var installLinkers = map[string]struct {
+	entry func(config.MCPServer) map[string]any
+	link  func(name string, entry []byte) string
+}{
+	"cursor":      {settingsEntry, cursorDeeplink},
+	"claude-code": {claudeCodeEntry, claudeAddJSON},
+}
+
+// InstallLinkClients returns the clients InstallLink supports
+func InstallLinkClients() []string {
+	names := make([]string, 0, len(installLinkers))
+	for name := range installLinkers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// InstallLink returns a one-click install of server for the client: a
+// cursor:// deeplink for Cursor, and for Claude Code, which has no install
+// links, a 'claude mcp add-json' command. Placeholders are expanded as for
+// a global sync, but sandboxing, shims, socket bridges and env files, which
+// only work on this machine, are left out.
+func (c *Client) InstallLink(server config.MCPServer) (string, error) {
+	linker, ok := installLinkers[c.Name]
+	if !ok {
+		return "", fmt.Errorf("%s has no install links; supported clients: %s", c.DisplayName, strings.Join(InstallLinkClients(), ", "))
+	}
+	if c.StdioOnly && server.Type == "http" {
+		return "", fmt.Errorf("%s only supports stdio servers", c.DisplayName)
+	}
+
+	servers, err := c.expandVariables(applyPlatforms([]config.MCPServer{server}), false)
+	if err != nil {
+		return "", err
+	}
+	entry, err := json.Marshal(linker.entry(servers[0]))
+	if err != nil {
+		return "", fmt.Errorf("failed to encode server: %w", err)
+	}
+	return linker.link(server.Name, entry), nil
+}
+
+// cursorDeeplink builds a Cursor install link, which carries the entry as
+// base64-encoded JSON
+func cursorDeeplink(name string, entry []byte) string {
+	// Built by hand since url.Values would sort config before name
+	return cursorDeeplinkBase + "?name=" + url.QueryEscape(name) +
+		"&config=" + url.QueryEscape(base64.StdEncoding.EncodeToString(entry))
+}
+
+// claudeAddJSON builds the Claude Code command adding the entry
+func claudeAddJSON(name string, entry []byte) string {
+	return "claude mcp add-json " + shellQuote(name) + " " + shellQuote(string(entry))
+}
+
+// shellQuote quotes s for a POSIX shell when it holds anything but plain
+// word characters
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./:@=") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,59 @@
+package clients
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/jrandolf/mcpr/config"
+)
+
+func TestInstallLink_Cursor(t *testing.T) {
+	client, _ := GetClient("cursor")
+	server := config.MCPServer{Name: "my server", Type: "stdio", Command: "npx", Args: []string{"-y", "pkg"}, Env: map[string]string{"TOKEN": "${TOKEN}"}}
+	link, err := client.InstallLink(server)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(link, cursorDeeplinkBase+"?name=my+server&config=") {
+		t.Fatalf("unexpected link: %s", link)
+	}
+
+	u, err := url.Parse(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := base64.StdEncoding.DecodeString(u.Query().Get("config"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entry map[string]any
+	if err := json.Unmarshal(data, &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry["command"] != "npx" || entry["env"].(map[string]any)["TOKEN"] != "${TOKEN}" {
+		t.Errorf("unexpected entry: %v", entry)
+	}
+}
+
+func TestInstallLink_ClaudeCode(t *testing.T) {
+	client, _ := GetClient("claude-code")
+	server := config.MCPServer{Name: "api", Type: "http", URL: "https://example.com/mcp", Headers: map[string]string{"X-Note": "it's"}}
+	link, err := client.InstallLink(server)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `claude mcp add-json api '{"headers":{"X-Note":"it'\''s"},"type":"http","url":"https://example.com/mcp"}'`
+	if link != want {
+		t.Errorf("expected %s, got %s", want, link)
+	}
+}
+
+func TestInstallLink_Unsupported(t *testing.T) {
+	client, _ := GetClient("zed")
+	if _, err := client.InstallLink(config.MCPServer{Name: "fs", Type: "stdio", Command: "npx"}); err == nil {
+		t.Error("expected an error for a client without install links")
+	}
+}
@@ -0,0 +1,112 @@
+package clients
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jrandolf/mcpr/config"
+)
+
+// Emitter decodes a client config file into a neutral document and encodes
+// it back. Encode gets the file's previous content (nil for a new file) so
+// it can keep formatting the sync didn't change.
+type Emitter interface {
+	Decode(data []byte) (map[string]any, error)
+	Encode(doc map[string]any, original []byte) ([]byte, error)
+}
+
+// formatEmitter encodes documents in one of mcpr's config formats
+type formatEmitter struct {
+	format config.Format
+	// preserve keeps the comments and formatting of unchanged parts of a
+	// JSON file, for clients that read JSON with comments
+	preserve bool
+}
+
+var (
+	jsonEmitter  Emitter = formatEmitter{format: config.FormatJSON}
+	jsoncEmitter Emitter = formatEmitter{format: config.FormatJSON, preserve: true}
+	yamlEmitter  Emitter = formatEmitter{format: config.FormatYAML}
+	tomlEmitter  Emitter = formatEmitter{format: config.FormatTOML}
+)
+
+func (e formatEmitter) Decode(data []byte) (map[string]any, error) {
+	doc := make(map[string]any)
+	if err := config.Unmarshal(data, e.format, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func (e formatEmitter) Encode(doc map[string]any, original []byte) ([]byte, error) {
+	data, err := config.Marshal(doc, e.format)
+	if err != nil {
+		return nil, err
+	}
+	if e.preserve && len(original) > 0 {
+		data = config.PreserveFormatting(original, data)
+	}
+	return data, nil
+}
+
+// emitterForPath returns the YAML or TOML emitter for paths with those
+// extensions, and def for anything else
+func emitterForPath(path string, def Emitter) Emitter {
+	switch config.FormatForPath(path) {
+	case config.FormatYAML:
+		return yamlEmitter
+	case config.FormatTOML:
+		return tomlEmitter
+	default:
+		return def
+	}
+}
+
+// syncFile runs the sync pipeline for a client config file: the target is
+// loaded and decoded by emitter, apply makes mcpr's managed changes to the
+// document, and the result is encoded and written back. secret marks
+// documents holding env vars or headers, which are written with restricted
+// permissions.
+func syncFile(path string, emitter Emitter, secret bool, apply func(doc map[string]any)) error {
+	original, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+
+	doc := make(map[string]any)
+	if len(original) > 0 {
+		doc, err = emitter.Decode(original)
+		if err != nil {
+			return fmt.Errorf("failed to parse config: %w", err)
+		}
+	}
+
+	apply(doc)
+
+	return writeDocument(path, emitter, doc, original, secret)
+}
+
+// replaceFile writes doc as the whole content of a client config file,
+// discarding whatever the file held before
+func replaceFile(path string, emitter Emitter, doc map[string]any, secret bool) error {
+	return writeDocument(path, emitter, doc, nil, secret)
+}
+
+// writeDocument encodes doc with emitter and writes it to path
+func writeDocument(path string, emitter Emitter, doc map[string]any, original []byte, secret bool) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	data, err := emitter.Encode(doc, original)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	if err := config.WriteFile(path, data, secret); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package clients
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jrandolf/mcpr/config"
+)
+
+func TestReplaceFile(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	os.WriteFile(configPath, []byte(`{"theme": "dark"}`), 0o644)
+
+	doc := map[string]any{
+		"mcpServers": map[string]any{"test-server": map[string]any{"command": "npx"}},
+	}
+	if err := replaceFile(configPath, jsonEmitter, doc, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+	var cfg MCPClientConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+	if cfg.MCPServers["test-server"].Command != "npx" {
+		t.Errorf("expected test-server to be written, got %v", cfg.MCPServers)
+	}
+	if strings.Contains(string(data), "theme") {
+		t.Error("expected previous content to be replaced")
+	}
+}
+
+func TestReplaceFile_CreatesDirectory(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "nested", "dir", "config.json")
+
+	if err := replaceFile(configPath, jsonEmitter, map[string]any{}, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		t.Fatal("config file was not created")
+	}
+}
+
+func TestSyncFile_InvalidConfig(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "settings.json")
+	os.WriteFile(configPath, []byte("{not json"), 0o644)
+
+	err := syncFile(configPath, jsonEmitter, false, func(doc map[string]any) {})
+	if err == nil || !strings.Contains(err.Error(), "failed to parse config") {
+		t.Errorf("expected parse error, got %v", err)
+	}
+}
+
+func TestSyncFile_JSONCKeepsComments(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "settings.json")
+	os.WriteFile(configPath, []byte(`{
+  // editor theme
+  "theme": "One Dark",
+  "context_servers": {}
+}
+`), 0o644)
+
+	servers := []config.MCPServer{{Name: "fs", Type: "stdio", Command: "npx"}}
+	err := syncFile(configPath, jsoncEmitter, false, func(doc map[string]any) {
+		doc["context_servers"] = serverEntries(servers, settingsEntry)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, _ := os.ReadFile(configPath)
+	if !strings.Contains(string(data), "// editor theme") {
+		t.Errorf("expected comment to be kept, got:\n%s", data)
+	}
+	if !strings.Contains(string(data), `"command": "npx"`) {
+		t.Errorf("expected server to be written, got:\n%s", data)
+	}
+}
+
+func TestSyncFile_YAML(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(configPath, []byte("name: assistant\n"), 0o644)
+
+	err := syncFile(configPath, emitterForPath(configPath, jsonEmitter), false, func(doc map[string]any) {
+		doc["mcpServers"] = []any{map[string]any{"name": "fs"}}
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, _ := os.ReadFile(configPath)
+	if !strings.Contains(string(data), "name: assistant") || !strings.Contains(string(data), "- name: fs") {
+		t.Errorf("expected YAML with both keys, got:\n%s", data)
+	}
+}
+
+func TestEmitterForPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want Emitter
+	}{
+		{"config.json", jsonEmitter},
+		{"config.yaml", yamlEmitter},
+		{"config.yml", yamlEmitter},
+		{"config.toml", tomlEmitter},
+	}
+	for _, tt := range tests {
+		if got := emitterForPath(tt.path, jsonEmitter); got != tt.want {
+			t.Errorf("emitterForPath(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestCodexEmitter_KeepsComments(t *testing.T) {
+	original := []byte(`# model settings
+model = "o3"
+
+[mcp_servers.old]
+command = "node"
+`)
+	doc := map[string]any{
+		"model": "o3",
+		"mcp_servers": map[string]any{
+			"fs": map[string]any{"command": "npx", "args": []string{"-y", "fs"}},
+		},
+	}
+
+	data, err := codexEmitter{}.Encode(doc, original)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `# model settings
+model = "o3"
+
+[mcp_servers.fs]
+command = "npx"
+args = ["-y", "fs"]
+
+`
+	if string(data) != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", data, want)
+	}
+}
+
+// benchServers returns n stdio servers with args and env, like a large team config
+func benchServers(n int) []config.MCPServer {
+	servers := make([]config.MCPServer, n)
+	for i := range servers {
+		servers[i] = config.MCPServer{
+			Name:    fmt.Sprintf("server-%03d", i),
+			Type:    "stdio",
+			Command: "npx",
+			Args:    []string{"-y", fmt.Sprintf("@team/server-%03d", i), "--verbose"},
+			Env:     map[string]string{"API_KEY": "secret", "REGION": "eu-west-1"},
+		}
+	}
+	return servers
+}
+
+func BenchmarkClientSync(b *testing.B) {
+	servers := benchServers(200)
+	for _, name := range []string{"claude-code", "cursor", "zed", "codex"} {
+		b.Run(name, func(b *testing.B) {
+			client, _ := GetClient(name)
+			b.Setenv(client.EnvOverride(false), filepath.Join(b.TempDir(), "config"))
+			for b.Loop() {
+				if _, err := client.Sync(servers, false); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
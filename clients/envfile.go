@@ -0,0 +1,68 @@
+package clients
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"maps"
+	"os"
+	"strings"
+
+	"github.com/jrandolf/mcpr/config"
+)
+
+// resolveEnvFiles returns copies of servers ready for the client. Clients
+// that read envFile themselves get the reference as is; for every other
+// client the file is read and its variables are inlined into env, with
+// values set in env taking precedence.
+func (c *Client) resolveEnvFiles(servers []config.MCPServer) ([]config.MCPServer, error) {
+	if c.SupportsEnvFile {
+		return servers, nil
+	}
+
+	resolved := make([]config.MCPServer, len(servers))
+	for i, server := range servers {
+		if server.EnvFile != "" {
+			vars, err := readEnvFile(server.EnvFile)
+			if err != nil {
+				return nil, fmt.Errorf("server %q: %w", server.Name, err)
+			}
+			maps.Copy(vars, server.Env)
+			server.Env = vars
+			server.EnvFile = ""
+		}
+		resolved[i] = server
+	}
+	return resolved, nil
+}
+
+// readEnvFile parses a .env file of KEY=VALUE lines. Blank lines, comments
+// and an "export " prefix are ignored, and values may be quoted.
+func readEnvFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read env file: %w", err)
+	}
+
+	vars := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", path, n)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		vars[key] = value
+	}
+	return vars, scanner.Err()
+}
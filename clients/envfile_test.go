@@ -0,0 +1,91 @@
+package clients
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jrandolf/mcpr/config"
+)
+
+func TestReadEnvFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	os.WriteFile(path, []byte(`# secrets
+API_KEY=sk-123
+export REGION = eu-west-1
+QUOTED="hello world"
+SINGLE='x=y'
+
+`), 0o600)
+
+	vars, err := readEnvFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]string{
+		"API_KEY": "sk-123",
+		"REGION":  "eu-west-1",
+		"QUOTED":  "hello world",
+		"SINGLE":  "x=y",
+	}
+	for k, v := range expected {
+		if vars[k] != v {
+			t.Errorf("expected %s=%q, got %q", k, v, vars[k])
+		}
+	}
+	if len(vars) != len(expected) {
+		t.Errorf("expected %d vars, got %d", len(expected), len(vars))
+	}
+}
+
+func TestReadEnvFile_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	os.WriteFile(path, []byte("API_KEY\n"), 0o600)
+
+	if _, err := readEnvFile(path); err == nil {
+		t.Error("expected error for line without '='")
+	}
+}
+
+func TestResolveEnvFiles_Inlined(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	os.WriteFile(path, []byte("API_KEY=from-file\nREGION=eu\n"), 0o600)
+
+	client, _ := GetClient("claude-desktop")
+	servers := []config.MCPServer{{Name: "api", Command: "npx", EnvFile: path, Env: map[string]string{"API_KEY": "inline"}}}
+
+	resolved, err := client.resolveEnvFiles(servers)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resolved[0].EnvFile != "" {
+		t.Errorf("expected envFile to be inlined, got %q", resolved[0].EnvFile)
+	}
+	if resolved[0].Env["API_KEY"] != "inline" || resolved[0].Env["REGION"] != "eu" {
+		t.Errorf("expected env file merged below inline env, got %v", resolved[0].Env)
+	}
+}
+
+func TestClientSync_CursorEnvFile(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "mcp.json")
+	originalFunc := getCursorConfigPath
+	getCursorConfigPath = func() (string, error) { return configPath, nil }
+	defer func() { getCursorConfigPath = originalFunc }()
+
+	client, _ := GetClient("cursor")
+	servers := []config.MCPServer{{Name: "api", Type: "stdio", Command: "npx", EnvFile: "${home}/.api.env"}}
+	if _, err := client.Sync(servers, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, _ := os.ReadFile(configPath)
+	var cfg MCPClientConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+	if got := cfg.MCPServers["api"].EnvFile; got != "${userHome}/.api.env" {
+		t.Errorf("expected envFile reference to be kept, got %q", got)
+	}
+}
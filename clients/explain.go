@@ -0,0 +1,49 @@
+package clients
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+var (
+	traceMu sync.Mutex
+	trace   *[]string // steps of the path being explained, nil when not explaining
+)
+
+// tracef records a path resolution step while a path is being explained
+func tracef(format string, args ...any) {
+	if trace != nil {
+		*trace = append(*trace, fmt.Sprintf(format, args...))
+	}
+}
+
+// ExplainPath resolves the client's global or local config path and returns
+// the steps that led to it: environment variables consulted, the OS branch
+// taken and whether the file exists
+func (c *Client) ExplainPath(local bool) (string, []string, error) {
+	traceMu.Lock()
+	defer traceMu.Unlock()
+
+	var steps []string
+	trace = &steps
+	defer func() { trace = nil }()
+
+	if local && !c.SupportsLocal {
+		return "", nil, fmt.Errorf("%s does not support local config", c.DisplayName)
+	}
+	path, err := c.Path(local)
+	if err != nil {
+		tracef("failed: %v", err)
+		return "", steps, err
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		tracef("%s exists", path)
+	} else if os.IsNotExist(err) {
+		tracef("%s does not exist yet and will be created", path)
+	} else {
+		tracef("%s can't be checked: %v", path, err)
+	}
+	return path, steps, nil
+}
@@ -0,0 +1,96 @@
+package clients
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jrandolf/mcpr/config"
+)
+
+func TestExplainPath_Codex(t *testing.T) {
+	codexHome := t.TempDir()
+	t.Setenv("CODEX_HOME", codexHome)
+
+	client, _ := GetClient("codex")
+	path, steps, err := client.ExplainPath(false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := filepath.Join(codexHome, "config.toml")
+	if path != expected {
+		t.Errorf("expected path %q, got %q", expected, path)
+	}
+
+	joined := strings.Join(steps, "\n")
+	if !strings.Contains(joined, "$CODEX_HOME is set to "+codexHome) {
+		t.Errorf("expected CODEX_HOME step, got:\n%s", joined)
+	}
+	if !strings.Contains(joined, "does not exist yet") {
+		t.Errorf("expected existence check step, got:\n%s", joined)
+	}
+
+	// Tracing stops once the path is explained
+	if trace != nil {
+		t.Error("expected trace to be reset")
+	}
+}
+
+func TestExplainPath_Exists(t *testing.T) {
+	codexHome := t.TempDir()
+	t.Setenv("CODEX_HOME", codexHome)
+	os.WriteFile(filepath.Join(codexHome, "config.toml"), nil, 0o644)
+
+	client, _ := GetClient("codex")
+	_, steps, err := client.ExplainPath(false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if last := steps[len(steps)-1]; !strings.HasSuffix(last, "exists") {
+		t.Errorf("expected last step to report the file exists, got %q", last)
+	}
+}
+
+func TestExplainPath_LocalNotSupported(t *testing.T) {
+	client, _ := GetClient("claude-desktop")
+	if _, _, err := client.ExplainPath(true); err == nil {
+		t.Error("expected error for local path of client without local support")
+	}
+}
+
+func TestClientPath_EnvOverride(t *testing.T) {
+	override := filepath.Join(t.TempDir(), "custom.json")
+	t.Setenv("MCPR_CLAUDE_DESKTOP_CONFIG", override)
+
+	client, _ := GetClient("claude-desktop")
+	path, err := client.Path(false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if path != override {
+		t.Errorf("expected overridden path %q, got %q", override, path)
+	}
+
+	synced, err := client.Sync([]config.MCPServer{{Name: "fs", Type: "stdio", Command: "npx"}}, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if synced != override {
+		t.Errorf("expected sync to write %q, got %q", override, synced)
+	}
+	if _, err := os.Stat(override); err != nil {
+		t.Errorf("expected overridden config to be written: %v", err)
+	}
+}
+
+func TestClientEnvOverride(t *testing.T) {
+	client, _ := GetClient("kilo-code")
+	if got := client.EnvOverride(false); got != "MCPR_KILO_CODE_CONFIG" {
+		t.Errorf("expected MCPR_KILO_CODE_CONFIG, got %s", got)
+	}
+	if got := client.EnvOverride(true); got != "MCPR_KILO_CODE_LOCAL_CONFIG" {
+		t.Errorf("expected MCPR_KILO_CODE_LOCAL_CONFIG, got %s", got)
+	}
+}
@@ -0,0 +1,63 @@
+package clients
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+
+	"github.com/jrandolf/mcpr/config"
+)
+
+func FuzzCodexEmitter(f *testing.F) {
+	for _, seed := range []string{
+		"",
+		"model = \"o3\"\n",
+		"# settings\nmodel = \"o3\"\n\n[mcp_servers.old]\ncommand = \"old\"\n\n[profiles.fast]\nmodel = \"o4-mini\"\n",
+		"[mcp_servers.a]\ncommand = \"a\"\nargs = [\"x\"]\n[mcp_servers.a.env]\nKEY = \"v\"\n",
+		"[mcp_servers]\n",
+		"  [mcp_servers.indented]\n  url = \"https://example.com\"\n",
+		"[tools]\nweb_search = true\r\n",
+	} {
+		f.Add(seed)
+	}
+	servers := []config.MCPServer{
+		{Name: "fetch", Type: "stdio", Command: "uvx", Args: []string{"mcp-server-fetch"}, Env: map[string]string{"A": "1"}},
+		{Name: "remote", Type: "http", URL: "https://example.com/mcp", Headers: map[string]string{"X-Key": "v"}},
+	}
+
+	f.Fuzz(func(t *testing.T, original string) {
+		doc, err := codexEmitter{}.Decode([]byte(original))
+		if err != nil {
+			// Files Codex itself couldn't read are left for the user
+			return
+		}
+		doc["mcp_servers"] = serverEntries(servers, codexEntry)
+
+		out, err := codexEmitter{}.Encode(doc, []byte(original))
+		if err != nil {
+			t.Fatalf("failed to encode: %v", err)
+		}
+		written, err := tomlEmitter.Decode(out)
+		if err != nil {
+			t.Fatalf("wrote invalid TOML from %q: %v\n%s", original, err, out)
+		}
+		tables, _ := written["mcp_servers"].(map[string]any)
+		for _, server := range servers {
+			if _, ok := tables[server.Name]; !ok {
+				t.Errorf("expected server %q in\n%s", server.Name, out)
+			}
+		}
+
+		// A second sync leaves the file as the first wrote it
+		again, err := codexEmitter{}.Encode(doc, out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(again, out) {
+			t.Errorf("expected syncing twice to be stable, got\n%s\nthen\n%s", out, again)
+		}
+		if slices.Contains(tomlSplitLines(string(out)), "[mcp_servers.old]") {
+			t.Errorf("expected old servers to be removed, got\n%s", out)
+		}
+	})
+}
@@ -1,8 +1,12 @@
 package clients
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+
+	"github.com/jrandolf/mcpr/config"
 )
 
 // Path functions as variables for testing
@@ -11,19 +15,20 @@ var (
 	getGeminiLocalPath  = getGeminiLocalPathImpl
 )
 
-func init() {
-	RegisterClient(&Client{
+// newGeminiClient returns the Gemini CLI client
+func newGeminiClient() *Client {
+	return &Client{
 		Name:          "gemini",
 		DisplayName:   "Gemini CLI",
 		GlobalPath:    func() (string, error) { return getGeminiConfigPath() },
 		LocalPath:     func() (string, error) { return getGeminiLocalPath() },
 		SupportsLocal: true,
-		SyncFunc:      syncToSettingsWithMcpServers,
-	})
+		SyncFunc:      syncToGemini,
+	}
 }
 
 func getGeminiConfigPathImpl() (string, error) {
-	home, err := os.UserHomeDir()
+	home, err := userHomeDir()
 	if err != nil {
 		return "", err
 	}
@@ -31,9 +36,76 @@ func getGeminiConfigPathImpl() (string, error) {
 }
 
 func getGeminiLocalPathImpl() (string, error) {
-	cwd, err := os.Getwd()
+	cwd, err := workingDir()
 	if err != nil {
 		return "", err
 	}
 	return filepath.Join(cwd, ".gemini", "settings.json"), nil
 }
+
+// syncToGemini syncs servers to Gemini CLI's settings, which also accept a
+// description, trust and a timeout in milliseconds per server
+func syncToGemini(servers []config.MCPServer, path string) error {
+	warnGeminiExtensionConflicts(servers, filepath.Join(filepath.Dir(path), "extensions"))
+
+	return syncToSettingsWithEntries(servers, path, "mcpServers", func(server config.MCPServer) map[string]any {
+		entry := settingsEntry(server)
+		if server.Description != "" {
+			entry["description"] = server.Description
+		}
+		if server.Trust {
+			entry["trust"] = true
+		}
+		if server.Timeout > 0 {
+			entry["timeout"] = server.Timeout * 1000
+		}
+		return entry
+	})
+}
+
+// geminiExtensionServers returns the servers provided by installed Gemini CLI
+// extensions, mapped to the extension providing them. Extensions live in
+// <dir>/<name>/gemini-extension.json.
+func geminiExtensionServers(dir string) map[string]string {
+	provided := make(map[string]string)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return provided
+	}
+
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name(), "gemini-extension.json"))
+		if err != nil {
+			continue
+		}
+		var manifest struct {
+			Name       string                     `json:"name"`
+			MCPServers map[string]json.RawMessage `json:"mcpServers"`
+		}
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			continue
+		}
+		name := manifest.Name
+		if name == "" {
+			name = e.Name()
+		}
+		for server := range manifest.MCPServers {
+			provided[server] = name
+		}
+	}
+	return provided
+}
+
+// warnGeminiExtensionConflicts warns about servers that an installed Gemini
+// CLI extension already provides under the same name
+func warnGeminiExtensionConflicts(servers []config.MCPServer, dir string) {
+	provided := geminiExtensionServers(dir)
+	for _, server := range servers {
+		if ext, ok := provided[server.Name]; ok {
+			fmt.Fprintf(os.Stderr, "Warning: server %q is also provided by the Gemini CLI extension %q\n", server.Name, ext)
+		}
+	}
+}
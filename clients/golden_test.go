@@ -0,0 +1,94 @@
+package clients
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/jrandolf/mcpr/config"
+)
+
+var update = flag.Bool("update", false, "regenerate the golden client configs in testdata/golden")
+
+// goldenFixtures returns the servers every client's golden config is
+// rendered from
+func goldenFixtures(t *testing.T) []config.MCPServer {
+	t.Helper()
+	cfg, err := config.LoadFromPath(filepath.Join("testdata", "golden", "servers.json"))
+	if err != nil {
+		t.Fatalf("failed to load fixtures: %v", err)
+	}
+	return cfg.Servers
+}
+
+// TestGolden renders the fixtures in testdata/golden/servers.json through
+// every registered client and compares the result with the client's golden
+// config, testdata/golden/<client><ext>. A new client only needs its golden
+// config generated; run
+//
+//	go test ./clients -run TestGolden -update
+//
+// and review the files it writes.
+func TestGolden(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("APPDATA", filepath.Join(home, "AppData", "Roaming"))
+	originalGOOS := goos
+	goos = "linux"
+	defer func() { goos = originalGOOS }()
+
+	servers := goldenFixtures(t)
+	names := ListClientNames()
+	slices.Sort(names)
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			client, _ := GetClient(name)
+			t.Setenv(client.EnvOverride(false), "")
+			path, data, err := client.Render(servers, false)
+			if err != nil {
+				t.Fatalf("failed to render: %v", err)
+			}
+			golden := filepath.Join("testdata", "golden", name+filepath.Ext(path))
+
+			if *update {
+				if err := os.WriteFile(golden, data, 0o644); err != nil {
+					t.Fatalf("failed to write golden config: %v", err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if os.IsNotExist(err) {
+				t.Fatalf("no golden config for %s; run 'go test ./clients -run TestGolden -update' and review %s", name, golden)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(data, want) {
+				t.Errorf("rendered config differs from %s; if the change is intended, run 'go test ./clients -run TestGolden -update'\ngot:\n%s\nwant:\n%s", golden, data, want)
+			}
+		})
+	}
+}
+
+// TestGolden_NoStaleFiles checks that every golden config belongs to a
+// registered client, so removed clients don't leave theirs behind
+func TestGolden_NoStaleFiles(t *testing.T) {
+	entries, err := os.ReadDir(filepath.Join("testdata", "golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if name == "servers.json" {
+			continue
+		}
+		if _, err := GetClient(name[:len(name)-len(filepath.Ext(name))]); err != nil {
+			t.Errorf("golden config %s has no client", name)
+		}
+	}
+}
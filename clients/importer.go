@@ -0,0 +1,197 @@
+package clients
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jrandolf/mcpr/config"
+)
+
+// Importer reads the servers another MCP manager installed. Managers like
+// Smithery's CLI and mcp-get keep no registry of their own: they write
+// entries straight into a client config, Claude Desktop's by default.
+type Importer struct {
+	Name        string
+	DisplayName string
+	// Path returns the config the manager installs servers to by default
+	Path func() (string, error)
+	// Match reports whether the manager installed a server; nil matches
+	// every server in the config
+	Match func(config.MCPServer) bool
+}
+
+// builtinImporters construct the importers mcpr supports
+var builtinImporters = []func() *Importer{
+	func() *Importer {
+		return &Importer{
+			Name:        "smithery",
+			DisplayName: "Smithery",
+			Path:        claudeDesktopPath,
+			Match:       isSmitheryServer,
+		}
+	},
+	func() *Importer {
+		// mcp-get writes plain npx, uvx and docker entries
+		return &Importer{
+			Name:        "mcp-get",
+			DisplayName: "mcp-get",
+			Path:        claudeDesktopPath,
+		}
+	},
+}
+
+// ImporterRegistry is a set of importers looked up by name, like Registry
+// is for clients. Its methods are safe for concurrent use.
+type ImporterRegistry struct {
+	mu        sync.RWMutex
+	importers map[string]*Importer
+}
+
+// NewImporterRegistry returns a registry holding importers
+func NewImporterRegistry(importers ...*Importer) *ImporterRegistry {
+	r := &ImporterRegistry{importers: make(map[string]*Importer, len(importers))}
+	for _, importer := range importers {
+		r.Register(importer)
+	}
+	return r
+}
+
+// NewDefaultImporterRegistry returns a registry holding every supported
+// importer. Each registry gets its own importers, so changing one doesn't
+// affect another.
+func NewDefaultImporterRegistry() *ImporterRegistry {
+	importers := make([]*Importer, len(builtinImporters))
+	for i, newImporter := range builtinImporters {
+		importers[i] = newImporter()
+	}
+	return NewImporterRegistry(importers...)
+}
+
+// Register adds importer, replacing any importer of the same name
+func (r *ImporterRegistry) Register(importer *Importer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.importers[importer.Name] = importer
+}
+
+// Get returns the importer registered by name
+func (r *ImporterRegistry) Get(name string) (*Importer, error) {
+	r.mu.RLock()
+	importer, ok := r.importers[name]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown importer: %s (supported: %s)", name, strings.Join(r.Names(), ", "))
+	}
+	return importer, nil
+}
+
+// Names returns the names of the registered importers, sorted
+func (r *ImporterRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return slices.Sorted(maps.Keys(r.importers))
+}
+
+// defaultImporterRegistry is the registry the package-level functions use
+var defaultImporterRegistry = NewDefaultImporterRegistry()
+
+// GetImporter returns a specific importer by name from the default registry
+func GetImporter(name string) (*Importer, error) {
+	return defaultImporterRegistry.Get(name)
+}
+
+// ListImporterNames returns the names of all importers in the default
+// registry
+func ListImporterNames() []string {
+	return defaultImporterRegistry.Names()
+}
+
+// claudeDesktopPath returns the Claude Desktop config path, honoring its
+// override
+func claudeDesktopPath() (string, error) {
+	client, err := GetClient("claude-desktop")
+	if err != nil {
+		return "", err
+	}
+	return client.Path(false)
+}
+
+// isSmitheryServer reports whether a server runs through Smithery's CLI or
+// is hosted by Smithery
+func isSmitheryServer(server config.MCPServer) bool {
+	for _, arg := range server.Args {
+		if strings.HasPrefix(arg, "@smithery/cli") {
+			return true
+		}
+	}
+	if u, err := url.Parse(server.URL); err == nil && server.URL != "" {
+		host := u.Hostname()
+		return host == "smithery.ai" || strings.HasSuffix(host, ".smithery.ai")
+	}
+	return false
+}
+
+// importEntry is a server entry in an mcpServers client config
+type importEntry struct {
+	MCPServerEntry
+	Type      string `json:"type,omitempty"`
+	ServerURL string `json:"serverUrl,omitempty"` // Windsurf's name for url
+	Disabled  bool   `json:"disabled,omitempty"`
+}
+
+// Import returns the servers the manager installed in the client config at
+// path, or at its default path when path is empty, sorted by name. Windows
+// "cmd /c" wrappers are removed, since mcpr adds them back when syncing.
+func (i *Importer) Import(path string) (string, []config.MCPServer, error) {
+	if path == "" {
+		var err error
+		if path, err = i.Path(); err != nil {
+			return "", nil, err
+		}
+	}
+	data, err := os.ReadFile(longPath(path))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var doc struct {
+		MCPServers map[string]importEntry `json:"mcpServers"`
+	}
+	if err := config.Unmarshal(data, config.FormatForPath(path), &doc); err != nil {
+		return "", nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	var servers []config.MCPServer
+	for name, entry := range doc.MCPServers {
+		server := config.MCPServer{
+			Name:     name,
+			Type:     "stdio",
+			Command:  entry.Command,
+			Args:     entry.Args,
+			Env:      entry.Env,
+			EnvFile:  entry.EnvFile,
+			Disabled: entry.Disabled,
+		}
+		if u := cmp.Or(entry.URL, entry.ServerURL); u != "" || entry.Type == "http" || entry.Type == "sse" || entry.Type == "streamable-http" {
+			server = config.MCPServer{
+				Name:     name,
+				Type:     "http",
+				URL:      u,
+				Headers:  entry.Headers,
+				Disabled: entry.Disabled,
+			}
+		}
+		server = unshimCommand(server)
+		if i.Match == nil || i.Match(server) {
+			servers = append(servers, server)
+		}
+	}
+	sort.Slice(servers, func(a, b int) bool { return servers[a].Name < servers[b].Name })
+	return path, servers, nil
+}
@@ -0,0 +1,100 @@
+package clients
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const importConfig = `{
+  // Written by Smithery, mcp-get and by hand
+  "mcpServers": {
+    "github": {"command": "cmd", "args": ["/c", "npx", "-y", "@smithery/cli@latest", "run", "@smithery-ai/github", "--key", "abc"]},
+    "hosted": {"type": "streamable-http", "url": "https://server.smithery.ai/@org/hosted/mcp"},
+    "fetch": {"command": "uvx", "args": ["mcp-server-fetch"], "disabled": true},
+    "remote": {"serverUrl": "https://example.com/mcp", "headers": {"X-Team": "core"}}
+  }
+}`
+
+func TestImporter_Smithery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "claude_desktop_config.json")
+	os.WriteFile(path, []byte(importConfig), 0644)
+	t.Setenv("MCPR_CLAUDE_DESKTOP_CONFIG", path)
+
+	importer, err := GetImporter("smithery")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, servers, err := importer.Import("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != path {
+		t.Errorf("expected the Claude Desktop config to be read, got %s", got)
+	}
+	if len(servers) != 2 || servers[0].Name != "github" || servers[1].Name != "hosted" {
+		t.Fatalf("expected the Smithery servers, got %+v", servers)
+	}
+	if servers[0].Command != "npx" || strings.Join(servers[0].Args, " ") != "-y @smithery/cli@latest run @smithery-ai/github --key abc" {
+		t.Errorf("expected the cmd /c wrapper to be removed, got %s %v", servers[0].Command, servers[0].Args)
+	}
+	if servers[1].Type != "http" || servers[1].URL != "https://server.smithery.ai/@org/hosted/mcp" {
+		t.Errorf("unexpected hosted server: %+v", servers[1])
+	}
+}
+
+func TestImporter_MCPGet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mcp.json")
+	os.WriteFile(path, []byte(importConfig), 0644)
+
+	importer, _ := GetImporter("mcp-get")
+	_, servers, err := importer.Import(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(servers) != 4 {
+		t.Fatalf("expected every server, got %+v", servers)
+	}
+	if fetch := servers[0]; fetch.Name != "fetch" || fetch.Type != "stdio" || !fetch.Disabled {
+		t.Errorf("unexpected fetch server: %+v", fetch)
+	}
+	if remote := servers[3]; remote.Type != "http" || remote.URL != "https://example.com/mcp" || remote.Headers["X-Team"] != "core" {
+		t.Errorf("expected serverUrl to be read, got %+v", remote)
+	}
+}
+
+func TestGetImporter_Unknown(t *testing.T) {
+	if _, err := GetImporter("nope"); err == nil || !strings.Contains(err.Error(), "smithery") {
+		t.Errorf("expected an error listing the importers, got %v", err)
+	}
+}
+
+func TestNewDefaultImporterRegistry(t *testing.T) {
+	a, b := NewDefaultImporterRegistry(), NewDefaultImporterRegistry()
+	if !slices.Equal(a.Names(), ListImporterNames()) {
+		t.Errorf("expected the default registry to hold every supported importer, got %v", a.Names())
+	}
+
+	// Registries don't share importers
+	importer, err := a.Get("smithery")
+	if err != nil {
+		t.Fatal(err)
+	}
+	importer.DisplayName = "Changed"
+	if other, _ := b.Get("smithery"); other.DisplayName != "Smithery" {
+		t.Errorf("expected another registry to be unaffected, got %q", other.DisplayName)
+	}
+	if global, _ := GetImporter("smithery"); global.DisplayName != "Smithery" {
+		t.Errorf("expected the default registry to be unaffected, got %q", global.DisplayName)
+	}
+
+	r := NewImporterRegistry(&Importer{Name: "custom"})
+	if !slices.Equal(r.Names(), []string{"custom"}) {
+		t.Errorf("expected [custom], got %v", r.Names())
+	}
+	if _, err := GetImporter("custom"); err == nil {
+		t.Error("expected a registry's importers to stay out of the default registry")
+	}
+}
@@ -0,0 +1,109 @@
+package clients
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+
+	"github.com/jrandolf/mcpr/config"
+)
+
+// Placeholders that are expanded at sync time
+const (
+	VarHome            = "home"
+	VarWorkspaceFolder = "workspaceFolder"
+	VarProjectRoot     = "projectRoot"
+)
+
+var placeholderPattern = regexp.MustCompile(`\$\{(\w+)\}`)
+
+// getWorkingDir returns the project directory for local syncs; a variable so
+// tests can override it
+var getWorkingDir = workingDir
+
+// syncVariables returns the values placeholders expand to for a sync target.
+// Placeholders the client resolves itself are translated into its own syntax.
+// Project placeholders have no value in a global config unless the client
+// resolves them.
+func (c *Client) syncVariables(local bool) (map[string]string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+	vars := map[string]string{VarHome: home}
+
+	if local {
+		cwd, err := getWorkingDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get working directory: %w", err)
+		}
+		vars[VarWorkspaceFolder] = cwd
+		vars[VarProjectRoot] = cwd
+	}
+
+	maps.Copy(vars, c.NativeVariables)
+	return vars, nil
+}
+
+// expandVariables returns copies of servers with {{...}} templates
+// evaluated and ${...} placeholders in commands, args, env, urls and
+// headers expanded for the sync target. Unknown placeholders are left as is
+// so clients that expand environment variables still see them.
+func (c *Client) expandVariables(servers []config.MCPServer, local bool) ([]config.MCPServer, error) {
+	vars, err := c.syncVariables(local)
+	if err != nil {
+		return nil, err
+	}
+
+	expanded := make([]config.MCPServer, len(servers))
+	for i, server := range servers {
+		server, err := executeTemplates(server)
+		if err != nil {
+			return nil, err
+		}
+
+		var unresolved string
+		expand := func(s string) string {
+			return placeholderPattern.ReplaceAllStringFunc(s, func(match string) string {
+				name := match[2 : len(match)-1]
+				if value, ok := vars[name]; ok {
+					return value
+				}
+				if name == VarWorkspaceFolder || name == VarProjectRoot {
+					unresolved = match
+				}
+				return match
+			})
+		}
+
+		server.Command = expand(server.Command)
+		server.URL = expand(server.URL)
+		server.EnvFile = expand(server.EnvFile)
+		server.Args = slices.Clone(server.Args)
+		for j, arg := range server.Args {
+			server.Args[j] = expand(arg)
+		}
+		server.Env = expandValues(server.Env, expand)
+		server.Headers = expandValues(server.Headers, expand)
+
+		if unresolved != "" {
+			return nil, fmt.Errorf("server %q uses %s, which %s can't resolve in its global config; sync with --local instead", server.Name, unresolved, c.DisplayName)
+		}
+		expanded[i] = server
+	}
+	return expanded, nil
+}
+
+// expandValues returns a copy of m with expand applied to every value
+func expandValues(m map[string]string, expand func(string) string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[k] = expand(v)
+	}
+	return out
+}
@@ -0,0 +1,72 @@
+package clients
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jrandolf/mcpr/config"
+)
+
+func TestExpandVariables_Local(t *testing.T) {
+	originalFunc := getWorkingDir
+	getWorkingDir = func() (string, error) { return "/work/project", nil }
+	defer func() { getWorkingDir = originalFunc }()
+
+	home, _ := os.UserHomeDir()
+	client := &Client{Name: "test", DisplayName: "Test"}
+	servers := []config.MCPServer{{
+		Name:    "fs",
+		Command: "${home}/bin/server",
+		Args:    []string{"--root", "${projectRoot}", "${workspaceFolder}/src", "${API_KEY}"},
+		Env:     map[string]string{"ROOT": "${projectRoot}"},
+	}}
+
+	expanded, err := client.expandVariables(servers, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	s := expanded[0]
+	if s.Command != home+"/bin/server" {
+		t.Errorf("expected command %q, got %q", home+"/bin/server", s.Command)
+	}
+	expectedArgs := "--root /work/project /work/project/src ${API_KEY}"
+	if got := strings.Join(s.Args, " "); got != expectedArgs {
+		t.Errorf("expected args %q, got %q", expectedArgs, got)
+	}
+	if s.Env["ROOT"] != "/work/project" {
+		t.Errorf("expected env ROOT=/work/project, got %q", s.Env["ROOT"])
+	}
+
+	// The original servers must not be modified
+	if servers[0].Args[1] != "${projectRoot}" || servers[0].Env["ROOT"] != "${projectRoot}" {
+		t.Error("expected original server to be left untouched")
+	}
+}
+
+func TestExpandVariables_NativeSyntax(t *testing.T) {
+	client, _ := GetClient("vscode")
+	servers := []config.MCPServer{{Name: "fs", Command: "server", Args: []string{"${projectRoot}", "${home}"}}}
+
+	expanded, err := client.expandVariables(servers, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.Join(expanded[0].Args, " "); got != "${workspaceFolder} ${userHome}" {
+		t.Errorf("expected VS Code variables, got %q", got)
+	}
+}
+
+func TestExpandVariables_GlobalUnresolved(t *testing.T) {
+	client, _ := GetClient("claude-desktop")
+	servers := []config.MCPServer{{Name: "fs", Command: "server", Args: []string{"${workspaceFolder}"}}}
+
+	_, err := client.expandVariables(servers, false)
+	if err == nil {
+		t.Fatal("expected error for project placeholder in global config")
+	}
+	if !strings.Contains(err.Error(), "--local") {
+		t.Errorf("expected error to suggest --local, got %q", err.Error())
+	}
+}
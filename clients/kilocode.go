@@ -2,9 +2,10 @@ package clients
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 	"runtime"
+
+	"github.com/jrandolf/mcpr/config"
 )
 
 // Path functions as variables for testing
@@ -13,43 +14,53 @@ var (
 	getKiloCodeLocalPath  = getKiloCodeLocalPathImpl
 )
 
-func init() {
-	RegisterClient(&Client{
+// newKiloCodeClient returns the Kilo Code client
+func newKiloCodeClient() *Client {
+	return &Client{
 		Name:          "kilo-code",
 		DisplayName:   "Kilo Code",
 		GlobalPath:    func() (string, error) { return getKiloCodeConfigPath() },
 		LocalPath:     func() (string, error) { return getKiloCodeLocalPath() },
 		SupportsLocal: true,
-		SyncFunc:      syncToMCPConfig,
-	})
+		SyncFunc:      syncToKiloCode,
+		// Kilo Code has its own per-server disabled toggle
+		SupportsDisabled: true,
+	}
 }
 
 func getKiloCodeConfigPathImpl() (string, error) {
-	home, err := os.UserHomeDir()
+	home, err := userHomeDir()
 	if err != nil {
 		return "", err
 	}
 
-	switch runtime.GOOS {
+	switch currentOS() {
 	case "darwin":
 		return filepath.Join(home, "Library", "Application Support", "Code", "User", "globalStorage", "kilocode.kilo-code", "settings", "mcp_settings.json"), nil
 	case "windows":
-		appData := os.Getenv("APPDATA")
+		appData := getenv("APPDATA")
 		if appData == "" {
 			appData = filepath.Join(home, "AppData", "Roaming")
 		}
 		return filepath.Join(appData, "Code", "User", "globalStorage", "kilocode.kilo-code", "settings", "mcp_settings.json"), nil
 	case "linux":
-		return filepath.Join(home, ".config", "Code", "User", "globalStorage", "kilocode.kilo-code", "settings", "mcp_settings.json"), nil
+		return filepath.Join(xdgConfigHome(home), "Code", "User", "globalStorage", "kilocode.kilo-code", "settings", "mcp_settings.json"), nil
 	default:
 		return "", fmt.Errorf("unsupported operating system: %s", runtime.GOOS)
 	}
 }
 
 func getKiloCodeLocalPathImpl() (string, error) {
-	cwd, err := os.Getwd()
+	cwd, err := workingDir()
 	if err != nil {
 		return "", err
 	}
 	return filepath.Join(cwd, ".kilocode", "mcp.json"), nil
 }
+
+// syncToKiloCode syncs servers to Kilo Code's settings. Kilo Code shares
+// Cline's format, so entries get disabled, timeout and alwaysAllow, and fields
+// the extension stores on existing entries are kept.
+func syncToKiloCode(servers []config.MCPServer, path string) error {
+	return syncPreservingEntries(servers, path, "mcpServers", clineEntry)
+}
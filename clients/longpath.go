@@ -0,0 +1,48 @@
+package clients
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// windowsMaxPath is the length from which Windows paths need the \\?\ prefix.
+// Directories are limited to MAX_PATH minus room for an 8.3 file name.
+const windowsMaxPath = 248
+
+// longPath prepares a client config path for reading and writing. On Windows,
+// paths under deeply nested directories (e.g. VS Code globalStorage) can
+// exceed MAX_PATH, so they are made absolute and given the \\?\ prefix.
+func longPath(path string) string {
+	if goos != "windows" {
+		return path
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return windowsLongPath(path)
+}
+
+// windowsLongPath returns the \\?\ form of an absolute Windows path that is
+// too long for the regular Win32 APIs. UNC paths (\\server\share\...) become
+// \\?\UNC\server\share\.... Short, relative and already prefixed paths are
+// returned as is.
+func windowsLongPath(path string) string {
+	if len(path) < windowsMaxPath {
+		return path
+	}
+	if strings.HasPrefix(path, `\\?\`) || strings.HasPrefix(path, `\\.\`) {
+		return path
+	}
+
+	// Prefixed paths are passed to the file system verbatim, so separators
+	// must be backslashes
+	path = strings.ReplaceAll(path, "/", `\`)
+
+	if strings.HasPrefix(path, `\\`) {
+		return `\\?\UNC\` + path[2:]
+	}
+	if len(path) >= 3 && path[1] == ':' && path[2] == '\\' {
+		return `\\?\` + path
+	}
+	return path
+}
@@ -0,0 +1,42 @@
+package clients
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWindowsLongPath(t *testing.T) {
+	long := strings.Repeat(`nested\`, 40)
+
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{"short", `C:\Users\me\settings.json`, `C:\Users\me\settings.json`},
+		{"drive", `C:\` + long + "mcp.json", `\\?\C:\` + long + "mcp.json"},
+		{"forward slashes", `C:/` + strings.ReplaceAll(long, `\`, "/") + "mcp.json", `\\?\C:\` + long + "mcp.json"},
+		{"unc", `\\server\share\` + long + "mcp.json", `\\?\UNC\server\share\` + long + "mcp.json"},
+		{"already prefixed", `\\?\C:\` + long, `\\?\C:\` + long},
+		{"relative", long + "mcp.json", long + "mcp.json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := windowsLongPath(tt.path); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestLongPath_NonWindows(t *testing.T) {
+	originalGOOS := goos
+	goos = "linux"
+	defer func() { goos = originalGOOS }()
+
+	path := "/" + strings.Repeat("nested/", 40) + "mcp.json"
+	if got := longPath(path); got != path {
+		t.Errorf("expected path to be unchanged, got %q", got)
+	}
+}
@@ -0,0 +1,104 @@
+package clients
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/jrandolf/mcpr/config"
+)
+
+// MockClient is an in-memory client for tests of code built on this
+// package. Syncs to it are recorded instead of written, so what a sync
+// would send a client can be checked without touching the file system.
+// Register it with UseTestRegistry to have code that looks clients up by
+// name find it.
+type MockClient struct {
+	*Client
+
+	// SyncErr, if set, is returned by every sync
+	SyncErr error
+
+	mu    sync.Mutex
+	calls []SyncCall
+}
+
+// SyncCall is a sync recorded by a MockClient
+type SyncCall struct {
+	Servers []config.MCPServer // As prepared for the client
+	Local   bool
+}
+
+// NewMockClient returns a mock client named name that supports local
+// configs and disabled servers. Its config paths are in a directory that
+// is never created.
+func NewMockClient(name string) *MockClient {
+	dir := filepath.Join(os.TempDir(), "mcpr-mock", name)
+	global, local := filepath.Join(dir, "global.json"), filepath.Join(dir, "local.json")
+	m := &MockClient{}
+	m.Client = &Client{
+		Name:             name,
+		DisplayName:      name + " (mock)",
+		GlobalPath:       func() (string, error) { return global, nil },
+		LocalPath:        func() (string, error) { return local, nil },
+		SupportsLocal:    true,
+		SupportsDisabled: true,
+		SyncFunc: func(servers []config.MCPServer, path string) error {
+			if filepath.Dir(path) != dir {
+				// Staging renders into a private copy mcpr reads back
+				return syncToMCPConfig(servers, path)
+			}
+			return m.record(servers, path == local)
+		},
+	}
+	return m
+}
+
+// record keeps a sync of servers unless the mock is set to fail
+func (m *MockClient) record(servers []config.MCPServer, local bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.SyncErr != nil {
+		return m.SyncErr
+	}
+	m.calls = append(m.calls, SyncCall{Servers: slices.Clone(servers), Local: local})
+	return nil
+}
+
+// Calls returns the syncs made to the mock, oldest first
+func (m *MockClient) Calls() []SyncCall {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Clone(m.calls)
+}
+
+// Servers returns the servers the last sync to the global or local config
+// sent, and whether there was one
+func (m *MockClient) Servers(local bool) ([]config.MCPServer, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, call := range slices.Backward(m.calls) {
+		if call.Local == local {
+			return call.Servers, true
+		}
+	}
+	return nil, false
+}
+
+// Reset forgets the recorded syncs
+func (m *MockClient) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = nil
+}
+
+// UseTestRegistry makes a registry of clients, such as mocks, the default
+// registry until the returned function is called to restore it. It must not
+// be used while other goroutines look clients up; give those their own
+// Registry instead.
+func UseTestRegistry(clients ...*Client) (restore func()) {
+	saved := defaultRegistry
+	defaultRegistry = NewRegistry(clients...)
+	return func() { defaultRegistry = saved }
+}
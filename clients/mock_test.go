@@ -0,0 +1,77 @@
+package clients
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/jrandolf/mcpr/config"
+)
+
+func TestMockClient(t *testing.T) {
+	mock := NewMockClient("mock")
+	restore := UseTestRegistry(mock.Client)
+	defer restore()
+
+	if names := ListClientNames(); len(names) != 1 || names[0] != "mock" {
+		t.Fatalf("expected only the mock to be registered, got %v", names)
+	}
+	client, err := GetClient("mock")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	servers := []config.MCPServer{
+		{Name: "a", Type: "stdio", Command: "npx", Disabled: true},
+		{Name: "b", Type: "http", URL: "https://example.com/mcp"},
+	}
+	path, err := client.Sync(servers, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected nothing written to %s, got %v", path, err)
+	}
+	if _, err := client.Sync(servers[1:], true); err != nil {
+		t.Fatal(err)
+	}
+
+	calls := mock.Calls()
+	if len(calls) != 2 || calls[0].Local || !calls[1].Local {
+		t.Fatalf("expected a global and a local sync, got %+v", calls)
+	}
+	if global, ok := mock.Servers(false); !ok || len(global) != 2 || !global[0].Disabled {
+		t.Errorf("expected both servers synced globally, got %+v", global)
+	}
+	if local, ok := mock.Servers(true); !ok || len(local) != 1 || local[0].Name != "b" {
+		t.Errorf("expected one server synced locally, got %+v", local)
+	}
+
+	// Staging renders without syncing
+	_, data, err := client.Render(servers, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc MCPClientConfig
+	if err := json.Unmarshal(data, &doc); err != nil || len(doc.MCPServers) != 2 {
+		t.Errorf("expected a rendering of both servers, got %s, %v", data, err)
+	}
+	if len(mock.Calls()) != 2 {
+		t.Error("expected rendering not to be recorded as a sync")
+	}
+
+	mock.Reset()
+	mock.SyncErr = errors.New("disk full")
+	if _, err := client.Sync(servers, false); err == nil || err.Error() != "disk full" {
+		t.Errorf("expected the mock's error, got %v", err)
+	}
+	if len(mock.Calls()) != 0 {
+		t.Error("expected a failed sync not to be recorded")
+	}
+
+	restore()
+	if _, err := GetClient("claude-desktop"); err != nil {
+		t.Errorf("expected the registry to be restored, got %v", err)
+	}
+}
@@ -1,9 +1,6 @@
 package clients
 
 import (
-	"encoding/json"
-	"fmt"
-	"os"
 	"path/filepath"
 
 	"github.com/jrandolf/mcpr/config"
@@ -15,27 +12,31 @@ var (
 	getOpenCodeLocalPath  = getOpenCodeLocalPathImpl
 )
 
-func init() {
-	RegisterClient(&Client{
+// newOpenCodeClient returns the OpenCode client
+func newOpenCodeClient() *Client {
+	return &Client{
 		Name:          "opencode",
 		DisplayName:   "OpenCode",
 		GlobalPath:    func() (string, error) { return getOpenCodeConfigPath() },
 		LocalPath:     func() (string, error) { return getOpenCodeLocalPath() },
 		SupportsLocal: true,
 		SyncFunc:      syncToOpenCode,
-	})
+		ServersKey:    "mcp",
+		Schema:        embeddedSchema("opencode"),
+		SchemaURL:     "https://opencode.ai/config.json",
+	}
 }
 
 func getOpenCodeConfigPathImpl() (string, error) {
-	home, err := os.UserHomeDir()
+	home, err := userHomeDir()
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(home, ".config", "opencode", "opencode.json"), nil
+	return filepath.Join(xdgConfigHome(home), "opencode", "opencode.json"), nil
 }
 
 func getOpenCodeLocalPathImpl() (string, error) {
-	cwd, err := os.Getwd()
+	cwd, err := workingDir()
 	if err != nil {
 		return "", err
 	}
@@ -49,46 +50,32 @@ func getOpenCodeLocalPathImpl() (string, error) {
 // - environment: object (instead of env)
 // - url/headers for remote servers
 func syncToOpenCode(servers []config.MCPServer, path string) error {
-	var settings map[string]any
-	data, err := os.ReadFile(path)
-	if os.IsNotExist(err) {
-		settings = make(map[string]any)
-	} else if err != nil {
-		return fmt.Errorf("failed to read config: %w", err)
-	} else {
-		if err := json.Unmarshal(data, &settings); err != nil {
-			return fmt.Errorf("failed to parse config: %w", err)
-		}
-	}
-
-	mcpServers := make(map[string]any)
-	for _, server := range servers {
-		var entry map[string]any
-		if server.Type == "http" {
-			entry = map[string]any{
-				"type": "remote",
-				"url":  server.URL,
-			}
-			if len(server.Headers) > 0 {
-				entry["headers"] = server.Headers
-			}
-		} else {
-			// Build command array: command + args
-			command := []string{server.Command}
-			command = append(command, server.Args...)
+	return syncToSettingsWithEntries(servers, path, "mcp", openCodeEntry)
+}
 
-			entry = map[string]any{
-				"type":    "local",
-				"command": command,
-			}
-			if len(server.Env) > 0 {
-				entry["environment"] = server.Env
-			}
+// openCodeEntry builds an OpenCode entry for a server
+func openCodeEntry(server config.MCPServer) map[string]any {
+	if server.Type == "http" {
+		entry := map[string]any{
+			"type": "remote",
+			"url":  server.URL,
+		}
+		if len(server.Headers) > 0 {
+			entry["headers"] = server.Headers
 		}
-		mcpServers[server.Name] = entry
+		return entry
 	}
 
-	settings["mcp"] = mcpServers
+	// Build command array: command + args
+	command := []string{server.Command}
+	command = append(command, server.Args...)
 
-	return saveSettingsFile(path, settings)
+	entry := map[string]any{
+		"type":    "local",
+		"command": command,
+	}
+	if len(server.Env) > 0 {
+		entry["environment"] = server.Env
+	}
+	return entry
 }
@@ -0,0 +1,81 @@
+package clients
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/jrandolf/mcpr/config"
+)
+
+// userHomeDir returns the home directory, tracing where it came from
+func userHomeDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		tracef("home directory can't be determined: %v", err)
+		return "", err
+	}
+	source := "$HOME"
+	if runtime.GOOS == "windows" {
+		source = "%USERPROFILE%"
+	}
+	tracef("home directory is %s (from %s)", home, source)
+	return home, nil
+}
+
+// projectDir is the directory local configs are written in, if pinned with
+// SetProjectDir
+var projectDir string
+
+// SetProjectDir pins the directory local configs are written in and returns
+// the previous one; "" finds it from the current directory again
+func SetProjectDir(dir string) string {
+	previous := projectDir
+	projectDir = dir
+	return previous
+}
+
+// workingDir returns the directory local configs are written in, tracing
+// it: that of the nearest project config, so each package of a monorepo
+// gets its own, or else the current directory
+func workingDir() (string, error) {
+	if projectDir != "" {
+		tracef("local config is in %s", projectDir)
+		return projectDir, nil
+	}
+	if dir, found := config.ProjectDir(); found {
+		tracef("local config is next to the project config in %s", dir)
+		return dir, nil
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	tracef("local config is relative to the current directory %s", cwd)
+	return cwd, nil
+}
+
+// getenv returns an environment variable, tracing whether it was set
+func getenv(key string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		tracef("$%s is not set", key)
+	} else {
+		tracef("$%s is set to %s", key, value)
+	}
+	return value
+}
+
+// currentOS returns the operating system, tracing the branch taken
+func currentOS() string {
+	tracef("operating system is %s", runtime.GOOS)
+	return runtime.GOOS
+}
+
+// xdgConfigHome returns $XDG_CONFIG_HOME, or ~/.config when it isn't set
+func xdgConfigHome(home string) string {
+	if dir := getenv("XDG_CONFIG_HOME"); dir != "" {
+		return dir
+	}
+	return filepath.Join(home, ".config")
+}
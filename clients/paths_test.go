@@ -0,0 +1,97 @@
+package clients
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestClaudeCodeConfigPath_ClaudeConfigDir(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("CLAUDE_CONFIG_DIR", dir)
+
+	path, err := getClaudeCodeConfigPathImpl()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := filepath.Join(dir, ".claude.json"); path != expected {
+		t.Errorf("expected %q, got %q", expected, path)
+	}
+}
+
+func TestCursorConfigPath_CursorConfigDir(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("CURSOR_CONFIG_DIR", dir)
+
+	path, err := getCursorConfigPathImpl()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := filepath.Join(dir, "mcp.json"); path != expected {
+		t.Errorf("expected %q, got %q", expected, path)
+	}
+}
+
+func TestZedConfigPath_XDGConfigHome(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	path, err := getZedConfigPathImpl()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := filepath.Join(dir, "zed", "settings.json"); path != expected {
+		t.Errorf("expected %q, got %q", expected, path)
+	}
+}
+
+func TestVSCodeConfigPath_XDGConfigHome(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("XDG_CONFIG_HOME only applies on Linux")
+	}
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	path, err := getVSCodeConfigPathImpl()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := filepath.Join(dir, "Code", "User", "settings.json"); path != expected {
+		t.Errorf("expected %q, got %q", expected, path)
+	}
+}
+
+func TestXDGConfigHome_Default(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "")
+	if got := xdgConfigHome("/home/me"); got != filepath.Join("/home/me", ".config") {
+		t.Errorf("expected ~/.config fallback, got %q", got)
+	}
+}
+
+func TestCursorLocalPath_NearestProjectConfig(t *testing.T) {
+	// The working directory is reported with symlinks resolved
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg := filepath.Join(root, "packages", "web")
+	src := filepath.Join(pkg, "src")
+	if err := os.MkdirAll(src, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{root, pkg} {
+		if err := os.WriteFile(filepath.Join(dir, "mcpr.json"), []byte(`{"servers":[]}`), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Chdir(src)
+
+	path, err := getCursorLocalPathImpl()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := filepath.Join(pkg, ".cursor", "mcp.json"); path != expected {
+		t.Errorf("expected %q, got %q", expected, path)
+	}
+}
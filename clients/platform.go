@@ -0,0 +1,75 @@
+package clients
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/jrandolf/mcpr/config"
+)
+
+// templateData is what {{...}} expressions in server definitions see
+type templateData struct {
+	OS   string // Operating system, as in Go: "darwin", "linux", "windows", ...
+	Arch string // Architecture, as in Go: "amd64", "arm64", ...
+}
+
+// templateFuncs are shorthands for conditions on the operating system,
+// e.g. {{if windows}}npx.cmd{{else}}npx{{end}}
+var templateFuncs = template.FuncMap{
+	"windows": func() bool { return goos == "windows" },
+	"darwin":  func() bool { return goos == "darwin" },
+	"linux":   func() bool { return goos == "linux" },
+}
+
+// applyPlatforms returns copies of servers with the overrides for the
+// current operating system applied
+func applyPlatforms(servers []config.MCPServer) []config.MCPServer {
+	applied := make([]config.MCPServer, len(servers))
+	for i, server := range servers {
+		if o, ok := server.Platforms[goos]; ok {
+			server = o.Apply(server)
+		}
+		server.Platforms = nil
+		applied[i] = server
+	}
+	return applied
+}
+
+// executeTemplates returns a copy of server with {{...}} expressions in
+// commands, args, env, urls and headers evaluated for the current
+// operating system
+func executeTemplates(server config.MCPServer) (config.MCPServer, error) {
+	data := templateData{OS: goos, Arch: runtime.GOARCH}
+	var err error
+	execute := func(field, s string) string {
+		if err != nil || !strings.Contains(s, "{{") {
+			return s
+		}
+		tmpl, parseErr := template.New(field).Funcs(templateFuncs).Option("missingkey=error").Parse(s)
+		if parseErr != nil {
+			err = fmt.Errorf("server %q: invalid template in %s: %w", server.Name, field, parseErr)
+			return s
+		}
+		var buf bytes.Buffer
+		if execErr := tmpl.Execute(&buf, data); execErr != nil {
+			err = fmt.Errorf("server %q: failed to evaluate template in %s: %w", server.Name, field, execErr)
+			return s
+		}
+		return buf.String()
+	}
+
+	server.Command = execute("command", server.Command)
+	server.URL = execute("url", server.URL)
+	server.EnvFile = execute("envFile", server.EnvFile)
+	server.Args = slices.Clone(server.Args)
+	for i, arg := range server.Args {
+		server.Args[i] = execute("args", arg)
+	}
+	server.Env = expandValues(server.Env, func(v string) string { return execute("env", v) })
+	server.Headers = expandValues(server.Headers, func(v string) string { return execute("headers", v) })
+	return server, err
+}
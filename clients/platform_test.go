@@ -0,0 +1,68 @@
+package clients
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jrandolf/mcpr/config"
+)
+
+func TestApplyPlatforms(t *testing.T) {
+	originalGOOS := goos
+	goos = "windows"
+	defer func() { goos = originalGOOS }()
+
+	servers := []config.MCPServer{{
+		Name:    "fs",
+		Type:    "stdio",
+		Command: "mcp-fs",
+		Args:    []string{"/home/me"},
+		Env:     map[string]string{"A": "1"},
+		Platforms: map[string]config.ServerOverride{
+			"windows": {Args: []string{`C:\Users\me`}, Env: map[string]string{"B": "2"}},
+			"darwin":  {Command: "other"},
+		},
+	}}
+
+	applied := applyPlatforms(servers)
+	s := applied[0]
+	if s.Command != "mcp-fs" || s.Args[0] != `C:\Users\me` || s.Env["A"] != "1" || s.Env["B"] != "2" {
+		t.Errorf("expected the windows overrides, got %+v", s)
+	}
+	if s.Platforms != nil {
+		t.Error("expected platforms to be resolved")
+	}
+	if servers[0].Args[0] != "/home/me" || len(servers[0].Env) != 1 {
+		t.Error("expected original server to be left untouched")
+	}
+}
+
+func TestExpandVariables_Templates(t *testing.T) {
+	originalGOOS := goos
+	defer func() { goos = originalGOOS }()
+
+	client := &Client{Name: "test", DisplayName: "Test"}
+	servers := []config.MCPServer{{
+		Name:    "fs",
+		Command: "{{if windows}}npx.cmd{{else}}npx{{end}}",
+		Args:    []string{"--os={{.OS}}", "${API_KEY}"},
+		Env:     map[string]string{"SHELL": `{{if eq .OS "darwin"}}zsh{{else}}bash{{end}}`},
+	}}
+
+	for os, want := range map[string]string{"windows": "npx.cmd --os=windows ${API_KEY} bash", "darwin": "npx --os=darwin ${API_KEY} zsh"} {
+		goos = os
+		expanded, err := client.expandVariables(servers, false)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", os, err)
+		}
+		s := expanded[0]
+		if got := s.Command + " " + strings.Join(s.Args, " ") + " " + s.Env["SHELL"]; got != want {
+			t.Errorf("%s: expected %q, got %q", os, want, got)
+		}
+	}
+
+	servers[0].Args = []string{"{{if windows}}"}
+	if _, err := client.expandVariables(servers, false); err == nil || !strings.Contains(err.Error(), `server "fs": invalid template in args`) {
+		t.Errorf("expected an invalid template error, got %v", err)
+	}
+}
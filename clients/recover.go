@@ -0,0 +1,116 @@
+package clients
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jrandolf/mcpr/config"
+)
+
+// pendingWrite is what a client config held before a transaction started
+// writing it, and the hash of what was being written. It is kept in the
+// state directory until the write is verified or rolled back, so a write
+// cut short by a crash or a kill can be undone by the next sync.
+type pendingWrite struct {
+	Path   string      `json:"path"`
+	Exists bool        `json:"exists"`
+	Data   []byte      `json:"data,omitempty"`
+	Perm   os.FileMode `json:"perm,omitempty"`
+	Want   string      `json:"want,omitempty"` // SHA-256 of the data being written
+}
+
+// pendingPath returns where the pending write of the client config at path
+// is kept
+func pendingPath(path string) (string, error) {
+	state, err := config.StateDir()
+	if err != nil {
+		return "", err
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	sum := sha256.Sum256([]byte(path))
+	return filepath.Join(state, "pending", hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+// keep records b on disk as the pending write of written to the client
+// config at path
+func (b backup) keep(path string, written []byte) error {
+	pending, err := pendingPath(path)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(pendingWrite{Path: path, Exists: b.exists, Data: b.data, Perm: b.perm, Want: hashData(written)})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(pending), 0o700); err != nil {
+		return err
+	}
+	// Client configs may hold secrets, so backups are private
+	return config.AtomicWriteFile(pending, data, 0o600)
+}
+
+// dropPending forgets the pending write of the client config at path
+func dropPending(path string) {
+	if pending, err := pendingPath(path); err == nil {
+		os.Remove(pending)
+	}
+}
+
+// hashData returns the hex SHA-256 of data
+func hashData(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// RecoverInterrupted undoes a write of the client config at path that was
+// cut short: unless the file holds exactly what was being written, or still
+// holds what it did before, it is restored to that, or removed if it didn't
+// exist. It reports whether it did. Whether the file parses says nothing,
+// since a file cut short at the end of a TOML table is still valid TOML.
+func RecoverInterrupted(path string) (bool, error) {
+	pending, err := pendingPath(path)
+	if err != nil {
+		return false, err
+	}
+	data, err := os.ReadFile(pending)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read backup of %s: %w", path, err)
+	}
+	var b pendingWrite
+	if err := json.Unmarshal(data, &b); err != nil {
+		os.Remove(pending)
+		return false, fmt.Errorf("failed to parse backup of %s: %w", path, err)
+	}
+
+	current, err := os.ReadFile(longPath(path))
+	if err != nil && !os.IsNotExist(err) {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	exists := err == nil
+	unchanged := exists == b.Exists && (!exists || bytes.Equal(current, b.Data))
+	if unchanged || exists && hashData(current) == b.Want {
+		os.Remove(pending)
+		return false, nil
+	}
+
+	if b.Exists {
+		err = config.AtomicWriteFile(longPath(path), b.Data, b.Perm)
+	} else if err = os.Remove(longPath(path)); os.IsNotExist(err) {
+		err = nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to restore %s: %w", path, err)
+	}
+	os.Remove(pending)
+	return true, nil
+}
@@ -0,0 +1,108 @@
+package clients
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// builtinClients construct the clients mcpr supports
+var builtinClients = []func() *Client{
+	newClaudeDesktopClient,
+	newClaudeCodeClient,
+	newClineClient,
+	newCodexClient,
+	newContinueClient,
+	newCursorClient,
+	newGeminiClient,
+	newKiloCodeClient,
+	newOpenCodeClient,
+	newVSCodeClient,
+	newWindsurfClient,
+	newZedClient,
+	newZencoderClient,
+}
+
+// Registry is a set of clients looked up by name. Its methods are safe for
+// concurrent use.
+type Registry struct {
+	mu      sync.RWMutex
+	clients map[string]*Client
+}
+
+// NewRegistry returns a registry holding clients, such as a subset of the
+// supported clients or mocks
+func NewRegistry(clients ...*Client) *Registry {
+	r := &Registry{clients: make(map[string]*Client, len(clients))}
+	for _, client := range clients {
+		r.Register(client)
+	}
+	return r
+}
+
+// NewDefaultRegistry returns a registry holding every supported client.
+// Each registry gets its own clients, so changing one doesn't affect
+// another.
+func NewDefaultRegistry() *Registry {
+	clients := make([]*Client, len(builtinClients))
+	for i, newClient := range builtinClients {
+		clients[i] = newClient()
+	}
+	return NewRegistry(clients...)
+}
+
+// Register adds client, replacing any client of the same name
+func (r *Registry) Register(client *Client) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.clients[client.Name] = client
+}
+
+// Clients returns the registered clients by name
+func (r *Registry) Clients() map[string]*Client {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return maps.Clone(r.clients)
+}
+
+// Get returns the client registered by name
+func (r *Registry) Get(name string) (*Client, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	client, ok := r.clients[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown client: %s", name)
+	}
+	return client, nil
+}
+
+// Names returns the names of the registered clients, sorted
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return slices.Sorted(maps.Keys(r.clients))
+}
+
+// defaultRegistry is the registry the package-level functions use
+var defaultRegistry = NewDefaultRegistry()
+
+// RegisterClient adds a client to the default registry
+func RegisterClient(client *Client) {
+	defaultRegistry.Register(client)
+}
+
+// GetClients returns all clients in the default registry
+func GetClients() map[string]*Client {
+	return defaultRegistry.Clients()
+}
+
+// GetClient returns a specific client by name from the default registry
+func GetClient(name string) (*Client, error) {
+	return defaultRegistry.Get(name)
+}
+
+// ListClientNames returns the names of all clients in the default registry
+func ListClientNames() []string {
+	return defaultRegistry.Names()
+}
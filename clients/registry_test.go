@@ -0,0 +1,50 @@
+package clients
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNewDefaultRegistry(t *testing.T) {
+	a, b := NewDefaultRegistry(), NewDefaultRegistry()
+	if !slices.Equal(a.Names(), ListClientNames()) {
+		t.Errorf("expected the default registry to hold every supported client, got %v", a.Names())
+	}
+
+	// Registries don't share clients
+	client, err := a.Get("cursor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.DisplayName = "Changed"
+	if other, _ := b.Get("cursor"); other.DisplayName != "Cursor" {
+		t.Errorf("expected another registry to be unaffected, got %q", other.DisplayName)
+	}
+	if global, _ := GetClient("cursor"); global.DisplayName != "Cursor" {
+		t.Errorf("expected the default registry to be unaffected, got %q", global.DisplayName)
+	}
+}
+
+func TestNewRegistry_Subset(t *testing.T) {
+	t.Parallel()
+	full := NewDefaultRegistry()
+	cursor, _ := full.Get("cursor")
+	zed, _ := full.Get("zed")
+
+	r := NewRegistry(cursor, zed)
+	if names := r.Names(); !slices.Equal(names, []string{"cursor", "zed"}) {
+		t.Errorf("expected [cursor zed], got %v", names)
+	}
+	if _, err := r.Get("codex"); err == nil {
+		t.Error("expected clients left out of the subset to be unknown")
+	}
+
+	mock := NewMockClient("mock")
+	r.Register(mock.Client)
+	if len(r.Clients()) != 3 {
+		t.Errorf("expected 3 clients after registering a mock, got %d", len(r.Clients()))
+	}
+	if _, err := GetClient("mock"); err == nil {
+		t.Error("expected a registry's clients to stay out of the default registry")
+	}
+}
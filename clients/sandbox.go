@@ -0,0 +1,104 @@
+package clients
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/jrandolf/mcpr/config"
+)
+
+// lookPath finds sandbox tools on PATH; a variable so tests can override it
+var lookPath = exec.LookPath
+
+// sandboxCommands returns copies of servers whose commands are wrapped in
+// the OS sandbox according to their sandbox profile: bubblewrap on Linux
+// and sandbox-exec on macOS. Sandboxed servers are skipped with a warning
+// on other systems rather than being run unconfined.
+func sandboxCommands(servers []config.MCPServer) []config.MCPServer {
+	wrapped := make([]config.MCPServer, 0, len(servers))
+	for _, server := range servers {
+		if server.Sandbox == nil || server.Type != "stdio" {
+			wrapped = append(wrapped, server)
+			continue
+		}
+
+		var tool string
+		var args []string
+		switch goos {
+		case "linux":
+			tool, args = "bwrap", bwrapArgs(server.Sandbox)
+		case "darwin":
+			tool, args = "sandbox-exec", []string{"-p", sandboxExecProfile(server.Sandbox)}
+		default:
+			fmt.Fprintf(os.Stderr, "Warning: skipping sandboxed server %q, sandboxing isn't supported on %s\n", server.Name, goos)
+			continue
+		}
+		if _, err := lookPath(tool); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %s was not found on PATH; install it before starting the sandboxed server %q\n", tool, server.Name)
+		}
+
+		args = append(args, server.Command)
+		server.Args = append(args, server.Args...)
+		server.Command = tool
+		wrapped = append(wrapped, server)
+	}
+	return wrapped
+}
+
+// bwrapSystemPaths are mounted read-only in every bubblewrap sandbox so
+// common runtimes can start
+var bwrapSystemPaths = []string{"/usr", "/bin", "/sbin", "/lib", "/lib32", "/lib64", "/etc", "/opt"}
+
+// bwrapArgs returns the bubblewrap arguments for a sandbox profile, ending
+// with "--" before the command
+func bwrapArgs(sandbox *config.Sandbox) []string {
+	args := []string{"--die-with-parent", "--unshare-all"}
+	if sandbox.Network {
+		args = append(args, "--share-net")
+	}
+	for _, path := range bwrapSystemPaths {
+		args = append(args, "--ro-bind-try", path, path)
+	}
+	args = append(args, "--proc", "/proc", "--dev", "/dev", "--tmpfs", "/tmp")
+	for _, path := range sandbox.ReadOnly {
+		args = append(args, "--ro-bind", path, path)
+	}
+	for _, path := range sandbox.ReadWrite {
+		args = append(args, "--bind", path, path)
+	}
+	return append(args, "--")
+}
+
+// sandboxExecSystemPaths are readable in every macOS sandbox so common
+// runtimes can start
+var sandboxExecSystemPaths = []string{"/usr", "/bin", "/sbin", "/System", "/Library", "/opt/homebrew", "/private/etc", "/private/var/db", "/dev"}
+
+// sandboxExecProfile returns the sandbox-exec profile for a sandbox profile
+func sandboxExecProfile(sandbox *config.Sandbox) string {
+	var b strings.Builder
+	b.WriteString("(version 1)\n(deny default)\n")
+	b.WriteString("(allow process* sysctl-read mach-lookup ipc-posix-shm signal)\n")
+	b.WriteString("(allow file-read-metadata)\n")
+
+	b.WriteString("(allow file-read*")
+	for _, path := range append(sandboxExecSystemPaths, sandbox.ReadOnly...) {
+		fmt.Fprintf(&b, " (subpath %q)", path)
+	}
+	b.WriteString(")\n")
+
+	b.WriteString(`(allow file-write* (literal "/dev/null"))` + "\n")
+	if len(sandbox.ReadWrite) > 0 {
+		b.WriteString("(allow file-read* file-write*")
+		for _, path := range sandbox.ReadWrite {
+			fmt.Fprintf(&b, " (subpath %q)", path)
+		}
+		b.WriteString(")\n")
+	}
+
+	if sandbox.Network {
+		b.WriteString("(allow network*)\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,84 @@
+package clients
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jrandolf/mcpr/config"
+)
+
+func withSandboxOS(t *testing.T, os string) {
+	t.Helper()
+	originalGOOS, originalLookPath := goos, lookPath
+	goos = os
+	lookPath = func(file string) (string, error) { return "/usr/bin/" + file, nil }
+	t.Cleanup(func() { goos, lookPath = originalGOOS, originalLookPath })
+}
+
+func TestSandboxCommands_Linux(t *testing.T) {
+	withSandboxOS(t, "linux")
+
+	servers := []config.MCPServer{
+		{Name: "fs", Type: "stdio", Command: "npx", Args: []string{"-y", "server-fs"}, Sandbox: &config.Sandbox{
+			ReadOnly:  []string{"/srv/docs"},
+			ReadWrite: []string{"/srv/out"},
+		}},
+		{Name: "plain", Type: "stdio", Command: "node"},
+	}
+	wrapped := sandboxCommands(servers)
+
+	fs := wrapped[0]
+	if fs.Command != "bwrap" {
+		t.Fatalf("expected bwrap command, got %q", fs.Command)
+	}
+	args := strings.Join(fs.Args, " ")
+	for _, want := range []string{"--unshare-all", "--ro-bind /srv/docs /srv/docs", "--bind /srv/out /srv/out", "-- npx -y server-fs"} {
+		if !strings.Contains(args, want) {
+			t.Errorf("expected args to contain %q, got %q", want, args)
+		}
+	}
+	if slices.Contains(fs.Args, "--share-net") {
+		t.Error("expected network to be unshared by default")
+	}
+	if wrapped[1].Command != "node" {
+		t.Errorf("expected unsandboxed server to be unchanged, got %+v", wrapped[1])
+	}
+	if servers[0].Command != "npx" {
+		t.Error("expected the original servers to be left untouched")
+	}
+}
+
+func TestSandboxCommands_Darwin(t *testing.T) {
+	withSandboxOS(t, "darwin")
+
+	wrapped := sandboxCommands([]config.MCPServer{
+		{Name: "web", Type: "stdio", Command: "uvx", Args: []string{"fetch"}, Sandbox: &config.Sandbox{Network: true, ReadWrite: []string{"/tmp/cache"}}},
+	})
+
+	web := wrapped[0]
+	if web.Command != "sandbox-exec" || len(web.Args) != 4 || web.Args[0] != "-p" {
+		t.Fatalf("unexpected sandbox-exec invocation: %s %v", web.Command, web.Args)
+	}
+	profile := web.Args[1]
+	for _, want := range []string{"(deny default)", "(allow network*)", `(allow file-read* file-write* (subpath "/tmp/cache"))`} {
+		if !strings.Contains(profile, want) {
+			t.Errorf("expected profile to contain %q, got:\n%s", want, profile)
+		}
+	}
+	if web.Args[2] != "uvx" || web.Args[3] != "fetch" {
+		t.Errorf("expected the original command after the profile, got %v", web.Args[2:])
+	}
+}
+
+func TestSandboxCommands_UnsupportedOS(t *testing.T) {
+	withSandboxOS(t, "windows")
+
+	wrapped := sandboxCommands([]config.MCPServer{
+		{Name: "fs", Type: "stdio", Command: "npx", Sandbox: &config.Sandbox{}},
+		{Name: "plain", Type: "stdio", Command: "node"},
+	})
+	if len(wrapped) != 1 || wrapped[0].Name != "plain" {
+		t.Errorf("expected sandboxed server to be skipped, got %+v", wrapped)
+	}
+}
@@ -0,0 +1,136 @@
+package clients
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jrandolf/mcpr/config"
+)
+
+// schemaFS holds the JSON Schemas client configs are checked against after
+// a sync. They describe the parts of each config mcpr writes, the server
+// entries, closely enough to catch a malformed one before the client
+// chokes on it; other settings are left alone.
+//
+//go:embed schemas/*.json
+var schemaFS embed.FS
+
+// maxSchemaSize bounds how much of a published schema is downloaded
+const maxSchemaSize = 4 << 20
+
+// embeddedSchema returns the embedded schema of the client called name
+func embeddedSchema(name string) []byte {
+	data, err := schemaFS.ReadFile("schemas/" + name + ".json")
+	if err != nil {
+		panic(fmt.Sprintf("no embedded schema for %s: %v", name, err))
+	}
+	return data
+}
+
+// fetchedSchemaPath returns where the schema downloaded from the client's
+// SchemaURL is kept
+func (c *Client) fetchedSchemaPath() (string, error) {
+	state, err := config.StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(state, "schemas", c.Name+".json"), nil
+}
+
+// FetchSchema downloads the schema the client publishes at SchemaURL and
+// keeps it in the state directory, so later checks use it instead of the
+// bundled one. It returns where the schema was kept.
+func (c *Client) FetchSchema(ctx context.Context, httpClient *http.Client) (string, error) {
+	if c.SchemaURL == "" {
+		return "", fmt.Errorf("%s doesn't publish a config schema", c.DisplayName)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.SchemaURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/schema+json, application/json")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", c.SchemaURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("downloading %s returned %s", c.SchemaURL, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSchemaSize))
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", c.SchemaURL, err)
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return "", fmt.Errorf("%s isn't a JSON Schema: %w", c.SchemaURL, err)
+	}
+
+	path, err := c.fetchedSchemaPath()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create schema directory: %w", err)
+	}
+	if err := config.WriteFile(path, data, false); err != nil {
+		return "", fmt.Errorf("failed to save schema: %w", err)
+	}
+	return path, nil
+}
+
+// ActiveSchema returns the schema the client's config is checked against:
+// the one downloaded with FetchSchema if there is one, the bundled one
+// otherwise
+func (c *Client) ActiveSchema() []byte {
+	if c.SchemaURL != "" {
+		if path, err := c.fetchedSchemaPath(); err == nil {
+			if data, err := os.ReadFile(path); err == nil {
+				return data
+			}
+		}
+	}
+	return c.Schema
+}
+
+// CheckSchema returns where data, the content of the client config at
+// path, doesn't match the client's schema, each as "path: problem". Only
+// the server entries mcpr writes are checked, since a published schema
+// covers every setting of the client. Clients without a schema, and
+// configs that don't parse, aren't checked.
+func (c *Client) CheckSchema(path string, data []byte) ([]string, error) {
+	schema := c.ActiveSchema()
+	if schema == nil {
+		return nil, nil
+	}
+	var doc any
+	if err := config.Unmarshal(data, config.FormatForPath(path), &doc); err != nil {
+		return nil, nil
+	}
+	violations, err := config.SchemaViolations(schema, doc)
+	if err != nil {
+		return nil, err
+	}
+	var written []string
+	for _, v := range violations {
+		if c.writtenField(v) {
+			written = append(written, v)
+		}
+	}
+	return written, nil
+}
+
+// writtenField reports whether a schema violation is about the servers
+// block mcpr writes
+func (c *Client) writtenField(violation string) bool {
+	key := c.ServersBlockKey()
+	rest, ok := strings.CutPrefix(violation, key)
+	return ok && rest != "" && strings.ContainsRune(":.[", rune(rest[0]))
+}
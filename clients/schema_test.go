@@ -0,0 +1,130 @@
+package clients
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCheckSchema_Golden(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	for _, name := range ListClientNames() {
+		client, _ := GetClient(name)
+		if client.Schema == nil {
+			continue
+		}
+		t.Run(name, func(t *testing.T) {
+			matches, _ := filepath.Glob(filepath.Join("testdata", "golden", name+".*"))
+			if len(matches) != 1 {
+				t.Fatalf("expected one golden config for %s, got %v", name, matches)
+			}
+			data, err := os.ReadFile(matches[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			violations, err := client.CheckSchema(matches[0], data)
+			if err != nil || len(violations) != 0 {
+				t.Errorf("expected the golden config to match the schema, got %q, %v", violations, err)
+			}
+		})
+	}
+}
+
+func TestCheckSchema_Violations(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	client, _ := GetClient("opencode")
+	data := []byte(`{"$schema": "https://opencode.ai/config.json", "mcp": {"fs": {"type": "local", "command": "npx"}}}`)
+	violations, err := client.CheckSchema("opencode.json", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.ContainsFunc(violations, func(v string) bool { return strings.HasPrefix(v, "mcp.fs: ") }) {
+		t.Errorf("expected the string command to be flagged, got %q", violations)
+	}
+
+	// Clients without a schema aren't checked
+	cursor, _ := GetClient("cursor")
+	if violations, err := cursor.CheckSchema("mcp.json", []byte(`{"mcpServers": 1}`)); err != nil || violations != nil {
+		t.Errorf("expected no check, got %q, %v", violations, err)
+	}
+}
+
+func TestFetchSchema(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	// The published schema knows more settings than the bundled one, and
+	// no environment for local servers
+	published := `{
+		"type": "object",
+		"required": ["theme"],
+		"properties": {
+			"$schema": {"type": "string"},
+			"theme": {"type": "string"},
+			"mcp": {"type": "object", "additionalProperties": {"$ref": "#/definitions/server"}}
+		},
+		"additionalProperties": false,
+		"definitions": {
+			"server": {
+				"type": "object",
+				"properties": {"type": {"const": "local"}, "command": {"type": "array"}},
+				"additionalProperties": false
+			}
+		}
+	}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/config.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(published))
+	}))
+	defer srv.Close()
+
+	opencode, _ := GetClient("opencode")
+	client := *opencode
+	client.SchemaURL = srv.URL + "/config.json"
+
+	data := []byte(`{"$schema": "https://opencode.ai/config.json", "unknown": 1, "mcp": {"fs": {"type": "local", "command": ["npx"], "environment": {"A": "1"}}}}`)
+	if violations, _ := client.CheckSchema("opencode.json", data); len(violations) != 0 {
+		t.Fatalf("expected the bundled schema to accept the config, got %q", violations)
+	}
+
+	if _, err := client.FetchSchema(context.Background(), srv.Client()); err != nil {
+		t.Fatal(err)
+	}
+	violations, err := client.CheckSchema("opencode.json", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Settings mcpr doesn't write aren't flagged
+	if len(violations) != 1 || !strings.HasPrefix(violations[0], "mcp.fs.environment: ") {
+		t.Errorf("expected only the environment to be flagged, got %q", violations)
+	}
+
+	client.SchemaURL = srv.URL + "/missing.json"
+	if _, err := client.FetchSchema(context.Background(), srv.Client()); err == nil {
+		t.Error("expected a failed download to fail")
+	}
+	cursor, _ := GetClient("cursor")
+	if _, err := cursor.FetchSchema(context.Background(), srv.Client()); err == nil {
+		t.Error("expected a client without a published schema to fail")
+	}
+}
+
+func TestSyncToOpenCode_KeepsSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "opencode.json")
+	os.WriteFile(path, []byte(`{"$schema": "https://opencode.ai/config.json", "theme": "dark"}`), 0o644)
+
+	if err := syncToOpenCode(goldenFixtures(t), path); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), `"$schema": "https://opencode.ai/config.json"`) {
+		t.Errorf("expected $schema to be kept:\n%s", data)
+	}
+}
@@ -0,0 +1,59 @@
+package clients
+
+import (
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/jrandolf/mcpr/config"
+)
+
+// goos is the target operating system; a variable so tests can override it
+var goos = runtime.GOOS
+
+// windowsShimCommands are launchers installed as .cmd scripts on Windows,
+// which most clients can't spawn without going through cmd.exe
+var windowsShimCommands = []string{"npx", "npm", "pnpm", "pnpx", "yarn"}
+
+// shimCommands returns copies of servers whose commands are adapted to the
+// current OS. On Windows, .cmd launchers like npx are wrapped as
+// "cmd /c npx ..." unless the server opts out with noShim.
+func shimCommands(servers []config.MCPServer) []config.MCPServer {
+	if goos != "windows" {
+		return servers
+	}
+
+	shimmed := make([]config.MCPServer, len(servers))
+	for i, server := range servers {
+		if server.Type != "http" && !server.NoShim && needsWindowsShim(server.Command) {
+			server.Args = append([]string{"/c", server.Command}, server.Args...)
+			server.Command = "cmd"
+		}
+		shimmed[i] = server
+	}
+	return shimmed
+}
+
+// needsWindowsShim reports whether command is a .cmd launcher
+func needsWindowsShim(command string) bool {
+	base := strings.ToLower(filepath.Base(command))
+	if ext := filepath.Ext(base); ext == ".cmd" {
+		return true
+	} else if ext != "" {
+		return false
+	}
+	return slices.Contains(windowsShimCommands, base)
+}
+
+// unshimCommand undoes a Windows "cmd /c" wrapper around a .cmd launcher,
+// as found in client configs written by other tools
+func unshimCommand(server config.MCPServer) config.MCPServer {
+	base := strings.ToLower(filepath.Base(server.Command))
+	if (base != "cmd" && base != "cmd.exe") || len(server.Args) < 2 || !strings.EqualFold(server.Args[0], "/c") || !needsWindowsShim(server.Args[1]) {
+		return server
+	}
+	server.Command = server.Args[1]
+	server.Args = slices.Clone(server.Args[2:])
+	return server
+}
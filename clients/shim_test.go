@@ -0,0 +1,67 @@
+package clients
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jrandolf/mcpr/config"
+)
+
+func TestShimCommands_Windows(t *testing.T) {
+	originalGOOS := goos
+	goos = "windows"
+	defer func() { goos = originalGOOS }()
+
+	servers := []config.MCPServer{
+		{Name: "npx", Type: "stdio", Command: "npx", Args: []string{"-y", "pkg"}},
+		{Name: "cmd-ext", Type: "stdio", Command: `C:\tools\pnpm.CMD`},
+		{Name: "exe", Type: "stdio", Command: "node.exe", Args: []string{"server.js"}},
+		{Name: "opt-out", Type: "stdio", Command: "npx", NoShim: true},
+		{Name: "http", Type: "http", URL: "https://example.com/mcp"},
+	}
+
+	shimmed := shimCommands(servers)
+
+	if shimmed[0].Command != "cmd" || strings.Join(shimmed[0].Args, " ") != "/c npx -y pkg" {
+		t.Errorf("expected npx to be shimmed, got %s %v", shimmed[0].Command, shimmed[0].Args)
+	}
+	if shimmed[1].Command != "cmd" {
+		t.Errorf("expected .cmd launcher to be shimmed, got %s", shimmed[1].Command)
+	}
+	if shimmed[2].Command != "node.exe" {
+		t.Errorf("expected .exe to be left alone, got %s", shimmed[2].Command)
+	}
+	if shimmed[3].Command != "npx" {
+		t.Errorf("expected noShim server to be left alone, got %s", shimmed[3].Command)
+	}
+	if shimmed[4].Command != "" {
+		t.Errorf("expected http server to be left alone, got %s", shimmed[4].Command)
+	}
+
+	// The original servers must not be modified
+	if servers[0].Command != "npx" || len(servers[0].Args) != 2 {
+		t.Error("expected original server to be left untouched")
+	}
+}
+
+func TestShimCommands_OtherOS(t *testing.T) {
+	originalGOOS := goos
+	goos = "linux"
+	defer func() { goos = originalGOOS }()
+
+	shimmed := shimCommands([]config.MCPServer{{Name: "npx", Type: "stdio", Command: "npx"}})
+	if shimmed[0].Command != "npx" {
+		t.Errorf("expected no shim outside Windows, got %s", shimmed[0].Command)
+	}
+}
+
+func TestUnshimCommand(t *testing.T) {
+	server := unshimCommand(config.MCPServer{Command: "CMD.EXE", Args: []string{"/C", "npx", "-y", "pkg"}})
+	if server.Command != "npx" || strings.Join(server.Args, " ") != "-y pkg" {
+		t.Errorf("expected the wrapper to be removed, got %s %v", server.Command, server.Args)
+	}
+	server = unshimCommand(config.MCPServer{Command: "cmd", Args: []string{"/c", "script.bat"}})
+	if server.Command != "cmd" {
+		t.Errorf("expected other cmd invocations to be kept, got %s %v", server.Command, server.Args)
+	}
+}
@@ -0,0 +1,151 @@
+package clients
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/jrandolf/mcpr/config"
+)
+
+// snapshotLimit is the number of known good renderings kept per client
+// config file
+const snapshotLimit = 5
+
+// snapshotDir returns the directory holding the snapshots of the client
+// config at path. Local configs of different projects are kept apart by
+// keying on the path.
+func (c *Client) snapshotDir(path string) (string, error) {
+	state, err := config.StateDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(path))
+	key := c.Name + "-" + hex.EncodeToString(sum[:6])
+	return filepath.Join(state, "snapshots", key), nil
+}
+
+// Snapshot validates the client config mcpr just wrote to path and keeps it
+// as the last known good rendering. Unchanged renderings aren't stored
+// twice, and only the newest snapshotLimit are kept.
+func (c *Client) Snapshot(path string) error {
+	data, err := os.ReadFile(longPath(path))
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var doc map[string]any
+	if err := config.Unmarshal(data, config.FormatForPath(path), &doc); err != nil {
+		return fmt.Errorf("%s doesn't parse after sync: %w", path, err)
+	}
+
+	dir, err := c.snapshotDir(path)
+	if err != nil {
+		return err
+	}
+	snapshots, err := listSnapshots(dir)
+	if err != nil {
+		return err
+	}
+	if len(snapshots) > 0 {
+		if latest, err := os.ReadFile(snapshots[len(snapshots)-1]); err == nil && bytes.Equal(latest, data) {
+			return nil
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	// Client configs may hold secrets, so snapshots are private
+	name := time.Now().UTC().Format("20060102T150405.000000000Z")
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0o600); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	snapshots = append(snapshots, filepath.Join(dir, name))
+	for len(snapshots) > snapshotLimit {
+		os.Remove(snapshots[0])
+		snapshots = snapshots[1:]
+	}
+	return nil
+}
+
+// Rollback restores the client config to the newest known good rendering
+// that differs from the file as it is now, and returns the config path
+func (c *Client) Rollback(local bool) (string, error) {
+	path, err := c.Path(local)
+	if err != nil {
+		return "", err
+	}
+	dir, err := c.snapshotDir(path)
+	if err != nil {
+		return "", err
+	}
+	snapshots, err := listSnapshots(dir)
+	if err != nil {
+		return "", err
+	}
+	if len(snapshots) == 0 {
+		return "", fmt.Errorf("no known good config of %s for %s; sync it first", c.DisplayName, path)
+	}
+
+	current, _ := os.ReadFile(longPath(path))
+	for _, snapshot := range slices.Backward(snapshots) {
+		data, err := os.ReadFile(snapshot)
+		if err != nil {
+			return "", fmt.Errorf("failed to read snapshot: %w", err)
+		}
+		if bytes.Equal(data, current) {
+			continue
+		}
+		// Existing files keep their permissions; a recreated one may hold secrets
+		if err := config.WriteFile(longPath(path), data, true); err != nil {
+			return "", fmt.Errorf("failed to restore %s: %w", path, err)
+		}
+		return path, nil
+	}
+	return "", fmt.Errorf("%s already matches every known good config", path)
+}
+
+// listSnapshots returns the snapshot files in dir, oldest first
+func listSnapshots(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshots: %w", err)
+	}
+	var snapshots []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			snapshots = append(snapshots, filepath.Join(dir, entry.Name()))
+		}
+	}
+	// Names are UTC timestamps, so lexical order is chronological
+	slices.Sort(snapshots)
+	return snapshots, nil
+}
+
+// SnapshotEntries returns the server entries of the newest snapshot of the
+// client config at path, which are those mcpr last wrote there, and whether
+// there is a snapshot
+func (c *Client) SnapshotEntries(path string) (map[string]any, bool) {
+	dir, err := c.snapshotDir(path)
+	if err != nil {
+		return nil, false
+	}
+	snapshots, err := listSnapshots(dir)
+	if err != nil || len(snapshots) == 0 {
+		return nil, false
+	}
+	data, err := os.ReadFile(snapshots[len(snapshots)-1])
+	if err != nil {
+		return nil, false
+	}
+	return c.ParseServerEntries(path, data), true
+}
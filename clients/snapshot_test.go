@@ -0,0 +1,133 @@
+package clients
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSnapshotAndRollback(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	configPath := filepath.Join(t.TempDir(), "mcp.json")
+	originalFunc := getCursorConfigPath
+	getCursorConfigPath = func() (string, error) { return configPath, nil }
+	defer func() { getCursorConfigPath = originalFunc }()
+	client, _ := GetClient("cursor")
+
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	read := func() string {
+		t.Helper()
+		data, err := os.ReadFile(configPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	if _, err := client.Rollback(false); err == nil || !strings.Contains(err.Error(), "sync it first") {
+		t.Errorf("expected error without snapshots, got %v", err)
+	}
+
+	write(`{"mcpServers":{"a":{}}}`)
+	if err := client.Snapshot(configPath); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	write(`{"mcpServers":{"b":{}}}`)
+	if err := client.Snapshot(configPath); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// An unchanged rendering isn't stored again
+	if err := client.Snapshot(configPath); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	dir, _ := client.snapshotDir(configPath)
+	if snapshots, _ := listSnapshots(dir); len(snapshots) != 2 {
+		t.Errorf("expected 2 snapshots, got %d", len(snapshots))
+	}
+
+	// The file is damaged outside mcpr: rollback restores the latest sync
+	write("{broken")
+	if _, err := client.Rollback(false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := read(); got != `{"mcpServers":{"b":{}}}` {
+		t.Errorf("expected latest snapshot to be restored, got %s", got)
+	}
+
+	// Rolling back again steps to the sync before
+	if _, err := client.Rollback(false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := read(); got != `{"mcpServers":{"a":{}}}` {
+		t.Errorf("expected previous snapshot to be restored, got %s", got)
+	}
+}
+
+func TestSnapshot_InvalidConfig(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	configPath := filepath.Join(t.TempDir(), "mcp.json")
+	if err := os.WriteFile(configPath, []byte("{broken"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	client, _ := GetClient("cursor")
+	if err := client.Snapshot(configPath); err == nil {
+		t.Error("expected an unparseable config not to be kept as known good")
+	}
+}
+
+func TestSnapshot_Limit(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	configPath := filepath.Join(t.TempDir(), "mcp.json")
+	client, _ := GetClient("cursor")
+
+	for i := range snapshotLimit + 2 {
+		content := `{"n":` + strings.Repeat("1", i+1) + `}`
+		if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := client.Snapshot(configPath); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	dir, _ := client.snapshotDir(configPath)
+	snapshots, _ := listSnapshots(dir)
+	if len(snapshots) != snapshotLimit {
+		t.Fatalf("expected %d snapshots, got %d", snapshotLimit, len(snapshots))
+	}
+	newest, _ := os.ReadFile(snapshots[len(snapshots)-1])
+	if string(newest) != `{"n":`+strings.Repeat("1", snapshotLimit+2)+`}` {
+		t.Errorf("expected the newest rendering to be kept, got %s", newest)
+	}
+}
+
+func TestSnapshotEntries(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	configPath := filepath.Join(t.TempDir(), "mcp.json")
+	client, _ := GetClient("cursor")
+
+	if _, ok := client.SnapshotEntries(configPath); ok {
+		t.Error("expected no snapshot before syncing")
+	}
+	if err := os.WriteFile(configPath, []byte(`{"mcpServers":{"a":{"command":"npx"}}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Snapshot(configPath); err != nil {
+		t.Fatal(err)
+	}
+	// Later edits outside mcpr aren't what it wrote
+	if err := os.WriteFile(configPath, []byte(`{"mcpServers":{"b":{"command":"uvx"}}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	entries, ok := client.SnapshotEntries(configPath)
+	if _, hasA := entries["a"]; !ok || !hasA || len(entries) != 1 {
+		t.Errorf("expected the entries of the snapshot, got %v, %v", entries, ok)
+	}
+}
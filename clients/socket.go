@@ -0,0 +1,33 @@
+package clients
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jrandolf/mcpr/config"
+)
+
+// executable returns the path of the running mcpr binary; a variable so
+// tests can override it
+var executable = os.Executable
+
+// bridgeSockets returns copies of servers with socket servers turned into
+// stdio servers running "mcpr bridge socket <path>", since no client speaks
+// to Unix sockets itself
+func bridgeSockets(servers []config.MCPServer) ([]config.MCPServer, error) {
+	bridged := make([]config.MCPServer, len(servers))
+	for i, server := range servers {
+		if server.Type == "socket" {
+			mcpr, err := executable()
+			if err != nil {
+				return nil, fmt.Errorf("server %q: failed to locate mcpr for the socket bridge: %w", server.Name, err)
+			}
+			server.Type = "stdio"
+			server.Command = mcpr
+			server.Args = []string{"bridge", "socket", server.Path}
+			server.Path = ""
+		}
+		bridged[i] = server
+	}
+	return bridged, nil
+}
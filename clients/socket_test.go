@@ -0,0 +1,47 @@
+package clients
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jrandolf/mcpr/config"
+)
+
+func TestBridgeSockets(t *testing.T) {
+	original := executable
+	executable = func() (string, error) { return "/usr/local/bin/mcpr", nil }
+	defer func() { executable = original }()
+
+	servers := []config.MCPServer{
+		{Name: "sock", Type: "socket", Path: "/tmp/weather.sock"},
+		{Name: "fs", Type: "stdio", Command: "npx"},
+	}
+	bridged, err := bridgeSockets(servers)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sock := bridged[0]
+	if sock.Type != "stdio" || sock.Command != "/usr/local/bin/mcpr" || sock.Path != "" {
+		t.Errorf("expected socket server to become a bridge command, got %+v", sock)
+	}
+	if len(sock.Args) != 3 || sock.Args[0] != "bridge" || sock.Args[1] != "socket" || sock.Args[2] != "/tmp/weather.sock" {
+		t.Errorf("unexpected bridge args: %v", sock.Args)
+	}
+	if bridged[1].Command != "npx" {
+		t.Errorf("expected stdio server to be unchanged, got %+v", bridged[1])
+	}
+	if servers[0].Type != "socket" {
+		t.Error("expected the original servers to be left untouched")
+	}
+}
+
+func TestBridgeSockets_NoExecutable(t *testing.T) {
+	original := executable
+	executable = func() (string, error) { return "", errors.New("unknown") }
+	defer func() { executable = original }()
+
+	if _, err := bridgeSockets([]config.MCPServer{{Name: "sock", Type: "socket", Path: "/tmp/s"}}); err == nil {
+		t.Error("expected error when mcpr can't be located")
+	}
+}
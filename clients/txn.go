@@ -0,0 +1,137 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jrandolf/mcpr/config"
+)
+
+// Transaction writes staged client configs together: they are validated
+// before any is written, and if writing one fails, those already written
+// are restored. The zero value is ready to use.
+type Transaction struct {
+	staged  []Staged
+	written []backup
+}
+
+// backup is what a file held before a transaction wrote it
+type backup struct {
+	path   string
+	orig   string // The path as staged, before long path handling
+	data   []byte // nil if the file didn't exist
+	perm   os.FileMode
+	exists bool
+}
+
+// Add stages a config to be written on Commit
+func (t *Transaction) Add(staged Staged) {
+	t.staged = append(t.staged, staged)
+}
+
+// Commit writes the staged configs, giving up before the first write if
+// ctx is canceled. On failure every config is left as it was.
+func (t *Transaction) Commit(ctx context.Context) error {
+	for _, s := range t.staged {
+		var doc map[string]any
+		if err := config.Unmarshal(s.Data, config.FormatForPath(s.Path), &doc); err != nil {
+			return fmt.Errorf("rendered %s doesn't parse: %w", s.Path, err)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	for _, s := range t.staged {
+		if err := t.write(s); err != nil {
+			if rbErr := t.Rollback(); rbErr != nil {
+				return errors.Join(err, rbErr)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// write backs up the file at s.Path and writes s over it
+func (t *Transaction) write(s Staged) error {
+	path := longPath(s.Path)
+	b := backup{path: path, orig: s.Path}
+	if info, err := os.Stat(path); err == nil {
+		b.data, err = os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", s.Path, err)
+		}
+		b.perm = info.Mode().Perm()
+		b.exists = true
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", s.Path, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	// Recorded before writing, since a failed write may leave the file
+	// half written, and kept on disk too in case mcpr doesn't live to roll
+	// it back
+	t.written = append(t.written, b)
+	if err := b.keep(s.Path, s.Data); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to back up %s before writing it: %v\n", s.Path, err)
+	}
+	if err := writeFile(path, s.Data, s.Secret); err != nil {
+		return fmt.Errorf("failed to write %s: %w", s.Path, err)
+	}
+	if err := verifyWritten(path, s); err != nil {
+		return err
+	}
+	dropPending(s.Path)
+	return nil
+}
+
+// writeFile writes a client config; a variable so tests can cut writes
+// short
+var writeFile = config.WriteFile
+
+// verifyWritten reads back the config s was written to and checks that it
+// holds what was written and parses, so a write cut short is rolled back
+// instead of being left for the client to choke on
+func verifyWritten(path string, s Staged) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read back %s: %w", s.Path, err)
+	}
+	if !bytes.Equal(data, s.Data) {
+		return fmt.Errorf("%s doesn't hold what was written to it", s.Path)
+	}
+	var doc map[string]any
+	if err := config.Unmarshal(data, config.FormatForPath(s.Path), &doc); err != nil {
+		return fmt.Errorf("%s doesn't parse after writing: %w", s.Path, err)
+	}
+	return nil
+}
+
+// Rollback restores the configs written by Commit to what they held
+// before, removing those that didn't exist
+func (t *Transaction) Rollback() error {
+	var errs []error
+	for i := len(t.written) - 1; i >= 0; i-- {
+		b := t.written[i]
+		var err error
+		if b.exists {
+			err = config.AtomicWriteFile(b.path, b.data, b.perm)
+		} else if err = os.Remove(b.path); os.IsNotExist(err) {
+			err = nil
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore %s: %w", b.path, err))
+			continue
+		}
+		dropPending(b.orig)
+	}
+	t.written = nil
+	return errors.Join(errs...)
+}
@@ -0,0 +1,164 @@
+package clients
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jrandolf/mcpr/config"
+)
+
+func TestTransaction(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.json")
+	created := filepath.Join(dir, "created.json")
+	os.WriteFile(existing, []byte(`{"a": 1}`), 0o600)
+
+	var txn Transaction
+	txn.Add(Staged{Path: existing, Data: []byte(`{"a": 2}`)})
+	txn.Add(Staged{Path: created, Data: []byte(`{"b": 1}`)})
+	if err := txn.Commit(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(existing); string(data) != `{"a": 2}` {
+		t.Errorf("expected the existing config to be written, got %s", data)
+	}
+
+	// Rolling back restores the existing config, with its mode, and
+	// removes the new one
+	if err := txn.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(existing); string(data) != `{"a": 1}` {
+		t.Errorf("expected the existing config to be restored, got %s", data)
+	}
+	if info, err := os.Stat(existing); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("expected mode 0600 to be kept, got %v", info.Mode())
+	}
+	if _, err := os.Stat(created); !os.IsNotExist(err) {
+		t.Errorf("expected the new config to be removed, got %v", err)
+	}
+}
+
+func TestTransaction_RollsBackOnFailure(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.json")
+	os.WriteFile(first, []byte(`{"a": 1}`), 0o644)
+	// A directory can't be written over
+	blocked := filepath.Join(dir, "blocked.json")
+	os.Mkdir(blocked, 0o755)
+
+	var txn Transaction
+	txn.Add(Staged{Path: first, Data: []byte(`{"a": 2}`)})
+	txn.Add(Staged{Path: blocked, Data: []byte(`{}`)})
+	if err := txn.Commit(context.Background()); err == nil {
+		t.Fatal("expected the commit to fail")
+	}
+	if data, _ := os.ReadFile(first); string(data) != `{"a": 1}` {
+		t.Errorf("expected the first config to be restored, got %s", data)
+	}
+}
+
+func TestTransaction_ValidatesFirst(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.json")
+
+	var txn Transaction
+	txn.Add(Staged{Path: good, Data: []byte(`{}`)})
+	txn.Add(Staged{Path: filepath.Join(dir, "bad.json"), Data: []byte(`{"a":`)})
+	if err := txn.Commit(context.Background()); err == nil {
+		t.Fatal("expected invalid data to fail the commit")
+	}
+	if _, err := os.Stat(good); !os.IsNotExist(err) {
+		t.Errorf("expected nothing to be written, got %v", err)
+	}
+}
+
+func TestTransaction_VerifiesWrites(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.json")
+	codex := filepath.Join(dir, "config.toml")
+	os.WriteFile(first, []byte(`{"a": 1}`), 0o644)
+	os.WriteFile(codex, []byte("[mcp_servers.a]\ncommand = \"x\"\n"), 0o644)
+
+	// The write of the TOML config is cut short
+	defer func(old func(string, []byte, bool) error) { writeFile = old }(writeFile)
+	writeFile = func(path string, data []byte, secret bool) error {
+		if filepath.Ext(path) == ".toml" {
+			data = data[:len(data)/2]
+		}
+		return config.WriteFile(path, data, secret)
+	}
+
+	var txn Transaction
+	txn.Add(Staged{Path: first, Data: []byte(`{"a": 2}`)})
+	txn.Add(Staged{Path: codex, Data: []byte("[mcp_servers.b]\ncommand = \"y\"\n")})
+	if err := txn.Commit(context.Background()); err == nil {
+		t.Fatal("expected the commit to fail")
+	}
+	if data, _ := os.ReadFile(codex); string(data) != "[mcp_servers.a]\ncommand = \"x\"\n" {
+		t.Errorf("expected the TOML config to be restored, got %q", data)
+	}
+	if data, _ := os.ReadFile(first); string(data) != `{"a": 1}` {
+		t.Errorf("expected the first config to be restored, got %s", data)
+	}
+	if restored, err := RecoverInterrupted(codex); restored || err != nil {
+		t.Errorf("expected no backup to be left, got %v, %v", restored, err)
+	}
+}
+
+func TestRecoverInterrupted(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	codex := filepath.Join(t.TempDir(), "config.toml")
+	before := "model = \"o3\"\n\n[mcp_servers.a]\ncommand = \"x\"\n"
+	after := "model = \"o3\"\n\n[mcp_servers.a]\ncommand = \"x\"\n\n[mcp_servers.b]\ncommand = \"y\"\n"
+	os.WriteFile(codex, []byte(before), 0o600)
+
+	// A crash midway through writing leaves the backup and a broken file
+	b := backup{path: codex, data: []byte(before), perm: 0o600, exists: true}
+	if err := b.keep(codex, []byte(after)); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(codex, []byte("[mcp_servers.b]\ncomma"), 0o600)
+
+	restored, err := RecoverInterrupted(codex)
+	if err != nil || !restored {
+		t.Fatalf("expected the config to be restored, got %v, %v", restored, err)
+	}
+	if data, _ := os.ReadFile(codex); string(data) != before {
+		t.Errorf("expected %q, got %q", before, data)
+	}
+
+	// A file cut short right after a table header is still valid TOML,
+	// but isn't what was written
+	b.keep(codex, []byte(after))
+	os.WriteFile(codex, []byte("model = \"o3\"\n\n[mcp_servers.a]\n"), 0o600)
+	restored, err = RecoverInterrupted(codex)
+	if err != nil || !restored {
+		t.Fatalf("expected the truncated config to be restored, got %v, %v", restored, err)
+	}
+	if data, _ := os.ReadFile(codex); string(data) != before {
+		t.Errorf("expected %q, got %q", before, data)
+	}
+
+	// A write that finished before the crash is kept
+	b.keep(codex, []byte(after))
+	os.WriteFile(codex, []byte(after), 0o600)
+	if restored, err := RecoverInterrupted(codex); restored || err != nil {
+		t.Errorf("expected a complete write to be kept, got %v, %v", restored, err)
+	}
+	if data, _ := os.ReadFile(codex); string(data) != after {
+		t.Errorf("expected %q, got %q", after, data)
+	}
+
+	// As is a write that never started
+	b.keep(codex, []byte(after))
+	os.WriteFile(codex, []byte(before), 0o600)
+	if restored, err := RecoverInterrupted(codex); restored || err != nil {
+		t.Errorf("expected an untouched config to be kept, got %v, %v", restored, err)
+	}
+}
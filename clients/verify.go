@@ -0,0 +1,160 @@
+package clients
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"github.com/jrandolf/mcpr/config"
+)
+
+// Render returns the client's global or local config path and what syncing
+// servers would make of the file, without writing it
+func (c *Client) Render(servers []config.MCPServer, local bool) (string, []byte, error) {
+	staged, err := c.Stage(servers, local)
+	if err != nil {
+		return "", nil, err
+	}
+	return staged.Path, staged.Data, nil
+}
+
+// Staged is a client config rendered for a sync, to be written later
+type Staged struct {
+	Client *Client
+	Path   string
+	Data   []byte
+	Secret bool // Holds env vars or headers, so a new file is private
+}
+
+// Stage renders what syncing servers would make of the client's global or
+// local config, without writing it, so it can be written together with
+// other configs in a Transaction
+func (c *Client) Stage(servers []config.MCPServer, local bool) (Staged, error) {
+	return c.StagePartial(servers, nil, local)
+}
+
+// StagePartial is like Stage, but the servers named in asIs were read back
+// from the client's config with EntryServer and are written as they are
+// rather than prepared again, so a sync can replace some entries and leave
+// the others alone
+func (c *Client) StagePartial(servers []config.MCPServer, asIs map[string]bool, local bool) (Staged, error) {
+	path, err := c.Path(local)
+	if err != nil {
+		return Staged{}, err
+	}
+	fresh := make([]config.MCPServer, 0, len(servers))
+	for _, server := range servers {
+		if !asIs[server.Name] {
+			fresh = append(fresh, server)
+		}
+	}
+	fresh, err = c.prepareServers(fresh, local)
+	if err != nil {
+		return Staged{}, err
+	}
+	if len(asIs) == 0 {
+		servers = fresh
+	} else {
+		prepared := make(map[string]config.MCPServer, len(fresh))
+		for _, server := range fresh {
+			prepared[server.Name] = server
+		}
+		merged := make([]config.MCPServer, 0, len(servers))
+		for _, server := range servers {
+			if asIs[server.Name] {
+				merged = append(merged, server)
+			} else if p, ok := prepared[server.Name]; ok {
+				merged = append(merged, p)
+			}
+		}
+		servers = merged
+	}
+
+	// Sync into a private copy that keeps the file name, so clients pick
+	// the same format and merge into the same settings
+	dir, err := os.MkdirTemp("", "mcpr-render")
+	if err != nil {
+		return Staged{}, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	rendered := filepath.Join(dir, filepath.Base(path))
+	if data, err := os.ReadFile(longPath(path)); err == nil {
+		if err := os.WriteFile(rendered, data, 0o600); err != nil {
+			return Staged{}, fmt.Errorf("failed to copy %s: %w", path, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return Staged{}, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if err := c.SyncFunc(servers, rendered); err != nil {
+		return Staged{}, err
+	}
+	data, err := os.ReadFile(rendered)
+	if err != nil {
+		return Staged{}, fmt.Errorf("failed to read rendered config: %w", err)
+	}
+	return Staged{Client: c, Path: path, Data: data, Secret: config.HasSecrets(servers)}, nil
+}
+
+// Verify reports whether the client's global or local config holds what
+// syncing servers would write, returning its path. Formatting is ignored;
+// a missing config isn't in sync.
+func (c *Client) Verify(servers []config.MCPServer, local bool) (string, bool, error) {
+	path, want, err := c.Render(servers, local)
+	if err != nil {
+		return "", false, err
+	}
+	have, err := os.ReadFile(longPath(path))
+	if os.IsNotExist(err) {
+		return path, false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	format := config.FormatForPath(path)
+	var wantDoc, haveDoc any
+	if err := config.Unmarshal(want, format, &wantDoc); err != nil {
+		return "", false, fmt.Errorf("failed to parse rendered config: %w", err)
+	}
+	if err := config.Unmarshal(have, format, &haveDoc); err != nil {
+		return path, false, nil
+	}
+	return path, reflect.DeepEqual(wantDoc, haveDoc), nil
+}
+
+// ServerEntries returns the server entries of the client config at path by
+// name. Missing and unparseable configs have none.
+func (c *Client) ServerEntries(path string) map[string]any {
+	data, err := os.ReadFile(longPath(path))
+	if err != nil {
+		return nil
+	}
+	return c.ParseServerEntries(path, data)
+}
+
+// ParseServerEntries is ServerEntries for the content of a config at path
+func (c *Client) ParseServerEntries(path string, data []byte) map[string]any {
+	var doc map[string]any
+	if err := config.Unmarshal(data, config.FormatForPath(path), &doc); err != nil {
+		return nil
+	}
+
+	entries := make(map[string]any)
+	switch block := doc[c.ServersBlockKey()].(type) {
+	case map[string]any:
+		maps.Copy(entries, block)
+	case []any:
+		// Lists of entries carry their names, like Continue's
+		for _, entry := range block {
+			if m, ok := entry.(map[string]any); ok {
+				if name, ok := m["name"].(string); ok {
+					entries[name] = m
+				}
+			}
+		}
+	}
+	return entries
+}
@@ -0,0 +1,50 @@
+package clients
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jrandolf/mcpr/config"
+)
+
+func TestRenderAndVerify(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	t.Setenv("MCPR_ZED_CONFIG", path)
+	client, _ := GetClient("zed")
+	servers := []config.MCPServer{{Name: "fs", Type: "stdio", Command: "npx"}}
+
+	// Rendering leaves the file alone
+	os.WriteFile(path, []byte(`{"theme": "dark"}`), 0644)
+	if _, data, err := client.Render(servers, false); err != nil || len(data) == 0 {
+		t.Fatalf("unexpected render: %q, %v", data, err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != `{"theme": "dark"}` {
+		t.Errorf("expected render not to write the config, got %s", data)
+	}
+	if _, ok, err := client.Verify(servers, false); err != nil || ok {
+		t.Errorf("expected unsynced config not to verify, got %v, %v", ok, err)
+	}
+
+	if _, err := client.Sync(servers, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, err := client.Verify(servers, false); err != nil || !ok {
+		t.Errorf("expected synced config to verify, got %v, %v", ok, err)
+	}
+
+	// Formatting doesn't matter, content does
+	synced, _ := os.ReadFile(path)
+	var doc map[string]any
+	config.Unmarshal(synced, config.FormatJSON, &doc)
+	compact, _ := json.Marshal(doc)
+	os.WriteFile(path, append([]byte("// comment\n"), compact...), 0644)
+	if _, ok, _ := client.Verify(servers, false); !ok {
+		data, _ := os.ReadFile(path)
+		t.Errorf("expected reformatted config to verify:\n%s", data)
+	}
+	if _, ok, _ := client.Verify(append(servers, config.MCPServer{Name: "git", Type: "stdio", Command: "git-mcp"}), false); ok {
+		t.Error("expected config missing a server not to verify")
+	}
+}
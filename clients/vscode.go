@@ -2,7 +2,6 @@ package clients
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 	"runtime"
 
@@ -15,41 +14,49 @@ var (
 	getVSCodeLocalPath  = getVSCodeLocalPathImpl
 )
 
-func init() {
-	RegisterClient(&Client{
+// newVSCodeClient returns the VS Code (Copilot) client
+func newVSCodeClient() *Client {
+	return &Client{
 		Name:          "vscode",
 		DisplayName:   "VS Code (Copilot)",
 		GlobalPath:    func() (string, error) { return getVSCodeConfigPath() },
 		LocalPath:     func() (string, error) { return getVSCodeLocalPath() },
 		SupportsLocal: true,
 		SyncFunc:      syncToVSCodeMCP,
-	})
+		ServersKey:    "servers",
+		NativeVariables: map[string]string{
+			VarHome:            "${userHome}",
+			VarWorkspaceFolder: "${workspaceFolder}",
+			VarProjectRoot:     "${workspaceFolder}",
+		},
+		Schema: embeddedSchema("vscode"),
+	}
 }
 
 func getVSCodeConfigPathImpl() (string, error) {
-	home, err := os.UserHomeDir()
+	home, err := userHomeDir()
 	if err != nil {
 		return "", err
 	}
 
-	switch runtime.GOOS {
+	switch currentOS() {
 	case "darwin":
 		return filepath.Join(home, "Library", "Application Support", "Code", "User", "settings.json"), nil
 	case "windows":
-		appData := os.Getenv("APPDATA")
+		appData := getenv("APPDATA")
 		if appData == "" {
 			appData = filepath.Join(home, "AppData", "Roaming")
 		}
 		return filepath.Join(appData, "Code", "User", "settings.json"), nil
 	case "linux":
-		return filepath.Join(home, ".config", "Code", "User", "settings.json"), nil
+		return filepath.Join(xdgConfigHome(home), "Code", "User", "settings.json"), nil
 	default:
 		return "", fmt.Errorf("unsupported operating system: %s", runtime.GOOS)
 	}
 }
 
 func getVSCodeLocalPathImpl() (string, error) {
-	cwd, err := os.Getwd()
+	cwd, err := workingDir()
 	if err != nil {
 		return "", err
 	}
@@ -58,33 +65,8 @@ func getVSCodeLocalPathImpl() (string, error) {
 
 func syncToVSCodeMCP(servers []config.MCPServer, path string) error {
 	// VS Code uses "servers" key in mcp.json
-	serversMap := make(map[string]any)
-	for _, server := range servers {
-		var entry map[string]any
-		if server.Type == "http" {
-			entry = map[string]any{
-				"url": server.URL,
-			}
-			if len(server.Headers) > 0 {
-				entry["headers"] = server.Headers
-			}
-		} else {
-			entry = map[string]any{
-				"command": server.Command,
-			}
-			if len(server.Args) > 0 {
-				entry["args"] = server.Args
-			}
-			if len(server.Env) > 0 {
-				entry["env"] = server.Env
-			}
-		}
-		serversMap[server.Name] = entry
+	doc := map[string]any{
+		"servers": serverEntries(servers, settingsEntry),
 	}
-
-	config := map[string]any{
-		"servers": serversMap,
-	}
-
-	return saveSettingsFile(path, config)
+	return replaceFile(path, jsonEmitter, doc, config.HasSecrets(servers))
 }
@@ -2,7 +2,6 @@ package clients
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 	"runtime"
 )
@@ -13,41 +12,42 @@ var (
 	getWindsurfLocalPath  = getWindsurfLocalPathImpl
 )
 
-func init() {
-	RegisterClient(&Client{
+// newWindsurfClient returns the Windsurf client
+func newWindsurfClient() *Client {
+	return &Client{
 		Name:          "windsurf",
 		DisplayName:   "Windsurf",
 		GlobalPath:    func() (string, error) { return getWindsurfConfigPath() },
 		LocalPath:     func() (string, error) { return getWindsurfLocalPath() },
 		SupportsLocal: true,
 		SyncFunc:      syncToMCPConfig,
-	})
+	}
 }
 
 func getWindsurfConfigPathImpl() (string, error) {
-	home, err := os.UserHomeDir()
+	home, err := userHomeDir()
 	if err != nil {
 		return "", err
 	}
 
-	switch runtime.GOOS {
+	switch currentOS() {
 	case "darwin":
 		return filepath.Join(home, "Library", "Application Support", "Windsurf", "User", "globalStorage", "windsurf.mcp", "mcp.json"), nil
 	case "windows":
-		appData := os.Getenv("APPDATA")
+		appData := getenv("APPDATA")
 		if appData == "" {
 			appData = filepath.Join(home, "AppData", "Roaming")
 		}
 		return filepath.Join(appData, "Windsurf", "User", "globalStorage", "windsurf.mcp", "mcp.json"), nil
 	case "linux":
-		return filepath.Join(home, ".config", "Windsurf", "User", "globalStorage", "windsurf.mcp", "mcp.json"), nil
+		return filepath.Join(xdgConfigHome(home), "Windsurf", "User", "globalStorage", "windsurf.mcp", "mcp.json"), nil
 	default:
 		return "", fmt.Errorf("unsupported operating system: %s", runtime.GOOS)
 	}
 }
 
 func getWindsurfLocalPathImpl() (string, error) {
-	cwd, err := os.Getwd()
+	cwd, err := workingDir()
 	if err != nil {
 		return "", err
 	}
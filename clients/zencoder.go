@@ -2,9 +2,10 @@ package clients
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 	"runtime"
+
+	"github.com/jrandolf/mcpr/config"
 )
 
 // Path functions as variables for testing
@@ -12,35 +13,42 @@ var (
 	getZencoderConfigPath = getZencoderConfigPathImpl
 )
 
-func init() {
-	RegisterClient(&Client{
+// newZencoderClient returns the ZenCoder client
+func newZencoderClient() *Client {
+	return &Client{
 		Name:          "zencoder",
 		DisplayName:   "ZenCoder",
 		GlobalPath:    func() (string, error) { return getZencoderConfigPath() },
 		LocalPath:     nil,
 		SupportsLocal: false,
-		SyncFunc:      syncToMCPConfig,
-	})
+		SyncFunc:      syncToZencoder,
+	}
 }
 
 func getZencoderConfigPathImpl() (string, error) {
-	home, err := os.UserHomeDir()
+	home, err := userHomeDir()
 	if err != nil {
 		return "", err
 	}
 
-	switch runtime.GOOS {
+	switch currentOS() {
 	case "darwin":
 		return filepath.Join(home, "Library", "Application Support", "Code", "User", "globalStorage", "zencoderAI.zencoder", "mcp_settings.json"), nil
 	case "windows":
-		appData := os.Getenv("APPDATA")
+		appData := getenv("APPDATA")
 		if appData == "" {
 			appData = filepath.Join(home, "AppData", "Roaming")
 		}
 		return filepath.Join(appData, "Code", "User", "globalStorage", "zencoderAI.zencoder", "mcp_settings.json"), nil
 	case "linux":
-		return filepath.Join(home, ".config", "Code", "User", "globalStorage", "zencoderAI.zencoder", "mcp_settings.json"), nil
+		return filepath.Join(xdgConfigHome(home), "Code", "User", "globalStorage", "zencoderAI.zencoder", "mcp_settings.json"), nil
 	default:
 		return "", fmt.Errorf("unsupported operating system: %s", runtime.GOOS)
 	}
 }
+
+// syncToZencoder syncs servers to Zencoder's settings, keeping other settings
+// and fields the extension stores on existing entries
+func syncToZencoder(servers []config.MCPServer, path string) error {
+	return syncPreservingEntries(servers, path, "mcpServers", settingsEntry)
+}
@@ -1,16 +1,28 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/jrandolf/mcpr/config"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
-var addLocal bool
+var (
+	addLocal       bool
+	addDependsOn   []string
+	addDescription string
+	addDocsURL     string
+	addNoShim      bool
+	addTimeout     int
+	addTrust       bool
+)
 
 var addCmd = &cobra.Command{
 	Use:   "add",
@@ -23,13 +35,19 @@ The server configuration will be stored in:
 
 Use one of the subcommands:
   mcpr add stdio  - Add a stdio-based MCP server
-  mcpr add http   - Add an HTTP/SSE-based MCP server`,
+  mcpr add http   - Add an HTTP/SSE-based MCP server
+  mcpr add python - Add a Python package server run with uvx or pipx
+  mcpr add node   - Add a Node package server run with npx
+  mcpr add link   - Add a server shared with 'mcpr share'
+  mcpr add clipboard - Add a server shared with 'mcpr share' from the clipboard`,
 }
 
 // stdio subcommand
 var (
-	stdioName string
-	stdioEnv  []string
+	stdioName    string
+	stdioEnv     []string
+	stdioEnvJSON string
+	stdioEnvFile string
 )
 
 var addStdioCmd = &cobra.Command{
@@ -47,6 +65,10 @@ Examples:
   # Add with environment variables
   mcpr add stdio --env API_KEY=xxx --env DEBUG=true node server.js
 
+  # Take API_KEY from the current environment, or env vars from JSON
+  mcpr add stdio --env API_KEY node server.js
+  mcpr add stdio --env-json '{"API_KEY":"xxx"}' node server.js
+
   # Add to local config
   mcpr add stdio --local ./my-server`,
 	Args: cobra.MinimumNArgs(1),
@@ -55,10 +77,16 @@ Examples:
 
 // http subcommand
 var (
-	httpName    string
-	httpHeaders []string
+	httpName       string
+	httpHeaders    []string
+	httpHeaderFile string
+	httpVerify     bool
 )
 
+// verifyTimeout is the time a server probed with add http --verify gets to
+// answer
+const verifyTimeout = 10 * time.Second
+
 var addHttpCmd = &cobra.Command{
 	Use:   "http [url]",
 	Short: "Add an HTTP/SSE-based MCP server",
@@ -71,8 +99,12 @@ Examples:
   # Add with custom name
   mcpr add http --name my-api https://example.com/mcp
 
-  # Add with headers
-  mcpr add http --header Authorization=Bearer\ token https://example.com/mcp
+  # Add with headers, as Key: Value or Key=Value
+  mcpr add http --header "Authorization: Bearer token" https://example.com/mcp
+  mcpr add http --header-file headers.txt https://example.com/mcp
+
+  # Check that the server answers before saving it
+  mcpr add http --verify https://example.com/mcp
 
   # Add to local config
   mcpr add http --local https://example.com/mcp`,
@@ -80,23 +112,59 @@ Examples:
 	RunE: runAddHttp,
 }
 
+// socket subcommand
+var socketName string
+
+var addSocketCmd = &cobra.Command{
+	Use:   "socket [path]",
+	Short: "Add an MCP server listening on a Unix socket",
+	Long: `Add an MCP server that is already running and listening on a Unix socket.
+Clients reach it through "mcpr bridge socket", which mcpr writes into their
+configs as a stdio command.
+
+Examples:
+  # Add a server listening on a socket
+  mcpr add socket /tmp/weather.sock
+
+  # Add with custom name
+  mcpr add socket --name weather /run/user/1000/mcp.sock`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAddSocket,
+}
+
 func init() {
 	// Parent add command
 	addCmd.PersistentFlags().BoolVarP(&addLocal, "local", "l", false, "Save to local mcpr.json instead of global config")
+	addCmd.PersistentFlags().StringSliceVarP(&addDependsOn, "depends-on", "d", nil, "Servers this server depends on (comma-separated)")
+	_ = addCmd.RegisterFlagCompletionFunc("depends-on", completeList(serverCompletions))
+	addCmd.PersistentFlags().StringVar(&addDescription, "description", "", "What the server is for, shown in list and supporting clients")
+	addCmd.PersistentFlags().StringVar(&addDocsURL, "docs-url", "", "Documentation URL for the server")
+	addCmd.PersistentFlags().IntVar(&addTimeout, "timeout", 0, "Request timeout in seconds, for clients that support one")
+	addCmd.PersistentFlags().BoolVar(&addTrust, "trust", false, "Skip tool call confirmations, for clients that support it")
+	addCmd.PersistentFlags().BoolVar(&addNoShim, "no-shim", false, "Don't wrap npx and similar launchers in \"cmd /c\" when syncing on Windows")
+	addCmd.PersistentFlags().BoolVar(&unlockConfig, "unlock", false, unlockUsage)
 
 	// stdio subcommand flags
-	addStdioCmd.Flags().StringVarP(&stdioName, "name", "n", "", "Server name (defaults to command name)")
-	addStdioCmd.Flags().StringSliceVarP(&stdioEnv, "env", "e", nil, "Environment variables (KEY=VALUE)")
+	addStdioCmd.Flags().StringVarP(&stdioName, "name", "n", "", "Server name (defaults to the package or command name)")
+	addStdioCmd.Flags().StringArrayVarP(&stdioEnv, "env", "e", nil, envUsage)
+	addStdioCmd.Flags().StringVar(&stdioEnvJSON, "env-json", "", envJSONUsage)
+	addStdioCmd.Flags().StringVar(&stdioEnvFile, "env-file", "", ".env file with environment variables, kept out of client configs where supported")
 	// Disable interspersed flags so args like "-y" aren't parsed as flags
 	addStdioCmd.Flags().SetInterspersed(false)
 
 	// http subcommand flags
-	addHttpCmd.Flags().StringVarP(&httpName, "name", "n", "", "Server name (defaults to URL host)")
-	addHttpCmd.Flags().StringSliceVarP(&httpHeaders, "header", "H", nil, "HTTP headers (Key=Value)")
+	addHttpCmd.Flags().StringVarP(&httpName, "name", "n", "", "Server name (defaults to one taken from the URL)")
+	addHttpCmd.Flags().StringArrayVarP(&httpHeaders, "header", "H", nil, "HTTP header as \"Key: Value\" or Key=Value (repeatable)")
+	addHttpCmd.Flags().StringVar(&httpHeaderFile, "header-file", "", "File of HTTP headers, one \"Key: Value\" or Key=Value per line")
+	addHttpCmd.Flags().BoolVar(&httpVerify, "verify", false, "Check that the server answers the MCP initialize request before saving it")
+
+	// socket subcommand flags
+	addSocketCmd.Flags().StringVarP(&socketName, "name", "n", "", "Server name (defaults to the socket file name)")
 
 	// Add subcommands
 	addCmd.AddCommand(addStdioCmd)
 	addCmd.AddCommand(addHttpCmd)
+	addCmd.AddCommand(addSocketCmd)
 }
 
 func runAddStdio(cmd *cobra.Command, args []string) error {
@@ -106,16 +174,13 @@ func runAddStdio(cmd *cobra.Command, args []string) error {
 	// Determine name
 	name := stdioName
 	if name == "" {
-		name = filepath.Base(command)
+		name = stdioServerName(command, serverArgs)
 	}
 
 	// Parse environment variables
-	env := make(map[string]string)
-	for _, e := range stdioEnv {
-		parts := strings.SplitN(e, "=", 2)
-		if len(parts) == 2 {
-			env[parts[0]] = parts[1]
-		}
+	env, err := parseEnv(stdioEnv, stdioEnvJSON, os.Stdin)
+	if err != nil {
+		return err
 	}
 
 	// Load config
@@ -134,38 +199,27 @@ func runAddStdio(cmd *cobra.Command, args []string) error {
 	if len(env) > 0 {
 		server.Env = env
 	}
-
-	// Add and save
-	if err := cfg.AddServer(server); err != nil {
-		return err
-	}
-
-	if err := cfg.Save(); err != nil {
-		return fmt.Errorf("failed to save config: %w", err)
-	}
-
-	fmt.Printf("Added stdio server %q to %s\n", name, cfg.Path())
-	resyncAll(cfg)
-	return nil
+	server.EnvFile = stdioEnvFile
+	return saveNewServer(cmd.Context(), cfg, server, stdioName != "")
 }
 
 func runAddHttp(cmd *cobra.Command, args []string) error {
-	url := args[0]
+	url, err := normalizeServerURL(args[0])
+	if err != nil {
+		return err
+	}
+	warnInsecureURL(url)
 
 	// Determine name
 	name := httpName
 	if name == "" {
-		// Extract host from URL as default name
-		name = extractHostFromURL(url)
+		name = urlServerName(url)
 	}
 
 	// Parse headers
-	headers := make(map[string]string)
-	for _, h := range httpHeaders {
-		parts := strings.SplitN(h, "=", 2)
-		if len(parts) == 2 {
-			headers[parts[0]] = parts[1]
-		}
+	headers, err := parseHeaders(httpHeaders, httpHeaderFile)
+	if err != nil {
+		return err
 	}
 
 	// Load config
@@ -183,19 +237,102 @@ func runAddHttp(cmd *cobra.Command, args []string) error {
 	if len(headers) > 0 {
 		server.Headers = headers
 	}
+	if httpVerify {
+		if err := verifyNewServer(cmd.Context(), cfg, server); err != nil {
+			return err
+		}
+	}
+	return saveNewServer(cmd.Context(), cfg, server, httpName != "")
+}
 
-	// Add and save
-	if err := cfg.AddServer(server); err != nil {
+// verifyNewServer probes server with the MCP initialize request before it
+// is added
+func verifyNewServer(ctx context.Context, cfg *config.Config, server config.MCPServer) error {
+	result, err := checkServer(ctx, server, netOptions(cfg), verifyTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to verify %s: %w\n\nAdd it without --verify to save it anyway", server.URL, err)
+	}
+	fmt.Printf("%s Verified %s (HTTP %d, %s)\n", okMark(), server.URL, result.Status, formatLatency(result.Latency))
+	if result.URL != "" {
+		fmt.Printf("  Redirected to %s\n", result.URL)
+	}
+	return nil
+}
+
+func runAddSocket(cmd *cobra.Command, args []string) error {
+	path, err := filepath.Abs(args[0])
+	if err != nil {
 		return err
 	}
 
-	if err := cfg.Save(); err != nil {
-		return fmt.Errorf("failed to save config: %w", err)
+	// Determine name
+	name := socketName
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
 	}
 
-	fmt.Printf("Added http server %q to %s\n", name, cfg.Path())
-	resyncAll(cfg)
-	return nil
+	// Load config
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	// Create server
+	server := config.MCPServer{
+		Name: name,
+		Type: "socket",
+		Path: path,
+	}
+	return saveNewServer(cmd.Context(), cfg, server, socketName != "")
+}
+
+// Usage of the env var flags shared by the add subcommands
+const (
+	envUsage     = "Environment variable as KEY=VALUE, or KEY to take its value from the current environment (repeatable)"
+	envJSONUsage = "Environment variables as a JSON object, or - to read it from stdin"
+)
+
+// saveNewServer applies the shared add flags that were given to server,
+// adds it to cfg, saves and resyncs all synced clients, failing if any
+// client does. explicitName tells whether the user chose its name, which is
+// only changed with their consent if it's taken.
+func saveNewServer(ctx context.Context, cfg *config.Config, server config.MCPServer, explicitName bool) error {
+	if len(addDependsOn) > 0 {
+		server.DependsOn = addDependsOn
+	}
+	if addDescription != "" {
+		server.Description = addDescription
+	}
+	if addDocsURL != "" {
+		server.DocsURL = addDocsURL
+	}
+	if addTimeout != 0 {
+		server.Timeout = addTimeout
+	}
+	if addTrust {
+		server.Trust = true
+	}
+	if addNoShim && server.Type == "stdio" {
+		server.NoShim = true
+	}
+	warnUnknownDependencies(cfg, server.DependsOn)
+	if server.Provenance == nil {
+		server.Provenance = &config.Provenance{Source: config.SourceManual}
+	}
+	server.Provenance.Added = time.Now().UTC().Truncate(time.Second)
+
+	name, err := resolveServerName(os.Stdout, os.Stdin, term.IsTerminal(int(os.Stdin.Fd())), cfg, server.Name, explicitName)
+	if err != nil {
+		return err
+	}
+	server.Name = name
+
+	// Add, then save and resync together
+	if err := cfg.AddServer(server); err != nil {
+		return err
+	}
+
+	return saveAndResync(ctx, os.Stdout, cfg, fmt.Sprintf("Added %s server %q to %s", server.Type, server.Name, cfg.Path()))
 }
 
 func loadConfig() (*config.Config, error) {
@@ -208,34 +345,33 @@ func loadConfig() (*config.Config, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to load config: %w", err)
 		}
-		return cfg, nil
+		return applyUnlock(cfg), nil
 	}
 
 	cfg, err := config.Load()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
-	return cfg, nil
+	return applyUnlock(cfg), nil
 }
 
-func extractHostFromURL(url string) string {
-	// Remove protocol
-	s := url
-	if strings.HasPrefix(s, "https://") {
-		s = s[8:]
-	} else if strings.HasPrefix(s, "http://") {
-		s = s[7:]
-	}
-
-	// Take only host part (before first /)
-	if idx := strings.Index(s, "/"); idx != -1 {
-		s = s[:idx]
+// parseKeyValues parses KEY=VALUE pairs, ignoring entries without "="
+func parseKeyValues(pairs []string) map[string]string {
+	values := make(map[string]string)
+	for _, p := range pairs {
+		parts := strings.SplitN(p, "=", 2)
+		if len(parts) == 2 {
+			values[parts[0]] = parts[1]
+		}
 	}
+	return values
+}
 
-	// Remove port
-	if idx := strings.Index(s, ":"); idx != -1 {
-		s = s[:idx]
+// warnUnknownDependencies warns about dependencies that aren't configured yet
+func warnUnknownDependencies(cfg *config.Config, deps []string) {
+	for _, dep := range deps {
+		if _, err := cfg.GetServer(dep); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: dependency %q is not configured\n", dep)
+		}
 	}
-
-	return s
 }
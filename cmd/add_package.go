@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/jrandolf/mcpr/config"
+
+	"github.com/spf13/cobra"
+)
+
+// python subcommand
+var (
+	pythonName    string
+	pythonEnv     []string
+	pythonEnvJSON string
+	pythonVersion string
+	pythonRunner  string
+)
+
+var addPythonCmd = &cobra.Command{
+	Use:   "python [package] [args...]",
+	Short: "Add a Python package MCP server run with uvx or pipx",
+	Long: `Add an MCP server published as a Python package. The server is run with
+uvx, or with pipx when uvx isn't installed.
+
+Examples:
+  # Add a server from PyPI
+  mcpr add python mcp-server-fetch
+
+  # Pin a version
+  mcpr add python mcp-server-fetch==2025.1.17
+  mcpr add python --version 2025.1.17 mcp-server-fetch
+
+  # Use pipx and pass arguments to the server
+  mcpr add python --runner pipx mcp-server-git --repository .`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runAddPython,
+}
+
+// node subcommand
+var (
+	nodeName    string
+	nodeEnv     []string
+	nodeEnvJSON string
+	nodeVersion string
+)
+
+var addNodeCmd = &cobra.Command{
+	Use:   "node [package] [args...]",
+	Short: "Add a Node package MCP server run with npx",
+	Long: `Add an MCP server published as an npm package. The server is run with npx.
+
+Examples:
+  # Add a server from npm
+  mcpr add node @modelcontextprotocol/server-filesystem /path
+
+  # Pin a version
+  mcpr add node @modelcontextprotocol/server-memory@2025.4.25
+  mcpr add node --version 2025.4.25 @modelcontextprotocol/server-memory`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runAddNode,
+}
+
+// lookPath finds runtimes on PATH; a variable so tests can override it
+var lookPath = exec.LookPath
+
+func init() {
+	addPythonCmd.Flags().StringVarP(&pythonName, "name", "n", "", "Server name (defaults to the package name)")
+	addPythonCmd.Flags().StringArrayVarP(&pythonEnv, "env", "e", nil, envUsage)
+	addPythonCmd.Flags().StringVar(&pythonEnvJSON, "env-json", "", envJSONUsage)
+	addPythonCmd.Flags().StringVar(&pythonVersion, "version", "", "Package version to pin")
+	addPythonCmd.Flags().StringVar(&pythonRunner, "runner", "", "Package runner: uvx or pipx (defaults to uvx if installed)")
+	addPythonCmd.Flags().SetInterspersed(false)
+
+	addNodeCmd.Flags().StringVarP(&nodeName, "name", "n", "", "Server name (defaults to the package name)")
+	addNodeCmd.Flags().StringArrayVarP(&nodeEnv, "env", "e", nil, envUsage)
+	addNodeCmd.Flags().StringVar(&nodeEnvJSON, "env-json", "", envJSONUsage)
+	addNodeCmd.Flags().StringVar(&nodeVersion, "version", "", "Package version to pin")
+	addNodeCmd.Flags().SetInterspersed(false)
+
+	addCmd.AddCommand(addPythonCmd)
+	addCmd.AddCommand(addNodeCmd)
+}
+
+func runAddPython(cmd *cobra.Command, args []string) error {
+	pkg, version := splitPythonPackage(args[0])
+	if pythonVersion != "" {
+		version = pythonVersion
+	}
+
+	runner := pythonRunner
+	if runner == "" {
+		runner = "uvx"
+		if _, err := lookPath("uvx"); err != nil {
+			if _, err := lookPath("pipx"); err == nil {
+				runner = "pipx"
+			}
+		}
+	}
+	command, runnerArgs, err := pythonInvocation(runner, pkg, version)
+	if err != nil {
+		return err
+	}
+	warnMissingRuntime(command)
+
+	name := pythonName
+	if name == "" {
+		name = packageServerName(pkg)
+	}
+	env, err := parseEnv(pythonEnv, pythonEnvJSON, os.Stdin)
+	if err != nil {
+		return err
+	}
+	provenance := config.Provenance{Source: config.SourcePackage, Registry: "pypi", Package: pkg, Version: version}
+	return addPackageServer(cmd.Context(), name, pythonName != "", command, append(runnerArgs, args[1:]...), env, provenance)
+}
+
+func runAddNode(cmd *cobra.Command, args []string) error {
+	pkg, version := splitNodePackage(args[0])
+	if nodeVersion != "" {
+		version = nodeVersion
+	}
+	warnMissingRuntime("npx")
+
+	spec := pkg
+	if version != "" {
+		spec += "@" + version
+	}
+
+	name := nodeName
+	if name == "" {
+		name = packageServerName(pkg)
+	}
+	env, err := parseEnv(nodeEnv, nodeEnvJSON, os.Stdin)
+	if err != nil {
+		return err
+	}
+	provenance := config.Provenance{Source: config.SourcePackage, Registry: "npm", Package: pkg, Version: version}
+	return addPackageServer(cmd.Context(), name, nodeName != "", "npx", append([]string{"-y", spec}, args[1:]...), env, provenance)
+}
+
+// addPackageServer adds a stdio server running a package, under a name the
+// user chose if explicitName is set
+func addPackageServer(ctx context.Context, name string, explicitName bool, command string, args []string, env map[string]string, provenance config.Provenance) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	server := config.MCPServer{
+		Name:       name,
+		Type:       "stdio",
+		Command:    command,
+		Args:       args,
+		Provenance: &provenance,
+	}
+	if len(env) > 0 {
+		server.Env = env
+	}
+	return saveNewServer(ctx, cfg, server, explicitName)
+}
+
+// pythonInvocation returns the command and arguments that run a Python
+// package with the given runner
+func pythonInvocation(runner, pkg, version string) (string, []string, error) {
+	switch runner {
+	case "uvx":
+		if version != "" {
+			return "uvx", []string{pkg + "@" + version}, nil
+		}
+		return "uvx", []string{pkg}, nil
+	case "pipx":
+		if version != "" {
+			return "pipx", []string{"run", "--spec", pkg + "==" + version, pkg}, nil
+		}
+		return "pipx", []string{"run", pkg}, nil
+	default:
+		return "", nil, fmt.Errorf("unknown runner %q (expected \"uvx\" or \"pipx\")", runner)
+	}
+}
+
+// warnMissingRuntime warns when a package runner isn't on PATH. The server is
+// still added since clients may run with a different PATH.
+func warnMissingRuntime(command string) {
+	if _, err := lookPath(command); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %s was not found on PATH; install it before starting this server\n", command)
+	}
+}
+
+// splitPythonPackage splits "pkg==1.0" or "pkg@1.0" into package and version
+func splitPythonPackage(spec string) (string, string) {
+	if pkg, version, ok := strings.Cut(spec, "=="); ok {
+		return pkg, version
+	}
+	if pkg, version, ok := strings.Cut(spec, "@"); ok {
+		return pkg, version
+	}
+	return spec, ""
+}
+
+// splitNodePackage splits "pkg@1.0" or "@scope/pkg@1.0" into package and
+// version
+func splitNodePackage(spec string) (string, string) {
+	if idx := strings.LastIndex(spec, "@"); idx > 0 {
+		return spec[:idx], spec[idx+1:]
+	}
+	return spec, ""
+}
+
+// packageServerName derives a server name from a package name, dropping npm
+// scopes and common MCP prefixes and suffixes:
+// "@modelcontextprotocol/server-filesystem" -> "filesystem",
+// "mcp_server_fetch" -> "fetch"
+func packageServerName(pkg string) string {
+	name := strings.ToLower(pkg)
+	if idx := strings.LastIndex(name, "/"); idx != -1 {
+		// "@playwright/mcp" is named by its scope
+		scope := strings.TrimPrefix(name[:idx], "@")
+		if name = name[idx+1:]; isGenericName(name) && scope != "" && !strings.Contains(scope, "/") {
+			name = scope
+		}
+	}
+	name = strings.ReplaceAll(name, "_", "-")
+
+	for _, prefix := range []string{"mcp-server-", "server-", "mcp-"} {
+		if trimmed := strings.TrimPrefix(name, prefix); trimmed != name && trimmed != "" {
+			name = trimmed
+			break
+		}
+	}
+	for _, suffix := range []string{"-mcp-server", "-server", "-mcp"} {
+		if trimmed := strings.TrimSuffix(name, suffix); trimmed != name && trimmed != "" {
+			name = trimmed
+			break
+		}
+	}
+	return name
+}
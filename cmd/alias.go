@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jrandolf/mcpr/config"
+
+	"github.com/spf13/cobra"
+)
+
+// deprecatedCommands maps deprecated command paths to their replacements.
+// Invocations starting with a deprecated path are rewritten before cobra
+// parses them, so old scripts keep working while printing a warning.
+var deprecatedCommands = map[string]string{}
+
+// registerDeprecated forwards the command path old to replacement
+func registerDeprecated(old, replacement string) {
+	deprecatedCommands[old] = replacement
+}
+
+var aliasCmd = &cobra.Command{
+	Use:   "alias",
+	Short: "Manage command aliases",
+	Long: `Manage user-defined command aliases stored in your mcpr config.
+
+An alias expands to a command path and optional arguments. Anything after the
+alias on the command line is appended to the expansion.
+
+Examples:
+  mcpr alias set s "client sync"
+  mcpr s cursor            # runs: mcpr client sync cursor
+  mcpr alias list
+  mcpr alias remove s`,
+}
+
+var aliasSetCmd = &cobra.Command{
+	Use:   "set [name] [expansion]",
+	Short: "Add or replace an alias",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runAliasSet,
+}
+
+var aliasListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List configured aliases",
+	Args:  cobra.NoArgs,
+	RunE:  runAliasList,
+}
+
+var aliasRemoveCmd = &cobra.Command{
+	Use:     "remove [name]",
+	Aliases: []string{"rm"},
+	Short:   "Remove an alias",
+	Args:    cobra.ExactArgs(1),
+	RunE:    runAliasRemove,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) != 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		cfg, err := config.Load()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		var names []string
+		for name := range cfg.Aliases {
+			names = append(names, name)
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+func init() {
+	aliasCmd.PersistentFlags().BoolVar(&unlockConfig, "unlock", false, unlockUsage)
+	aliasCmd.AddCommand(aliasSetCmd)
+	aliasCmd.AddCommand(aliasListCmd)
+	aliasCmd.AddCommand(aliasRemoveCmd)
+}
+
+func runAliasSet(cmd *cobra.Command, args []string) error {
+	name, expansion := args[0], args[1]
+
+	if isBuiltinCommand(name) {
+		return fmt.Errorf("%q is a built-in command and cannot be aliased", name)
+	}
+	if len(strings.Fields(expansion)) == 0 {
+		return fmt.Errorf("alias expansion cannot be empty")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if err := applyUnlock(cfg).CheckLocked(); err != nil {
+		return err
+	}
+	cfg.SetAlias(name, expansion)
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	fmt.Printf("Alias %q %s %q saved to %s\n", name, arrow(), expansion, cfg.Path())
+	return nil
+}
+
+func runAliasList(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if len(cfg.Aliases) == 0 {
+		fmt.Println("No aliases configured.")
+		fmt.Println("Use 'mcpr alias set' to add one.")
+		return nil
+	}
+
+	names := make([]string, 0, len(cfg.Aliases))
+	for name := range cfg.Aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Printf("Aliases (from %s):\n\n", cfg.Path())
+	for _, name := range names {
+		fmt.Printf("  %s %s %s\n", name, arrow(), cfg.Aliases[name])
+	}
+	return nil
+}
+
+func runAliasRemove(cmd *cobra.Command, args []string) error {
+	name := args[0]
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if err := applyUnlock(cfg).CheckLocked(); err != nil {
+		return err
+	}
+	if err := cfg.RemoveAlias(name); err != nil {
+		return err
+	}
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	fmt.Printf("Removed alias %q from %s\n", name, cfg.Path())
+	return nil
+}
+
+// isBuiltinCommand reports whether name is a top-level command or command alias
+func isBuiltinCommand(name string) bool {
+	for _, c := range rootCmd.Commands() {
+		if c.Name() == name || c.HasAlias(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// expandArgs rewrites command-line arguments for deprecated command paths
+// and user-defined aliases. Built-in commands always take precedence over
+// aliases. Shell completion requests are rewritten the same way, so
+// arguments of aliased commands complete too.
+func expandArgs(args []string, aliases map[string]string) []string {
+	if len(args) > 0 && (args[0] == cobra.ShellCompRequestCmd || args[0] == cobra.ShellCompNoDescRequestCmd) {
+		// The last argument is the word being completed, which may be an
+		// alias still being typed
+		if len(args) < 3 {
+			return args
+		}
+		return append([]string{args[0]}, expandArgs(args[1:], aliases)...)
+	}
+
+	for old, replacement := range deprecatedCommands {
+		oldPath := strings.Fields(old)
+		if hasPrefix(args, oldPath) {
+			fmt.Fprintf(os.Stderr, "Warning: 'mcpr %s' is deprecated, use 'mcpr %s' instead\n", old, replacement)
+			return append(strings.Fields(replacement), args[len(oldPath):]...)
+		}
+	}
+
+	if len(args) == 0 || isBuiltinCommand(args[0]) {
+		return args
+	}
+	if expansion, ok := aliases[args[0]]; ok {
+		return append(strings.Fields(expansion), args[1:]...)
+	}
+	return args
+}
+
+// hasPrefix reports whether args starts with prefix
+func hasPrefix(args, prefix []string) bool {
+	if len(prefix) == 0 || len(args) < len(prefix) {
+		return false
+	}
+	for i, p := range prefix {
+		if args[i] != p {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jrandolf/mcpr/config"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	benchTool       string
+	benchArgs       string
+	benchIterations int
+)
+
+var benchCmd = &cobra.Command{
+	Use:   "bench [server...]",
+	Short: "Measure the latency of configured servers",
+	Long: `Connect to each server and measure the handshake time, the latency
+percentiles of repeated requests and their throughput. Requests are pings
+unless --tool names a tool to call. Pass several servers to compare them, such
+as a local stdio server and its remote http equivalent.
+
+Examples:
+  mcpr bench filesystem
+  mcpr bench --tool search --args '{"query": "mcp"}' --iterations 50 search-local search-remote`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeServerName,
+	RunE:              runBench,
+}
+
+func init() {
+	benchCmd.Flags().StringVar(&benchTool, "tool", "", "Tool to call instead of sending pings")
+	benchCmd.Flags().StringVar(&benchArgs, "args", "", "Tool arguments as a JSON object")
+	benchCmd.Flags().IntVarP(&benchIterations, "iterations", "n", 20, "Number of requests per server")
+}
+
+func runBench(cmd *cobra.Command, args []string) error {
+	if benchIterations < 1 {
+		return fmt.Errorf("--iterations must be at least 1")
+	}
+	var toolArgs json.RawMessage
+	if benchArgs != "" {
+		if !json.Valid([]byte(benchArgs)) {
+			return fmt.Errorf("--args must be valid JSON")
+		}
+		toolArgs = json.RawMessage(benchArgs)
+	}
+
+	var results []*benchResult
+	for _, name := range args {
+		server, err := loadServer(name)
+		if err != nil {
+			return err
+		}
+		result, err := benchServer(cmd.Context(), server, benchTool, toolArgs, benchIterations)
+		if err != nil {
+			return err
+		}
+		results = append(results, result)
+	}
+	return printBenchResults(os.Stdout, results)
+}
+
+// benchResult holds the measurements for one server
+type benchResult struct {
+	Server    string
+	Handshake time.Duration
+	Latencies []time.Duration // Sorted latencies of successful requests
+	Elapsed   time.Duration   // Time spent on all requests
+	Errors    int
+}
+
+// benchServer connects to server and sends iterations requests one after
+// another: calls to tool if set, pings otherwise
+func benchServer(ctx context.Context, server config.MCPServer, tool string, args json.RawMessage, iterations int) (*benchResult, error) {
+	start := time.Now()
+	client, err := connectServer(ctx, server)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+	result := &benchResult{Server: server.Name, Handshake: time.Since(start)}
+
+	request := func() error {
+		return client.Request(ctx, "ping", nil, nil)
+	}
+	if tool != "" {
+		request = func() error {
+			res, err := client.CallTool(ctx, tool, args)
+			if err == nil && res.IsError {
+				err = fmt.Errorf("tool %s reported an error", tool)
+			}
+			return err
+		}
+	}
+
+	start = time.Now()
+	for range iterations {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		begin := time.Now()
+		if err := request(); err != nil {
+			// Report the first failure; the rest are only counted
+			if result.Errors == 0 {
+				fmt.Fprintf(os.Stderr, "Warning: %s: %v\n", server.Name, err)
+			}
+			result.Errors++
+			continue
+		}
+		result.Latencies = append(result.Latencies, time.Since(begin))
+	}
+	result.Elapsed = time.Since(start)
+	slices.Sort(result.Latencies)
+	return result, nil
+}
+
+// percentile returns the latency below which p percent of requests fell
+func (r *benchResult) percentile(p int) time.Duration {
+	if len(r.Latencies) == 0 {
+		return 0
+	}
+	i := (len(r.Latencies)*p + 99) / 100
+	return r.Latencies[max(i-1, 0)]
+}
+
+// throughput returns the successful requests per second
+func (r *benchResult) throughput() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	return float64(len(r.Latencies)) / r.Elapsed.Seconds()
+}
+
+// printBenchResults writes one row of measurements per server
+func printBenchResults(w io.Writer, results []*benchResult) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SERVER\tHANDSHAKE\tP50\tP90\tP99\tMAX\tREQ/S\tERRORS")
+	for _, r := range results {
+		var maxLatency time.Duration
+		if len(r.Latencies) > 0 {
+			maxLatency = r.Latencies[len(r.Latencies)-1]
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%.1f\t%d\n",
+			r.Server,
+			formatLatency(r.Handshake),
+			formatLatency(r.percentile(50)),
+			formatLatency(r.percentile(90)),
+			formatLatency(r.percentile(99)),
+			formatLatency(maxLatency),
+			r.throughput(),
+			r.Errors,
+		)
+	}
+	return tw.Flush()
+}
+
+// formatLatency rounds a duration for display
+func formatLatency(d time.Duration) string {
+	switch {
+	case d == 0:
+		return "-"
+	case d < time.Millisecond:
+		return d.Round(time.Microsecond).String()
+	default:
+		return d.Round(10 * time.Microsecond).String()
+	}
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var bridgeCmd = &cobra.Command{
+	Use:   "bridge",
+	Short: "Bridge servers to stdio for clients",
+	Long: `Bridge servers that clients can't reach natively to stdio. Clients run
+these commands themselves; mcpr writes them into client configs when syncing
+socket servers.`,
+}
+
+var bridgeSocketCmd = &cobra.Command{
+	Use:   "socket [path]",
+	Short: "Relay stdio to an MCP server listening on a Unix socket",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return bridgeSocket(cmd.Context(), args[0], os.Stdin, os.Stdout)
+	},
+}
+
+func init() {
+	bridgeCmd.AddCommand(bridgeSocketCmd)
+}
+
+// bridgeSocket connects to the Unix socket at path and relays in to it and
+// its replies to out, until the server closes the connection
+func bridgeSocket(ctx context.Context, path string, in io.Reader, out io.Writer) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "unix", path)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", path, err)
+	}
+	defer conn.Close()
+
+	// Closing the connection unblocks the relay when the command is canceled
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	go func() {
+		io.Copy(conn, in)
+		// Tell the server the client is done, but keep reading its replies
+		if uc, ok := conn.(*net.UnixConn); ok {
+			uc.CloseWrite()
+		}
+	}()
+
+	if _, err := io.Copy(out, conn); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("failed to read from %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,508 @@
+package cmd
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jrandolf/mcpr/clients"
+	"github.com/jrandolf/mcpr/config"
+
+	"github.com/spf13/cobra"
+)
+
+var bugreportOutput string
+
+var bugreportCmd = &cobra.Command{
+	Use:   "bugreport",
+	Short: "Collect a sanitized bundle to attach to bug reports",
+	Long: `Collect everything needed to reproduce a sync problem into a zip file:
+
+  - report.json   OS, mcpr version and how every config path was resolved
+  - mcpr.json     your servers and synced clients
+  - clients/      the servers blocks of your synced clients' configs
+
+Env values, headers and values of keys that look like tokens, secrets or
+passwords are replaced with "<redacted>", as are values of secret-looking
+flags in args, credentials in URLs and bearer tokens. The rest of a client's
+config, such as history or account details, is left out. Review the bundle
+before sharing it.
+
+Reproduce a bundle locally with 'mcpr replay'.
+
+Examples:
+  mcpr bugreport
+  mcpr bugreport -o report.zip`,
+	Args: cobra.NoArgs,
+	RunE: runBugreport,
+}
+
+var (
+	replayDir  string
+	replayKeep bool
+)
+
+var replayCmd = &cobra.Command{
+	Use:   "replay [bundle]",
+	Short: "Reproduce the sync behavior captured in a bug report bundle",
+	Long: `Replay a bundle created with 'mcpr bugreport'. The reporter's client configs
+are restored into a sandbox directory (like 'mcpr simulate') and their synced
+clients are resynced there, so you can inspect exactly what mcpr writes.
+
+Examples:
+  mcpr replay mcpr-bugreport-20250101-120000.zip
+  mcpr replay --dir ./sandbox report.zip`,
+	Args: cobra.ExactArgs(1),
+	RunE: runReplay,
+}
+
+func init() {
+	bugreportCmd.Flags().StringVarP(&bugreportOutput, "output", "o", "", "Bundle path (defaults to mcpr-bugreport-<time>.zip)")
+
+	replayCmd.Flags().StringVarP(&replayDir, "dir", "d", "", "Sandbox directory (defaults to a temporary directory)")
+	replayCmd.Flags().BoolVarP(&replayKeep, "keep", "k", false, "Keep the temporary sandbox directory")
+}
+
+// redactedValue replaces secrets in bug report bundles
+const redactedValue = "<redacted>"
+
+// bugReport is the report.json manifest of a bug report bundle
+type bugReport struct {
+	Version      string                `json:"version"`
+	OS           string                `json:"os"`
+	Arch         string                `json:"arch"`
+	GoVersion    string                `json:"goVersion"`
+	Created      string                `json:"created"`
+	ConfigPath   string                `json:"configPath"`
+	ConfigReason string                `json:"configReason"`
+	Layers       []bugReportLayer      `json:"layers"`
+	Clients      []bugReportClientPath `json:"clients"`
+}
+
+// bugReportConfig is the mcpr.json of a bug report bundle: the reporter's
+// servers together with the state a resync needs, which real configs keep in
+// the state file
+type bugReportConfig struct {
+	Servers       []config.MCPServer             `json:"servers"`
+	SyncedClients []config.SyncedClient          `json:"synced_clients,omitempty"`
+	Health        map[string]config.HealthRecord `json:"health,omitempty"`
+}
+
+type bugReportLayer struct {
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	Exists bool   `json:"exists"`
+}
+
+type bugReportClientPath struct {
+	Client string `json:"client"`
+	Scope  string `json:"scope"`
+	Path   string `json:"path,omitempty"`
+	Exists bool   `json:"exists"`
+	Error  string `json:"error,omitempty"`
+	File   string `json:"file,omitempty"` // Excerpt in the bundle, if included
+	Note   string `json:"note,omitempty"`
+}
+
+func runBugreport(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	output := bugreportOutput
+	if output == "" {
+		output = fmt.Sprintf("mcpr-bugreport-%s.zip", time.Now().Format("20060102-150405"))
+	}
+
+	f, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf("failed to create bundle: %w", err)
+	}
+	defer f.Close()
+
+	if err := writeBugReport(f, cfg); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+
+	fmt.Printf("Wrote %s\n", output)
+	fmt.Println("Secrets were redacted, but review the bundle before attaching it to an issue.")
+	return nil
+}
+
+// writeBugReport writes a bug report bundle for cfg as a zip archive to w
+func writeBugReport(w io.Writer, cfg *config.Config) error {
+	zw := zip.NewWriter(w)
+
+	report := bugReport{
+		Version:   version,
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		GoVersion: runtime.Version(),
+		Created:   time.Now().UTC().Format(time.RFC3339),
+	}
+	if path, reason, err := config.ExplainConfigPath(); err == nil {
+		report.ConfigPath, report.ConfigReason = path, reason
+	}
+	for _, layer := range cfg.Layers() {
+		report.Layers = append(report.Layers, bugReportLayer{Name: layer.Name, Path: layer.Path, Exists: layer.Exists})
+	}
+
+	// Servers include inherited ones so a replay sees what the reporter saw
+	bundled := bugReportConfig{Servers: cfg.ListServers(), SyncedClients: cfg.GetSyncedClients(), Health: cfg.Health}
+	if err := writeZipJSON(zw, "mcpr.json", redactSecrets(toGeneric(bundled))); err != nil {
+		return err
+	}
+
+	names := clients.ListClientNames()
+	sort.Strings(names)
+	for _, name := range names {
+		client, _ := clients.GetClient(name)
+		scopes := []bool{false}
+		if client.SupportsLocal {
+			scopes = append(scopes, true)
+		}
+		for _, local := range scopes {
+			entry := bugReportClientPath{Client: name, Scope: "global"}
+			if local {
+				entry.Scope = "local"
+			}
+			path, err := client.Path(local)
+			if err != nil {
+				entry.Error = err.Error()
+				report.Clients = append(report.Clients, entry)
+				continue
+			}
+			entry.Path = path
+			_, statErr := os.Stat(path)
+			entry.Exists = statErr == nil
+
+			if entry.Exists && cfg.GetSyncedClient(name, local) != nil {
+				file := bundleClientFile(name, local, path)
+				if err := writeClientExcerpt(zw, file, path, client.ServersBlockKey()); err != nil {
+					entry.Note = err.Error()
+				} else {
+					entry.File = file
+				}
+			}
+			report.Clients = append(report.Clients, entry)
+		}
+	}
+
+	if err := writeZipJSON(zw, "report.json", report); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return nil
+}
+
+// bundleClientFile returns the bundle path of a client config excerpt
+func bundleClientFile(name string, local bool, path string) string {
+	if local {
+		name += ".local"
+	}
+	return "clients/" + name + filepath.Ext(path)
+}
+
+// writeClientExcerpt adds a redacted copy of the servers block at key of a
+// client config to the bundle. The rest of the config is left out: clients
+// like Claude Code keep history and account details next to their servers.
+func writeClientExcerpt(zw *zip.Writer, file, path, key string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("not included: %w", err)
+	}
+
+	format := config.FormatForPath(path)
+	var doc map[string]any
+	if err := config.Unmarshal(data, format, &doc); err != nil {
+		return fmt.Errorf("not included: failed to parse: %w", err)
+	}
+	excerpt := map[string]any{}
+	if servers, ok := doc[key]; ok {
+		excerpt[key] = servers
+	}
+	redacted := redactSecrets(excerpt)
+
+	switch format {
+	case config.FormatJSON:
+		return writeZipJSON(zw, file, redacted)
+	default:
+		out, err := config.Marshal(redacted, format)
+		if err != nil {
+			return err
+		}
+		return writeZipFile(zw, file, out)
+	}
+}
+
+// toGeneric converts v to maps and slices through its JSON encoding
+func toGeneric(v any) any {
+	data, _ := json.Marshal(v)
+	var out any
+	json.Unmarshal(data, &out)
+	return out
+}
+
+// secretContainerKeys hold maps whose values are all treated as secrets
+var secretContainerKeys = []string{"env", "environment", "headers", "http_headers", "env_http_headers"}
+
+// secretKeyHints mark keys whose string values are treated as secrets
+var secretKeyHints = []string{"token", "secret", "password", "apikey", "api_key", "authorization"}
+
+// secretParamHints mark flags and URL query parameters whose values are
+// treated as secrets
+var secretParamHints = []string{"token", "secret", "password", "passwd", "key", "auth", "credential", "sig"}
+
+// bearerPattern matches the credentials of Authorization-style values
+var bearerPattern = regexp.MustCompile(`(?i)\b(bearer)\s+[^\s"']+|\b(basic)\s+[A-Za-z0-9+/=]{8,}`)
+
+// urlPattern matches URLs within strings
+var urlPattern = regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://[^\s"']+`)
+
+// redactSecrets returns v with env values, headers and secret-looking values
+// replaced by a placeholder
+func redactSecrets(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, val := range v {
+			key := strings.ToLower(k)
+			switch {
+			case slices.Contains(secretContainerKeys, key):
+				if m, ok := val.(map[string]any); ok {
+					redacted := make(map[string]any, len(m))
+					for name := range m {
+						redacted[name] = redactedValue
+					}
+					out[k] = redacted
+					continue
+				}
+			case containsAny(key, secretKeyHints):
+				if _, ok := val.(string); ok {
+					out[k] = redactedValue
+					continue
+				}
+			}
+			out[k] = redactSecrets(val)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, val := range v {
+			out[i] = redactSecrets(val)
+		}
+		redactFlagValues(out)
+		return out
+	case string:
+		return redactString(v)
+	case []map[string]any:
+		out := make([]any, len(v))
+		for i, val := range v {
+			out[i] = redactSecrets(val)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// redactFlagValues redacts, in place, the values of secret-looking flags in
+// args: "--api-key=value" and "--api-key value" alike
+func redactFlagValues(args []any) {
+	for i, arg := range args {
+		s, ok := arg.(string)
+		if !ok || !strings.HasPrefix(s, "-") {
+			continue
+		}
+		name, _, hasValue := strings.Cut(s, "=")
+		if !containsAny(strings.ToLower(strings.TrimLeft(name, "-")), secretParamHints) {
+			continue
+		}
+		if hasValue {
+			args[i] = name + "=" + redactedValue
+		} else if i+1 < len(args) {
+			if next, ok := args[i+1].(string); ok && !strings.HasPrefix(next, "-") {
+				args[i+1] = redactedValue
+			}
+		}
+	}
+}
+
+// redactString redacts bearer tokens in s, and the passwords and secret
+// query parameters of URLs in it
+func redactString(s string) string {
+	s = bearerPattern.ReplaceAllString(s, "$1$2 "+redactedValue)
+	return urlPattern.ReplaceAllStringFunc(s, func(raw string) string {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return raw
+		}
+		if _, ok := u.User.Password(); ok {
+			raw = strings.Replace(raw, u.User.String()+"@", u.User.Username()+":"+redactedValue+"@", 1)
+		}
+		if u.RawQuery == "" {
+			return raw
+		}
+		params := strings.Split(u.RawQuery, "&")
+		for i, param := range params {
+			name, _, _ := strings.Cut(param, "=")
+			if containsAny(strings.ToLower(name), secretParamHints) {
+				params[i] = name + "=" + redactedValue
+			}
+		}
+		return strings.Replace(raw, "?"+u.RawQuery, "?"+strings.Join(params, "&"), 1)
+	})
+}
+
+// containsAny reports whether key contains one of words
+func containsAny(key string, words []string) bool {
+	for _, w := range words {
+		if strings.Contains(key, w) {
+			return true
+		}
+	}
+	return false
+}
+
+func writeZipJSON(zw *zip.Writer, name string, v any) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", name, err)
+	}
+	return writeZipFile(zw, name, buf.Bytes())
+}
+
+func writeZipFile(zw *zip.Writer, name string, data []byte) error {
+	fw, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s to bundle: %w", name, err)
+	}
+	if _, err := fw.Write(data); err != nil {
+		return fmt.Errorf("failed to add %s to bundle: %w", name, err)
+	}
+	return nil
+}
+
+func runReplay(cmd *cobra.Command, args []string) error {
+	root := replayDir
+	if root == "" {
+		var err error
+		root, err = os.MkdirTemp("", "mcpr-replay-*")
+		if err != nil {
+			return fmt.Errorf("failed to create sandbox: %w", err)
+		}
+		if !replayKeep {
+			defer os.RemoveAll(root)
+		}
+	}
+
+	if err := replay(cmd.Context(), os.Stdout, args[0], root); err != nil {
+		return err
+	}
+	if replayDir != "" || replayKeep {
+		fmt.Printf("\nSandbox: %s\n", root)
+	}
+	return nil
+}
+
+// replay restores the client configs of a bundle into the sandbox at root and
+// resyncs the reporter's synced clients there
+func replay(ctx context.Context, w io.Writer, bundle, root string) error {
+	zr, err := zip.OpenReader(bundle)
+	if err != nil {
+		return fmt.Errorf("failed to open bundle: %w", err)
+	}
+	defer zr.Close()
+
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	data, err := readZipFile(files, "mcpr.json")
+	if err != nil {
+		return err
+	}
+	var bundled bugReportConfig
+	if err := json.Unmarshal(data, &bundled); err != nil {
+		return fmt.Errorf("failed to parse bundled config: %w", err)
+	}
+	cfg := &config.Config{Servers: bundled.Servers, SyncedClients: bundled.SyncedClients, Health: bundled.Health}
+
+	var report bugReport
+	if data, err := readZipFile(files, "report.json"); err == nil {
+		json.Unmarshal(data, &report)
+		fmt.Fprintf(w, "Replaying a report from mcpr %s on %s/%s\n\n", report.Version, report.OS, report.Arch)
+	}
+
+	home, project, err := prepareSandbox(root)
+	if err != nil {
+		return err
+	}
+	restore, err := enterSandbox(home, project)
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	// Restore the reporter's client configs at this machine's paths
+	for _, entry := range report.Clients {
+		if entry.File == "" {
+			continue
+		}
+		client, err := clients.GetClient(entry.Client)
+		if err != nil {
+			continue
+		}
+		path, err := client.Path(entry.Scope == "local")
+		if err != nil {
+			continue
+		}
+		data, err := readZipFile(files, entry.File)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", entry.File, err)
+		}
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", entry.File, err)
+		}
+	}
+
+	return resyncAllTo(ctx, w, cfg)
+}
+
+func readZipFile(files map[string]*zip.File, name string) ([]byte, error) {
+	f, ok := files[name]
+	if !ok {
+		return nil, fmt.Errorf("bundle has no %s", name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
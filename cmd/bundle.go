@@ -0,0 +1,519 @@
+package cmd
+
+import (
+	"archive/zip"
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jrandolf/mcpr/config"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// bundleFormat is the version of the bundle layout. Bundles of a newer
+// format are refused rather than half imported.
+const bundleFormat = 1
+
+// bundlePassphraseEnv supplies the passphrase of encrypted bundles without
+// a prompt, for scripts
+const bundlePassphraseEnv = "MCPR_BUNDLE_PASSPHRASE"
+
+// bundleIterations is the PBKDF2 work factor deriving bundle keys
+const bundleIterations = 600_000
+
+var (
+	bundleOutput  string
+	bundleEncrypt bool
+	bundleForce   bool
+	bundleSync    bool
+)
+
+var bundleCmd = &cobra.Command{
+	Use:   "bundle",
+	Short: "Move your mcpr setup to another machine",
+}
+
+var bundleExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Write the global config to a portable bundle",
+	Long: `Write your global config and synced clients to a single zip file that
+'mcpr bundle import' restores on another machine:
+
+  - manifest.json   mcpr version and bundle format
+  - mcpr.json       the global config and the clients it was synced to
+  - secrets.json    secrets from env vars and headers, or secrets.enc when
+                    encrypted with --encrypt
+
+With --encrypt the secrets are encrypted with a passphrase you are asked for,
+or read from $` + bundlePassphraseEnv + `. Without it they are stored in plain
+text, so keep the bundle private.
+
+Examples:
+  mcpr bundle export
+  mcpr bundle export --encrypt -o laptop.zip`,
+	Args: cobra.NoArgs,
+	RunE: runBundleExport,
+}
+
+var bundleImportCmd = &cobra.Command{
+	Use:   "import [bundle]",
+	Short: "Restore a bundle made by mcpr bundle export",
+	Long: `Restore the servers, aliases and settings of a bundle made by 'mcpr bundle
+export' into the global config, asking for the passphrase if its secrets are
+encrypted.
+
+Servers, aliases and settings already configured are kept unless --force is
+given.
+With --sync the clients the bundle was synced to are synced again here;
+otherwise the commands to do so are printed.
+
+Examples:
+  mcpr bundle import mcpr-bundle-20250101-120000.zip
+  mcpr bundle import --sync laptop.zip`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBundleImport,
+}
+
+func init() {
+	bundleExportCmd.Flags().StringVarP(&bundleOutput, "output", "o", "", "Bundle path (defaults to mcpr-bundle-<time>.zip)")
+	bundleExportCmd.Flags().BoolVar(&bundleEncrypt, "encrypt", false, "Encrypt secrets with a passphrase")
+	bundleImportCmd.Flags().BoolVarP(&bundleForce, "force", "f", false, "Replace servers, aliases and settings that are already configured")
+	bundleImportCmd.Flags().BoolVar(&bundleSync, "sync", false, "Sync the clients the bundle was synced to")
+	bundleImportCmd.Flags().BoolVar(&unlockConfig, "unlock", false, unlockUsage)
+
+	bundleCmd.AddCommand(bundleExportCmd)
+	bundleCmd.AddCommand(bundleImportCmd)
+}
+
+// bundleManifest is the manifest.json of a bundle
+type bundleManifest struct {
+	Format  int    `json:"format"`
+	Version string `json:"version"`
+	Created string `json:"created"`
+}
+
+// bundleConfig is the mcpr.json of a bundle: the global config and the
+// clients it was synced to. Secrets are left out of the servers and kept in
+// secrets.json or secrets.enc.
+type bundleConfig struct {
+	*config.Config
+	SyncedClients []config.SyncedClient `json:"syncedClients,omitempty"`
+}
+
+// bundleSecrets are the secrets of a bundle's servers and machine overrides,
+// by server name or machine:<machine>/<server> and then as named by
+// serverSecrets
+type bundleSecrets map[string]map[string]string
+
+// encryptedSecrets is the secrets.enc of a bundle: bundleSecrets as JSON,
+// sealed with AES-256-GCM under a key derived from a passphrase
+type encryptedSecrets struct {
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Data       []byte `json:"data"`
+}
+
+func runBundleExport(cmd *cobra.Command, args []string) error {
+	cfg, err := loadGlobalConfig()
+	if err != nil {
+		return err
+	}
+
+	passphrase := ""
+	if bundleEncrypt {
+		if passphrase, err = readPassphrase(true); err != nil {
+			return err
+		}
+	}
+
+	output := bundleOutput
+	if output == "" {
+		output = fmt.Sprintf("mcpr-bundle-%s.zip", time.Now().Format("20060102-150405"))
+	}
+	f, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create bundle: %w", err)
+	}
+	defer f.Close()
+
+	secrets, err := writeBundle(f, cfg, passphrase)
+	if err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+
+	fmt.Printf("Wrote %d server(s) to %s\n", len(cfg.Servers), output)
+	if secrets > 0 && passphrase == "" {
+		fmt.Fprintf(os.Stderr, "Warning: the bundle holds %d secret(s) in plain text; keep it private or export with --encrypt\n", secrets)
+	}
+	return nil
+}
+
+// loadGlobalConfig loads the global config, whatever the current directory
+func loadGlobalConfig() (*config.Config, error) {
+	path, err := config.GetWriteConfigPath(false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config path: %w", err)
+	}
+	cfg, err := config.LoadFromPath(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	return applyUnlock(cfg), nil
+}
+
+// writeBundle writes a bundle of cfg's own config and global synced clients
+// as a zip archive to w, and returns how many secrets it holds. Secrets are
+// encrypted with passphrase, if given.
+func writeBundle(w io.Writer, cfg *config.Config, passphrase string) (int, error) {
+	zw := zip.NewWriter(w)
+
+	manifest := bundleManifest{Format: bundleFormat, Version: version, Created: time.Now().UTC().Format(time.RFC3339)}
+	if err := writeZipJSON(zw, "manifest.json", manifest); err != nil {
+		return 0, err
+	}
+
+	// A copy through JSON holds exactly what the config file does
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode config: %w", err)
+	}
+	bundled := bundleConfig{Config: &config.Config{}}
+	if err := json.Unmarshal(data, bundled.Config); err != nil {
+		return 0, fmt.Errorf("failed to encode config: %w", err)
+	}
+	secrets := bundleSecrets{}
+	count := 0
+	for i := range bundled.Servers {
+		count += secrets.take(bundled.Servers[i].Name, &bundled.Servers[i])
+	}
+	eachMachineOverride(bundled.Config, func(key string, server *config.MCPServer) {
+		count += secrets.take(key, server)
+	})
+	// Local syncs belong to project directories, which don't move along
+	for _, sc := range cfg.GetSyncedClients() {
+		if !sc.Local {
+			bundled.SyncedClients = append(bundled.SyncedClients, config.SyncedClient{Name: sc.Name, Servers: sc.Servers, Exclude: sc.Exclude})
+		}
+	}
+	if err := writeZipJSON(zw, "mcpr.json", bundled); err != nil {
+		return 0, err
+	}
+
+	if passphrase == "" {
+		if err := writeZipJSON(zw, "secrets.json", secrets); err != nil {
+			return 0, err
+		}
+	} else {
+		sealed, err := encryptSecrets(secrets, passphrase)
+		if err != nil {
+			return 0, err
+		}
+		if err := writeZipJSON(zw, "secrets.enc", sealed); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return 0, fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return count, nil
+}
+
+func runBundleImport(cmd *cobra.Command, args []string) error {
+	bundled, err := readBundle(args[0], func() (string, error) { return readPassphrase(false) })
+	if err != nil {
+		return err
+	}
+	cfg, err := loadGlobalConfig()
+	if err != nil {
+		return err
+	}
+
+	// Merged into an empty config, the settings show which the bundle has
+	if settings, _ := new(config.Config).MergeSettings(bundled.Config, false); len(bundled.Aliases) > 0 || len(settings) > 0 {
+		if err := cfg.CheckLocked(); err != nil {
+			return err
+		}
+	}
+	if !importBundle(os.Stdout, cfg, bundled, args[0], bundleForce) {
+		return nil
+	}
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	if len(bundled.SyncedClients) == 0 {
+		return nil
+	}
+	if !bundleSync {
+		fmt.Println("\nThe bundle was synced to these clients; sync them here with:")
+		for _, sc := range bundled.SyncedClients {
+			fmt.Printf("  mcpr client sync %s\n", sc.Name)
+		}
+		return nil
+	}
+	for _, sc := range bundled.SyncedClients {
+		cfg.AddSyncedClient(sc.Name, false, sc.Servers)
+		cfg.SetSyncedClientExclude(sc.Name, false, sc.Exclude)
+	}
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	fmt.Println()
+	return resyncAll(cmd.Context(), cfg)
+}
+
+// readBundle reads the bundle at path with its secrets restored, calling
+// passphrase if they are encrypted
+func readBundle(path string, passphrase func() (string, error)) (bundleConfig, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return bundleConfig{}, fmt.Errorf("failed to open bundle: %w", err)
+	}
+	defer zr.Close()
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	var manifest bundleManifest
+	if err := readZipJSON(files, "manifest.json", &manifest); err != nil {
+		return bundleConfig{}, err
+	}
+	if manifest.Format > bundleFormat {
+		return bundleConfig{}, fmt.Errorf("the bundle was made by a newer mcpr (%s); upgrade to import it", manifest.Version)
+	}
+	bundled := bundleConfig{Config: &config.Config{}}
+	if err := readZipJSON(files, "mcpr.json", &bundled); err != nil {
+		return bundleConfig{}, err
+	}
+
+	secrets := bundleSecrets{}
+	if _, ok := files["secrets.enc"]; ok {
+		var sealed encryptedSecrets
+		if err := readZipJSON(files, "secrets.enc", &sealed); err != nil {
+			return bundleConfig{}, err
+		}
+		key, err := passphrase()
+		if err != nil {
+			return bundleConfig{}, err
+		}
+		if secrets, err = decryptSecrets(sealed, key); err != nil {
+			return bundleConfig{}, err
+		}
+	} else if err := readZipJSON(files, "secrets.json", &secrets); err != nil {
+		return bundleConfig{}, err
+	}
+
+	for i := range bundled.Servers {
+		secrets.restore(bundled.Servers[i].Name, &bundled.Servers[i])
+	}
+	eachMachineOverride(bundled.Config, secrets.restore)
+	return bundled, nil
+}
+
+// take moves the secrets of server into s under key, and returns how many
+// there were
+func (s bundleSecrets) take(key string, server *config.MCPServer) int {
+	names := serverSecrets(*server)
+	for _, secret := range names {
+		if s[key] == nil {
+			s[key] = make(map[string]string)
+		}
+		s[key][secret] = serverSecret(*server, secret)
+		setServerSecret(server, secret, "")
+	}
+	return len(names)
+}
+
+// restore sets the secrets kept in s under key back in server
+func (s bundleSecrets) restore(key string, server *config.MCPServer) {
+	for _, secret := range slices.Sorted(maps.Keys(s[key])) {
+		setServerSecret(server, secret, s[key][secret])
+	}
+}
+
+// eachMachineOverride calls f with the env vars and headers of every
+// machine override of cfg, as a server whose maps are the override's, and
+// the key its secrets are kept under in a bundle
+func eachMachineOverride(cfg *config.Config, f func(key string, server *config.MCPServer)) {
+	for _, machine := range slices.Sorted(maps.Keys(cfg.Machines)) {
+		overrides := cfg.Machines[machine].Servers
+		for _, name := range slices.Sorted(maps.Keys(overrides)) {
+			override := overrides[name]
+			server := config.MCPServer{Env: override.Env, Headers: override.Headers}
+			f("machine:"+machine+"/"+name, &server)
+			override.Env, override.Headers = server.Env, server.Headers
+			overrides[name] = override
+		}
+	}
+}
+
+// importBundle adds the servers, aliases and settings of the bundle at path
+// to cfg, writing what was done to w, and reports whether cfg changed.
+// Servers, aliases and settings already configured are kept unless force is
+// set.
+func importBundle(w io.Writer, cfg *config.Config, bundled bundleConfig, path string, force bool) bool {
+	changed := len(bundled.Servers) > 0 && importServers(w, cfg, bundled.Servers, config.Provenance{Source: config.SourceBundle, From: path}, force) > 0
+
+	aliases := 0
+	for _, name := range slices.Sorted(maps.Keys(bundled.Aliases)) {
+		expansion := bundled.Aliases[name]
+		if existing, ok := cfg.Aliases[name]; ok && existing != expansion && !force {
+			fmt.Fprintf(w, "- alias %s: already set to %q; use --force to replace it\n", name, existing)
+			continue
+		}
+		cfg.SetAlias(name, expansion)
+		aliases++
+	}
+	if aliases > 0 {
+		fmt.Fprintf(w, "Imported %d/%d alias(es)\n", aliases, len(bundled.Aliases))
+		changed = true
+	}
+
+	copied, kept := cfg.MergeSettings(bundled.Config, force)
+	for _, name := range kept {
+		fmt.Fprintf(w, "- setting %s: already set; use --force to replace it\n", name)
+	}
+	if len(copied) > 0 {
+		fmt.Fprintf(w, "Imported setting(s): %s\n", strings.Join(copied, ", "))
+		changed = true
+	}
+	return changed
+}
+
+// readZipJSON decodes the JSON file name of a bundle into v
+func readZipJSON(files map[string]*zip.File, name string, v any) error {
+	data, err := readZipFile(files, name)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	return nil
+}
+
+// encryptSecrets seals secrets with a key derived from passphrase
+func encryptSecrets(secrets bundleSecrets, passphrase string) (encryptedSecrets, error) {
+	data, err := json.Marshal(secrets)
+	if err != nil {
+		return encryptedSecrets{}, fmt.Errorf("failed to encode secrets: %w", err)
+	}
+	sealed := encryptedSecrets{KDF: "pbkdf2-sha256", Iterations: bundleIterations, Salt: make([]byte, 16)}
+	if _, err := rand.Read(sealed.Salt); err != nil {
+		return encryptedSecrets{}, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	gcm, err := bundleCipher(sealed, passphrase)
+	if err != nil {
+		return encryptedSecrets{}, err
+	}
+	sealed.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(sealed.Nonce); err != nil {
+		return encryptedSecrets{}, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed.Data = gcm.Seal(nil, sealed.Nonce, data, nil)
+	return sealed, nil
+}
+
+// decryptSecrets opens secrets sealed by encryptSecrets
+func decryptSecrets(sealed encryptedSecrets, passphrase string) (bundleSecrets, error) {
+	if sealed.KDF != "pbkdf2-sha256" {
+		return nil, fmt.Errorf("unsupported secrets encryption %q", sealed.KDF)
+	}
+	gcm, err := bundleCipher(sealed, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed.Nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("invalid secrets.enc: bad nonce")
+	}
+	data, err := gcm.Open(nil, sealed.Nonce, sealed.Data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt secrets: wrong passphrase or corrupted bundle")
+	}
+	var secrets bundleSecrets
+	if err := json.Unmarshal(data, &secrets); err != nil {
+		return nil, fmt.Errorf("failed to parse secrets: %w", err)
+	}
+	return secrets, nil
+}
+
+// bundleCipher returns the AES-256-GCM cipher keyed from passphrase with the
+// KDF parameters of sealed
+func bundleCipher(sealed encryptedSecrets, passphrase string) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, sealed.Salt, sealed.Iterations, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// readPassphrase returns the bundle passphrase from $MCPR_BUNDLE_PASSPHRASE,
+// or asks for it without echo on a terminal, twice when confirm is set
+func readPassphrase(confirm bool) (string, error) {
+	if passphrase := os.Getenv(bundlePassphraseEnv); passphrase != "" {
+		return passphrase, nil
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", fmt.Errorf("failed to read passphrase: %w", err)
+		}
+		if line = strings.TrimRight(line, "\r\n"); line == "" {
+			return "", fmt.Errorf("no passphrase given; set %s or run in a terminal", bundlePassphraseEnv)
+		}
+		return line, nil
+	}
+
+	ask := func(prompt string) (string, error) {
+		fmt.Fprint(os.Stderr, prompt)
+		data, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("failed to read passphrase: %w", err)
+		}
+		return string(data), nil
+	}
+	passphrase, err := ask("Passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if passphrase == "" {
+		return "", fmt.Errorf("the passphrase must not be empty")
+	}
+	if confirm {
+		again, err := ask("Repeat passphrase: ")
+		if err != nil {
+			return "", err
+		}
+		if again != passphrase {
+			return "", fmt.Errorf("the passphrases don't match")
+		}
+	}
+	return passphrase, nil
+}
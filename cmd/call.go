@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/jrandolf/mcpr/clients"
+	"github.com/jrandolf/mcpr/config"
+	"github.com/jrandolf/mcpr/mcp"
+
+	"github.com/spf13/cobra"
+)
+
+var callArgs string
+
+var callCmd = &cobra.Command{
+	Use:   "call [server] [tool]",
+	Short: "Call a tool on a configured server",
+	Long: `Connect to a configured server, call one of its tools with JSON arguments
+and print the result. Text content is printed as is and any other content as
+JSON. The command fails if the tool reports an error.
+
+stdio servers are started for the call and stopped afterwards, with their
+stderr passed through. A server's timeout, if set, limits the whole call.
+
+Examples:
+  mcpr call filesystem list_directory --args '{"path": "/tmp"}'
+
+  # Read the arguments from stdin
+  echo '{"path": "/tmp"}' | mcpr call filesystem list_directory --args -`,
+	Args:              cobra.ExactArgs(2),
+	RunE:              runCall,
+	ValidArgsFunction: completeServerName,
+}
+
+func init() {
+	callCmd.Flags().StringVar(&callArgs, "args", "", "Tool arguments as a JSON object, or - to read them from stdin")
+}
+
+func runCall(cmd *cobra.Command, args []string) error {
+	var toolArgs json.RawMessage
+	switch callArgs {
+	case "":
+	case "-":
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("failed to read arguments: %w", err)
+		}
+		toolArgs = data
+	default:
+		toolArgs = json.RawMessage(callArgs)
+	}
+	if toolArgs != nil && !json.Valid(toolArgs) {
+		return fmt.Errorf("--args must be valid JSON")
+	}
+
+	server, err := loadServer(args[0])
+	if err != nil {
+		return err
+	}
+	return callTool(cmd.Context(), os.Stdout, server, args[1], toolArgs)
+}
+
+// loadServer returns the configured server called name
+func loadServer(name string) (config.MCPServer, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return config.MCPServer{}, fmt.Errorf("failed to load config: %w", err)
+	}
+	server, err := cfg.GetServer(name)
+	if err != nil {
+		return config.MCPServer{}, err
+	}
+	return *server, nil
+}
+
+// callTool calls tool on server and prints its result to w
+func callTool(ctx context.Context, w io.Writer, server config.MCPServer, tool string, args json.RawMessage) error {
+	return withServer(ctx, server, func(ctx context.Context, client *mcp.Client) error {
+		result, err := client.CallTool(ctx, tool, args)
+		if err != nil {
+			return fmt.Errorf("failed to call %s: %w", tool, err)
+		}
+		if err := printContent(w, result.Content); err != nil {
+			return err
+		}
+		if result.IsError {
+			return fmt.Errorf("tool %s reported an error", tool)
+		}
+		return nil
+	})
+}
+
+// withServer connects to server, runs fn with the client and closes it. A
+// server's timeout, if set, limits the whole session.
+func withServer(ctx context.Context, server config.MCPServer, fn func(ctx context.Context, client *mcp.Client) error) error {
+	if server.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(server.Timeout)*time.Second)
+		defer cancel()
+	}
+
+	client, err := connectServer(ctx, server)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	return fn(ctx, client)
+}
+
+// connectServer starts or connects to server and performs the MCP
+// handshake. The caller must close the client.
+func connectServer(ctx context.Context, server config.MCPServer) (*mcp.Client, error) {
+	server, err := clients.ResolveServer(server)
+	if err != nil {
+		return nil, err
+	}
+
+	var transport mcp.Transport
+	switch server.Type {
+	case "http":
+		httpClient, err := loadHTTPClient()
+		if err != nil {
+			return nil, err
+		}
+		transport = mcp.NewHTTPClientTransport(server.URL, server.Headers, httpClient)
+	case "socket":
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "unix", server.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to %s: %w", server.Path, err)
+		}
+		transport = mcp.NewStreamTransport(conn, conn, conn.Close)
+	default:
+		command := exec.Command(server.Command, server.Args...)
+		command.Env = os.Environ()
+		for k, v := range server.Env {
+			command.Env = append(command.Env, k+"="+v)
+		}
+		command.Stderr = os.Stderr
+		transport, err = mcp.NewCommandTransport(command)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	client := mcp.NewClient(transport, "mcpr", version)
+	if _, err := client.Initialize(ctx); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to initialize %s: %w", server.Name, err)
+	}
+	return client, nil
+}
+
+// printContent writes text content blocks as is and any other block as
+// JSON, one block per line
+func printContent(w io.Writer, content []mcp.Content) error {
+	for _, block := range content {
+		if block.Type == "text" {
+			if _, err := fmt.Fprintln(w, block.Text); err != nil {
+				return err
+			}
+			continue
+		}
+		data, err := json.Marshal(block)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w, string(data)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
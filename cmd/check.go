@@ -0,0 +1,298 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"text/template"
+	"time"
+
+	"github.com/jrandolf/mcpr/clients"
+	"github.com/jrandolf/mcpr/config"
+	"github.com/jrandolf/mcpr/mcp"
+	"github.com/jrandolf/mcpr/netutil"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	checkTimeout      time.Duration
+	checkNoQuarantine bool
+	checkTemplate     string
+	checkCABundle     string
+)
+
+var checkCmd = &cobra.Command{
+	Use:   "check [server...]",
+	Short: "Check that configured servers start and answer",
+	Long: fmt.Sprintf(`Health check servers by connecting to them and performing the MCP
+handshake. Without arguments every enabled server is checked.
+
+Results are recorded in mcpr's state file. A server that fails %d checks in
+a row is quarantined: it is synced to clients disabled, so a crash-looping
+server can't break every client's startup, until 'mcpr unquarantine'
+restores it.
+
+HTTP servers are sent the MCP initialize request with their headers,
+following redirects and validating TLS certificates. Their HTTP status and
+latency are reported apart from protocol errors, so a rejected token, a
+broken endpoint and a server that doesn't speak MCP are told apart. Trust a
+private CA with --ca-bundle.
+
+With --template each result is printed with a Go template, which sees
+.Name, .OK, .Error, .Latency (a duration) and .Quarantined, and for HTTP
+servers .Status (the HTTP status code, 0 if none was received), .URL (where
+redirects led) and .Failure ("connection", "status" or "protocol").
+
+Examples:
+  mcpr check
+  mcpr check --timeout 30s filesystem github
+  mcpr check --ca-bundle corp-ca.pem remote-api
+  mcpr check --template '{{.Name}} {{if .OK}}up {{.Latency.Milliseconds}}ms{{else}}down{{end}}'`, config.QuarantineThreshold),
+	ValidArgsFunction: completeServerName,
+	RunE:              runCheck,
+}
+
+var unquarantineCmd = &cobra.Command{
+	Use:               "unquarantine [server]",
+	Short:             "Restore a quarantined server to clients",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeServerName,
+	RunE:              runUnquarantine,
+}
+
+func init() {
+	checkCmd.Flags().DurationVar(&checkTimeout, "timeout", 10*time.Second, "Time each server gets to answer")
+	checkCmd.Flags().BoolVar(&checkNoQuarantine, "no-quarantine", false, "Record results without quarantining failing servers")
+	checkCmd.Flags().StringVar(&checkTemplate, "template", "", "Print each result with a Go template, e.g. '{{.Name}} {{.OK}}'")
+	checkCmd.Flags().StringVar(&checkCABundle, "ca-bundle", "", "PEM file of CA certificates to trust for http servers, besides the system ones")
+}
+
+// checkResult is what check --template sees for each server
+type checkResult struct {
+	Name        string
+	OK          bool
+	Error       string        // Why the check failed
+	Latency     time.Duration // How long the handshake took
+	Quarantined bool          // Synced disabled after failing checks in a row
+	Status      int           // HTTP status of the initialize request, for http servers
+	URL         string        // Where redirects led, for http servers
+	Failure     string        // "connection", "status" or "protocol", for http servers
+}
+
+func runCheck(cmd *cobra.Command, args []string) error {
+	tmpl, err := parseOutputTemplate(checkTemplate)
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	var servers []config.MCPServer
+	if len(args) > 0 {
+		for _, name := range args {
+			server, err := cfg.GetServer(name)
+			if err != nil {
+				return err
+			}
+			servers = append(servers, *server)
+		}
+	} else {
+		for _, server := range cfg.ListServers() {
+			if !server.Disabled {
+				servers = append(servers, server)
+			}
+		}
+	}
+	if len(servers) == 0 {
+		return fmt.Errorf("no servers to check")
+	}
+
+	failed, quarantined := checkServers(cmd.Context(), os.Stdout, cfg, servers, checkTimeout, !checkNoQuarantine, tmpl)
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	if quarantined > 0 {
+		resyncAll(cmd.Context(), cfg)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d server(s) failed the health check", failed, len(servers))
+	}
+	return nil
+}
+
+// checkServers health checks servers one after another, records the results
+// in cfg and reports how many failed and how many were newly quarantined.
+// Results are printed with tmpl if it isn't nil, and notices go to stderr.
+func checkServers(ctx context.Context, w io.Writer, cfg *config.Config, servers []config.MCPServer, timeout time.Duration, quarantine bool, tmpl *template.Template) (failed, quarantined int) {
+	notices := w
+	if tmpl != nil {
+		notices = os.Stderr
+	}
+	network := netOptions(cfg)
+	if checkCABundle != "" {
+		network.CABundle = checkCABundle
+	}
+	for _, server := range servers {
+		if ctx.Err() != nil {
+			break
+		}
+
+		result, err := checkServer(ctx, server, network, timeout)
+		newlyQuarantined := cfg.RecordHealthCheck(server.Name, err, quarantine)
+		if err != nil {
+			failed++
+		}
+		switch {
+		case tmpl != nil:
+			result.Name, result.OK, result.Quarantined = server.Name, err == nil, cfg.IsQuarantined(server.Name)
+			if err != nil {
+				result.Error = err.Error()
+			}
+			if err := writeTemplate(w, tmpl, result); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+		case err != nil:
+			fmt.Fprintf(w, "%s %s: %v\n", failMark(), server.Name, err)
+		case result.Status != 0:
+			fmt.Fprintf(w, "%s %s (HTTP %d, %s)\n", okMark(), server.Name, result.Status, formatLatency(result.Latency))
+		default:
+			fmt.Fprintf(w, "%s %s (%s)\n", okMark(), server.Name, formatLatency(result.Latency))
+		}
+		if result.URL != "" {
+			fmt.Fprintf(notices, "  Redirected to %s\n", result.URL)
+		}
+
+		if newlyQuarantined {
+			quarantined++
+			fmt.Fprintf(notices, "  Quarantined %q after %d failed checks; it is synced disabled until 'mcpr unquarantine %s'\n", server.Name, config.QuarantineThreshold, server.Name)
+		} else if err == nil && cfg.IsQuarantined(server.Name) {
+			fmt.Fprintf(notices, "  %q is quarantined; run 'mcpr unquarantine %s' to restore it\n", server.Name, server.Name)
+		}
+	}
+	return failed, quarantined
+}
+
+// checkServer connects to server and performs the MCP handshake, returning
+// how long it took and, for http servers, how the server answered
+func checkServer(ctx context.Context, server config.MCPServer, network netutil.Options, timeout time.Duration) (checkResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if server.Type == "http" {
+		return checkHTTPServer(ctx, server, network, timeout)
+	}
+
+	start := time.Now()
+	client, err := connectServer(ctx, server)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return checkResult{}, fmt.Errorf("no answer within %s", timeout)
+		}
+		return checkResult{}, err
+	}
+	latency := time.Since(start)
+	client.Close()
+	return checkResult{Latency: latency}, nil
+}
+
+// maxRedirects is how many redirects an http check follows
+const maxRedirects = 10
+
+// checkHTTPServer sends the MCP initialize request to an http server and
+// tells failures to connect, error statuses and protocol errors apart
+func checkHTTPServer(ctx context.Context, server config.MCPServer, network netutil.Options, timeout time.Duration) (checkResult, error) {
+	server, err := clients.ResolveServer(server)
+	if err != nil {
+		return checkResult{}, err
+	}
+	transport, err := netutil.NewTransport(network)
+	if err != nil {
+		return checkResult{}, err
+	}
+
+	var result checkResult
+	start := time.Now()
+	recorder := &statusRecorder{base: transport, start: start, result: &result}
+	httpClient := &http.Client{
+		Transport: recorder,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			result.URL = req.URL.String()
+			return nil
+		},
+	}
+	client := mcp.NewClient(mcp.NewHTTPClientTransport(server.URL, server.Headers, httpClient), "mcpr", version)
+	defer client.Close()
+
+	_, err = client.Initialize(ctx)
+	if result.Status == 0 {
+		result.Latency = time.Since(start)
+	}
+	var statusErr *mcp.HTTPStatusError
+	switch {
+	case err == nil:
+		result.Latency = time.Since(start)
+		return result, nil
+	case errors.As(err, &statusErr):
+		result.Failure = "status"
+		err = fmt.Errorf("HTTP %s in %s", statusErr.Status, formatLatency(result.Latency))
+		if statusErr.Body != "" {
+			err = fmt.Errorf("%w: %s", err, statusErr.Body)
+		}
+	case result.Status == 0:
+		result.Failure = "connection"
+		if ctx.Err() == context.DeadlineExceeded {
+			err = fmt.Errorf("no answer within %s", timeout)
+		} else {
+			err = fmt.Errorf("failed to connect: %w", err)
+		}
+	default:
+		result.Failure = "protocol"
+		err = fmt.Errorf("HTTP %d in %s, but the MCP handshake failed: %w", result.Status, formatLatency(result.Latency), err)
+	}
+	return result, err
+}
+
+// statusRecorder notes the status and latency of the first response to an
+// http check that isn't a redirect
+type statusRecorder struct {
+	base   http.RoundTripper
+	start  time.Time
+	result *checkResult
+}
+
+func (r *statusRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := r.base.RoundTrip(req)
+	if err == nil && r.result.Status == 0 && (resp.StatusCode < 300 || resp.StatusCode >= 400) {
+		r.result.Status = resp.StatusCode
+		r.result.Latency = time.Since(r.start)
+	}
+	return resp, err
+}
+
+func runUnquarantine(cmd *cobra.Command, args []string) error {
+	name := args[0]
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := cfg.Unquarantine(name); err != nil {
+		return err
+	}
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	fmt.Printf("Unquarantined %q\n", name)
+	resyncAll(cmd.Context(), cfg)
+	return nil
+}
@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jrandolf/mcpr/clients"
+	"github.com/jrandolf/mcpr/config"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	cleanYes           bool
+	cleanDryRun        bool
+	cleanRetentionDays int
+)
+
+var cleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Remove empty and orphaned files mcpr left behind",
+	Long: `Find and remove what mcpr leaves behind over time:
+
+  - client configs whose servers block is empty
+  - zero-byte client configs left by failed writes
+  - config snapshots past their retention
+  - local client configs (.mcp.json, .cursor/mcp.json, ...) of projects whose
+    mcpr config was deleted
+
+Client configs in the current directory and in every project recorded in the
+state file are checked. Each removal is confirmed first unless --yes is given.
+
+Examples:
+  mcpr clean --dry-run
+  mcpr clean --yes --retention-days 30`,
+	Args: cobra.NoArgs,
+	RunE: runClean,
+}
+
+func init() {
+	cleanCmd.Flags().BoolVarP(&cleanYes, "yes", "y", false, "Clean without asking")
+	cleanCmd.Flags().BoolVar(&cleanDryRun, "dry-run", false, "Only list what would be cleaned")
+	cleanCmd.Flags().IntVar(&cleanRetentionDays, "retention-days", 90, "Remove snapshots of client configs not synced for this many days")
+}
+
+// cleanup is a single leftover to remove
+type cleanup struct {
+	path   string
+	reason string
+	apply  func() error
+}
+
+func runClean(cmd *cobra.Command, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+	state, err := config.LoadState()
+	if err != nil {
+		return err
+	}
+
+	cleanups, err := collectCleanups(state, cwd, time.Duration(cleanRetentionDays)*24*time.Hour)
+	if err != nil {
+		return err
+	}
+	if len(cleanups) == 0 {
+		fmt.Println("Nothing to clean")
+		return nil
+	}
+	return applyCleanups(os.Stdout, os.Stdin, cleanups, cleanYes, cleanDryRun)
+}
+
+// collectCleanups finds the leftovers in the client configs of cwd and of
+// the projects recorded in state, and among snapshots older than maxAge
+func collectCleanups(state *config.State, cwd string, maxAge time.Duration) ([]cleanup, error) {
+	var cleanups []cleanup
+	seen := make(map[string]bool)
+	add := func(c cleanup) {
+		if !seen[c.path] {
+			seen[c.path] = true
+			cleanups = append(cleanups, c)
+		}
+	}
+
+	projectDirs := []string{cwd}
+	paths := make([]string, 0, len(state.Configs))
+	for path := range state.Configs {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+
+	for _, path := range paths {
+		dir := filepath.Dir(path)
+		if _, err := os.Stat(dir); err != nil {
+			continue
+		}
+		if _, err := os.Stat(path); err == nil {
+			projectDirs = append(projectDirs, dir)
+			continue
+		}
+
+		// The project's config is gone but its directory isn't: local
+		// client configs synced from it are orphans
+		for _, sc := range state.Configs[path].SyncedClients {
+			client, err := clients.GetClient(sc.Name)
+			if !sc.Local || err != nil {
+				continue
+			}
+			local, err := client.LocalPathIn(dir)
+			if err != nil {
+				continue
+			}
+			if _, err := os.Stat(local); err == nil {
+				add(cleanup{
+					path:   local,
+					reason: fmt.Sprintf("%s config of deleted project config %s", client.DisplayName, path),
+					apply:  func() error { return os.Remove(local) },
+				})
+			}
+		}
+	}
+
+	names := clients.ListClientNames()
+	slices.Sort(names)
+	for _, name := range names {
+		client, _ := clients.GetClient(name)
+		var candidates []string
+		if path, err := client.Path(false); err == nil {
+			candidates = append(candidates, path)
+		}
+		if client.SupportsLocal {
+			for _, dir := range projectDirs {
+				if path, err := client.LocalPathIn(dir); err == nil {
+					candidates = append(candidates, path)
+				}
+			}
+		}
+
+		for _, path := range candidates {
+			reason, err := client.EmptyConfig(path)
+			if err != nil {
+				return nil, err
+			}
+			if reason != "" {
+				add(cleanup{
+					path:   path,
+					reason: fmt.Sprintf("%s config with %s", client.DisplayName, reason),
+					apply: func() error {
+						_, err := client.CleanConfig(path)
+						return err
+					},
+				})
+			}
+		}
+	}
+
+	stale, err := clients.StaleSnapshots(maxAge)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range stale {
+		add(cleanup{
+			path:   path,
+			reason: "snapshot past retention",
+			apply:  func() error { return os.RemoveAll(path) },
+		})
+	}
+	return cleanups, nil
+}
+
+// applyCleanups applies each cleanup, asking on in before each one unless
+// yes is set. A dry run only lists them.
+func applyCleanups(w io.Writer, in io.Reader, cleanups []cleanup, yes, dryRun bool) error {
+	if dryRun {
+		for _, c := range cleanups {
+			fmt.Fprintf(w, "Would clean %s (%s)\n", c.path, c.reason)
+		}
+		return nil
+	}
+
+	reader := bufio.NewReader(in)
+	cleaned := 0
+	var errors []string
+	for _, c := range cleanups {
+		if !yes {
+			fmt.Fprintf(w, "Clean %s (%s)? [y/N] ", c.path, c.reason)
+			answer, _ := reader.ReadString('\n')
+			if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+				continue
+			}
+		}
+		if err := c.apply(); err != nil {
+			errors = append(errors, err.Error())
+			continue
+		}
+		fmt.Fprintf(w, "%s Cleaned %s\n", okMark(), c.path)
+		cleaned++
+	}
+
+	fmt.Fprintf(w, "\nCleaned %d/%d item(s)\n", cleaned, len(cleanups))
+	if len(errors) > 0 {
+		fmt.Fprintln(w, "\nErrors:")
+		for _, e := range errors {
+			fmt.Fprintf(w, "  - %s\n", e)
+		}
+		return fmt.Errorf("some items failed to clean")
+	}
+	return nil
+}
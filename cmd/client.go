@@ -1,18 +1,28 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
 	"strings"
 
 	"github.com/jrandolf/mcpr/clients"
 	"github.com/jrandolf/mcpr/config"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 var (
 	clientSyncServers []string
+	clientSyncExclude []string
 	clientSyncLocal   bool
+	clientSyncExplain bool
+	clientSyncIgnore  bool
 )
 
 var clientCmd = &cobra.Command{
@@ -20,78 +30,101 @@ var clientCmd = &cobra.Command{
 	Short: "Manage synced clients",
 	Long: `Manage which clients are synced with your MCP server configurations.
 
-Subcommands:
-  sync   - Sync servers to a client (or resync all)
-  remove - Remove a client from the sync list`,
+See 'mcpr client sync --help' for the supported clients.`,
 }
 
 var clientSyncCmd = &cobra.Command{
-	Use:   "sync [client-name]",
+	Use:   "sync [client-name|@group]...",
 	Short: "Sync MCP servers to a client",
-	Long: `Sync MCP server configurations to a specific client.
+	Long: `Sync MCP server configurations to specific clients.
 
 When called without a client name, it will resync all previously synced clients.
+A client group defined with 'mcpr client group set' syncs every client in it.
 
-Supported clients:
-  - claude-desktop  : Claude Desktop application
-  - claude-code     : Claude Code CLI
-  - cursor          : Cursor editor
-  - windsurf        : Windsurf editor
-  - zed             : Zed editor
-  - opencode        : OpenCode CLI
-  - cline           : Cline VS Code extension
-  - vscode          : VS Code with GitHub Copilot
-  - continue        : Continue (VS Code/JetBrains)
-  - codex           : Codex (OpenAI)
-  - gemini          : Gemini CLI (Google)
-  - kilo-code       : Kilo Code VS Code extension
-  - zencoder        : ZenCoder VS Code extension
-
-The --local flag syncs to project-local config (if supported).
+{{clients}}
+
+The --local flag syncs to project-local config, for clients marked local.
+
+The --exclude flag keeps specific servers out of the client. Exclusions are
+remembered and honored whenever the client is resynced, including by syncing
+it again without --exclude; --exclude "" clears them.
+
+Server entries mcpr didn't write, such as ones added to the client by hand,
+are never dropped silently: syncing a client config holding them fails, as
+does resyncing it. To take the config over, --force --adopt imports them
+into the mcpr config as servers first, and --force --discard drops them.
+
+Servers changed in the client config since mcpr synced them conflict with
+mcpr's definitions. In a terminal, you are asked for each whether to keep
+mcpr's, keep the client's or edit a merge of both; --ours and --theirs
+choose for every conflict without asking. Resyncs fail on conflicts.
 
 Examples:
   mcpr client sync claude-desktop
   mcpr client sync claude-code --local
   mcpr client sync cursor --servers my-server,another-server
+  mcpr client sync zed --exclude playwright
+  mcpr client sync @editors
+  mcpr client sync cursor --force --adopt
   mcpr client sync  # resync all`,
-	Args: cobra.MaximumNArgs(1),
-	RunE: runClientSync,
-	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		if len(args) != 0 {
-			return nil, cobra.ShellCompDirectiveNoFileComp
-		}
-		return clients.ListClientNames(), cobra.ShellCompDirectiveNoFileComp
-	},
+	RunE:              runClientSync,
+	ValidArgsFunction: completeClientNames,
 }
 
 var clientRemoveCmd = &cobra.Command{
-	Use:   "remove [client-name]",
+	Use:   "remove [client-name|@group]...",
 	Short: "Remove a client from the sync list",
-	Long: `Remove a client from the list of synced clients.
+	Long: `Remove clients from the list of synced clients.
 
 This stops the client from being updated when servers are added or removed.
 It does not modify the client's current configuration.
 
 Examples:
   mcpr client remove claude-desktop
-  mcpr client remove cursor --local`,
-	Args: cobra.ExactArgs(1),
-	RunE: runClientRemove,
-	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		if len(args) != 0 {
-			return nil, cobra.ShellCompDirectiveNoFileComp
-		}
-		return clients.ListClientNames(), cobra.ShellCompDirectiveNoFileComp
-	},
+  mcpr client remove cursor --local
+  mcpr client remove @editors`,
+	Args:              cobra.MinimumNArgs(1),
+	RunE:              runClientRemove,
+	ValidArgsFunction: completeClientNames,
+}
+
+var clientRollbackCmd = &cobra.Command{
+	Use:   "rollback [client-name]",
+	Short: "Restore a client's last known good config",
+	Long: `Restore a client's config file to the last rendering mcpr synced and
+validated. Every sync keeps a snapshot of what it wrote, the newest five per
+config file, in ~/.local/state/mcpr/snapshots. Rollback restores the newest
+one that differs from the file as it is now, so running it again steps
+further back.
+
+Examples:
+  mcpr client rollback cursor
+  mcpr client rollback claude-code --local`,
+	Args:              cobra.ExactArgs(1),
+	RunE:              runClientRollback,
+	ValidArgsFunction: completeClientName,
 }
 
 func init() {
 	clientCmd.AddCommand(clientSyncCmd)
 	clientCmd.AddCommand(clientRemoveCmd)
+	clientCmd.AddCommand(clientRollbackCmd)
+	withClientHelp(clientSyncCmd)
 
 	clientSyncCmd.Flags().StringSliceVarP(&clientSyncServers, "servers", "s", nil, "Specific servers to sync (comma-separated)")
+	clientSyncCmd.Flags().StringSliceVarP(&clientSyncExclude, "exclude", "x", nil, "Servers to never sync to this client (comma-separated, remembered; \"\" clears them)")
 	clientSyncCmd.Flags().BoolVarP(&clientSyncLocal, "local", "l", false, "Sync to project-local config instead of global")
+	clientSyncCmd.Flags().BoolVar(&clientSyncExplain, "explain", false, "Explain how each client config path was chosen")
+	clientSyncCmd.Flags().BoolVar(&clientSyncIgnore, "gitignore", false, "Add a local config holding secrets to .gitignore without asking")
+	clientSyncCmd.Flags().BoolVarP(&clientSyncForce, "force", "f", false, "Take over a client config holding servers mcpr doesn't manage, with --adopt or --discard")
+	clientSyncCmd.Flags().BoolVar(&clientSyncAdopt, "adopt", false, "With --force, import the servers mcpr doesn't manage before syncing")
+	clientSyncCmd.Flags().BoolVar(&clientSyncDiscard, "discard", false, "With --force, drop the servers mcpr doesn't manage")
+	clientSyncCmd.Flags().BoolVar(&clientSyncOurs, "ours", false, "Overwrite servers changed in the client config with mcpr's definitions")
+	clientSyncCmd.Flags().BoolVar(&clientSyncTheirs, "theirs", false, "Take the definitions of servers changed in the client config into mcpr")
 	clientRemoveCmd.Flags().BoolVarP(&clientSyncLocal, "local", "l", false, "Remove project-local sync instead of global")
+	clientRollbackCmd.Flags().BoolVarP(&clientSyncLocal, "local", "l", false, "Restore the project-local config instead of global")
+	_ = clientSyncCmd.RegisterFlagCompletionFunc("servers", completeList(serverCompletions))
+	_ = clientSyncCmd.RegisterFlagCompletionFunc("exclude", completeList(serverCompletions))
 }
 
 func runClientSync(cmd *cobra.Command, args []string) error {
@@ -101,164 +134,897 @@ func runClientSync(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
+	takeover, err := syncTakeover()
+	if err != nil {
+		return err
+	}
+	resolve, err := syncResolver(os.Stdout, os.Stdin, term.IsTerminal(int(os.Stdin.Fd())))
+	if err != nil {
+		return err
+	}
+
 	// If no client specified, resync all stored clients
 	if len(args) == 0 {
-		return resyncAll(cfg)
+		if takeover != takeoverNone {
+			return fmt.Errorf("--force takes over the config of a named client, not every client")
+		}
+		if clientSyncOurs || clientSyncTheirs {
+			return fmt.Errorf("--ours and --theirs resolve conflicts in the config of a named client, not every client")
+		}
+		if clientSyncExplain {
+			for _, sc := range cfg.GetSyncedClients() {
+				if client, err := clients.GetClient(sc.Name); err == nil {
+					explainClientPath(os.Stdout, client, sc.Local)
+				}
+			}
+		}
+		return resyncAll(cmd.Context(), cfg)
+	}
+
+	names, err := cfg.ExpandClients(args)
+	if err != nil {
+		return err
+	}
+	if len(names) == 1 {
+		return syncNamedClient(cmd.Context(), cfg, names[0], takeover, resolve)
+	}
+
+	failed := 0
+	for i, name := range names {
+		if i > 0 {
+			fmt.Println()
+		}
+		if err := syncNamedClient(cmd.Context(), cfg, name, takeover, resolve); err != nil {
+			fmt.Printf("%s %s: %v\n", failMark(), name, err)
+			failed++
+		}
 	}
+	if failed > 0 {
+		return fmt.Errorf("failed to sync %d of %d clients", failed, len(names))
+	}
+	return nil
+}
 
-	clientName := args[0]
+// syncNamedClient syncs a client named on the command line with the sync
+// flags and reports the result
+func syncNamedClient(ctx context.Context, cfg *config.Config, name, takeover string, resolve conflictResolver) error {
+	if clientSyncExplain {
+		if client, err := clients.GetClient(name); err == nil {
+			explainClientPath(os.Stdout, client, clientSyncLocal)
+		}
+	}
+
+	var previouslyExcluded []string
+	if sc := cfg.GetSyncedClient(name, clientSyncLocal); sc != nil {
+		previouslyExcluded = sc.Exclude
+	}
+	result, err := syncClient(ctx, cfg, name, clientSyncLocal, clientSyncServers, clientSyncExclude, takeover, resolve)
+	if err != nil {
+		return err
+	}
 
+	if len(result.Adopted) > 0 {
+		fmt.Printf("Adopted %d server(s) from %s: %s\n", len(result.Adopted), result.Path, strings.Join(result.Adopted, ", "))
+	}
+	if len(result.Discarded) > 0 {
+		fmt.Printf("Discarded %d server(s) from %s: %s\n", len(result.Discarded), result.Path, strings.Join(result.Discarded, ", "))
+	}
+	if len(result.Resolved) > 0 {
+		fmt.Printf("Took %s from %s into mcpr; run 'mcpr sync %s' to push the changes to other clients\n", strings.Join(result.Resolved, ", "), result.Path, strings.Join(result.Resolved, " "))
+	}
+	fmt.Printf("Synced %d server(s) to %s\n", len(result.Servers), result.Client.DisplayName)
+	fmt.Printf("Config location: %s\n", result.Path)
+	fmt.Println("\nSynced servers:")
+	for _, server := range result.Servers {
+		fmt.Printf("  - %s\n", server.Name)
+	}
+	switch {
+	case len(result.Excluded) > 0:
+		fmt.Printf("\nExcluded servers: %s (use --exclude \"\" to clear)\n", strings.Join(result.Excluded, ", "))
+	case len(previouslyExcluded) > 0:
+		fmt.Printf("\nCleared the excluded servers: %s\n", strings.Join(previouslyExcluded, ", "))
+	}
+	if sc := cfg.GetSyncedClient(name, clientSyncLocal); sc != nil && sc.Paused {
+		fmt.Printf("\n%s is paused, so resyncs leave it alone until 'mcpr client resume %s'\n", name, name)
+	}
+
+	// Local configs live in the project, where they could be committed
+	if clientSyncLocal {
+		data, err := os.ReadFile(result.Path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", result.Path, err)
+		}
+		return guardLocalConfig(os.Stdout, os.Stdin, term.IsTerminal(int(os.Stdin.Fd())), result.Path, data, clientSyncIgnore)
+	}
+	return nil
+}
+
+// clientSyncResult describes a completed client sync
+type clientSyncResult struct {
+	Client    *clients.Client
+	Path      string
+	Servers   []config.MCPServer
+	Excluded  []string // Servers kept out of the client
+	Adopted   []string // Entries mcpr didn't manage, imported as servers
+	Discarded []string // Entries mcpr didn't manage, dropped
+	Resolved  []string // Servers changed in the client, whose definitions mcpr took
+}
+
+// syncClient syncs servers to a client and records it in the synced client
+// list. An empty include list syncs all servers; exclude is remembered for
+// future resyncs, and a nil exclude keeps the remembered one. Entries in the client config mcpr doesn't manage are
+// adopted or discarded as takeover says; with takeoverNone, the sync fails
+// if it would drop any. Servers changed in the client config are resolved
+// with resolve; if it is nil, the sync fails on them.
+func syncClient(ctx context.Context, cfg *config.Config, clientName string, local bool, include, exclude []string, takeover string, resolve conflictResolver) (*clientSyncResult, error) {
 	// Get the client
 	client, err := clients.GetClient(clientName)
 	if err != nil {
-		return fmt.Errorf("%w\n\nSupported clients: %s", err, strings.Join(clients.ListClientNames(), ", "))
+		return nil, fmt.Errorf("%w\n\nSupported clients: %s", err, strings.Join(clients.ListClientNames(), ", "))
 	}
 
-	// Get servers to sync
-	var serversToSync []config.MCPServer
-	var serverNames []string
+	// Exclusions are remembered: without an exclude list, the stored one is
+	// kept, and an empty list clears it
+	if sc := cfg.GetSyncedClient(clientName, local); sc != nil && exclude == nil {
+		exclude = sc.Exclude
+	}
+	serversToSync, err := selectServers(cfg, clientName, include, exclude)
+	if err != nil {
+		return nil, err
+	}
+	serverNames := include // nil means all servers
 
-	if len(clientSyncServers) > 0 {
-		// Sync specific servers
-		for _, name := range clientSyncServers {
-			server, err := cfg.GetServer(name)
+	// Sync to client, restoring its config if the synced client info
+	// can't be saved
+	recoverInterrupted(client, local)
+	synced := cfg.GetSyncedClient(clientName, local) != nil
+	staged, conflicts, unmanaged, err := stageSync(cfg, client, local, synced, serversToSync)
+	if err != nil {
+		return nil, err
+	}
+
+	// Servers changed in the client are resolved first; if mcpr takes any
+	// definitions, they are synced again, overwriting the rest
+	if len(conflicts) > 0 {
+		if resolve == nil {
+			return nil, conflictError(client, local, conflicts)
+		}
+		var resolved []string
+		for _, c := range conflicts {
+			server, err := resolve(c)
 			if err != nil {
-				return err
+				return nil, err
+			}
+			if server == nil {
+				continue
 			}
-			serversToSync = append(serversToSync, *server)
-			serverNames = append(serverNames, name)
+			if err := cfg.ReplaceServer(*server); err != nil {
+				return nil, err
+			}
+			resolved = append(resolved, server.Name)
+		}
+		if len(resolved) > 0 {
+			result, err := syncClient(ctx, cfg, clientName, local, include, exclude, takeover, resolveOurs)
+			if err != nil {
+				return nil, err
+			}
+			result.Resolved = resolved
+			return result, nil
 		}
-	} else {
-		// Sync all servers
-		serversToSync = cfg.ListServers()
-		serverNames = nil // nil means all servers
 	}
 
-	if len(serversToSync) == 0 {
-		return fmt.Errorf("no servers configured. Use 'mcpr add' to add a server first")
+	// Entries mcpr doesn't manage are adopted before syncing again, or
+	// discarded
+	var discarded []string
+	if len(unmanaged) > 0 {
+		switch takeover {
+		case takeoverAdopt:
+			if err := adoptEntries(cfg, client, staged.Path, unmanaged); err != nil {
+				return nil, err
+			}
+			if len(include) > 0 {
+				include = append(slices.Clone(include), unmanaged...)
+			}
+			result, err := syncClient(ctx, cfg, clientName, local, include, exclude, takeoverNone, resolveOurs)
+			if err != nil {
+				return nil, err
+			}
+			result.Adopted = unmanaged
+			return result, nil
+		case takeoverDiscard:
+			discarded = unmanaged
+		default:
+			return nil, unmanagedError(client, local, staged.Path, unmanaged)
+		}
 	}
 
-	// Sync to client
-	configPath, err := client.Sync(serversToSync, clientSyncLocal)
-	if err != nil {
-		return fmt.Errorf("failed to sync to %s: %w", client.DisplayName, err)
+	var txn clients.Transaction
+	txn.Add(staged)
+	if err := txn.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to sync to %s: %w", client.DisplayName, err)
 	}
+	configPath := staged.Path
 
 	// Store synced client info
-	cfg.AddSyncedClient(clientName, clientSyncLocal, serverNames)
+	cfg.AddSyncedClient(clientName, local, serverNames)
+	cfg.SetSyncedClientExclude(clientName, local, exclude)
+	recordSync(cfg, client, local, configPath)
 	if err := cfg.Save(); err != nil {
-		return fmt.Errorf("failed to save synced client info: %w", err)
+		if rbErr := txn.Rollback(); rbErr != nil {
+			err = errors.Join(err, rbErr)
+		}
+		return nil, fmt.Errorf("failed to save synced client info, so %s was left as it was: %w", configPath, err)
 	}
 
-	fmt.Printf("Synced %d server(s) to %s\n", len(serversToSync), client.DisplayName)
-	fmt.Printf("Config location: %s\n", configPath)
-	fmt.Println("\nSynced servers:")
-	for _, server := range serversToSync {
-		fmt.Printf("  - %s\n", server.Name)
+	return &clientSyncResult{Client: client, Path: configPath, Servers: serversToSync, Excluded: exclude, Discarded: discarded}, nil
+}
+
+// selectServers returns the servers a sync writes to the named client:
+// those named by include, or all of them, without those named by exclude and
+// with quarantined ones disabled, dependencies first
+func selectServers(cfg *config.Config, clientName string, include, exclude []string) ([]config.MCPServer, error) {
+	var servers []config.MCPServer
+	if len(include) > 0 {
+		for _, name := range include {
+			server, err := cfg.GetServer(name)
+			if err != nil {
+				return nil, err
+			}
+			servers = append(servers, *server)
+		}
+	} else {
+		servers = cfg.ListServers()
 	}
 
-	return nil
-}
+	if len(servers) == 0 {
+		return nil, fmt.Errorf("no servers configured. Use 'mcpr add' to add a server first")
+	}
 
-func runClientRemove(cmd *cobra.Command, args []string) error {
-	clientName := args[0]
+	// Drop excluded servers
+	for _, name := range exclude {
+		if _, err := cfg.GetServer(name); err != nil {
+			return nil, err
+		}
+	}
+	total := len(servers)
+	servers = filterExcluded(servers, exclude)
+	if len(servers) == 0 {
+		return nil, fmt.Errorf("all %d server(s) are excluded from %s. Use --exclude \"\" to clear the exclusions", total, clientName)
+	}
+	servers = disableQuarantined(cfg, servers)
+	return orderServers(clientName, servers)
+}
 
-	// Validate client name
-	if _, err := clients.GetClient(clientName); err != nil {
-		return fmt.Errorf("%w\n\nSupported clients: %s", err, strings.Join(clients.ListClientNames(), ", "))
+// stageSync stages servers for the client and runs the checks every sync
+// runs before writing: it fails on credentials written out in plain text,
+// and returns the servers changed in the client config and the entries mcpr
+// doesn't manage, for the caller to resolve or refuse
+func stageSync(cfg *config.Config, client *clients.Client, local, synced bool, servers []config.MCPServer) (clients.Staged, []syncConflict, []string, error) {
+	staged, err := client.Stage(servers, local)
+	if err == nil {
+		err = checkPlaintextSecrets(staged)
+	}
+	if err != nil {
+		return clients.Staged{}, nil, nil, fmt.Errorf("failed to sync to %s: %w", client.DisplayName, err)
 	}
+	conflicts := findConflicts(client, synced, staged.Path, staged.Data, cfg.ListServers())
+	unmanaged := unmanagedEntries(client, synced, staged.Path, staged.Data)
+	return staged, conflicts, unmanaged, nil
+}
 
+func runClientRemove(cmd *cobra.Command, args []string) error {
 	// Load config
 	cfg, err := config.Load()
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
+	names, err := cfg.ExpandClients(args)
+	if err != nil {
+		return err
+	}
 
-	// Check if client is synced
-	if cfg.GetSyncedClient(clientName, clientSyncLocal) == nil {
-		localStr := ""
-		if clientSyncLocal {
-			localStr = " (local)"
+	localStr := ""
+	if clientSyncLocal {
+		localStr = " (local)"
+	}
+	for _, clientName := range names {
+		// Validate client name
+		if _, err := clients.GetClient(clientName); err != nil {
+			return fmt.Errorf("%w\n\nSupported clients: %s", err, strings.Join(clients.ListClientNames(), ", "))
+		}
+
+		// Check if client is synced
+		if cfg.GetSyncedClient(clientName, clientSyncLocal) == nil {
+			return fmt.Errorf("client %q%s is not in the sync list", clientName, localStr)
 		}
-		return fmt.Errorf("client %q%s is not in the sync list", clientName, localStr)
 	}
 
 	// Remove from synced clients
-	cfg.RemoveSyncedClient(clientName, clientSyncLocal)
+	for _, clientName := range names {
+		cfg.RemoveSyncedClient(clientName, clientSyncLocal)
+	}
 	if err := cfg.Save(); err != nil {
 		return fmt.Errorf("failed to save config: %w", err)
 	}
 
-	localStr := ""
-	if clientSyncLocal {
-		localStr = " (local)"
+	for _, clientName := range names {
+		fmt.Printf("Removed %s%s from sync list\n", clientName, localStr)
 	}
-	fmt.Printf("Removed %s%s from sync list\n", clientName, localStr)
+	return nil
+}
 
+func runClientRollback(cmd *cobra.Command, args []string) error {
+	client, err := clients.GetClient(args[0])
+	if err != nil {
+		return fmt.Errorf("%w\n\nSupported clients: %s", err, strings.Join(clients.ListClientNames(), ", "))
+	}
+	path, err := client.Rollback(clientSyncLocal)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Restored the last known good %s config to %s\n", client.DisplayName, path)
 	return nil
 }
 
-func resyncAll(cfg *config.Config) error {
-	syncedClients := cfg.GetSyncedClients()
-	if len(syncedClients) == 0 {
-		fmt.Println("No synced clients. Use 'mcpr client sync <client-name>' to add one.")
-		return nil
+// recordSync keeps what a sync wrote as the client's last known good config
+// and notes when it was written and its hash in the sync record. What was
+// written is checked against the client's schema. Failing to do so doesn't
+// fail the sync.
+func recordSync(cfg *config.Config, client *clients.Client, local bool, path string) {
+	if err := client.Snapshot(path); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to snapshot %s config: %v\n", client.DisplayName, err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to hash %s config: %v\n", client.DisplayName, err)
+		return
 	}
+	cfg.RecordSync(client.Name, local, contentHash(data))
+	warnSchemaViolations(os.Stderr, client, path, data)
+}
 
-	var errors []string
-	successCount := 0
+// warnSchemaViolations warns on w when data, written to the client config
+// at path, doesn't match the client's schema
+func warnSchemaViolations(w io.Writer, client *clients.Client, path string, data []byte) {
+	violations, err := client.CheckSchema(path, data)
+	if err != nil {
+		fmt.Fprintf(w, "Warning: failed to check %s against the %s schema: %v\n", path, client.DisplayName, err)
+		return
+	}
+	if len(violations) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "Warning: %s doesn't match the %s config schema, so %s may reject it:\n", path, client.DisplayName, client.DisplayName)
+	for _, v := range violations {
+		fmt.Fprintf(w, "  - %s\n", v)
+	}
+}
+
+// recoverInterrupted restores the client's config if an earlier sync was
+// cut short while writing it, so the sync merges into what it held before
+func recoverInterrupted(client *clients.Client, local bool) {
+	path, err := client.Path(local)
+	if err != nil {
+		return
+	}
+	restored, err := clients.RecoverInterrupted(path)
+	switch {
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	case restored:
+		fmt.Fprintf(os.Stderr, "Warning: an earlier sync was interrupted while writing %s; restored what it held before\n", path)
+	}
+}
 
+func resyncAll(ctx context.Context, cfg *config.Config) error {
+	return resyncAllTo(ctx, os.Stdout, cfg)
+}
+
+// syncResult is the outcome of resyncing one synced client
+type syncResult struct {
+	Name    string // Client name, e.g. "cursor"
+	Client  *clients.Client
+	Local   bool
+	Path    string   // Config written
+	Servers int      // Servers written
+	Added   int      // Server entries the config didn't have before
+	Removed int      // Server entries no longer in the config
+	Updated int      // Server entries that changed
+	Changed []string // Names of the entries added, removed or updated
+	Missing []string // Servers the client asks for that don't exist
+	Skipped bool     // Left as it was, since none of the servers resynced changed in it
+	Paused  bool     // Left as it was, since the client is paused
+	Err     error
+}
+
+// label names the client and scope, e.g. "cursor (local)"
+func (r syncResult) label() string {
+	if r.Local {
+		return r.Name + " (local)"
+	}
+	return r.Name
+}
+
+// changes describes what the sync changed in the client config in a few
+// words, e.g. "+1 server, 2 updated"
+func (r syncResult) changes() string {
+	var parts []string
+	if r.Added > 0 {
+		parts = append(parts, fmt.Sprintf("+%d %s", r.Added, plural(r.Added, "server")))
+	}
+	if r.Removed > 0 {
+		parts = append(parts, fmt.Sprintf("-%d %s", r.Removed, plural(r.Removed, "server")))
+	}
+	if r.Updated > 0 {
+		parts = append(parts, fmt.Sprintf("%d updated", r.Updated))
+	}
+	if len(parts) == 0 {
+		return "up to date"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// plural returns word for one thing and its plural for any other count
+func plural(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
+
+// errNotWritten is the result of clients that rendered fine in a resync
+// that wrote nothing, because another client failed
+var errNotWritten = errors.New("not written, since another client failed")
+
+// resyncClients resyncs every synced client as one transaction: every
+// client config is rendered first, and none is written unless all of them
+// render. save, if not nil, runs once they are written, and they are
+// restored if it fails, so a change to the mcpr config and the clients
+// syncing it land together or not at all. report is called with the result
+// of each client. If ctx is canceled before anything is written, nothing is.
+//
+// only, if not empty, names the servers whose changes are being pushed:
+// clients that neither get any of them nor have an entry for one are left
+// out, the others only have the entries of the named servers replaced or
+// removed, and clients whose entries for them wouldn't change are reported
+// as skipped and not written.
+func resyncClients(ctx context.Context, cfg *config.Config, only []string, save func() error, report func(syncResult)) error {
+	syncedClients := cfg.GetSyncedClients()
+
+	// Servers are listed and indexed once, not per client
+	allServers := disableQuarantined(cfg, cfg.ListServers())
+	byName := make(map[string]config.MCPServer, len(allServers))
+	for _, server := range allServers {
+		byName[server.Name] = server
+	}
+
+	var txn clients.Transaction
+	results := make([]syncResult, 0, len(syncedClients))
+	failed := 0
 	for _, sc := range syncedClients {
-		client, err := clients.GetClient(sc.Name)
-		if err != nil {
-			errors = append(errors, fmt.Sprintf("%s: %v", sc.Name, err))
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if len(only) > 0 && !getsAny(sc, allServers, byName, only) && !hasAnyEntry(sc, only) {
 			continue
 		}
+		if sc.Paused {
+			results = append(results, syncResult{Name: sc.Name, Local: sc.Local, Paused: true})
+			continue
+		}
+		result, staged := stageResync(sc, allServers, byName, only)
+		switch {
+		case result.Err != nil:
+			failed++
+		case len(only) > 0 && !slices.ContainsFunc(result.Changed, func(name string) bool { return slices.Contains(only, name) }):
+			result.Skipped = true
+		default:
+			txn.Add(staged)
+		}
+		results = append(results, result)
+	}
 
-		// Get servers to sync
-		var serversToSync []config.MCPServer
-		if len(sc.Servers) > 0 {
-			for _, name := range sc.Servers {
-				server, err := cfg.GetServer(name)
-				if err != nil {
-					errors = append(errors, fmt.Sprintf("%s: server %q not found", sc.Name, name))
-					continue
-				}
-				serversToSync = append(serversToSync, *server)
+	// fail reports every client that had nothing wrong with it as err
+	fail := func(err error) {
+		for _, r := range results {
+			if r.Err == nil && !r.Paused {
+				r.Err = err
+			}
+			report(r)
+		}
+	}
+	if failed > 0 {
+		fail(errNotWritten)
+		return fmt.Errorf("failed to sync %d of %d client(s), so no client config was changed", failed, len(results))
+	}
+	if err := txn.Commit(ctx); err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
+		fail(err)
+		return fmt.Errorf("failed to sync clients, so no client config was changed: %w", err)
+	}
+	if save != nil {
+		if err := save(); err != nil {
+			if rbErr := txn.Rollback(); rbErr != nil {
+				err = errors.Join(err, rbErr)
 			}
-		} else {
-			serversToSync = cfg.ListServers()
+			fail(errors.New("rolled back, since the config wasn't saved"))
+			return fmt.Errorf("failed to save config: %w", err)
 		}
+	}
 
-		if len(serversToSync) == 0 {
-			errors = append(errors, fmt.Sprintf("%s: no servers to sync", sc.Name))
+	for _, r := range results {
+		if r.Skipped || r.Paused {
+			report(r)
 			continue
 		}
+		recordSync(cfg, r.Client, r.Local, r.Path)
+		if r.Local {
+			if data, err := os.ReadFile(r.Path); err == nil {
+				warnExposedSecrets(r.Path, data)
+			}
+		}
+		report(r)
+	}
+	if len(results) > 0 {
+		if err := cfg.SaveState(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to save sync records: %v\n", err)
+		}
+	}
+	return nil
+}
 
-		// Sync to client
-		configPath, err := client.Sync(serversToSync, sc.Local)
+// stageResync renders what resyncing a synced client would write, and
+// what that changes in its config. With only, just the entries of the named
+// servers are rendered anew, see pushOnly.
+func stageResync(sc config.SyncedClient, allServers []config.MCPServer, byName map[string]config.MCPServer, only []string) (syncResult, clients.Staged) {
+	result := syncResult{Name: sc.Name, Local: sc.Local}
+	client, err := clients.GetClient(sc.Name)
+	if err != nil {
+		result.Err = err
+		return result, clients.Staged{}
+	}
+	result.Client = client
+
+	// Get servers to sync
+	var serversToSync []config.MCPServer
+	serversToSync, result.Missing = syncedServers(sc, allServers, byName)
+	if len(serversToSync) == 0 {
+		result.Err = fmt.Errorf("no servers to sync")
+		return result, clients.Staged{}
+	}
+	serversToSync, err = orderServers(sc.Name, serversToSync)
+	if err != nil {
+		result.Err = err
+		return result, clients.Staged{}
+	}
+	recoverInterrupted(client, sc.Local)
+	var asIs map[string]bool
+	if len(only) > 0 {
+		serversToSync, asIs, err = pushOnly(client, sc.Local, serversToSync, only)
 		if err != nil {
-			errors = append(errors, fmt.Sprintf("%s: %v", sc.Name, err))
+			result.Err = err
+			return result, clients.Staged{}
+		}
+	}
+	staged, err := client.StagePartial(serversToSync, asIs, sc.Local)
+	if err == nil {
+		err = checkPlaintextSecrets(staged)
+	}
+	if err != nil {
+		result.Err = err
+		return result, clients.Staged{}
+	}
+	result.Path = staged.Path
+	result.Servers = len(serversToSync)
+	if unmanaged := unmanagedEntries(client, true, staged.Path, staged.Data); len(unmanaged) > 0 {
+		result.Err = unmanagedError(client, sc.Local, staged.Path, unmanaged)
+		return result, clients.Staged{}
+	}
+	if conflicts := findConflicts(client, true, staged.Path, staged.Data, allServers); len(conflicts) > 0 {
+		result.Err = conflictError(client, sc.Local, conflicts)
+		return result, clients.Staged{}
+	}
+
+	// Compare the entries before and after
+	before := client.ServerEntries(staged.Path)
+	after := client.ParseServerEntries(staged.Path, staged.Data)
+	for name, entry := range after {
+		old, ok := before[name]
+		switch {
+		case !ok:
+			result.Added++
+		case !sameEntry(name, old, entry):
+			result.Updated++
+		default:
 			continue
 		}
+		result.Changed = append(result.Changed, name)
+	}
+	for name := range before {
+		if _, ok := after[name]; !ok {
+			result.Removed++
+			result.Changed = append(result.Changed, name)
+		}
+	}
+	slices.Sort(result.Changed)
+	return result, staged
+}
+
+// pushOnly returns what to write to the client's config when pushing only
+// the changes to the named servers: its current entries, with those of the
+// named servers replaced by their definition in servers, or removed if
+// servers doesn't have them. Other entries are kept as they are, named in
+// asIs, and servers the config has no entry for yet aren't added.
+func pushOnly(client *clients.Client, local bool, servers []config.MCPServer, only []string) ([]config.MCPServer, map[string]bool, error) {
+	path, err := client.Path(local)
+	if err != nil {
+		return nil, nil, err
+	}
+	current := client.ServerEntries(path)
+	asIs := make(map[string]bool)
+	keep := func(name string) (config.MCPServer, error) {
+		server, err := clients.EntryServer(name, current[name])
+		if err != nil {
+			command := "mcpr client sync " + client.Name
+			if local {
+				command += " --local"
+			}
+			return config.MCPServer{}, fmt.Errorf("can't keep the entry of %q as it is (%v); run '%s' to rewrite the whole config", name, err, command)
+		}
+		asIs[name] = true
+		return server, nil
+	}
+
+	// Servers mcpr defines keep their order
+	var result []config.MCPServer
+	for _, server := range servers {
+		if slices.Contains(only, server.Name) {
+			result = append(result, server)
+			continue
+		}
+		if _, ok := current[server.Name]; !ok {
+			continue
+		}
+		kept, err := keep(server.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+		result = append(result, kept)
+	}
+	// followed by the entries it doesn't define
+	for _, name := range slices.Sorted(maps.Keys(current)) {
+		defined := slices.ContainsFunc(servers, func(server config.MCPServer) bool { return server.Name == name })
+		if defined || slices.Contains(only, name) {
+			continue
+		}
+		kept, err := keep(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		result = append(result, kept)
+	}
+	return result, asIs, nil
+}
+
+// hasAnyEntry reports whether the config of a synced client has an entry
+// for any of the named servers
+func hasAnyEntry(sc config.SyncedClient, names []string) bool {
+	client, err := clients.GetClient(sc.Name)
+	if err != nil {
+		return false
+	}
+	path, err := client.Path(sc.Local)
+	if err != nil {
+		return false
+	}
+	entries := client.ServerEntries(path)
+	return slices.ContainsFunc(names, func(name string) bool {
+		_, ok := entries[name]
+		return ok
+	})
+}
+
+// getsAny reports whether a synced client gets any of the named servers
+func getsAny(sc config.SyncedClient, allServers []config.MCPServer, byName map[string]config.MCPServer, names []string) bool {
+	servers, _ := syncedServers(sc, allServers, byName)
+	return slices.ContainsFunc(servers, func(server config.MCPServer) bool {
+		return slices.Contains(names, server.Name)
+	})
+}
+
+// resyncAllTo resyncs every synced client, writing progress to w. If ctx is
+// canceled before they are written, none is.
+func resyncAllTo(ctx context.Context, w io.Writer, cfg *config.Config) error {
+	syncedClients := cfg.GetSyncedClients()
+	if len(syncedClients) == 0 {
+		fmt.Fprintln(w, "No synced clients. Use 'mcpr client sync <client-name>' to add one.")
+		return nil
+	}
+	total := 0
+	for _, sc := range syncedClients {
+		if !sc.Paused {
+			total++
+		}
+	}
+
+	var errors []string
+	successCount := 0
+	err := resyncClients(ctx, cfg, nil, nil, func(r syncResult) {
+		if r.Paused {
+			fmt.Fprintf(w, "- %s: paused\n", r.label())
+			return
+		}
+		for _, name := range r.Missing {
+			errors = append(errors, fmt.Sprintf("%s: server %q not found", r.Name, name))
+		}
+		if r.Err != nil {
+			errors = append(errors, fmt.Sprintf("%s: %v", r.Name, r.Err))
+			return
+		}
 
 		localStr := ""
-		if sc.Local {
+		if r.Local {
 			localStr = " (local)"
 		}
-		fmt.Printf("✓ %s%s: %d server(s) → %s\n", client.DisplayName, localStr, len(serversToSync), configPath)
+		fmt.Fprintf(w, "%s %s%s: %d server(s) %s %s\n", okMark(), r.Client.DisplayName, localStr, r.Servers, arrow(), r.Path)
 		successCount++
-	}
+	})
 
-	fmt.Printf("\nSynced %d/%d client(s)\n", successCount, len(syncedClients))
+	fmt.Fprintf(w, "\nSynced %d/%d client(s)\n", successCount, total)
+	if ctx.Err() != nil && successCount == 0 {
+		fmt.Fprintf(w, "Interrupted: %d client(s) not synced\n", total)
+		return fmt.Errorf("sync interrupted: %w", ctx.Err())
+	}
 
 	if len(errors) > 0 {
-		fmt.Println("\nErrors:")
+		fmt.Fprintln(w, "\nErrors:")
 		for _, e := range errors {
-			fmt.Printf("  - %s\n", e)
+			fmt.Fprintf(w, "  - %s\n", e)
 		}
 		return fmt.Errorf("some clients failed to sync")
 	}
+	return err
+}
+
+// saveAndResync saves cfg and resyncs every synced client as one
+// transaction, so a server added or removed can't end up in some client
+// configs but not others. change is printed once saved, followed by one
+// line per client with what changed in its config, e.g. "cursor: +1
+// server". Clients asking for servers that don't exist are still synced,
+// but fail it.
+func saveAndResync(ctx context.Context, w io.Writer, cfg *config.Config, change string) error {
+	var lines []string
+	failed, total := 0, 0
+	err := resyncClients(ctx, cfg, nil, cfg.Save, func(r syncResult) {
+		if r.Paused {
+			lines = append(lines, fmt.Sprintf("  %s: paused", r.label()))
+			return
+		}
+		total++
+		var problems []string
+		for _, name := range r.Missing {
+			problems = append(problems, fmt.Sprintf("server %q not found", name))
+		}
+		if r.Err != nil {
+			problems = append(problems, r.Err.Error())
+		}
+		if len(problems) > 0 {
+			failed++
+			lines = append(lines, fmt.Sprintf("  %s: failed: %s", r.label(), strings.Join(problems, "; ")))
+			return
+		}
+		lines = append(lines, fmt.Sprintf("  %s: %s", r.label(), r.changes()))
+	})
+	if err == nil {
+		fmt.Fprintln(w, change)
+		if len(lines) == 0 {
+			fmt.Fprintln(w, "No synced clients. Use 'mcpr client sync <client-name>' to add one.")
+		}
+	}
+	for _, line := range lines {
+		fmt.Fprintln(w, line)
+	}
 
+	switch {
+	case ctx.Err() != nil && len(lines) == 0:
+		return fmt.Errorf("interrupted, nothing was changed: %w", ctx.Err())
+	case err != nil:
+		return err
+	case failed > 0:
+		return fmt.Errorf("failed to sync %d of %d client(s)", failed, total)
+	}
 	return nil
 }
+
+// syncedServers returns the servers a synced client gets out of allServers,
+// indexed by name in byName, and the names of servers it asks for that
+// don't exist
+func syncedServers(sc config.SyncedClient, allServers []config.MCPServer, byName map[string]config.MCPServer) ([]config.MCPServer, []string) {
+	servers := allServers
+	var missing []string
+	if len(sc.Servers) > 0 {
+		servers = nil
+		for _, name := range sc.Servers {
+			server, ok := byName[name]
+			if !ok {
+				missing = append(missing, name)
+				continue
+			}
+			servers = append(servers, server)
+		}
+	}
+	return filterExcluded(servers, sc.Exclude), missing
+}
+
+// disableQuarantined returns servers with quarantined servers turned off,
+// so a crash-looping server can't break client startup
+func disableQuarantined(cfg *config.Config, servers []config.MCPServer) []config.MCPServer {
+	result := make([]config.MCPServer, len(servers))
+	for i, server := range servers {
+		if cfg.IsQuarantined(server.Name) {
+			server.Disabled = true
+		}
+		result[i] = server
+	}
+	return result
+}
+
+// explainClientPath prints a client's config path and the steps that led to it
+func explainClientPath(w io.Writer, client *clients.Client, local bool) {
+	scope := "global"
+	if local {
+		scope = "local"
+	}
+	path, steps, err := client.ExplainPath(local)
+	if err != nil {
+		fmt.Fprintf(w, "%s (%s): %v\n", client.Name, scope, err)
+	} else {
+		fmt.Fprintf(w, "%s (%s): %s\n", client.Name, scope, path)
+	}
+	for _, step := range steps {
+		fmt.Fprintf(w, "  - %s\n", step)
+	}
+	fmt.Fprintln(w)
+}
+
+// filterExcluded returns servers whose names are not in the exclude list
+func filterExcluded(servers []config.MCPServer, exclude []string) []config.MCPServer {
+	if len(exclude) == 0 {
+		return servers
+	}
+
+	excluded := make(map[string]bool, len(exclude))
+	for _, name := range exclude {
+		excluded[name] = true
+	}
+
+	filtered := make([]config.MCPServer, 0, len(servers))
+	for _, server := range servers {
+		if !excluded[server.Name] {
+			filtered = append(filtered, server)
+		}
+	}
+	return filtered
+}
+
+// orderServers sorts servers so dependencies come first and warns about
+// dependencies that are not part of the client's server set. Only clients
+// keeping servers in a list, like Continue, write them in that order; the
+// others key entries by name, which sorts them when written.
+func orderServers(clientName string, servers []config.MCPServer) ([]config.MCPServer, error) {
+	sorted, err := config.SortByDependencies(servers)
+	if err != nil {
+		return nil, err
+	}
+
+	missing := config.MissingDependencies(sorted)
+	for _, server := range sorted {
+		if deps, ok := missing[server.Name]; ok {
+			fmt.Fprintf(os.Stderr, "Warning: %s: server %q depends on %s, which is not synced\n", clientName, server.Name, strings.Join(deps, ", "))
+		}
+	}
+
+	return sorted, nil
+}
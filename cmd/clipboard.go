@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"rsc.io/qr"
+)
+
+// clipboardReaders are the commands printing the system clipboard on each
+// OS, tried in order; other Unix systems use the Linux ones
+var clipboardReaders = map[string][][]string{
+	"darwin":  {{"pbpaste"}},
+	"windows": {{"powershell", "-NoProfile", "-Command", "Get-Clipboard"}},
+	"linux":   {{"wl-paste", "--no-newline"}, {"xclip", "-selection", "clipboard", "-o"}, {"xsel", "--clipboard", "--output"}},
+}
+
+// clipboardWriters are the commands setting the system clipboard from
+// stdin on each OS, tried in order
+var clipboardWriters = map[string][][]string{
+	"darwin":  {{"pbcopy"}},
+	"windows": {{"clip"}},
+	"linux":   {{"wl-copy"}, {"xclip", "-selection", "clipboard"}, {"xsel", "--clipboard", "--input"}},
+}
+
+// clipboardCommand returns the first of the OS's candidate commands that is
+// installed
+func clipboardCommand(candidates map[string][][]string) ([]string, error) {
+	commands, ok := candidates[runtime.GOOS]
+	if !ok {
+		commands = candidates["linux"]
+	}
+	var names []string
+	for _, command := range commands {
+		if _, err := lookPath(command[0]); err == nil {
+			return command, nil
+		}
+		names = append(names, command[0])
+	}
+	return nil, fmt.Errorf("no clipboard tool found; install one of %s", strings.Join(names, ", "))
+}
+
+// readClipboard returns the text on the system clipboard
+func readClipboard() (string, error) {
+	command, err := clipboardCommand(clipboardReaders)
+	if err != nil {
+		return "", err
+	}
+	out, err := exec.Command(command[0], command[1:]...).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read the clipboard with %s: %w", command[0], err)
+	}
+	return string(out), nil
+}
+
+// writeClipboard puts text on the system clipboard
+func writeClipboard(text string) error {
+	command, err := clipboardCommand(clipboardWriters)
+	if err != nil {
+		return err
+	}
+	c := exec.Command(command[0], command[1:]...)
+	c.Stdin = strings.NewReader(text)
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("failed to copy to the clipboard with %s: %w", command[0], err)
+	}
+	return nil
+}
+
+// qrQuietZone is the light border around a QR code scanners need
+const qrQuietZone = 2
+
+// printQR draws text as a QR code with half-block characters, two rows of
+// modules per line. Light modules are drawn, so the code scans on the usual
+// dark terminal background.
+func printQR(w io.Writer, text string) error {
+	code, err := qr.Encode(text, qr.L)
+	if err != nil {
+		return fmt.Errorf("failed to encode QR code: %w", err)
+	}
+	light := func(x, y int) bool {
+		if x < 0 || y < 0 || x >= code.Size || y >= code.Size {
+			return y < code.Size+qrQuietZone
+		}
+		return !code.Black(x, y)
+	}
+
+	var b strings.Builder
+	for y := -qrQuietZone; y < code.Size+qrQuietZone; y += 2 {
+		for x := -qrQuietZone; x < code.Size+qrQuietZone; x++ {
+			top, bottom := light(x, y), light(x, y+1)
+			switch {
+			case top && bottom:
+				b.WriteString("█")
+			case top:
+				b.WriteString("▀")
+			case bottom:
+				b.WriteString("▄")
+			default:
+				b.WriteString(" ")
+			}
+		}
+		b.WriteString("\n")
+	}
+	_, err = io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/jrandolf/mcpr/clients"
+	"github.com/jrandolf/mcpr/config"
+
+	"github.com/spf13/cobra"
+)
+
+// Completions are "value\tdescription"; shells that show descriptions,
+// like PowerShell and fish, display them next to the value.
+
+// completeServerName completes the first argument with configured server
+// names
+func completeServerName(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return serverCompletions(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeServerNames completes every argument with configured server
+// names, leaving out those already given
+func completeServerNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var completions []string
+	for _, c := range serverCompletions() {
+		if name, _, _ := strings.Cut(c, "\t"); !slices.Contains(args, name) {
+			completions = append(completions, c)
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeClientName completes the first argument with supported client
+// names
+func completeClientName(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return clientCompletions(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeClientNames completes every argument with supported client names
+// and @groups, leaving out those already given
+func completeClientNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var completions []string
+	for _, c := range append(clientCompletions(), groupCompletions()...) {
+		if name, _, _ := strings.Cut(c, "\t"); !slices.Contains(args, name) {
+			completions = append(completions, c)
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeList completes a comma-separated list of candidates, such as
+// --servers a,b, leaving out those already listed
+func completeList(candidates func() []string) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		prefix := toComplete[:strings.LastIndex(toComplete, ",")+1]
+		listed := strings.Split(prefix, ",")
+		var completions []string
+		for _, c := range candidates() {
+			if name, _, _ := strings.Cut(c, "\t"); !slices.Contains(listed, name) {
+				completions = append(completions, prefix+c)
+			}
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	}
+}
+
+// serverCompletions returns the configured servers with their descriptions
+func serverCompletions() []string {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil
+	}
+	var completions []string
+	for _, s := range cfg.ListServers() {
+		completions = append(completions, completion(s.Name, s.Description))
+	}
+	return completions
+}
+
+// clientCompletions returns the supported clients with their display names
+func clientCompletions() []string {
+	names := clients.ListClientNames()
+	slices.Sort(names)
+	completions := make([]string, len(names))
+	for i, name := range names {
+		client, _ := clients.GetClient(name)
+		completions[i] = completion(name, client.DisplayName)
+	}
+	return completions
+}
+
+// groupCompletions returns the client groups as @name with their clients
+func groupCompletions() []string {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil
+	}
+	var completions []string
+	for name, members := range cfg.GetClientGroups() {
+		completions = append(completions, completion(config.GroupPrefix+name, strings.Join(members, ", ")))
+	}
+	slices.Sort(completions)
+	return completions
+}
+
+// completion formats a completion with an optional description
+func completion(value, description string) string {
+	if description == "" {
+		return value
+	}
+	return value + "\t" + description
+}
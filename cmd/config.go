@@ -0,0 +1,428 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/jrandolf/mcpr/config"
+
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the mcpr configuration",
+	Long: `Inspect the mcpr configuration files.
+
+Subcommands:
+  path    - Print the active config file and why it was chosen
+  cat     - Pretty-print the active config
+  edit    - Open the active config in $EDITOR and validate on save
+  layers  - Show the config layers and how their servers are merged
+  machine - Show which machine overrides apply on this machine
+  repair  - Recover a config file that doesn't parse`,
+}
+
+var configPathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Print the active config file and why it was chosen",
+	Long: `Print the path of the active config file and explain how it was found.
+
+mcpr looks for mcpr.json in the current directory and its parents, and falls
+back to ~/.config/mcpr/config.json.`,
+	Args: cobra.NoArgs,
+	RunE: runConfigPath,
+}
+
+var configCatCmd = &cobra.Command{
+	Use:   "cat",
+	Short: "Pretty-print the active config",
+	Args:  cobra.NoArgs,
+	RunE:  runConfigCat,
+}
+
+var configEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Open the active config in your editor",
+	Long: `Open the active config file in $VISUAL or $EDITOR.
+
+Changes are validated before they are saved. If the edited file is invalid,
+the error (with line and column for JSON syntax errors) is shown and you can
+edit again or discard the changes.`,
+	Args: cobra.NoArgs,
+	RunE: runConfigEdit,
+}
+
+var configLayersCmd = &cobra.Command{
+	Use:   "layers",
+	Short: "Show config layers and the merged server set",
+	Long: `Show every config layer from lowest to highest precedence and which
+layer provides each effective server.
+
+Layers:
+  system  - /etc/mcpr/config.json (%ProgramData%\mcpr\config.json on Windows),
+            provisioned by administrators for every user on the machine
+  user    - ~/.config/mcpr/config.json
+  project - mcpr.json in the current or a parent directory
+
+The system layer is merged below the active user or project config. A
+server defined in a higher layer overrides a server with the same name in a
+lower one.`,
+	Args: cobra.NoArgs,
+	RunE: runConfigLayers,
+}
+
+var configMachineCmd = &cobra.Command{
+	Use:   "machine",
+	Short: "Show which machine overrides apply on this machine",
+	Long: `Show the names this machine is matched by and the sections of the
+config's "machines" object that apply here.
+
+A config shared between computers can adjust servers per machine. Sections
+are keyed by hostname, with or without its domain, or by the name set in
+$MCPR_MACHINE, and their fields replace the server's; env vars and headers
+are merged into the server's:
+
+  "machines": {
+    "work-laptop": {
+      "servers": {
+        "github": {"env": {"GITHUB_HOST": "github.example.com"}},
+        "notes": {"disabled": true}
+      }
+    }
+  }`,
+	Args: cobra.NoArgs,
+	RunE: runConfigMachine,
+}
+
+var configRepairFromBackup bool
+
+var configRepairCmd = &cobra.Command{
+	Use:   "repair",
+	Short: "Recover a config file that doesn't parse",
+	Long: `Recover the active config file when it no longer parses, for example
+after a bad hand edit or a write cut short.
+
+A JSON config is first parsed leniently: strings, objects and arrays left
+open are closed, missing commas are inserted and anything after the config
+is cut off. If that doesn't give a valid config, or with --from-backup, the
+newest earlier version that parses is restored instead. mcpr keeps the last
+5 versions of a config each time it saves over it.
+
+The broken file is kept next to the config with a .broken extension.
+
+Examples:
+  mcpr config repair
+  mcpr config repair --from-backup`,
+	Args: cobra.NoArgs,
+	RunE: runConfigRepair,
+}
+
+func init() {
+	configCmd.AddCommand(configPathCmd)
+	configCmd.AddCommand(configCatCmd)
+	configCmd.AddCommand(configEditCmd)
+	configEditCmd.Flags().BoolVar(&unlockConfig, "unlock", false, unlockUsage)
+	configCmd.AddCommand(configLayersCmd)
+	configCmd.AddCommand(configMachineCmd)
+	configCmd.AddCommand(configRepairCmd)
+	configRepairCmd.Flags().BoolVar(&configRepairFromBackup, "from-backup", false, "Restore the newest earlier version rather than parsing leniently")
+}
+
+func runConfigPath(cmd *cobra.Command, args []string) error {
+	path, reason, err := config.ExplainConfigPath()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(path)
+	fmt.Printf("  (%s)\n", reason)
+	return nil
+}
+
+func runConfigCat(cmd *cobra.Command, args []string) error {
+	path, err := config.GetConfigPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		fmt.Printf("No config file at %s yet.\n", path)
+		fmt.Println("Use 'mcpr add' to add a server.")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+
+	format := config.FormatForPath(path)
+	if err := config.ValidateFormat(data, format); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	// YAML, TOML and files with comments are shown as written
+	var out bytes.Buffer
+	if format != config.FormatJSON {
+		fmt.Print(string(data))
+		return nil
+	}
+	if err := json.Indent(&out, data, "", "  "); err != nil {
+		fmt.Print(string(data))
+		return nil
+	}
+	fmt.Println(out.String())
+	return nil
+}
+
+func runConfigEdit(cmd *cobra.Command, args []string) error {
+	path, err := config.GetConfigPath()
+	if err != nil {
+		return err
+	}
+
+	format := config.FormatForPath(path)
+	original, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		original = []byte(fmt.Sprintf("{\n  \"$schema\": %q,\n  \"servers\": []\n}\n", config.SchemaURL))
+	} else if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	} else if cfg, err := config.LoadFromPath(path); err == nil {
+		if err := applyUnlock(cfg).CheckLocked(); err != nil {
+			return err
+		}
+	}
+
+	// Edit a temporary copy so invalid content never reaches the real file.
+	// The extension is kept so editors pick the right syntax highlighting.
+	tmp, err := os.CreateTemp("", "mcpr-*"+filepath.Ext(path))
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(original); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	tmp.Close()
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		if err := openInEditor(tmp.Name()); err != nil {
+			return err
+		}
+
+		edited, err := os.ReadFile(tmp.Name())
+		if err != nil {
+			return fmt.Errorf("failed to read edited file: %w", err)
+		}
+
+		if bytes.Equal(edited, original) {
+			fmt.Println("No changes made.")
+			return nil
+		}
+
+		validationErr := config.ValidateFormat(edited, format)
+		if validationErr == nil {
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return fmt.Errorf("failed to create config directory: %w", err)
+			}
+			// Kept as a backup first, so an edit that breaks the config
+			// can be undone with 'mcpr config repair'
+			if err := config.WriteConfigFile(path, edited); err != nil {
+				return fmt.Errorf("failed to write config: %w", err)
+			}
+			fmt.Printf("Saved %s\n", path)
+			fmt.Println("Run 'mcpr client sync' to apply the changes to your clients.")
+			return nil
+		}
+
+		fmt.Fprintf(os.Stderr, "Invalid config: %v\n", validationErr)
+		fmt.Print("Edit again? [Y/n] ")
+		answer, _ := reader.ReadString('\n')
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		if answer == "n" || answer == "no" {
+			return fmt.Errorf("changes discarded")
+		}
+	}
+}
+
+// openInEditor opens path in $VISUAL or $EDITOR and waits for it to exit
+func openInEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		} else {
+			editor = "vi"
+		}
+	}
+
+	// Allow editors with arguments, e.g. "code --wait"
+	parts := strings.Fields(editor)
+	c := exec.Command(parts[0], append(parts[1:], path)...)
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("editor %q failed: %w", editor, err)
+	}
+	return nil
+}
+
+func runConfigLayers(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	layers := cfg.Layers()
+
+	fmt.Println("Config layers (lowest precedence first):")
+	fmt.Println()
+	for i, layer := range layers {
+		status := fmt.Sprintf("%d server(s)", len(layer.Servers))
+		if !layer.Exists {
+			status = "not found"
+		}
+		active := ""
+		if i == len(layers)-1 {
+			active = " [active]"
+		}
+		fmt.Printf("  %-8s %s (%s)%s\n", layer.Name, layer.Path, status, active)
+	}
+
+	servers := cfg.ListServers()
+	if len(servers) == 0 {
+		return nil
+	}
+
+	fmt.Println("\nEffective servers:")
+	fmt.Println()
+	for _, server := range servers {
+		var definedIn []string
+		for _, layer := range layers {
+			for _, s := range layer.Servers {
+				if s.Name == server.Name {
+					definedIn = append(definedIn, layer.Name)
+					break
+				}
+			}
+		}
+
+		source := cfg.ServerLayer(server.Name)
+		if len(definedIn) > 1 {
+			fmt.Printf("  %s (%s, overrides %s)\n", server.Name, source, strings.Join(definedIn[:len(definedIn)-1], ", "))
+		} else {
+			fmt.Printf("  %s (%s)\n", server.Name, source)
+		}
+	}
+
+	return nil
+}
+
+func runConfigMachine(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	names := config.MachineNames()
+	if len(names) == 0 {
+		fmt.Printf("This machine has no name; set $%s to name it\n", config.MachineEnv)
+	} else {
+		fmt.Printf("This machine matches: %s\n", strings.Join(names, ", "))
+	}
+
+	keys := config.MatchingMachines(cfg.Machines)
+	if len(keys) == 0 {
+		fmt.Printf("No machine section of %s applies\n", cfg.Path())
+		return nil
+	}
+	fmt.Printf("\nSections of %s that apply, in order:\n\n", cfg.Path())
+	for _, key := range keys {
+		servers := slices.Sorted(maps.Keys(cfg.Machines[key].Servers))
+		fmt.Printf("  %s: %s\n", key, strings.Join(servers, ", "))
+	}
+	return nil
+}
+
+func runConfigRepair(cmd *cobra.Command, args []string) error {
+	path, err := config.GetConfigPath()
+	if err != nil {
+		return err
+	}
+	return repairConfig(os.Stdout, path, configRepairFromBackup)
+}
+
+// repairConfig replaces the config at path, if it doesn't parse, with a
+// lenient parse of it or the newest backup that parses, keeping the broken
+// file as <path>.broken
+func repairConfig(w io.Writer, path string, fromBackup bool) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("no config file at %s", path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	format := config.FormatForPath(path)
+	if err := config.ValidateFormat(data, format); err == nil {
+		fmt.Fprintf(w, "%s %s parses; nothing to repair\n", okMark(), path)
+		return nil
+	}
+
+	var repaired []byte
+	how := ""
+	if format == config.FormatJSON && !fromBackup {
+		if repaired, err = config.RepairJSON(data); err == nil {
+			how = "by a lenient parse; check that every server is still there with 'mcpr list'"
+		}
+	}
+	if repaired == nil {
+		backups, err := config.Backups(path)
+		if err != nil {
+			return err
+		}
+		for _, backup := range backups {
+			candidate, err := os.ReadFile(backup.Path)
+			if err == nil && config.ValidateFormat(candidate, format) == nil {
+				repaired = candidate
+				how = "from the version saved " + backup.Saved.Local().Format("2006-01-02 15:04:05")
+				break
+			}
+		}
+	}
+	if repaired == nil {
+		return fmt.Errorf("failed to repair %s: no lenient parse or backup gives a valid config; fix it with 'mcpr config edit'", path)
+	}
+
+	// The broken file may hold env vars and headers
+	broken := path + ".broken"
+	if err := config.WriteFile(broken, data, true); err != nil {
+		return fmt.Errorf("failed to keep broken config: %w", err)
+	}
+	var fixed config.Config
+	if err := config.Unmarshal(repaired, format, &fixed); err != nil {
+		return fmt.Errorf("failed to parse repaired config: %w", err)
+	}
+	if err := config.WriteFile(path, repaired, config.HasSecrets(fixed.Servers)); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	fmt.Fprintf(w, "%s Repaired %s %s\n", okMark(), path, how)
+	fmt.Fprintf(w, "  The broken file was kept as %s\n", broken)
+	return nil
+}
@@ -0,0 +1,297 @@
+package cmd
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/jrandolf/mcpr/clients"
+	"github.com/jrandolf/mcpr/config"
+)
+
+var (
+	clientSyncOurs   bool
+	clientSyncTheirs bool
+)
+
+// syncConflict is a server a client config defines differently from mcpr,
+// because its entry there was changed outside mcpr
+type syncConflict struct {
+	Client   *clients.Client
+	Path     string
+	Ours     config.MCPServer // As mcpr defines it
+	Rendered config.MCPServer // As mcpr writes it to the client, read back
+	Theirs   config.MCPServer // As read back from the client's entry
+}
+
+// conflictResolver resolves a conflict, returning the server mcpr should
+// define from then on, or nil to keep its own definition and overwrite the
+// client's
+type conflictResolver func(c syncConflict) (*config.MCPServer, error)
+
+// resolveOurs keeps mcpr's definition of every conflicting server
+func resolveOurs(syncConflict) (*config.MCPServer, error) {
+	return nil, nil
+}
+
+// resolveTheirs takes the client's definition of every conflicting server
+func resolveTheirs(c syncConflict) (*config.MCPServer, error) {
+	server, err := takeTheirs(c)
+	if err != nil {
+		return nil, err
+	}
+	return &server, nil
+}
+
+// syncResolver returns how --ours, --theirs and the terminal resolve
+// conflicts: nil fails the sync, as it does when nobody can be asked
+func syncResolver(w io.Writer, in io.Reader, interactive bool) (conflictResolver, error) {
+	switch {
+	case clientSyncOurs && clientSyncTheirs:
+		return nil, errors.New("--ours and --theirs can't be used together")
+	case clientSyncOurs:
+		return resolveOurs, nil
+	case clientSyncTheirs:
+		return resolveTheirs, nil
+	case interactive:
+		return promptResolver(w, bufio.NewReader(in), openInEditor), nil
+	}
+	return nil, nil
+}
+
+// promptResolver asks on in how to resolve each conflict: keep mcpr's
+// definition, take the client's, or edit a merge of both with edit
+func promptResolver(w io.Writer, in *bufio.Reader, edit func(path string) error) conflictResolver {
+	return func(c syncConflict) (*config.MCPServer, error) {
+		fmt.Fprintf(w, "\nServer %q was changed in %s (%s):\n", c.Ours.Name, c.Client.DisplayName, c.Path)
+		fmt.Fprintf(w, "  mcpr:  %s\n", describeServer(c.Ours))
+		fmt.Fprintf(w, "  %s: %s\n", c.Client.Name, describeServer(c.Theirs))
+		for {
+			fmt.Fprint(w, "Keep [m]ine, keep [t]heirs or [e]dit merged? [m/t/e] ")
+			answer, err := in.ReadString('\n')
+			switch strings.ToLower(strings.TrimSpace(answer)) {
+			case "m", "mine":
+				return nil, nil
+			case "t", "theirs":
+				return resolveTheirs(c)
+			case "e", "edit":
+				return editMerged(c, edit)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("conflict over %q not resolved", c.Ours.Name)
+			}
+		}
+	}
+}
+
+// describeServer describes how a server is started or reached in one line
+func describeServer(server config.MCPServer) string {
+	var b strings.Builder
+	if server.Type == "http" {
+		b.WriteString(server.URL)
+		for _, key := range slices.Sorted(maps.Keys(server.Headers)) {
+			fmt.Fprintf(&b, " %s: %s", key, server.Headers[key])
+		}
+		return b.String()
+	}
+	for _, key := range slices.Sorted(maps.Keys(server.Env)) {
+		fmt.Fprintf(&b, "%s=%s ", key, server.Env[key])
+	}
+	b.WriteString(strings.Join(append([]string{server.Command}, server.Args...), " "))
+	return b.String()
+}
+
+// editMerged opens the client's definition merged into mcpr's with edit,
+// mcpr's own following in a comment, and returns the server as saved. When
+// the client's changes can't be merged, mcpr's definition is edited.
+func editMerged(c syncConflict, edit func(path string) error) (*config.MCPServer, error) {
+	draft, err := takeTheirs(c)
+	if err != nil {
+		draft = c.Ours
+	}
+	merged, err := json.MarshalIndent(draft, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	ours, err := json.MarshalIndent(c.Ours, "// ", "  ")
+	if err != nil {
+		return nil, err
+	}
+	tmp, err := os.CreateTemp("", "mcpr-*.json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = fmt.Fprintf(tmp, "%s\n\n// mcpr's definition, before %s changed it:\n// %s\n", merged, c.Client.DisplayName, ours)
+	tmp.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	if err := edit(tmp.Name()); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(tmp.Name())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read edited file: %w", err)
+	}
+	var server config.MCPServer
+	if err := config.Unmarshal(data, config.FormatJSON, &server); err != nil {
+		return nil, fmt.Errorf("failed to parse edited server: %w", err)
+	}
+	if server.Name != c.Ours.Name {
+		return nil, fmt.Errorf("the edited server must keep its name %q", c.Ours.Name)
+	}
+	if err := config.ValidateServer(server); err != nil {
+		return nil, err
+	}
+	return &server, nil
+}
+
+// takeTheirs returns mcpr's server with the changes the client's entry
+// made to how it is started or reached. Fields are compared with what mcpr
+// rendered for the client, and only those the client changed are taken, so
+// placeholders, env files, platform overrides and the like stay as they are
+// in mcpr. Commands mcpr wraps, in a sandbox or a socket bridge, can't be
+// taken back, since the client only holds the wrapper.
+func takeTheirs(c syncConflict) (config.MCPServer, error) {
+	ours, rendered, theirs := c.Ours, c.Rendered, c.Theirs
+	merged := ours
+	launch := theirs.Type != rendered.Type || theirs.Command != rendered.Command || !slices.Equal(theirs.Args, rendered.Args)
+	if launch && (ours.Sandbox != nil || ours.Type == "socket") {
+		return config.MCPServer{}, fmt.Errorf("can't take %s's change to %q: mcpr wraps its command, so edit the server with mcpr instead", c.Client.DisplayName, ours.Name)
+	}
+	if launch {
+		merged.Type = theirs.Type
+		merged.Command = pickChanged(ours.Command, rendered.Command, theirs.Command)
+		merged.Args = theirs.Args
+		if len(ours.Args) == len(rendered.Args) {
+			// Args the client kept are mapped back to mcpr's
+			unrendered := make(map[string]string, len(rendered.Args))
+			for i, arg := range rendered.Args {
+				unrendered[arg] = ours.Args[i]
+			}
+			merged.Args = make([]string, len(theirs.Args))
+			for i, arg := range theirs.Args {
+				merged.Args[i] = cmp.Or(unrendered[arg], arg)
+			}
+		}
+		// Overrides would undo the client's change at the next sync
+		merged.Path = ""
+		merged.Platforms = nil
+	}
+	merged.URL = pickChanged(ours.URL, rendered.URL, theirs.URL)
+	merged.Env = mergeChanged(ours.Env, rendered.Env, theirs.Env)
+	merged.Headers = mergeChanged(ours.Headers, rendered.Headers, theirs.Headers)
+	if theirs.EnvFile != rendered.EnvFile {
+		merged.EnvFile = theirs.EnvFile
+	}
+	return merged, nil
+}
+
+// pickChanged returns theirs if the client changed the value mcpr rendered
+// from ours, and ours otherwise
+func pickChanged(ours, rendered, theirs string) string {
+	if theirs == rendered {
+		return ours
+	}
+	return theirs
+}
+
+// mergeChanged returns ours with the values the client added, changed or
+// removed compared with what mcpr rendered from it. Values mcpr added when
+// rendering, such as those of an env file, aren't taken.
+func mergeChanged(ours, rendered, theirs map[string]string) map[string]string {
+	merged := maps.Clone(ours)
+	for key, value := range theirs {
+		if old, ok := rendered[key]; !ok || old != value {
+			if merged == nil {
+				merged = make(map[string]string)
+			}
+			merged[key] = value
+		}
+	}
+	for key := range rendered {
+		if _, ok := theirs[key]; !ok {
+			delete(merged, key)
+		}
+	}
+	if len(merged) == 0 {
+		return nil
+	}
+	return merged
+}
+
+// findConflicts returns the servers the client config at path defines
+// differently from data, the rendering of servers about to be written,
+// because their entries were changed outside mcpr. An entry is mcpr's as
+// long as it matches the newest snapshot of what mcpr synced there. A
+// synced client without snapshots was synced before they were kept, so
+// none of its entries conflict.
+func findConflicts(client *clients.Client, synced bool, path string, data []byte, servers []config.MCPServer) []syncConflict {
+	written, ok := client.SnapshotEntries(path)
+	if !ok && synced {
+		return nil
+	}
+	after := client.ParseServerEntries(path, data)
+	var conflicts []syncConflict
+	for name, entry := range client.ServerEntries(path) {
+		want, kept := after[name]
+		if !kept || sameEntry(name, entry, want) {
+			continue
+		}
+		if last, ok := written[name]; ok && sameEntry(name, entry, last) {
+			continue
+		}
+		theirs, err := clients.EntryServer(name, entry)
+		if err != nil {
+			continue
+		}
+		i := slices.IndexFunc(servers, func(server config.MCPServer) bool { return server.Name == name })
+		if i < 0 {
+			continue
+		}
+		rendered, err := clients.EntryServer(name, want)
+		if err != nil {
+			rendered = servers[i]
+		}
+		conflicts = append(conflicts, syncConflict{Client: client, Path: path, Ours: servers[i], Rendered: rendered, Theirs: theirs})
+	}
+	slices.SortFunc(conflicts, func(a, b syncConflict) int { return strings.Compare(a.Ours.Name, b.Ours.Name) })
+	return conflicts
+}
+
+// sameEntry reports whether two entries of a server define it the same way,
+// as MCPServer.Equal does. Fields clients keep for themselves, such as tool
+// approvals, are ignored. Entries that can't be read are compared as is.
+func sameEntry(name string, a, b any) bool {
+	as, aErr := clients.EntryServer(name, a)
+	bs, bErr := clients.EntryServer(name, b)
+	if aErr != nil || bErr != nil {
+		return reflect.DeepEqual(a, b)
+	}
+	return as.Equal(bs)
+}
+
+// conflictError describes the conflicts a sync of a client ran into, and
+// how to resolve them without asking
+func conflictError(client *clients.Client, local bool, conflicts []syncConflict) error {
+	names := make([]string, len(conflicts))
+	for i, c := range conflicts {
+		names[i] = c.Ours.Name
+	}
+	command := "mcpr client sync " + client.Name
+	if local {
+		command += " --local"
+	}
+	return fmt.Errorf("%s changed %s outside mcpr; run '%s' in a terminal to choose, or add --ours to overwrite them or --theirs to keep them",
+		client.DisplayName, strings.Join(quoteAll(names), ", "), command)
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jrandolf/mcpr/clients"
+	"github.com/jrandolf/mcpr/config"
+)
+
+// allowPlaintextSecrets lets syncs write values looking like live
+// credentials into client configs
+var allowPlaintextSecrets bool
+
+// checkPlaintextSecrets fails if staged writes values looking like live
+// credentials, such as AWS keys or GitHub tokens, into its client config.
+// Those the config already holds are left to the user.
+func checkPlaintextSecrets(staged clients.Staged) error {
+	if allowPlaintextSecrets {
+		return nil
+	}
+	existing := make(map[string]bool)
+	if data, err := os.ReadFile(staged.Path); err == nil {
+		for _, c := range config.FindCredentials(data) {
+			existing[c.Value] = true
+		}
+	}
+
+	var found []string
+	for _, c := range config.FindCredentials(staged.Data) {
+		if !existing[c.Value] {
+			found = append(found, fmt.Sprintf("%s on line %d", c.Kind, c.Line))
+		}
+	}
+	if len(found) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s would hold what looks like a live credential in plaintext (%s); reference it as ${VAR} or keep it in an env file, or pass --allow-plaintext-secrets", staged.Path, strings.Join(found, ", "))
+}
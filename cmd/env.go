@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// parseEnv returns the env vars of envJSON, a JSON object of strings or -
+// to read one from stdin, overridden by those given with --env. An --env
+// without "=" takes the value from the current environment, so secrets
+// don't end up in shell history.
+func parseEnv(pairs []string, envJSON string, stdin io.Reader) (map[string]string, error) {
+	env := make(map[string]string)
+	if envJSON != "" {
+		data := []byte(envJSON)
+		if envJSON == "-" {
+			var err error
+			if data, err = io.ReadAll(stdin); err != nil {
+				return nil, fmt.Errorf("failed to read env vars: %w", err)
+			}
+		}
+		if err := json.Unmarshal(data, &env); err != nil {
+			return nil, fmt.Errorf("--env-json must be a JSON object of strings: %w", err)
+		}
+	}
+	for _, p := range pairs {
+		key, value, ok := strings.Cut(p, "=")
+		if key == "" {
+			return nil, fmt.Errorf("invalid env var %q (expected KEY=VALUE or KEY)", p)
+		}
+		if !ok {
+			if value, ok = os.LookupEnv(key); !ok {
+				return nil, fmt.Errorf("env var %s isn't set in the current environment", key)
+			}
+		}
+		env[key] = value
+	}
+	return env, nil
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/jrandolf/mcpr/config"
+)
+
+// gitIgnoreStatus returns the top of the git work tree holding path, or ""
+// if there is none, and whether git ignores path there; a variable so tests
+// can override it
+var gitIgnoreStatus = func(path string) (top string, ignored bool) {
+	dir := filepath.Dir(path)
+	top, err := gitOutput("-C", dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", false
+	}
+	err = exec.Command("git", "-C", dir, "check-ignore", "-q", "--", filepath.Base(path)).Run()
+	return filepath.FromSlash(top), err == nil
+}
+
+// exposedSecrets returns where the local client config at path, holding
+// data, writes out secrets git would commit: none if it isn't in a git
+// work tree or is ignored there. top is the top of the work tree.
+func exposedSecrets(path string, data []byte) (top string, found []string) {
+	var doc any
+	if err := config.Unmarshal(data, config.FormatForPath(path), &doc); err != nil {
+		return "", nil
+	}
+	if found = literalSecrets(doc, ""); len(found) == 0 {
+		return "", nil
+	}
+	top, ignored := gitIgnoreStatus(path)
+	if top == "" || ignored {
+		return "", nil
+	}
+	return top, found
+}
+
+// warnExposedSecrets warns when the local client config at path holds
+// secrets and isn't ignored by git
+func warnExposedSecrets(path string, data []byte) {
+	if top, found := exposedSecrets(path, data); top != "" {
+		fmt.Fprintf(os.Stderr, "Warning: %s holds secrets (%s) but isn't ignored by git; add it to .gitignore or reference them as ${VAR}\n", path, strings.Join(found, ", "))
+	}
+}
+
+// guardLocalConfig offers to add the local client config at path, holding
+// data, to .gitignore when it holds secrets git would commit. With add it
+// is added without asking; otherwise the user is asked on in if it's
+// interactive, and warned if it isn't.
+func guardLocalConfig(w io.Writer, in io.Reader, interactive bool, path string, data []byte, add bool) error {
+	top, found := exposedSecrets(path, data)
+	if top == "" {
+		return nil
+	}
+	if !add {
+		if !interactive {
+			warnExposedSecrets(path, data)
+			return nil
+		}
+		fmt.Fprintf(w, "\n%s holds secrets (%s) but isn't ignored by git. Add it to %s? [y/N] ", path, strings.Join(found, ", "), filepath.Join(top, ".gitignore"))
+		answer, _ := bufio.NewReader(in).ReadString('\n')
+		if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+			return nil
+		}
+	}
+
+	entry, err := addToGitignore(top, path)
+	if err != nil {
+		return err
+	}
+	if add {
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintf(w, "%s Added %s to %s\n", okMark(), entry, filepath.Join(top, ".gitignore"))
+	if _, err := gitOutput("-C", top, "ls-files", "--error-unmatch", "--", entry[1:]); err == nil {
+		fmt.Fprintf(os.Stderr, "Warning: %s is already committed; run 'git rm --cached %s' to stop tracking it\n", path, entry[1:])
+	}
+	return nil
+}
+
+// addToGitignore appends path, anchored to the work tree at top, to the
+// .gitignore there and returns the entry
+func addToGitignore(top, path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	// git reports top with symlinks resolved
+	if resolved, err := filepath.EvalSymlinks(filepath.Dir(abs)); err == nil {
+		abs = filepath.Join(resolved, filepath.Base(abs))
+	}
+	rel, err := filepath.Rel(top, abs)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("%s isn't in the git work tree at %s", path, top)
+	}
+	entry := "/" + filepath.ToSlash(rel)
+
+	gitignore := filepath.Join(top, ".gitignore")
+	existing, err := os.ReadFile(gitignore)
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read %s: %w", gitignore, err)
+	}
+	content := string(existing)
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	content += entry + "\n"
+	if err := os.WriteFile(gitignore, []byte(content), 0o644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", gitignore, err)
+	}
+	return entry, nil
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/jrandolf/mcpr/clients"
+	"github.com/jrandolf/mcpr/config"
+
+	"github.com/spf13/cobra"
+)
+
+var clientGroupCmd = &cobra.Command{
+	Use:   "group",
+	Short: "Manage client groups",
+	Long: `Manage named groups of clients stored in your mcpr config.
+
+A group is referenced as @name wherever clients are named, so one command
+syncs, removes or verifies every client in it.
+
+Examples:
+  mcpr client group set editors cursor vscode zed
+  mcpr client group set cli claude-code gemini codex
+  mcpr client sync @editors
+  mcpr verify @cli
+  mcpr client group list
+  mcpr client group remove cli`,
+}
+
+var clientGroupSetCmd = &cobra.Command{
+	Use:   "set [name] [client-name]...",
+	Short: "Add or replace a client group",
+	Args:  cobra.MinimumNArgs(2),
+	RunE:  runClientGroupSet,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		var completions []string
+		for _, c := range clientCompletions() {
+			if name, _, _ := strings.Cut(c, "\t"); !slices.Contains(args[1:], name) {
+				completions = append(completions, c)
+			}
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+var clientGroupListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List client groups",
+	Args:  cobra.NoArgs,
+	RunE:  runClientGroupList,
+}
+
+var clientGroupRemoveCmd = &cobra.Command{
+	Use:     "remove [name]",
+	Aliases: []string{"rm"},
+	Short:   "Remove a client group",
+	Args:    cobra.ExactArgs(1),
+	RunE:    runClientGroupRemove,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) != 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		cfg, err := config.Load()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return slices.Sorted(maps.Keys(cfg.GetClientGroups())), cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+func init() {
+	clientGroupCmd.PersistentFlags().BoolVar(&unlockConfig, "unlock", false, unlockUsage)
+	clientGroupCmd.AddCommand(clientGroupSetCmd)
+	clientGroupCmd.AddCommand(clientGroupListCmd)
+	clientGroupCmd.AddCommand(clientGroupRemoveCmd)
+	clientCmd.AddCommand(clientGroupCmd)
+}
+
+func runClientGroupSet(cmd *cobra.Command, args []string) error {
+	name, members := strings.TrimPrefix(args[0], config.GroupPrefix), args[1:]
+	if name == "" {
+		return fmt.Errorf("client group name cannot be empty")
+	}
+	for _, member := range members {
+		if strings.HasPrefix(member, config.GroupPrefix) {
+			return fmt.Errorf("client groups can't contain other groups, such as %s", member)
+		}
+		if _, err := clients.GetClient(member); err != nil {
+			return fmt.Errorf("%w\n\nSupported clients: %s", err, strings.Join(clients.ListClientNames(), ", "))
+		}
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := applyUnlock(cfg).CheckLocked(); err != nil {
+		return err
+	}
+	cfg.SetClientGroup(name, members)
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	fmt.Printf("Client group %s%s %s %s saved to %s\n", config.GroupPrefix, name, arrow(), strings.Join(members, ", "), cfg.Path())
+	return nil
+}
+
+func runClientGroupList(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	groups := cfg.GetClientGroups()
+	if len(groups) == 0 {
+		fmt.Println("No client groups configured.")
+		fmt.Println("Use 'mcpr client group set' to add one.")
+		return nil
+	}
+
+	fmt.Printf("Client groups (from %s):\n\n", cfg.Path())
+	for _, name := range slices.Sorted(maps.Keys(groups)) {
+		fmt.Printf("  %s%s %s %s\n", config.GroupPrefix, name, arrow(), strings.Join(groups[name], ", "))
+	}
+	return nil
+}
+
+func runClientGroupRemove(cmd *cobra.Command, args []string) error {
+	name := strings.TrimPrefix(args[0], config.GroupPrefix)
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := applyUnlock(cfg).CheckLocked(); err != nil {
+		return err
+	}
+	if err := cfg.RemoveClientGroup(name); err != nil {
+		return err
+	}
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	fmt.Printf("Removed client group %s%s\n", config.GroupPrefix, name)
+	return nil
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// parseHeaders returns the headers in the lines of the header file at
+// path, if any, overridden by those given with --header
+func parseHeaders(flags []string, path string) (map[string]string, error) {
+	headers := make(map[string]string)
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read header file: %w", err)
+		}
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for n := 1; scanner.Scan(); n++ {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			key, value, err := parseHeader(line)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", path, n, err)
+			}
+			headers[key] = value
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read header file: %w", err)
+		}
+	}
+	for _, h := range flags {
+		key, value, err := parseHeader(h)
+		if err != nil {
+			return nil, err
+		}
+		headers[key] = value
+	}
+	return headers, nil
+}
+
+// parseHeader splits a header given as "Key: Value" or "Key=Value" at the
+// first colon or equals sign, so values keep any that follow, as in
+// "Authorization=Bearer abc=def"
+func parseHeader(s string) (string, string, error) {
+	i := strings.IndexAny(s, ":=")
+	if i < 0 {
+		return "", "", fmt.Errorf("invalid header %q (expected Key: Value or Key=Value)", s)
+	}
+	key, value := strings.TrimSpace(s[:i]), strings.TrimSpace(s[i+1:])
+	if !validHeaderName(key) {
+		return "", "", fmt.Errorf("invalid header name %q in %q", key, s)
+	}
+	return key, value, nil
+}
+
+// validHeaderName reports whether name is a valid HTTP header name: one or
+// more token characters
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case strings.ContainsRune("!#$%&'*+-.^_`|~", r):
+		default:
+			return false
+		}
+	}
+	return true
+}
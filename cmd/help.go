@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jrandolf/mcpr/clients"
+
+	"github.com/spf13/cobra"
+)
+
+// clientListMarker in a command's long help is replaced with the supported
+// clients when the help is shown
+const clientListMarker = "{{clients}}"
+
+// withClientHelp makes the help of cmd list the supported clients in place
+// of clientListMarker, so the list always matches the registry
+func withClientHelp(cmd *cobra.Command) {
+	long := cmd.Long
+	cmd.SetHelpFunc(func(c *cobra.Command, args []string) {
+		c.Long = strings.Replace(long, clientListMarker, clientHelp(), 1)
+		c.Root().HelpFunc()(c, args)
+	})
+}
+
+// clientHelp describes the supported clients for help text: their names,
+// what they support and where their global config is on this machine
+func clientHelp() string {
+	home, _ := os.UserHomeDir()
+	var b strings.Builder
+	b.WriteString("Supported clients (local: also syncs project configs with --local):\n")
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	for _, name := range clients.ListClientNames() {
+		client, _ := clients.GetClient(name)
+		var notes []string
+		if client.SupportsLocal {
+			notes = append(notes, "local")
+		}
+		if client.StdioOnly {
+			notes = append(notes, "stdio only")
+		}
+		path, err := client.Path(false)
+		if err != nil {
+			path = err.Error()
+		} else if home != "" && strings.HasPrefix(path, home+string(filepath.Separator)) {
+			path = "~" + path[len(home):]
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", name, client.DisplayName, strings.Join(notes, ", "), path)
+	}
+	tw.Flush()
+	return strings.TrimRight(b.String(), "\n")
+}
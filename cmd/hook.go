@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// hookCommand is what the installed pre-commit hook runs
+const hookCommand = "mcpr verify --local"
+
+// hookMarker identifies pre-commit hooks written by mcpr
+const hookMarker = "# Installed by mcpr hook install"
+
+var hookForce bool
+
+var hookCmd = &cobra.Command{
+	Use:   "hook",
+	Short: "Manage the git hook that verifies client configs",
+}
+
+var hookInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install a pre-commit hook running mcpr verify",
+	Long: `Install a git pre-commit hook in the current repository that runs
+'` + hookCommand + `', so committed client configs such as .mcp.json and
+.cursor/mcp.json can't drift from the mcpr config or leak secrets.
+
+Repositories using Husky get the command added to .husky/pre-commit. For
+repositories using the pre-commit framework, the hook to add to
+.pre-commit-config.yaml is printed instead. Otherwise the hook is written to
+the repository's hooks directory.
+
+Examples:
+  mcpr hook install
+  mcpr hook install --force`,
+	Args: cobra.NoArgs,
+	RunE: runHookInstall,
+}
+
+func init() {
+	hookInstallCmd.Flags().BoolVarP(&hookForce, "force", "f", false, "Replace an existing pre-commit hook")
+	hookCmd.AddCommand(hookInstallCmd)
+}
+
+func runHookInstall(cmd *cobra.Command, args []string) error {
+	top, err := gitOutput("rev-parse", "--show-toplevel")
+	if err != nil {
+		return fmt.Errorf("not in a git repository: %w", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(top, ".pre-commit-config.yaml")); err == nil {
+		fmt.Printf("This repository uses pre-commit. Add this hook to .pre-commit-config.yaml:\n\n%s", preCommitHookConfig)
+		return nil
+	}
+
+	if info, err := os.Stat(filepath.Join(top, ".husky")); err == nil && info.IsDir() {
+		path := filepath.Join(top, ".husky", "pre-commit")
+		added, err := installHuskyHook(path)
+		if err != nil {
+			return err
+		}
+		if !added {
+			fmt.Printf("%s already runs '%s'\n", path, hookCommand)
+			return nil
+		}
+		fmt.Printf("Added '%s' to %s\n", hookCommand, path)
+		return nil
+	}
+
+	hooks, err := gitOutput("rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return fmt.Errorf("failed to locate git hooks: %w", err)
+	}
+	path := filepath.Join(hooks, "pre-commit")
+	if !filepath.IsAbs(path) {
+		cwd, _ := os.Getwd()
+		path = filepath.Join(cwd, path)
+	}
+	if err := installGitHook(path, hookForce); err != nil {
+		return err
+	}
+	fmt.Printf("Installed pre-commit hook at %s\n", path)
+	return nil
+}
+
+// preCommitHookConfig is the hook for repositories using the pre-commit
+// framework, which owns the git hook itself
+const preCommitHookConfig = `  - repo: local
+    hooks:
+      - id: mcpr-verify
+        name: mcpr verify
+        entry: ` + hookCommand + `
+        language: system
+        pass_filenames: false
+`
+
+// installGitHook writes a pre-commit hook running hookCommand to path. A
+// hook mcpr didn't write is only replaced with force.
+func installGitHook(path string, force bool) error {
+	if existing, err := os.ReadFile(path); err == nil && !force && !bytes.Contains(existing, []byte(hookMarker)) {
+		return fmt.Errorf("a pre-commit hook already exists at %s; add '%s' to it, or replace it with --force", path, hookCommand)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+	script := "#!/bin/sh\n" + hookMarker + "\nexec " + hookCommand + "\n"
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		return fmt.Errorf("failed to write hook: %w", err)
+	}
+	// WriteFile keeps the mode of an existing file
+	return os.Chmod(path, 0o755)
+}
+
+// installHuskyHook appends hookCommand to the Husky hook at path unless it
+// already runs it, and reports whether it was added
+func installHuskyHook(path string) (bool, error) {
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	for _, line := range strings.Split(string(existing), "\n") {
+		if strings.TrimSpace(line) == hookCommand {
+			return false, nil
+		}
+	}
+
+	content := string(existing)
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	content += hookCommand + "\n"
+	if err := os.WriteFile(path, []byte(content), 0o755); err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return true, os.Chmod(path, 0o755)
+}
+
+// gitOutput runs git with args and returns its trimmed output
+func gitOutput(args ...string) (string, error) {
+	out, err := exec.Command("git", args...).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("%s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jrandolf/mcpr/clients"
+	"github.com/jrandolf/mcpr/config"
+
+	"github.com/spf13/cobra"
+)
+
+var importForce bool
+
+var importCmd = &cobra.Command{
+	Use:   "import [manager] [config-file]",
+	Short: "Import servers installed by another MCP manager",
+	Long: `Import the servers another MCP manager installed into the mcpr config.
+
+Supported managers: ` + strings.Join(clients.ListImporterNames(), ", ") + `
+
+Smithery's CLI and mcp-get don't keep a registry of their own; they write
+servers straight into a client config, Claude Desktop's by default. That
+config is read unless another one is given, e.g. when servers were installed
+with --client cursor. From Smithery, only servers run through its CLI or
+hosted by it are imported; mcp-get writes plain entries, so every server in
+the config is.
+
+Servers already configured are skipped unless --force is given.
+
+Examples:
+  mcpr import smithery
+  mcpr import mcp-get
+  mcpr import smithery ~/.cursor/mcp.json`,
+	Args: cobra.RangeArgs(1, 2),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return clients.ListImporterNames(), cobra.ShellCompDirectiveNoFileComp
+		}
+		return nil, cobra.ShellCompDirectiveDefault
+	},
+	RunE: runImport,
+}
+
+func init() {
+	importCmd.Flags().BoolVarP(&addLocal, "local", "l", false, "Save to local mcpr.json instead of global config")
+	importCmd.Flags().BoolVarP(&importForce, "force", "f", false, "Replace servers that are already configured")
+	importCmd.Flags().BoolVar(&unlockConfig, "unlock", false, unlockUsage)
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	importer, err := clients.GetImporter(args[0])
+	if err != nil {
+		return err
+	}
+	file := ""
+	if len(args) > 1 {
+		file = args[1]
+	}
+	path, servers, err := importer.Import(file)
+	if err != nil {
+		return err
+	}
+	if len(servers) == 0 {
+		fmt.Printf("No %s servers found in %s\n", importer.DisplayName, path)
+		return nil
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Importing %s servers from %s\n", importer.DisplayName, path)
+	provenance := config.Provenance{Source: config.SourceImport, Manager: importer.Name, From: path}
+	if importServers(os.Stdout, cfg, servers, provenance, importForce) == 0 {
+		return nil
+	}
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	resyncAll(cmd.Context(), cfg)
+	return nil
+}
+
+// importServers adds servers to cfg, writing a line per server to w, and
+// returns how many were added. Servers without a provenance get provenance.
+// Invalid servers are skipped, as are servers already configured, by name
+// or under another name, unless force is set. A server configured exactly
+// as imported is skipped even then.
+func importServers(w io.Writer, cfg *config.Config, servers []config.MCPServer, provenance config.Provenance, force bool) int {
+	// Configured servers by their definition, to find those imported under
+	// another name
+	byDefinition := make(map[string]string)
+	for _, server := range cfg.ListServers() {
+		byDefinition[definitionHash(server)] = server.Name
+	}
+
+	imported := 0
+	for _, server := range servers {
+		// Servers that know where they came from keep it
+		if server.Provenance == nil {
+			p := provenance
+			p.Added = time.Now().UTC().Truncate(time.Second)
+			server.Provenance = &p
+		}
+		if err := config.ValidateServer(server); err != nil {
+			fmt.Fprintf(w, "%s %v\n", failMark(), err)
+			continue
+		}
+		if current, err := cfg.GetServer(server.Name); err == nil {
+			if current.Equal(server) {
+				fmt.Fprintf(w, "- %s: already configured\n", server.Name)
+				continue
+			}
+			if !force {
+				fmt.Fprintf(w, "- %s: already configured; use --force to replace it\n", server.Name)
+				continue
+			}
+			// Servers of lower layers are shadowed rather than removed
+			if err := cfg.RemoveServer(server.Name); errors.Is(err, config.ErrLocked) {
+				fmt.Fprintf(w, "%s %v\n", failMark(), err)
+				continue
+			}
+		} else if name, ok := byDefinition[definitionHash(server)]; ok && !force {
+			fmt.Fprintf(w, "- %s: already configured as %q\n", server.Name, name)
+			continue
+		}
+		if err := cfg.AddServer(server); err != nil {
+			fmt.Fprintf(w, "%s %v\n", failMark(), err)
+			continue
+		}
+		fmt.Fprintf(w, "%s %s (%s)\n", okMark(), server.Name, server.Type)
+		byDefinition[definitionHash(server)] = server.Name
+		imported++
+	}
+	fmt.Fprintf(w, "\nImported %d/%d server(s) into %s\n", imported, len(servers), cfg.Path())
+	return imported
+}
+
+// definitionHash returns the hash of what server is, whatever its name
+func definitionHash(server config.MCPServer) string {
+	server.Name = ""
+	return server.Hash()
+}
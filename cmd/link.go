@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jrandolf/mcpr/clients"
+	"github.com/jrandolf/mcpr/config"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	deeplinkClient         string
+	deeplinkIncludeSecrets bool
+)
+
+var deeplinkCmd = &cobra.Command{
+	Use:   "link [server]",
+	Short: "Print a one-click install link of a server for a client",
+	Long: `Print a link that installs a configured server in a client with one click,
+for sharing with people who don't use mcpr.
+
+For Cursor this is a cursor:// deeplink. Claude Code has no install links, so
+for it the 'claude mcp add-json' command adding the server is printed instead.
+
+Secrets in env vars and headers are replaced by ${VAR} references to
+environment variables that must be set where the server is installed, unless
+--include-secrets is given. Use 'mcpr share' to share a server with mcpr
+users, who are asked for the secrets instead.
+
+Examples:
+  mcpr link github --client cursor
+  mcpr link github --client claude`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeServerName,
+	RunE:              runDeeplink,
+}
+
+func init() {
+	deeplinkCmd.Flags().StringVarP(&deeplinkClient, "client", "c", "", "Client to link to: cursor or claude")
+	deeplinkCmd.Flags().BoolVar(&deeplinkIncludeSecrets, "include-secrets", false, "Put secrets in the link instead of referencing variables")
+	_ = deeplinkCmd.MarkFlagRequired("client")
+	_ = deeplinkCmd.RegisterFlagCompletionFunc("client", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"cursor", "claude"}, cobra.ShellCompDirectiveNoFileComp
+	})
+}
+
+func runDeeplink(cmd *cobra.Command, args []string) error {
+	name := deeplinkClient
+	// Claude Desktop has no install links either, so claude means Claude Code
+	if name == "claude" {
+		name = "claude-code"
+	}
+	client, err := clients.GetClient(name)
+	if err != nil {
+		return err
+	}
+	server, err := loadServer(args[0])
+	if err != nil {
+		return err
+	}
+
+	server, refs := deeplinkServer(server, deeplinkIncludeSecrets)
+	link, err := client.InstallLink(server)
+	if err != nil {
+		return err
+	}
+	fmt.Println(link)
+	for _, ref := range refs {
+		fmt.Fprintf(os.Stderr, "A secret is replaced by ${%s}; set it where the server is installed\n", ref)
+	}
+	return nil
+}
+
+// deeplinkServer returns server as put in an install link, with literal
+// secrets replaced by ${VAR} references unless includeSecrets is set, and
+// the references it made. A header's variable is named after the header.
+func deeplinkServer(server config.MCPServer, includeSecrets bool) (config.MCPServer, []string) {
+	shared := shareServer(server, includeSecrets)
+	server = shared.Server
+
+	var refs []string
+	for _, prompt := range shared.Prompts {
+		kind, name, _ := strings.Cut(prompt, ":")
+		if kind == "env" {
+			server.Env[name] = "${" + name + "}"
+			refs = append(refs, name)
+			continue
+		}
+		variable := strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+		server.Headers[name] = "${" + variable + "}"
+		refs = append(refs, variable)
+	}
+	return server, refs
+}
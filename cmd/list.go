@@ -2,7 +2,9 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 	"strings"
+	"text/template"
 
 	"github.com/jrandolf/mcpr/clients"
 	"github.com/jrandolf/mcpr/config"
@@ -10,7 +12,11 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var listClients bool
+var (
+	listClients  bool
+	listExplain  bool
+	listTemplate string
+)
 
 var listCmd = &cobra.Command{
 	Use:   "list",
@@ -22,27 +28,90 @@ Examples:
   mcpr list
 
   # List supported clients
-  mcpr list --clients`,
+  mcpr list --clients
+
+  # Show how client config paths are resolved on this machine
+  mcpr list --clients --explain
+
+  # Shape the output for scripts
+  mcpr list --template '{{.Name}} {{.Type}}'
+  mcpr list --template '{{.Name}}{{if .Disabled}} (off){{end}}: {{join .Args " "}}'
+  mcpr list --clients --template '{{.Name}}={{.Config}}'
+
+--template is a Go template executed for each server or client. Servers
+have the fields of their definition (.Name, .Type, .Command, .Args, .Env,
+.URL, .Headers, .Description, .Provenance, ...) plus .Layer and
+.Quarantined; clients have .Name, .DisplayName, .Config and .LocalConfig.
+The functions join, lower, upper and json are available.`,
 	RunE: runList,
 }
 
 func init() {
 	listCmd.Flags().BoolVarP(&listClients, "clients", "c", false, "List supported clients instead of servers")
+	listCmd.Flags().BoolVar(&listExplain, "explain", false, "Explain how config paths were chosen")
+	listCmd.Flags().StringVar(&listTemplate, "template", "", "Print each server or client with a Go template, e.g. '{{.Name}} {{.Type}}'")
+}
+
+// serverListing is what list --template sees for each server
+type serverListing struct {
+	config.MCPServer
+	Layer       string // Config layer defining the server: "system", "user" or "project"
+	Quarantined bool   // Synced disabled after failing health checks
+}
+
+// clientListing is what list --clients --template sees for each client
+type clientListing struct {
+	Name        string
+	DisplayName string
+	Config      string // Global config path
+	LocalConfig string // Project config path, empty if the client has none
 }
 
 func runList(cmd *cobra.Command, args []string) error {
+	tmpl, err := parseOutputTemplate(listTemplate)
+	if err != nil {
+		return err
+	}
 	if listClients {
-		return listSupportedClients()
+		return listSupportedClients(tmpl)
 	}
-	return listServers()
+	return listServers(tmpl)
 }
 
-func listServers() error {
+func listServers(tmpl *template.Template) error {
 	cfg, err := config.Load()
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
+	if tmpl != nil {
+		servers, err := config.SortByDependencies(cfg.ListServers())
+		if err != nil {
+			return err
+		}
+		for _, server := range servers {
+			item := serverListing{MCPServer: server, Layer: cfg.ServerLayer(server.Name), Quarantined: cfg.IsQuarantined(server.Name)}
+			if err := writeTemplate(os.Stdout, tmpl, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if listExplain {
+		if path, reason, err := config.ExplainConfigPath(); err == nil {
+			fmt.Printf("Config: %s\n  - %s\n", path, reason)
+			for _, layer := range cfg.Layers() {
+				state := "not found"
+				if layer.Exists {
+					state = "found"
+				}
+				fmt.Printf("  - %s layer %s: %s\n", layer.Name, layer.Path, state)
+			}
+			fmt.Println()
+		}
+	}
+
 	servers := cfg.ListServers()
 	if len(servers) == 0 {
 		fmt.Println("No servers configured.")
@@ -50,10 +119,29 @@ func listServers() error {
 		return nil
 	}
 
+	servers, err = config.SortByDependencies(servers)
+	if err != nil {
+		return err
+	}
+	missing := config.MissingDependencies(servers)
+
 	fmt.Printf("Configured servers (from %s):\n\n", cfg.Path())
 	for _, server := range servers {
-		fmt.Printf("  %s\n", server.Name)
-		fmt.Printf("    Command: %s\n", server.Command)
+		if server.Disabled {
+			fmt.Printf("  %s (disabled)\n", server.Name)
+		} else if cfg.IsQuarantined(server.Name) {
+			fmt.Printf("  %s (quarantined)\n", server.Name)
+		} else {
+			fmt.Printf("  %s\n", server.Name)
+		}
+		if server.Description != "" {
+			fmt.Printf("    %s\n", server.Description)
+		}
+		if server.Type == "socket" {
+			fmt.Printf("    Socket:  %s\n", server.Path)
+		} else {
+			fmt.Printf("    Command: %s\n", server.Command)
+		}
 		if len(server.Args) > 0 {
 			fmt.Printf("    Args:    %s\n", strings.Join(server.Args, " "))
 		}
@@ -64,20 +152,81 @@ func listServers() error {
 			}
 			fmt.Printf("    Env:     %s\n", strings.Join(envPairs, ", "))
 		}
+		if server.Sandbox != nil {
+			fmt.Printf("    Sandbox: %s\n", sandboxSummary(server.Sandbox))
+		}
+		if server.DocsURL != "" {
+			fmt.Printf("    Docs:    %s\n", server.DocsURL)
+		}
+		if server.Provenance != nil {
+			fmt.Printf("    Source:  %s\n", server.Provenance)
+		}
+		if layer := cfg.ServerLayer(server.Name); layer == config.LayerSystem {
+			fmt.Printf("    Layer:   %s\n", layer)
+		}
+		if len(server.DependsOn) > 0 {
+			fmt.Printf("    Depends: %s\n", strings.Join(server.DependsOn, ", "))
+		}
+		if deps, ok := missing[server.Name]; ok {
+			fmt.Printf("    Warning: missing dependencies: %s\n", strings.Join(deps, ", "))
+		}
 		fmt.Println()
 	}
 
 	return nil
 }
 
-func listSupportedClients() error {
+func listSupportedClients(tmpl *template.Template) error {
+	if tmpl != nil {
+		for _, name := range clients.ListClientNames() {
+			client, _ := clients.GetClient(name)
+			item := clientListing{Name: name, DisplayName: client.DisplayName}
+			item.Config, _ = client.Path(false)
+			if client.SupportsLocal {
+				item.LocalConfig, _ = client.Path(true)
+			}
+			if err := writeTemplate(os.Stdout, tmpl, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
 	fmt.Println("Supported MCP clients:")
 	fmt.Println()
-	for name, client := range clients.GetClients() {
+	for _, name := range clients.ListClientNames() {
+		client, _ := clients.GetClient(name)
+		if listExplain {
+			explainClientPath(os.Stdout, client, false)
+			if client.SupportsLocal {
+				explainClientPath(os.Stdout, client, true)
+			}
+			continue
+		}
 		path, _ := client.ConfigPath()
 		fmt.Printf("  %s (%s)\n", name, client.DisplayName)
-		fmt.Printf("    Config: %s\n", path)
+		fmt.Printf("    Config:   %s\n", path)
+		overrides := "$" + client.EnvOverride(false)
+		if client.SupportsLocal {
+			overrides += ", $" + client.EnvOverride(true)
+		}
+		fmt.Printf("    Override: %s\n", overrides)
 		fmt.Println()
 	}
 	return nil
 }
+
+// sandboxSummary describes a sandbox profile in one line
+func sandboxSummary(sandbox *config.Sandbox) string {
+	parts := []string{"no network"}
+	if sandbox.Network {
+		parts[0] = "network"
+	}
+	if len(sandbox.ReadOnly) > 0 {
+		parts = append(parts, "read "+strings.Join(sandbox.ReadOnly, ", "))
+	}
+	if len(sandbox.ReadWrite) > 0 {
+		parts = append(parts, "write "+strings.Join(sandbox.ReadWrite, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,17 @@
+package cmd
+
+import "github.com/jrandolf/mcpr/config"
+
+// unlockConfig lets commands change locked configs and servers
+var unlockConfig bool
+
+// unlockUsage describes the --unlock flag of commands changing the config
+const unlockUsage = "Change the config or servers even if they are locked"
+
+// applyUnlock lets cfg be changed even if locked, when --unlock is given
+func applyUnlock(cfg *config.Config) *config.Config {
+	if unlockConfig {
+		cfg.Unlock()
+	}
+	return cfg
+}
@@ -0,0 +1,296 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jrandolf/mcpr/clients"
+	"github.com/jrandolf/mcpr/config"
+	"github.com/jrandolf/mcpr/mcp"
+
+	"github.com/spf13/cobra"
+)
+
+var mcpServeCmd = &cobra.Command{
+	Use:   "mcp-serve",
+	Short: "Run an MCP server that manages mcpr itself",
+	Long: `Run a Model Context Protocol server over stdio exposing mcpr's own
+operations as tools, so AI assistants can manage your MCP configuration.
+
+Tools:
+  list_servers  - List configured MCP servers
+  list_clients  - List supported clients and their config paths
+  add_server    - Add a stdio or http server and resync clients
+  remove_server - Remove a server and resync clients
+  sync_client   - Sync servers to a client
+  check_server  - Health check a server with the MCP handshake
+
+Register it like any other stdio server:
+  mcpr add stdio --name mcpr mcpr mcp-serve`,
+	Args: cobra.NoArgs,
+	RunE: runMCPServe,
+}
+
+func runMCPServe(cmd *cobra.Command, args []string) error {
+	return newMCPServer().Serve(os.Stdin, os.Stdout)
+}
+
+// newMCPServer builds the MCP server with mcpr's management tools
+func newMCPServer() *mcp.Server {
+	s := mcp.NewServer("mcpr", version)
+
+	s.AddTool(mcp.Tool{
+		Name:        "list_servers",
+		Description: "List all MCP servers configured in mcpr",
+	}, mcpListServers)
+
+	s.AddTool(mcp.Tool{
+		Name:        "list_clients",
+		Description: "List the MCP clients mcpr can sync to, with their config paths",
+	}, mcpListClients)
+
+	s.AddTool(mcp.Tool{
+		Name:        "add_server",
+		Description: "Add an MCP server to the mcpr config and resync all synced clients",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"name":        map[string]any{"type": "string", "description": "Server name"},
+				"type":        map[string]any{"type": "string", "enum": []string{"stdio", "http", "socket"}},
+				"command":     map[string]any{"type": "string", "description": "Command to run (stdio)"},
+				"args":        map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+				"env":         map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string"}},
+				"envFile":     map[string]any{"type": "string", "description": ".env file with environment variables (stdio)"},
+				"url":         map[string]any{"type": "string", "description": "Server URL (http)"},
+				"headers":     map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string"}},
+				"path":        map[string]any{"type": "string", "description": "Unix socket path (socket)"},
+				"dependsOn":   map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+				"description": map[string]any{"type": "string", "description": "What the server is for"},
+				"docsUrl":     map[string]any{"type": "string", "description": "Documentation URL"},
+				"timeout":     map[string]any{"type": "integer", "description": "Request timeout in seconds"},
+				"alwaysAllow": map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "description": "Tools to run without confirmation"},
+				"disabled":    map[string]any{"type": "boolean", "description": "Keep the server configured but turned off"},
+				"trust":       map[string]any{"type": "boolean", "description": "Skip tool call confirmations"},
+				"noShim":      map[string]any{"type": "boolean", "description": "Don't wrap npx in \"cmd /c\" on Windows (stdio)"},
+			},
+			"required": []string{"name", "type"},
+		},
+	}, mcpAddServer)
+
+	s.AddTool(mcp.Tool{
+		Name:        "remove_server",
+		Description: "Remove an MCP server from the mcpr config and resync all synced clients",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"name": map[string]any{"type": "string", "description": "Server name"},
+			},
+			"required": []string{"name"},
+		},
+	}, mcpRemoveServer)
+
+	s.AddTool(mcp.Tool{
+		Name:        "sync_client",
+		Description: "Sync MCP servers to a client application",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"client":  map[string]any{"type": "string", "enum": clients.ListClientNames()},
+				"local":   map[string]any{"type": "boolean", "description": "Sync to project-local config"},
+				"servers": map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "description": "Only sync these servers"},
+				"exclude": map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "description": "Never sync these servers; omitted keeps the ones excluded before, and an empty list clears them"},
+			},
+			"required": []string{"client"},
+		},
+	}, mcpSyncClient)
+
+	s.AddTool(mcp.Tool{
+		Name:        "check_server",
+		Description: "Health check a configured MCP server by connecting to it and performing the MCP handshake",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"name":    map[string]any{"type": "string", "description": "Server name"},
+				"timeout": map[string]any{"type": "integer", "description": "Seconds the server gets to answer (default 10)"},
+			},
+			"required": []string{"name"},
+		},
+	}, mcpCheckServer)
+
+	return s
+}
+
+func mcpListServers(args json.RawMessage) (string, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return "", fmt.Errorf("failed to load config: %w", err)
+	}
+
+	data, err := json.MarshalIndent(cfg.ListServers(), "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func mcpListClients(args json.RawMessage) (string, error) {
+	names := clients.ListClientNames()
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		client, _ := clients.GetClient(name)
+		path, _ := client.ConfigPath()
+		fmt.Fprintf(&b, "%s (%s): %s\n", name, client.DisplayName, path)
+	}
+	return b.String(), nil
+}
+
+func mcpAddServer(args json.RawMessage) (string, error) {
+	var server config.MCPServer
+	if err := json.Unmarshal(args, &server); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	switch server.Type {
+	case "stdio":
+		if server.Command == "" {
+			return "", fmt.Errorf("command is required for stdio servers")
+		}
+	case "http":
+		if server.URL == "" {
+			return "", fmt.Errorf("url is required for http servers")
+		}
+	case "socket":
+		if server.Path == "" {
+			return "", fmt.Errorf("path is required for socket servers")
+		}
+	default:
+		return "", fmt.Errorf("type must be \"stdio\", \"http\" or \"socket\"")
+	}
+	if server.Name == "" {
+		return "", fmt.Errorf("name is required")
+	}
+
+	server.Provenance = &config.Provenance{Source: config.SourceMCPServe, Added: time.Now().UTC().Truncate(time.Second)}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return "", fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := cfg.AddServer(server); err != nil {
+		return "", err
+	}
+	return mcpSaveAndResync(cfg, fmt.Sprintf("Added %s server %q to %s", server.Type, server.Name, cfg.Path()))
+}
+
+func mcpRemoveServer(args json.RawMessage) (string, error) {
+	var in struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(args, &in); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return "", fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := cfg.RemoveServer(in.Name); err != nil {
+		return "", err
+	}
+	return mcpSaveAndResync(cfg, fmt.Sprintf("Removed server %q from %s", in.Name, cfg.Path()))
+}
+
+// mcpSaveAndResync is saveAndResync for tools, failing with what it
+// printed
+func mcpSaveAndResync(cfg *config.Config, change string) (string, error) {
+	var out bytes.Buffer
+	if err := saveAndResync(context.Background(), &out, cfg, change); err != nil {
+		return "", fmt.Errorf("%w\n%s", err, out.String())
+	}
+	return out.String(), nil
+}
+
+func mcpSyncClient(args json.RawMessage) (string, error) {
+	var in struct {
+		Client  string   `json:"client"`
+		Local   bool     `json:"local"`
+		Servers []string `json:"servers"`
+		Exclude []string `json:"exclude"`
+	}
+	if err := json.Unmarshal(args, &in); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return "", fmt.Errorf("failed to load config: %w", err)
+	}
+
+	result, err := syncClient(context.Background(), cfg, in.Client, in.Local, in.Servers, in.Exclude, takeoverNone, nil)
+	if err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "Synced %d server(s) to %s (%s)\n", len(result.Servers), result.Client.DisplayName, result.Path)
+	for _, server := range result.Servers {
+		fmt.Fprintf(&out, "  - %s\n", server.Name)
+	}
+	return out.String(), nil
+}
+
+// mcpCheckTimeout is how long check_server waits for a server by default
+const mcpCheckTimeout = 10 * time.Second
+
+func mcpCheckServer(args json.RawMessage) (string, error) {
+	var in struct {
+		Name    string `json:"name"`
+		Timeout int    `json:"timeout"`
+	}
+	if err := json.Unmarshal(args, &in); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return "", fmt.Errorf("failed to load config: %w", err)
+	}
+	timeout := mcpCheckTimeout
+	if in.Timeout > 0 {
+		timeout = time.Duration(in.Timeout) * time.Second
+	}
+	return checkServerReport(context.Background(), cfg, in.Name, timeout)
+}
+
+// checkServerReport health checks the server called name and returns the
+// result as JSON, as 'mcpr check --template' sees it. The check is recorded
+// in the health history, but doesn't quarantine the server.
+func checkServerReport(ctx context.Context, cfg *config.Config, name string, timeout time.Duration) (string, error) {
+	server, err := cfg.GetServer(name)
+	if err != nil {
+		return "", err
+	}
+	result, checkErr := checkServer(ctx, *server, netOptions(cfg), timeout)
+	cfg.RecordHealthCheck(server.Name, checkErr, false)
+	if err := cfg.SaveState(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to save health history: %v\n", err)
+	}
+
+	result.Name, result.OK, result.Quarantined = server.Name, checkErr == nil, cfg.IsQuarantined(server.Name)
+	if checkErr != nil {
+		result.Error = checkErr.Error()
+	}
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jrandolf/mcpr/config"
+)
+
+// packageRunners are launchers that run a package named by their first
+// argument, with the subcommand to skip before it, if any
+var packageRunners = map[string]string{
+	"npx":  "",
+	"bunx": "",
+	"pnpx": "",
+	"uvx":  "",
+	"pipx": "run",
+	"pnpm": "dlx",
+	"yarn": "dlx",
+	"npm":  "exec",
+}
+
+// scriptRunners are interpreters that run a script named by their first
+// argument
+var scriptRunners = []string{"node", "bun", "deno", "python", "python3", "uv", "tsx", "ts-node"}
+
+// genericNames are file and path names that say nothing about a server
+var genericNames = []string{"index", "main", "server", "mcp", "sse", "api", "dist", "build", "src", "run", "start"}
+
+// stdioServerName derives a server name from its command line: from the
+// package a launcher such as npx or uvx runs ("npx -y
+// @modelcontextprotocol/server-filesystem" -> "filesystem"), from the
+// script an interpreter runs, or else from the command
+func stdioServerName(command string, args []string) string {
+	base := strings.TrimSuffix(strings.ToLower(filepath.Base(command)), filepath.Ext(command))
+	if sub, ok := packageRunners[base]; ok {
+		if pkg := firstOperand(args, sub); pkg != "" {
+			if base == "uvx" || base == "pipx" {
+				pkg, _ = splitPythonPackage(pkg)
+			} else {
+				pkg, _ = splitNodePackage(pkg)
+			}
+			return packageServerName(pkg)
+		}
+	}
+	if slices.Contains(scriptRunners, base) {
+		sub := ""
+		if base == "uv" {
+			sub = "run"
+		}
+		// "server/build/index.js" says the most in "server"
+		parts := strings.Split(filepath.ToSlash(firstOperand(args, sub)), "/")
+		for _, part := range slices.Backward(parts) {
+			name := strings.TrimSuffix(part, filepath.Ext(part))
+			if name != "" && name != "." && name != ".." && !strings.HasSuffix(name, ":") && !isGenericName(name) {
+				return packageServerName(name)
+			}
+		}
+	}
+	return base
+}
+
+// firstOperand returns the first argument that isn't a flag, after sub if
+// it is given and comes first
+func firstOperand(args []string, sub string) string {
+	if sub != "" {
+		if len(args) == 0 || args[0] != sub {
+			return ""
+		}
+		args = args[1:]
+	}
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			return arg
+		}
+	}
+	return ""
+}
+
+// urlServerName derives a server name from the URL of an http server: its
+// last path segment that says something ("https://example.com/github/mcp"
+// -> "github"), or else its host. Placeholders never make up the name.
+func urlServerName(rawURL string) string {
+	const placeholder = "mcpr-placeholder"
+	u, err := url.Parse(templatePattern.ReplaceAllString(rawURL, placeholder))
+	if err != nil {
+		return "server"
+	}
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		name := strings.ToLower(segments[i])
+		if name != "" && !strings.Contains(name, placeholder) && !isGenericName(name) && !isVersionSegment(name) {
+			return packageServerName(name)
+		}
+	}
+	if host := u.Hostname(); host != "" && !strings.Contains(host, placeholder) {
+		return host
+	}
+	return "server"
+}
+
+// isGenericName reports whether name says nothing about a server
+func isGenericName(name string) bool {
+	return slices.Contains(genericNames, strings.ToLower(name))
+}
+
+// isVersionSegment reports whether a URL path segment is an API version
+// such as "v1"
+func isVersionSegment(segment string) bool {
+	_, err := strconv.Atoi(strings.TrimPrefix(segment, "v"))
+	return err == nil
+}
+
+// freeServerName returns name if the config doesn't define a server by it
+// yet, or else name with the lowest free suffix, such as "fetch-2"
+func freeServerName(cfg *config.Config, name string) string {
+	if !cfg.HasServer(name) {
+		return name
+	}
+	for n := 2; ; n++ {
+		if candidate := fmt.Sprintf("%s-%d", name, n); !cfg.HasServer(candidate) {
+			return candidate
+		}
+	}
+}
+
+// resolveServerName returns the name to add a server under when the config
+// already defines one by name. A name mcpr inferred gets a suffix. A name
+// given with --name only does if the user agrees when asked on in, if it's
+// interactive; otherwise adding fails as it did before.
+func resolveServerName(w io.Writer, in io.Reader, interactive bool, cfg *config.Config, name string, explicit bool) (string, error) {
+	free := freeServerName(cfg, name)
+	if free == name {
+		return name, nil
+	}
+	if !explicit {
+		fmt.Fprintf(w, "Server %q already exists; adding this one as %q\n", name, free)
+		return free, nil
+	}
+	if !interactive {
+		return "", fmt.Errorf("server %q already exists; choose another --name", name)
+	}
+	fmt.Fprintf(w, "Server %q already exists. Add this one as %q? [y/N] ", name, free)
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+		return "", fmt.Errorf("server %q already exists", name)
+	}
+	return free, nil
+}
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jrandolf/mcpr/config"
+	"github.com/jrandolf/mcpr/netutil"
+)
+
+// netOptions returns the proxy, CA and timeout settings of cfg for the
+// requests mcpr makes
+func netOptions(cfg *config.Config) netutil.Options {
+	n := cfg.NetworkSettings()
+	return netutil.Options{
+		Proxy:    n.Proxy,
+		NoProxy:  n.NoProxy,
+		CABundle: n.CABundle,
+		Timeout:  time.Duration(n.Timeout) * time.Second,
+	}
+}
+
+// loadHTTPClient returns an HTTP client with the network settings of the
+// active config
+func loadHTTPClient() (*http.Client, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	return netutil.NewClient(netOptions(cfg))
+}
@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/jrandolf/mcpr/config"
+
+	"github.com/spf13/cobra"
+)
+
+//go:embed all:templates
+var templatesFS embed.FS
+
+var newCmd = &cobra.Command{
+	Use:   "new",
+	Short: "Create new projects",
+}
+
+// new server subcommand
+var (
+	newServerLang       string
+	newServerDir        string
+	newServerNoRegister bool
+)
+
+var newServerCmd = &cobra.Command{
+	Use:   "server [name]",
+	Short: "Scaffold a starter MCP server project",
+	Long: `Scaffold a minimal MCP server project using the official SDK for the
+chosen language, with a sample tool and build scripts. The server is then
+registered as a stdio server in the project-local config (mcpr.json).
+
+Languages:
+  go       Go with github.com/modelcontextprotocol/go-sdk
+  ts       TypeScript with @modelcontextprotocol/sdk
+  python   Python with the mcp package, run with uv
+
+Examples:
+  mcpr new server weather
+  mcpr new server --lang ts weather
+  mcpr new server --lang python --dir servers/weather weather`,
+	Args: cobra.ExactArgs(1),
+	RunE: runNewServer,
+}
+
+func init() {
+	newServerCmd.Flags().StringVar(&newServerLang, "lang", "go", "Language: go, ts or python")
+	newServerCmd.Flags().StringVarP(&newServerDir, "dir", "d", "", "Project directory (defaults to ./<name>)")
+	newServerCmd.Flags().BoolVar(&newServerNoRegister, "no-register", false, "Don't register the server in the local config")
+	newServerCmd.Flags().BoolVar(&unlockConfig, "unlock", false, unlockUsage)
+
+	newCmd.AddCommand(newServerCmd)
+}
+
+// serverNamePattern matches names usable as a server, package and binary name
+var serverNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// scaffoldData is passed to the project templates
+type scaffoldData struct {
+	Name   string
+	Binary string
+}
+
+func runNewServer(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	if !serverNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid server name %q: use lowercase letters, digits, dashes and underscores", name)
+	}
+
+	dir := newServerDir
+	if dir == "" {
+		dir = name
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+
+	data := scaffoldData{Name: name, Binary: name}
+	if runtime.GOOS == "windows" {
+		data.Binary += ".exe"
+	}
+	if err := scaffoldServer(dir, newServerLang, data); err != nil {
+		return err
+	}
+	fmt.Printf("Created %s MCP server project in %s\n", newServerLang, dir)
+
+	if newServerNoRegister {
+		return nil
+	}
+
+	cfg, err := loadLocalConfig()
+	if err != nil {
+		return err
+	}
+	command, serverArgs := scaffoldCommand(newServerLang, dir, data)
+	server := config.MCPServer{
+		Name:        name,
+		Type:        "stdio",
+		Command:     command,
+		Args:        serverArgs,
+		Description: fmt.Sprintf("Starter %s MCP server in %s", newServerLang, dir),
+		Provenance:  &config.Provenance{Source: config.SourceScaffold, From: dir, Added: time.Now().UTC().Truncate(time.Second)},
+	}
+	if err := cfg.AddServer(server); err != nil {
+		return err
+	}
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	fmt.Printf("Added stdio server %q to %s\n", name, cfg.Path())
+	if newServerLang != "python" {
+		fmt.Printf("Build the project (see %s) before starting the server\n", filepath.Join(dir, "README.md"))
+	}
+	resyncAll(cmd.Context(), cfg)
+	return nil
+}
+
+// loadLocalConfig loads the project-local config, creating it in the current
+// directory if there is none
+func loadLocalConfig() (*config.Config, error) {
+	path, err := config.GetWriteConfigPath(true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config path: %w", err)
+	}
+	cfg, err := config.LoadFromPath(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	return applyUnlock(cfg), nil
+}
+
+// scaffoldServer renders the project templates for lang into dir, which must
+// not exist or be empty
+func scaffoldServer(dir, lang string, data scaffoldData) error {
+	root := path.Join("templates", "server", lang)
+	if _, err := fs.Stat(templatesFS, root); err != nil {
+		return fmt.Errorf("unknown language %q (expected \"go\", \"ts\" or \"python\")", lang)
+	}
+
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+		return fmt.Errorf("%s already exists and is not empty", dir)
+	}
+
+	return fs.WalkDir(templatesFS, root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		tmpl, err := template.ParseFS(templatesFS, name)
+		if err != nil {
+			return err
+		}
+
+		rel := strings.TrimSuffix(strings.TrimPrefix(name, root+"/"), ".tmpl")
+		target := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", filepath.Dir(target), err)
+		}
+
+		f, err := os.Create(target)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", target, err)
+		}
+		defer f.Close()
+		if err := tmpl.Execute(f, data); err != nil {
+			return fmt.Errorf("failed to write %s: %w", target, err)
+		}
+		return nil
+	})
+}
+
+// scaffoldCommand returns the command that starts a scaffolded server once
+// it is built
+func scaffoldCommand(lang, dir string, data scaffoldData) (string, []string) {
+	switch lang {
+	case "ts":
+		return "node", []string{filepath.Join(dir, "dist", "index.js")}
+	case "python":
+		return "uv", []string{"run", "--directory", dir, "server.py"}
+	default:
+		return filepath.Join(dir, data.Binary), nil
+	}
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/jrandolf/mcpr/clients"
+
+	"github.com/spf13/cobra"
+)
+
+var openLocal bool
+
+var openCmd = &cobra.Command{
+	Use:   "open <client-name>",
+	Short: "Open a client's config in your editor",
+	Long: `Open the config file of a client in $VISUAL or $EDITOR, or with the
+program your system opens the file with when neither is set. A missing
+config is created first, holding no servers, so the client accepts it.
+
+Examples:
+  mcpr open cursor
+  mcpr open claude-code --local`,
+	Args:              cobra.ExactArgs(1),
+	RunE:              runOpen,
+	ValidArgsFunction: completeClientName,
+}
+
+func init() {
+	openCmd.Flags().BoolVarP(&openLocal, "local", "l", false, "Open the project-local config instead of global")
+}
+
+func runOpen(cmd *cobra.Command, args []string) error {
+	return openClientConfig(os.Stdout, args[0], openLocal, openConfigFile)
+}
+
+// openClientConfig opens the global or local config of the named client
+// with open, creating it first if it's missing
+func openClientConfig(w io.Writer, name string, local bool, open func(path string) error) error {
+	client, err := clients.GetClient(name)
+	if err != nil {
+		return fmt.Errorf("%w\n\nSupported clients: %s", err, strings.Join(clients.ListClientNames(), ", "))
+	}
+	path, err := client.Path(local)
+	if err != nil {
+		return err
+	}
+	created, err := client.CreateConfig(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s config: %w", client.DisplayName, err)
+	}
+	if created {
+		fmt.Fprintf(w, "Created %s\n", path)
+	}
+	return open(path)
+}
+
+// openConfigFile opens path in $VISUAL or $EDITOR, or with the system's
+// default handler for it when neither is set. Without a default handler,
+// such as on a headless machine, the fallback editor is used.
+func openConfigFile(path string) error {
+	if os.Getenv("VISUAL") != "" || os.Getenv("EDITOR") != "" {
+		return openInEditor(path)
+	}
+	name, args := defaultOpener(runtime.GOOS, path)
+	if _, err := exec.LookPath(name); err != nil {
+		return openInEditor(path)
+	}
+	if err := exec.Command(name, args...).Run(); err != nil {
+		return fmt.Errorf("failed to open %s with %s: %w", path, name, err)
+	}
+	return nil
+}
+
+// defaultOpener returns the command opening path with the default handler
+// of the operating system goos
+func defaultOpener(goos, path string) (string, []string) {
+	switch goos {
+	case "darwin":
+		// -t opens the default text editor rather than whatever claims .json
+		return "open", []string{"-t", path}
+	case "windows":
+		return "rundll32", []string{"url.dll,FileProtocolHandler", path}
+	default:
+		return "xdg-open", []string{path}
+	}
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/jrandolf/mcpr/clients"
+	"github.com/jrandolf/mcpr/config"
+
+	"github.com/spf13/cobra"
+)
+
+var clientPauseCmd = &cobra.Command{
+	Use:   "pause [client-name|@group]...",
+	Short: "Leave a client out of resyncs for a while",
+	Long: `Pause synced clients, so resyncs after adding, removing or changing servers
+leave their configs alone, such as while testing a client config by hand.
+Paused clients stay in the sync list with their servers and exclusions, and
+'mcpr client resume' puts them back. Syncing a paused client by name still
+writes its config.
+
+Examples:
+  mcpr client pause cursor
+  mcpr client pause claude-code --local
+  mcpr client resume cursor`,
+	Args:              cobra.MinimumNArgs(1),
+	RunE:              runClientPause,
+	ValidArgsFunction: completeClientNames,
+}
+
+var clientResumeCmd = &cobra.Command{
+	Use:   "resume [client-name|@group]...",
+	Short: "Resync a paused client again",
+	Long: `Resume paused clients, so resyncs update their configs again. Resuming
+doesn't write the configs; run 'mcpr client sync' to catch them up.
+
+Examples:
+  mcpr client resume cursor
+  mcpr client resume @editors`,
+	Args:              cobra.MinimumNArgs(1),
+	RunE:              runClientResume,
+	ValidArgsFunction: completeClientNames,
+}
+
+func init() {
+	clientCmd.AddCommand(clientPauseCmd)
+	clientCmd.AddCommand(clientResumeCmd)
+	clientPauseCmd.Flags().BoolVarP(&clientSyncLocal, "local", "l", false, "Pause the project-local sync instead of global")
+	clientResumeCmd.Flags().BoolVarP(&clientSyncLocal, "local", "l", false, "Resume the project-local sync instead of global")
+}
+
+func runClientPause(cmd *cobra.Command, args []string) error {
+	return runSetPaused(args, true)
+}
+
+func runClientResume(cmd *cobra.Command, args []string) error {
+	return runSetPaused(args, false)
+}
+
+// runSetPaused pauses or resumes the clients named on the command line
+func runSetPaused(args []string, paused bool) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	names, err := cfg.ExpandClients(args)
+	if err != nil {
+		return err
+	}
+	return setPaused(os.Stdout, cfg, names, clientSyncLocal, paused)
+}
+
+// setPaused pauses or resumes synced clients and saves the sync records.
+// Every client must be in the sync list, or none is changed.
+func setPaused(w io.Writer, cfg *config.Config, names []string, local, paused bool) error {
+	localStr := ""
+	if local {
+		localStr = " (local)"
+	}
+	for _, name := range names {
+		if _, err := clients.GetClient(name); err != nil {
+			return fmt.Errorf("%w\n\nSupported clients: %s", err, strings.Join(clients.ListClientNames(), ", "))
+		}
+		if cfg.GetSyncedClient(name, local) == nil {
+			return fmt.Errorf("client %q%s is not in the sync list", name, localStr)
+		}
+	}
+
+	for _, name := range names {
+		cfg.SetSyncedClientPaused(name, local, paused)
+	}
+	if err := cfg.SaveState(); err != nil {
+		return fmt.Errorf("failed to save sync records: %w", err)
+	}
+
+	for _, name := range names {
+		if paused {
+			fmt.Fprintf(w, "Paused %s%s; resyncs leave its config alone until 'mcpr client resume %s'\n", name, localStr, name)
+		} else {
+			fmt.Fprintf(w, "Resumed %s%s\n", name, localStr)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import "os"
+
+// plainEnv turns on plain output like --plain does
+const plainEnv = "MCPR_PLAIN"
+
+// plainOutput makes output friendly to screen readers and basic terminals:
+// status symbols are spelled out and nothing is drawn
+var plainOutput = os.Getenv(plainEnv) != ""
+
+// okMark starts the line of something that succeeded
+func okMark() string {
+	if plainOutput {
+		return "OK"
+	}
+	return "✓"
+}
+
+// failMark starts the line of something that failed
+func failMark() string {
+	if plainOutput {
+		return "FAILED"
+	}
+	return "✗"
+}
+
+// arrow joins something and where it went
+func arrow() string {
+	if plainOutput {
+		return "to"
+	}
+	return "→"
+}
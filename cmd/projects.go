@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jrandolf/mcpr/config"
+
+	"github.com/spf13/cobra"
+)
+
+var projectsPrune bool
+
+var projectsCmd = &cobra.Command{
+	Use:   "projects",
+	Short: "List the configs mcpr has written or synced",
+	Long: `List every mcpr config recorded in mcpr's state file, with its server
+count, the clients synced from it and when it was last synced.
+
+Configs whose files no longer exist, such as those of deleted projects, are
+marked missing; --prune drops them from the state file.
+
+Examples:
+  mcpr projects
+  mcpr projects --prune`,
+	Args: cobra.NoArgs,
+	RunE: runProjects,
+}
+
+func init() {
+	projectsCmd.Flags().BoolVar(&projectsPrune, "prune", false, "Forget configs whose files no longer exist")
+}
+
+func runProjects(cmd *cobra.Command, args []string) error {
+	state, err := config.LoadState()
+	if err != nil {
+		return err
+	}
+
+	if projectsPrune {
+		pruned := state.Prune()
+		if len(pruned) == 0 {
+			fmt.Println("No missing configs to prune")
+			return nil
+		}
+		if err := state.Save(); err != nil {
+			return err
+		}
+		for _, path := range pruned {
+			fmt.Printf("Forgot %s\n", path)
+		}
+		return nil
+	}
+
+	if len(state.Configs) == 0 {
+		fmt.Println("No configs recorded yet. Configs are recorded when mcpr saves or syncs them.")
+		return nil
+	}
+	return printProjects(os.Stdout, state)
+}
+
+// printProjects lists the configs recorded in state, sorted by path
+func printProjects(w io.Writer, state *config.State) error {
+	paths := make([]string, 0, len(state.Configs))
+	for path := range state.Configs {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CONFIG\tSERVERS\tCLIENTS\tLAST SYNC")
+	for _, path := range paths {
+		entry := state.Configs[path]
+		servers := "missing"
+		if _, err := os.Stat(path); err == nil {
+			if cfg, err := config.LoadFromPath(path); err != nil {
+				servers = "invalid"
+			} else {
+				servers = fmt.Sprint(len(cfg.Servers))
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", path, servers, len(entry.SyncedClients), formatSyncTime(entry.LastSync()))
+	}
+	return tw.Flush()
+}
+
+// formatSyncTime formats when a sync happened for display
+func formatSyncTime(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/jrandolf/mcpr/config"
+	"github.com/jrandolf/mcpr/mcp"
+
+	"github.com/spf13/cobra"
+)
+
+var promptCmd = &cobra.Command{
+	Use:   "prompt",
+	Short: "List and render the prompts of a configured server",
+}
+
+var promptListCmd = &cobra.Command{
+	Use:               "list [server]",
+	Short:             "List the prompts a server exposes",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeServerName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		server, err := loadServer(args[0])
+		if err != nil {
+			return err
+		}
+		return listPrompts(cmd.Context(), os.Stdout, server)
+	},
+}
+
+var promptArgs []string
+
+var promptGetCmd = &cobra.Command{
+	Use:   "get [server] [name]",
+	Short: "Render a prompt from a server",
+	Long: `Render a prompt from a configured server with the given arguments and
+print its messages.
+
+Examples:
+  mcpr prompt get git commit-message
+  mcpr prompt get git review --arg branch=main --arg style=short`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeServerName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		server, err := loadServer(args[0])
+		if err != nil {
+			return err
+		}
+		return getPrompt(cmd.Context(), os.Stdout, server, args[1], parseKeyValues(promptArgs))
+	},
+}
+
+func init() {
+	promptGetCmd.Flags().StringArrayVarP(&promptArgs, "arg", "a", nil, "Prompt argument in KEY=VALUE format (repeatable)")
+
+	promptCmd.AddCommand(promptListCmd)
+	promptCmd.AddCommand(promptGetCmd)
+}
+
+// listPrompts prints the prompts server exposes and their arguments
+func listPrompts(ctx context.Context, w io.Writer, server config.MCPServer) error {
+	return withServer(ctx, server, func(ctx context.Context, client *mcp.Client) error {
+		prompts, err := client.ListPrompts(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list prompts: %w", err)
+		}
+		if len(prompts) == 0 {
+			fmt.Fprintf(w, "%s exposes no prompts.\n", server.Name)
+			return nil
+		}
+		for _, prompt := range prompts {
+			fmt.Fprintf(w, "  %s\n", prompt.Name)
+			if prompt.Description != "" {
+				fmt.Fprintf(w, "    %s\n", prompt.Description)
+			}
+			for _, arg := range prompt.Arguments {
+				required := ""
+				if arg.Required {
+					required = " (required)"
+				}
+				fmt.Fprintf(w, "    --arg %s%s", arg.Name, required)
+				if arg.Description != "" {
+					fmt.Fprintf(w, ": %s", arg.Description)
+				}
+				fmt.Fprintln(w)
+			}
+		}
+		return nil
+	})
+}
+
+// getPrompt renders the prompt called name and prints its messages
+func getPrompt(ctx context.Context, w io.Writer, server config.MCPServer, name string, args map[string]string) error {
+	return withServer(ctx, server, func(ctx context.Context, client *mcp.Client) error {
+		result, err := client.GetPrompt(ctx, name, args)
+		if err != nil {
+			return fmt.Errorf("failed to get prompt %s: %w", name, err)
+		}
+		for i, message := range result.Messages {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			fmt.Fprintf(w, "[%s]\n", message.Role)
+			if err := printContent(w, []mcp.Content{message.Content}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
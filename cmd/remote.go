@@ -0,0 +1,385 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jrandolf/mcpr/config"
+	"github.com/jrandolf/mcpr/netutil"
+	"github.com/jrandolf/mcpr/remote"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	remoteSettings remote.Settings
+	remoteForce    bool
+)
+
+var remoteCmd = &cobra.Command{
+	Use:   "remote",
+	Short: "Configure where mcpr push and pull keep the global config",
+	Long: `Configure a remote copy of the global config, so 'mcpr push' and
+'mcpr pull' keep several machines converged.
+
+Supported remotes:
+  s3://bucket/key     Amazon S3, or S3-compatible services with --endpoint
+  gs://bucket/key     Google Cloud Storage, with HMAC keys
+  https://host/path   WebDAV servers, such as Nextcloud
+
+A URL ending in "/" names a directory the config is stored in under its own
+file name. Settings are kept in remote.json next to the global config,
+readable only by you; credentials left out are read from AWS_ACCESS_KEY_ID,
+AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN and AWS_REGION for S3 and GCS, and
+from MCPR_REMOTE_PASSWORD for WebDAV.`,
+}
+
+var remoteSetCmd = &cobra.Command{
+	Use:   "set [url]",
+	Short: "Set the remote",
+	Long: `Set the remote the global config is pushed to and pulled from.
+
+Examples:
+  mcpr remote set s3://my-bucket/mcpr/
+  mcpr remote set gs://my-bucket/mcpr/ --access-key GOOG1E... --secret-key ...
+  mcpr remote set https://cloud.example.com/remote.php/dav/files/me/mcpr/ --username me`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRemoteSet,
+}
+
+var remoteShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the remote and when it was last synced",
+	Args:  cobra.NoArgs,
+	RunE:  runRemoteShow,
+}
+
+var remoteUnsetCmd = &cobra.Command{
+	Use:   "unset",
+	Short: "Forget the remote",
+	Args:  cobra.NoArgs,
+	RunE:  runRemoteUnset,
+}
+
+var pushCmd = &cobra.Command{
+	Use:   "push",
+	Short: "Upload the global config to the remote",
+	Long: `Upload the global config to the remote set with 'mcpr remote set'.
+
+If the remote copy changed since this machine last pushed or pulled, the
+push is refused so another machine's changes aren't lost, also when they
+land while pushing: pull them first, or overwrite them with --force.
+
+Examples:
+  mcpr push
+  mcpr push --force`,
+	Args: cobra.NoArgs,
+	RunE: runPush,
+}
+
+var pullCmd = &cobra.Command{
+	Use:   "pull",
+	Short: "Download the global config from the remote and resync clients",
+	Long: `Replace the global config with the remote copy and resync clients.
+
+If the global config changed since it was last pushed or pulled, the pull is
+refused so the changes aren't lost: push them first, or discard them with
+--force.
+
+Examples:
+  mcpr pull
+  mcpr pull --force`,
+	Args: cobra.NoArgs,
+	RunE: runPull,
+}
+
+func init() {
+	remoteSetCmd.Flags().StringVar(&remoteSettings.Username, "username", "", "WebDAV username")
+	remoteSetCmd.Flags().StringVar(&remoteSettings.Password, "password", "", "WebDAV password (prefer $MCPR_REMOTE_PASSWORD)")
+	remoteSetCmd.Flags().StringVar(&remoteSettings.AccessKey, "access-key", "", "S3 access key or GCS HMAC key")
+	remoteSetCmd.Flags().StringVar(&remoteSettings.SecretKey, "secret-key", "", "S3 secret key or GCS HMAC secret")
+	remoteSetCmd.Flags().StringVar(&remoteSettings.Region, "region", "", "S3 region")
+	remoteSetCmd.Flags().StringVar(&remoteSettings.Endpoint, "endpoint", "", "Endpoint of an S3-compatible service, such as MinIO or R2")
+	remoteCmd.AddCommand(remoteSetCmd)
+	remoteCmd.AddCommand(remoteShowCmd)
+	remoteCmd.AddCommand(remoteUnsetCmd)
+
+	pushCmd.Flags().BoolVarP(&remoteForce, "force", "f", false, "Overwrite changes made on the remote")
+	pullCmd.Flags().BoolVarP(&remoteForce, "force", "f", false, "Discard changes made to the global config")
+	pullCmd.Flags().BoolVar(&unlockConfig, "unlock", false, unlockUsage)
+}
+
+func runRemoteSet(cmd *cobra.Command, args []string) error {
+	settings := remoteSettings
+	settings.URL = args[0]
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+	if err := settings.Save(); err != nil {
+		return err
+	}
+	path, _ := remote.SettingsPath()
+	fmt.Printf("Remote set to %s in %s\n", settings.URL, path)
+	return nil
+}
+
+func runRemoteShow(cmd *cobra.Command, args []string) error {
+	settings, err := remote.LoadSettings()
+	if err != nil {
+		return err
+	}
+	if settings == nil {
+		fmt.Println("No remote set. Use 'mcpr remote set <url>' to set one.")
+		return nil
+	}
+	fmt.Printf("Remote: %s\n", settings.URL)
+	if settings.Endpoint != "" {
+		fmt.Printf("Endpoint: %s\n", settings.Endpoint)
+	}
+	state, err := config.LoadState()
+	if err != nil {
+		return err
+	}
+	if rs := state.Remote; rs != nil && rs.URL == settings.URL && !rs.Synced.IsZero() {
+		fmt.Printf("Last synced: %s\n", rs.Synced.Local().Format("2006-01-02 15:04"))
+	} else {
+		fmt.Println("Last synced: never")
+	}
+	return nil
+}
+
+func runRemoteUnset(cmd *cobra.Command, args []string) error {
+	if err := remote.RemoveSettings(); err != nil {
+		return err
+	}
+	state, err := config.LoadState()
+	if err != nil {
+		return err
+	}
+	if state.Remote != nil {
+		state.Remote = nil
+		if err := state.Save(); err != nil {
+			return err
+		}
+	}
+	fmt.Println("Remote unset")
+	return nil
+}
+
+// openRemote returns the configured backend, its URL and the global config
+// path it syncs
+func openRemote() (remote.Backend, string, string, error) {
+	settings, err := remote.LoadSettings()
+	if err != nil {
+		return nil, "", "", err
+	}
+	if settings == nil {
+		return nil, "", "", fmt.Errorf("no remote set; use 'mcpr remote set <url>' first")
+	}
+	path, err := config.GetWriteConfigPath(false)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to get config path: %w", err)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to load config: %w", err)
+	}
+	if settings.Client, err = netutil.NewClient(netOptions(cfg)); err != nil {
+		return nil, "", "", err
+	}
+	if settings.Retry, err = cfg.RetryPolicy(config.RetryRemoteOp); err != nil {
+		return nil, "", "", err
+	}
+	backend, err := remote.New(*settings, filepath.Base(path))
+	if err != nil {
+		return nil, "", "", err
+	}
+	return backend, settings.URL, path, nil
+}
+
+func runPush(cmd *cobra.Command, args []string) error {
+	backend, url, path, err := openRemote()
+	if err != nil {
+		return err
+	}
+	state, err := config.LoadState()
+	if err != nil {
+		return err
+	}
+	if err := pushConfig(cmd.Context(), os.Stdout, backend, url, path, state, remoteForce); err != nil {
+		return err
+	}
+	return state.Save()
+}
+
+func runPull(cmd *cobra.Command, args []string) error {
+	backend, url, path, err := openRemote()
+	if err != nil {
+		return err
+	}
+	state, err := config.LoadState()
+	if err != nil {
+		return err
+	}
+	changed, err := pullConfig(cmd.Context(), os.Stdout, backend, url, path, state, remoteForce)
+	if err != nil {
+		return err
+	}
+	if err := state.Save(); err != nil {
+		return err
+	}
+	if !changed {
+		return nil
+	}
+
+	cfg, err := loadGlobalConfig()
+	if err != nil {
+		return err
+	}
+	fmt.Println()
+	return resyncAll(cmd.Context(), cfg)
+}
+
+// pushConfig uploads the config at path to backend and records it in
+// state. A remote copy changed since the last push or pull is only
+// overwritten with force.
+func pushConfig(ctx context.Context, w io.Writer, backend remote.Backend, url, path string, state *config.State, force bool) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("no global config to push at %s", path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	last := lastRemoteSync(state, url)
+	changedErr := fmt.Errorf("%s changed since this machine last pushed or pulled; run 'mcpr pull' first, or 'mcpr push --force' to overwrite it", url)
+
+	// The put only goes through if the remote copy is still the one read
+	// here, so a push from another machine in between isn't overwritten
+	remoteChanged := false
+	ifMatch := remote.NoConfig
+	obj, err := backend.Get(ctx)
+	switch {
+	case errors.Is(err, remote.ErrNotFound):
+	case err != nil:
+		return err
+	case bytes.Equal(obj.Data, data):
+		recordRemoteSync(state, url, obj.ETag, data)
+		fmt.Fprintf(w, "%s is up to date\n", url)
+		return nil
+	default:
+		remoteChanged = last == nil || obj.ETag != last.ETag
+		ifMatch = obj.ETag
+	}
+	if remoteChanged {
+		if !force {
+			return changedErr
+		}
+		fmt.Fprintf(os.Stderr, "Warning: overwriting changes made to %s from another machine\n", url)
+	}
+	if force {
+		ifMatch = ""
+	}
+
+	etag, err := backend.Put(ctx, data, ifMatch)
+	if errors.Is(err, remote.ErrChanged) {
+		return changedErr
+	}
+	if err != nil {
+		return err
+	}
+	recordRemoteSync(state, url, etag, data)
+	fmt.Fprintf(w, "Pushed %s to %s\n", path, url)
+	return nil
+}
+
+// pullConfig replaces the config at path with the remote copy and records
+// it in state, reporting whether the config changed. Changes made to the
+// config since the last push or pull are only discarded with force.
+func pullConfig(ctx context.Context, w io.Writer, backend remote.Backend, url, path string, state *config.State, force bool) (bool, error) {
+	obj, err := backend.Get(ctx)
+	if errors.Is(err, remote.ErrNotFound) {
+		return false, fmt.Errorf("nothing to pull: %s holds no config; run 'mcpr push' first", url)
+	}
+	if err != nil {
+		return false, err
+	}
+	local, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, fmt.Errorf("failed to read config: %w", err)
+	}
+	if local != nil && bytes.Equal(local, obj.Data) {
+		recordRemoteSync(state, url, obj.ETag, obj.Data)
+		fmt.Fprintf(w, "%s is up to date\n", path)
+		return false, nil
+	}
+
+	last := lastRemoteSync(state, url)
+	if local != nil && (last == nil || contentHash(local) != last.Hash) {
+		if !force {
+			return false, fmt.Errorf("%s changed since it was last pushed or pulled; run 'mcpr push' to keep the changes, or 'mcpr pull --force' to discard them", path)
+		}
+		fmt.Fprintf(os.Stderr, "Warning: discarding changes made to %s\n", path)
+	}
+	if local != nil {
+		if err := checkPullLocked(path); err != nil {
+			return false, err
+		}
+	}
+	if err := config.ValidateFormat(obj.Data, config.FormatForPath(path)); err != nil {
+		return false, fmt.Errorf("the config on %s is invalid: %w", url, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return false, fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := config.WriteConfigFile(path, obj.Data); err != nil {
+		return false, fmt.Errorf("failed to write config: %w", err)
+	}
+	recordRemoteSync(state, url, obj.ETag, obj.Data)
+	fmt.Fprintf(w, "Pulled %s into %s\n", url, path)
+	return true, nil
+}
+
+// checkPullLocked fails if the config at path is locked, or defines a
+// locked server, unless --unlock is given, since a pull replaces all of it
+func checkPullLocked(path string) error {
+	cfg, err := config.LoadFromPath(path)
+	if err != nil {
+		// A config that doesn't load can't be locked, and pulling fixes it
+		return nil
+	}
+	names := make([]string, len(cfg.Servers))
+	for i, server := range cfg.Servers {
+		names[i] = server.Name
+	}
+	return applyUnlock(cfg).CheckLocked(names...)
+}
+
+// lastRemoteSync returns the record of the last push or pull to url
+func lastRemoteSync(state *config.State, url string) *config.RemoteState {
+	if state.Remote == nil || state.Remote.URL != url {
+		return nil
+	}
+	return state.Remote
+}
+
+// recordRemoteSync records that the config holding data was pushed to or
+// pulled from url
+func recordRemoteSync(state *config.State, url, etag string, data []byte) {
+	state.Remote = &config.RemoteState{URL: url, ETag: etag, Hash: contentHash(data), Synced: time.Now().UTC()}
+}
+
+// contentHash returns the hex SHA-256 of data
+func contentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/jrandolf/mcpr/config"
 
@@ -23,23 +25,13 @@ Examples:
 
   # Using the alias
   mcpr rm my-server`,
-	Args: cobra.ExactArgs(1),
-	RunE: runRemove,
-	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		if len(args) != 0 {
-			return nil, cobra.ShellCompDirectiveNoFileComp
-		}
-		// Load config and return server names for completion
-		cfg, err := config.Load()
-		if err != nil {
-			return nil, cobra.ShellCompDirectiveNoFileComp
-		}
-		var names []string
-		for _, s := range cfg.ListServers() {
-			names = append(names, s.Name)
-		}
-		return names, cobra.ShellCompDirectiveNoFileComp
-	},
+	Args:              cobra.ExactArgs(1),
+	RunE:              runRemove,
+	ValidArgsFunction: completeServerName,
+}
+
+func init() {
+	removeCmd.Flags().BoolVar(&unlockConfig, "unlock", false, unlockUsage)
 }
 
 func runRemove(cmd *cobra.Command, args []string) error {
@@ -50,18 +42,15 @@ func runRemove(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
+	applyUnlock(cfg)
 
 	// Remove server
 	if err := cfg.RemoveServer(name); err != nil {
 		return err
 	}
 
-	// Save config
-	if err := cfg.Save(); err != nil {
-		return fmt.Errorf("failed to save config: %w", err)
+	if dependents := cfg.Dependents(name); len(dependents) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: %s still depend on %q\n", strings.Join(dependents, ", "), name)
 	}
-
-	fmt.Printf("Removed server %q from %s\n", name, cfg.Path())
-	resyncAll(cfg)
-	return nil
+	return saveAndResync(cmd.Context(), os.Stdout, cfg, fmt.Sprintf("Removed server %q from %s", name, cfg.Path()))
 }
@@ -0,0 +1,360 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jrandolf/mcpr/config"
+	"github.com/jrandolf/mcpr/mcp"
+
+	"github.com/spf13/cobra"
+)
+
+var replCmd = &cobra.Command{
+	Use:   "repl [server]",
+	Short: "Interact with a configured server from a prompt",
+	Long: `Start an interactive session with a configured server. The server stays
+connected until you exit, so you can list and call its tools, read resources
+and render prompts one after another. JSON responses are pretty-printed.
+
+Commands:
+  tools                     List tools
+  call <tool> [json]        Call a tool with JSON arguments
+  resources                 List resources
+  read <uri>                Read a resource
+  prompts                   List prompts
+  prompt <name> [k=v ...]   Render a prompt
+  history                   Show command history
+  !<n>                      Run command n from the history again
+  save <file>               Save the session transcript
+  help                      Show this help
+  exit                      End the session
+
+Tool and prompt names may be shortened to any unique prefix. History is kept
+across sessions in ~/.config/mcpr/repl_history.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeServerName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		server, err := loadServer(args[0])
+		if err != nil {
+			return err
+		}
+		return runREPL(cmd.Context(), os.Stdin, os.Stdout, server)
+	},
+}
+
+// replHistoryLimit is the number of commands kept in the history file
+const replHistoryLimit = 500
+
+// getREPLHistoryPath returns the history file path; a variable so tests can
+// override it
+var getREPLHistoryPath = func() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "mcpr", "repl_history"), nil
+}
+
+// replSession is an interactive session with a connected server
+type replSession struct {
+	ctx        context.Context
+	client     *mcp.Client
+	out        io.Writer // writes to the terminal and the transcript
+	transcript bytes.Buffer
+	history    []string
+}
+
+func runREPL(ctx context.Context, in io.Reader, out io.Writer, server config.MCPServer) error {
+	client, err := connectServer(ctx, server)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	s := &replSession{ctx: ctx, client: client, history: loadREPLHistory()}
+	s.out = io.MultiWriter(out, &s.transcript)
+	fmt.Fprintf(s.out, "Connected to %s. Type \"help\" for commands.\n", server.Name)
+
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(in)
+		scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	for {
+		fmt.Fprint(out, server.Name+"> ")
+		var line string
+		select {
+		case l, ok := <-lines:
+			if !ok {
+				fmt.Fprintln(out)
+				return saveREPLHistory(s.history)
+			}
+			line = strings.TrimSpace(l)
+		case <-ctx.Done():
+			fmt.Fprintln(out)
+			return saveREPLHistory(s.history)
+		}
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "!") {
+			n, err := strconv.Atoi(line[1:])
+			if err != nil || n < 1 || n > len(s.history) {
+				fmt.Fprintf(s.out, "Error: no command %s in the history\n", line)
+				continue
+			}
+			line = s.history[n-1]
+			fmt.Fprintln(out, line)
+		}
+		fmt.Fprintf(&s.transcript, "> %s\n", line)
+		if line != "history" {
+			s.history = append(s.history, line)
+		}
+
+		if line == "exit" || line == "quit" {
+			return saveREPLHistory(s.history)
+		}
+		if err := s.run(line); err != nil {
+			fmt.Fprintf(s.out, "Error: %v\n", err)
+		}
+	}
+}
+
+// run executes one REPL command
+func (s *replSession) run(line string) error {
+	command, rest, _ := strings.Cut(line, " ")
+	rest = strings.TrimSpace(rest)
+
+	switch command {
+	case "help":
+		fmt.Fprintln(s.out, "Commands: tools, call <tool> [json], resources, read <uri>, prompts, prompt <name> [k=v ...], history, !<n>, save <file>, exit")
+	case "tools":
+		tools, err := s.client.ListTools(s.ctx)
+		if err != nil {
+			return err
+		}
+		for _, tool := range tools {
+			fmt.Fprintf(s.out, "  %s", tool.Name)
+			if tool.Description != "" {
+				fmt.Fprintf(s.out, " - %s", tool.Description)
+			}
+			fmt.Fprintln(s.out)
+		}
+	case "call":
+		name, args, _ := strings.Cut(rest, " ")
+		tool, err := s.completeTool(name)
+		if err != nil {
+			return err
+		}
+		var raw json.RawMessage
+		if args = strings.TrimSpace(args); args != "" {
+			if !json.Valid([]byte(args)) {
+				return fmt.Errorf("arguments must be valid JSON")
+			}
+			raw = json.RawMessage(args)
+		}
+		result, err := s.client.CallTool(s.ctx, tool, raw)
+		if err != nil {
+			return err
+		}
+		if result.IsError {
+			fmt.Fprintln(s.out, "Tool reported an error:")
+		}
+		printPrettyContent(s.out, result.Content)
+		if result.StructuredContent != nil {
+			data, _ := json.MarshalIndent(result.StructuredContent, "", "  ")
+			fmt.Fprintln(s.out, string(data))
+		}
+	case "resources":
+		resources, err := s.client.ListResources(s.ctx)
+		if err != nil {
+			return err
+		}
+		for _, resource := range resources {
+			fmt.Fprintf(s.out, "  %s", resource.URI)
+			if resource.Name != "" {
+				fmt.Fprintf(s.out, " (%s)", resource.Name)
+			}
+			fmt.Fprintln(s.out)
+		}
+	case "read":
+		if rest == "" {
+			return fmt.Errorf("usage: read <uri>")
+		}
+		contents, err := s.client.ReadResource(s.ctx, rest)
+		if err != nil {
+			return err
+		}
+		for _, content := range contents {
+			if content.Blob != "" {
+				fmt.Fprintf(s.out, "<%s, %d bytes of base64; use mcpr resource get --output to save it>\n", content.MimeType, len(content.Blob))
+				continue
+			}
+			printPrettyContent(s.out, []mcp.Content{{Type: "text", Text: content.Text}})
+		}
+	case "prompts":
+		prompts, err := s.client.ListPrompts(s.ctx)
+		if err != nil {
+			return err
+		}
+		for _, prompt := range prompts {
+			fmt.Fprintf(s.out, "  %s", prompt.Name)
+			for _, arg := range prompt.Arguments {
+				fmt.Fprintf(s.out, " %s=", arg.Name)
+			}
+			fmt.Fprintln(s.out)
+		}
+	case "prompt":
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			return fmt.Errorf("usage: prompt <name> [key=value ...]")
+		}
+		name, err := s.completePrompt(fields[0])
+		if err != nil {
+			return err
+		}
+		result, err := s.client.GetPrompt(s.ctx, name, parseKeyValues(fields[1:]))
+		if err != nil {
+			return err
+		}
+		for _, message := range result.Messages {
+			fmt.Fprintf(s.out, "[%s]\n", message.Role)
+			printPrettyContent(s.out, []mcp.Content{message.Content})
+		}
+	case "history":
+		for i, entry := range s.history {
+			fmt.Fprintf(s.out, "%4d  %s\n", i+1, entry)
+		}
+	case "save":
+		if rest == "" {
+			return fmt.Errorf("usage: save <file>")
+		}
+		if err := os.WriteFile(rest, s.transcript.Bytes(), 0644); err != nil {
+			return fmt.Errorf("failed to save transcript: %w", err)
+		}
+		fmt.Fprintf(s.out, "Saved transcript to %s\n", rest)
+	default:
+		return fmt.Errorf("unknown command %q (type \"help\" for commands)", command)
+	}
+	return nil
+}
+
+// completeTool resolves a tool name or unique prefix of one
+func (s *replSession) completeTool(prefix string) (string, error) {
+	if prefix == "" {
+		return "", fmt.Errorf("usage: call <tool> [json]")
+	}
+	tools, err := s.client.ListTools(s.ctx)
+	if err != nil {
+		return "", err
+	}
+	names := make([]string, len(tools))
+	for i, tool := range tools {
+		names[i] = tool.Name
+	}
+	return completeName("tool", prefix, names)
+}
+
+// completePrompt resolves a prompt name or unique prefix of one
+func (s *replSession) completePrompt(prefix string) (string, error) {
+	prompts, err := s.client.ListPrompts(s.ctx)
+	if err != nil {
+		return "", err
+	}
+	names := make([]string, len(prompts))
+	for i, prompt := range prompts {
+		names[i] = prompt.Name
+	}
+	return completeName("prompt", prefix, names)
+}
+
+// completeName returns the name matching prefix exactly, or the only name
+// starting with it
+func completeName(kind, prefix string, names []string) (string, error) {
+	if slices.Contains(names, prefix) {
+		return prefix, nil
+	}
+	var matches []string
+	for _, name := range names {
+		if strings.HasPrefix(name, prefix) {
+			matches = append(matches, name)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no %s matches %q", kind, prefix)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("%q matches several %ss: %s", prefix, kind, strings.Join(matches, ", "))
+	}
+}
+
+// printPrettyContent prints content like printContent, indenting text that
+// holds a JSON object or array
+func printPrettyContent(w io.Writer, content []mcp.Content) {
+	for _, block := range content {
+		if block.Type == "text" {
+			text := strings.TrimSpace(block.Text)
+			var indented bytes.Buffer
+			if (strings.HasPrefix(text, "{") || strings.HasPrefix(text, "[")) && json.Indent(&indented, []byte(text), "", "  ") == nil {
+				fmt.Fprintln(w, indented.String())
+			} else {
+				fmt.Fprintln(w, block.Text)
+			}
+			continue
+		}
+		data, _ := json.MarshalIndent(block, "", "  ")
+		fmt.Fprintln(w, string(data))
+	}
+}
+
+// loadREPLHistory returns the commands saved by previous sessions
+func loadREPLHistory() []string {
+	path, err := getREPLHistoryPath()
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || len(data) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+// saveREPLHistory writes the most recent commands to the history file
+func saveREPLHistory(history []string) error {
+	if len(history) == 0 {
+		return nil
+	}
+	path, err := getREPLHistoryPath()
+	if err != nil {
+		return nil
+	}
+	if len(history) > replHistoryLimit {
+		history = history[len(history)-replHistoryLimit:]
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to save history: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(history, "\n")+"\n"), 0600); err != nil {
+		return fmt.Errorf("failed to save history: %w", err)
+	}
+	return nil
+}
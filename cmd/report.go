@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"maps"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jrandolf/mcpr/clients"
+	"github.com/jrandolf/mcpr/config"
+
+	"github.com/spf13/cobra"
+)
+
+var reportHTML string
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Write a report of servers, clients and their health",
+	Long: `Write a self-contained HTML report of the configured servers, the
+clients they are synced to and whether those configs changed since, and the
+last health check of each server, to share your setup with teammates or
+attach it to a support request.
+
+Health checks are those recorded by 'mcpr check'; run it first for fresh
+results. Env var and header values are left out of the report.
+
+Examples:
+  mcpr report --html report.html`,
+	Args: cobra.NoArgs,
+	RunE: runReport,
+}
+
+func init() {
+	reportCmd.Flags().StringVar(&reportHTML, "html", "", "Write the report as an HTML file")
+	_ = reportCmd.MarkFlagRequired("html")
+}
+
+// report is what the HTML report shows
+type report struct {
+	Version   string
+	OS        string
+	Arch      string
+	Generated time.Time
+	Layers    []config.Layer
+	Servers   []reportServer
+	Clients   []reportClient
+}
+
+// reportServer is a server as shown in the report, without secrets
+type reportServer struct {
+	Name        string
+	Type        string
+	Description string
+	Target      string   // Command line, URL or socket path
+	Env         []string // Env var names
+	Headers     []string // Header names
+	Status      string   // "enabled", "disabled" or "quarantined"
+	Layer       string
+	Source      string // Provenance, if recorded
+	Health      *config.HealthRecord
+}
+
+// reportClient is a synced client as shown in the report
+type reportClient struct {
+	DisplayName string
+	Local       bool
+	Path        string
+	Servers     []string // Empty for all
+	Exclude     []string
+	LastSync    time.Time
+	Status      string
+	InSync      bool
+}
+
+func runReport(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := writeHTMLReport(&buf, buildReport(cfg)); err != nil {
+		return err
+	}
+	if err := os.WriteFile(reportHTML, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	fmt.Printf("Wrote %s\n", reportHTML)
+	return nil
+}
+
+// buildReport gathers what the report shows about cfg
+func buildReport(cfg *config.Config) report {
+	r := report{
+		Version:   version,
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		Generated: time.Now(),
+		Layers:    cfg.Layers(),
+	}
+
+	servers, err := config.SortByDependencies(cfg.ListServers())
+	if err != nil {
+		servers = cfg.ListServers()
+	}
+	for _, server := range servers {
+		item := reportServer{
+			Name:        server.Name,
+			Type:        server.Type,
+			Description: server.Description,
+			Env:         slices.Sorted(maps.Keys(server.Env)),
+			Headers:     slices.Sorted(maps.Keys(server.Headers)),
+			Status:      "enabled",
+			Layer:       cfg.ServerLayer(server.Name),
+		}
+		switch server.Type {
+		case "http":
+			item.Target = server.URL
+		case "socket":
+			item.Target = server.Path
+		default:
+			item.Target = strings.Join(append([]string{server.Command}, server.Args...), " ")
+		}
+		if server.Disabled {
+			item.Status = "disabled"
+		} else if cfg.IsQuarantined(server.Name) {
+			item.Status = "quarantined"
+		}
+		if server.Provenance != nil {
+			item.Source = server.Provenance.String()
+		}
+		if record, ok := cfg.HealthOf(server.Name); ok {
+			item.Health = &record
+		}
+		r.Servers = append(r.Servers, item)
+	}
+
+	for _, sc := range cfg.GetSyncedClients() {
+		item := reportClient{DisplayName: sc.Name, Local: sc.Local, Servers: sc.Servers, Exclude: sc.Exclude, LastSync: sc.LastSync}
+		client, err := clients.GetClient(sc.Name)
+		if err != nil {
+			item.Status = err.Error()
+			r.Clients = append(r.Clients, item)
+			continue
+		}
+		item.DisplayName = client.DisplayName
+		if item.Path, err = client.Path(sc.Local); err != nil {
+			item.Status = err.Error()
+		} else {
+			item.Status, item.InSync = syncStatus(sc, item.Path)
+		}
+		r.Clients = append(r.Clients, item)
+	}
+	return r
+}
+
+// syncStatus describes whether the client config at path is still what
+// mcpr last wrote to it
+func syncStatus(sc config.SyncedClient, path string) (string, bool) {
+	data, err := os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		return "config missing", false
+	case err != nil:
+		return err.Error(), false
+	case sc.Hash == "":
+		return "synced before changes were tracked", false
+	case contentHash(data) != sc.Hash:
+		return "changed since last sync", false
+	}
+	return "in sync", true
+}
+
+// writeHTMLReport renders r as a self-contained HTML page to w
+func writeHTMLReport(w io.Writer, r report) error {
+	tmpl, err := template.New("report.html").Funcs(template.FuncMap{
+		"join":    strings.Join,
+		"fmtTime": func(t time.Time) string { return t.Local().Format("2006-01-02 15:04") },
+	}).ParseFS(templatesFS, "templates/report/report.html")
+	if err != nil {
+		return fmt.Errorf("failed to parse report template: %w", err)
+	}
+	if err := tmpl.Execute(w, r); err != nil {
+		return fmt.Errorf("failed to render report: %w", err)
+	}
+	return nil
+}
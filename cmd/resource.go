@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/jrandolf/mcpr/config"
+	"github.com/jrandolf/mcpr/mcp"
+
+	"github.com/spf13/cobra"
+)
+
+var resourceCmd = &cobra.Command{
+	Use:   "resource",
+	Short: "List and read the resources of a configured server",
+}
+
+var resourceListCmd = &cobra.Command{
+	Use:               "list [server]",
+	Short:             "List the resources a server exposes",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeServerName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		server, err := loadServer(args[0])
+		if err != nil {
+			return err
+		}
+		return listResources(cmd.Context(), os.Stdout, server)
+	},
+}
+
+var resourceOutput string
+
+var resourceGetCmd = &cobra.Command{
+	Use:   "get [server] [uri]",
+	Short: "Read a resource from a server",
+	Long: `Read a resource from a configured server and print its text. Binary
+resources must be saved to a file with --output.
+
+Examples:
+  mcpr resource get filesystem file:///tmp/notes.md
+  mcpr resource get screenshots screen://current --output screen.png`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeServerName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		server, err := loadServer(args[0])
+		if err != nil {
+			return err
+		}
+		return readResource(cmd.Context(), os.Stdout, server, args[1], resourceOutput)
+	},
+}
+
+func init() {
+	resourceGetCmd.Flags().StringVarP(&resourceOutput, "output", "o", "", "Write the resource to this file instead of stdout")
+
+	resourceCmd.AddCommand(resourceListCmd)
+	resourceCmd.AddCommand(resourceGetCmd)
+}
+
+// listResources prints the resources server exposes
+func listResources(ctx context.Context, w io.Writer, server config.MCPServer) error {
+	return withServer(ctx, server, func(ctx context.Context, client *mcp.Client) error {
+		resources, err := client.ListResources(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list resources: %w", err)
+		}
+		if len(resources) == 0 {
+			fmt.Fprintf(w, "%s exposes no resources.\n", server.Name)
+			return nil
+		}
+		for _, resource := range resources {
+			fmt.Fprintf(w, "  %s\n", resource.URI)
+			if resource.Name != "" {
+				fmt.Fprintf(w, "    Name:        %s\n", resource.Name)
+			}
+			if resource.Description != "" {
+				fmt.Fprintf(w, "    Description: %s\n", resource.Description)
+			}
+			if resource.MimeType != "" {
+				fmt.Fprintf(w, "    Type:        %s\n", resource.MimeType)
+			}
+		}
+		return nil
+	})
+}
+
+// readResource prints the text of the resource at uri to w, or writes it to
+// output if set
+func readResource(ctx context.Context, w io.Writer, server config.MCPServer, uri, output string) error {
+	return withServer(ctx, server, func(ctx context.Context, client *mcp.Client) error {
+		contents, err := client.ReadResource(ctx, uri)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", uri, err)
+		}
+
+		if output != "" {
+			data, err := resourceData(contents)
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(output, data, 0644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", output, err)
+			}
+			fmt.Fprintf(os.Stderr, "Wrote %d bytes to %s\n", len(data), output)
+			return nil
+		}
+
+		for _, content := range contents {
+			if content.Blob != "" {
+				return fmt.Errorf("%s is binary (%s); save it with --output", content.URI, content.MimeType)
+			}
+			text := content.Text
+			if !strings.HasSuffix(text, "\n") {
+				text += "\n"
+			}
+			if _, err := io.WriteString(w, text); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// resourceData concatenates resource contents, decoding binary blobs
+func resourceData(contents []mcp.ResourceContents) ([]byte, error) {
+	var data []byte
+	for _, content := range contents {
+		if content.Blob == "" {
+			data = append(data, content.Text...)
+			continue
+		}
+		blob, err := base64.StdEncoding.DecodeString(content.Blob)
+		if err != nil {
+			return nil, fmt.Errorf("invalid blob in %s: %w", content.URI, err)
+		}
+		data = append(data, blob...)
+	}
+	return data, nil
+}
@@ -1,12 +1,21 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+
+	"github.com/jrandolf/mcpr/config"
+	"github.com/jrandolf/mcpr/retry"
 
 	"github.com/spf13/cobra"
 )
 
+// version is the mcpr version, set at build time with -ldflags "-X"
+var version = "dev"
+
 var rootCmd = &cobra.Command{
 	Use:   "mcpr",
 	Short: "MCP Registry - Manage MCP servers across clients",
@@ -14,21 +23,96 @@ var rootCmd = &cobra.Command{
 
 It allows you to:
   - Add MCP server configurations
-  - Install servers to various MCP clients (Claude Desktop, Claude Code, Cursor, Windsurf)
+  - Install servers to the MCP clients you use (see 'mcpr client sync --help')
   - Manage your MCP server configurations in a central location`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if noRetry {
+			retry.Disable()
+		}
+	},
 }
 
+// noRetry makes transient failures fail at once, for --no-retry
+var noRetry bool
+
 // Execute runs the root command
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	// Register cobra's lazily-added commands so aliases can't shadow them
+	rootCmd.InitDefaultHelpCmd()
+	rootCmd.InitDefaultCompletionCmd()
+
+	var aliases map[string]string
+	cfg, err := config.Load()
+	if err == nil {
+		aliases = cfg.Aliases
+	}
+	args := expandArgs(os.Args[1:], aliases)
+	rootCmd.SetArgs(args)
+
+	// Ctrl-C cancels the command's context, so multi-client syncs stop
+	// between clients and report what was done
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// The check for a newer release runs while the command does
+	var updates *updateCheck
+	if cmd, _, err := rootCmd.Find(args); err == nil && cfg != nil {
+		updates = startUpdateCheck(ctx, cfg, cmd)
+	}
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
 		fmt.Fprintln(os.Stderr, err)
+		if errors.Is(err, config.ErrLocked) {
+			fmt.Fprintln(os.Stderr, "Pass --unlock to change it anyway.")
+		}
+		var parseErr *config.ParseError
+		if errors.As(err, &parseErr) {
+			fmt.Fprintln(os.Stderr, "Run 'mcpr config repair' to recover it.")
+		}
 		os.Exit(1)
 	}
+	updates.finish(os.Stderr)
 }
 
 func init() {
+	rootCmd.PersistentFlags().BoolVar(&plainOutput, "plain", plainOutput, "Spell out symbols and draw nothing, for screen readers and basic terminals (or set "+plainEnv+")")
+	rootCmd.PersistentFlags().BoolVar(&allowPlaintextSecrets, "allow-plaintext-secrets", false, "Let syncs write values looking like live credentials, such as AWS keys or GitHub tokens, into client configs")
+	rootCmd.PersistentFlags().BoolVar(&noRetry, "no-retry", false, "Fail at once on locked files and flaky network requests instead of retrying")
+
 	rootCmd.AddCommand(addCmd)
 	rootCmd.AddCommand(removeCmd)
 	rootCmd.AddCommand(clientCmd)
+	rootCmd.AddCommand(syncCmd)
 	rootCmd.AddCommand(listCmd)
+	rootCmd.AddCommand(treeCmd)
+	rootCmd.AddCommand(aliasCmd)
+	rootCmd.AddCommand(mcpServeCmd)
+	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(schemaCmd)
+	rootCmd.AddCommand(simulateCmd)
+	rootCmd.AddCommand(bugreportCmd)
+	rootCmd.AddCommand(replayCmd)
+	rootCmd.AddCommand(reportCmd)
+	rootCmd.AddCommand(newCmd)
+	rootCmd.AddCommand(bridgeCmd)
+	rootCmd.AddCommand(callCmd)
+	rootCmd.AddCommand(resourceCmd)
+	rootCmd.AddCommand(promptCmd)
+	rootCmd.AddCommand(replCmd)
+	rootCmd.AddCommand(benchCmd)
+	rootCmd.AddCommand(checkCmd)
+	rootCmd.AddCommand(unquarantineCmd)
+	rootCmd.AddCommand(projectsCmd)
+	rootCmd.AddCommand(cleanCmd)
+	rootCmd.AddCommand(verifyCmd)
+	rootCmd.AddCommand(openCmd)
+	rootCmd.AddCommand(hookCmd)
+	rootCmd.AddCommand(shareCmd)
+	rootCmd.AddCommand(deeplinkCmd)
+	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(bundleCmd)
+	rootCmd.AddCommand(remoteCmd)
+	rootCmd.AddCommand(pushCmd)
+	rootCmd.AddCommand(pullCmd)
+	rootCmd.AddCommand(setupCmd)
 }
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/jrandolf/mcpr/clients"
+	"github.com/jrandolf/mcpr/config"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	schemaWrite  bool
+	schemaClient string
+	schemaFetch  bool
+)
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema for mcpr config files",
+	Long: `Print the JSON Schema describing mcpr.json and config.json files.
+
+Point your editor at the schema to get validation and completion while
+hand-editing the config. With --write, a "$schema" key referencing the
+published schema is added to the active config.
+
+With --client, the schema a client's config is checked against after a
+sync and by 'mcpr verify' is printed instead. mcpr bundles schemas of the
+server entries it writes; --fetch downloads the schema a client publishes,
+such as OpenCode's opencode.ai/config.json, and checks against it from
+then on.
+
+Examples:
+  mcpr schema > mcpr.schema.json
+  mcpr schema --write
+  mcpr schema --client opencode --fetch`,
+	Args: cobra.NoArgs,
+	RunE: runSchema,
+}
+
+func init() {
+	schemaCmd.Flags().BoolVarP(&schemaWrite, "write", "w", false, "Add a $schema key to the active config")
+	schemaCmd.Flags().BoolVar(&unlockConfig, "unlock", false, unlockUsage)
+	schemaCmd.Flags().StringVar(&schemaClient, "client", "", "Print the schema a client's config is checked against")
+	schemaCmd.Flags().BoolVar(&schemaFetch, "fetch", false, "Download the schema the client publishes first (with --client)")
+	_ = schemaCmd.RegisterFlagCompletionFunc("client", completeClientName)
+}
+
+func runSchema(cmd *cobra.Command, args []string) error {
+	if schemaFetch && schemaClient == "" {
+		return errors.New("--fetch needs --client")
+	}
+	if schemaClient != "" {
+		if schemaWrite {
+			return errors.New("--write and --client can't be used together")
+		}
+		return clientSchema(cmd.Context(), os.Stdout, os.Stderr, schemaClient, schemaFetch)
+	}
+	if !schemaWrite {
+		_, err := os.Stdout.Write(config.Schema())
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if err := applyUnlock(cfg).CheckLocked(); err != nil {
+		return err
+	}
+	cfg.Schema = config.SchemaURL
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	fmt.Printf("Added $schema to %s\n", cfg.Path())
+	return nil
+}
+
+// clientSchema writes the schema the named client's config is checked
+// against to w, downloading the one it publishes first if fetch is set
+func clientSchema(ctx context.Context, w, status io.Writer, name string, fetch bool) error {
+	client, err := clients.GetClient(name)
+	if err != nil {
+		return fmt.Errorf("%w\n\nSupported clients: %s", err, strings.Join(clients.ListClientNames(), ", "))
+	}
+	if fetch {
+		httpClient, err := loadHTTPClient()
+		if err != nil {
+			return err
+		}
+		path, err := client.FetchSchema(ctx, httpClient)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(status, "Saved %s to %s\n", client.SchemaURL, path)
+	}
+	schema := client.ActiveSchema()
+	if schema == nil {
+		return fmt.Errorf("%s configs aren't checked against a schema", client.DisplayName)
+	}
+	_, err = w.Write(schema)
+	return err
+}
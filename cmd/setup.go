@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var setupAddPath bool
+
+var setupCmd = &cobra.Command{
+	Use:   "setup",
+	Short: "Integrate mcpr with your shell and OS",
+}
+
+var setupWindowsCmd = &cobra.Command{
+	Use:   "windows",
+	Short: "Register PowerShell completion and optionally add mcpr to PATH",
+	Long: `Register tab completion for mcpr in your PowerShell profiles, for both
+PowerShell 7 (pwsh) and Windows PowerShell, so commands, flags, client names
+and server names complete. Profiles that already load it are left alone.
+
+With --path, the directory of the mcpr executable is also added to your
+user PATH in the registry (HKCU\Environment). Programs started afterwards
+see it; open a new terminal to use it.
+
+Examples:
+  mcpr setup windows
+  mcpr setup windows --path`,
+	Args: cobra.NoArgs,
+	RunE: runSetupWindows,
+}
+
+func init() {
+	setupWindowsCmd.Flags().BoolVar(&setupAddPath, "path", false, "Add the directory of mcpr to your user PATH")
+	setupCmd.AddCommand(setupWindowsCmd)
+}
+
+// powerShells are the PowerShell editions to set up, newest first
+var powerShells = []string{"pwsh", "powershell"}
+
+// completionMarker marks the profile lines loading mcpr completion
+const completionMarker = "# mcpr tab completion"
+
+// userPathScript adds $env:MCPR_SETUP_DIR to the user PATH in the
+// registry, keeping unexpanded entries like %USERPROFILE%\bin as they are,
+// and prints whether it was added or already present
+const userPathScript = `
+$dir = $env:MCPR_SETUP_DIR.TrimEnd('\')
+$key = [Microsoft.Win32.Registry]::CurrentUser.CreateSubKey('Environment')
+$entries = @($key.GetValue('Path', '', 'DoNotExpandEnvironmentNames') -split ';' | Where-Object { $_ })
+if ($entries | Where-Object { [Environment]::ExpandEnvironmentVariables($_).TrimEnd('\') -eq $dir }) { 'present'; exit }
+$key.SetValue('Path', ($entries + $dir) -join ';', 'ExpandString')
+# Setting a variable through .NET broadcasts the change to running programs
+[Environment]::SetEnvironmentVariable('MCPR_SETUP', '1', 'User')
+[Environment]::SetEnvironmentVariable('MCPR_SETUP', $null, 'User')
+'added'`
+
+// runPowerShell runs script with shell and returns its trimmed output; a
+// variable so tests can override it
+var runPowerShell = func(shell, script string, env ...string) (string, error) {
+	c := exec.Command(shell, "-NoProfile", "-NonInteractive", "-Command", script)
+	c.Env = append(os.Environ(), env...)
+	out, err := c.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("%s failed: %s", shell, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("%s failed: %w", shell, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func runSetupWindows(cmd *cobra.Command, args []string) error {
+	if runtime.GOOS != "windows" {
+		return fmt.Errorf("mcpr setup windows only runs on Windows; see 'mcpr completion --help' for other shells")
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find the mcpr executable: %w", err)
+	}
+	shells := installedPowerShells()
+	if len(shells) == 0 {
+		return fmt.Errorf("PowerShell not found")
+	}
+
+	if setupAddPath {
+		out, err := runPowerShell(shells[0], userPathScript, "MCPR_SETUP_DIR="+filepath.Dir(exe))
+		if err != nil {
+			return fmt.Errorf("failed to add mcpr to PATH: %w", err)
+		}
+		if out == "present" {
+			fmt.Printf("- PATH: already contains %s\n", filepath.Dir(exe))
+		} else {
+			fmt.Printf("%s Added %s to your PATH\n", okMark(), filepath.Dir(exe))
+		}
+	}
+
+	// Profiles run mcpr by name once it's on PATH, so they keep working
+	// when it's upgraded in place or moved
+	command := "mcpr"
+	if _, err := lookPath("mcpr"); err != nil && !setupAddPath {
+		command = "& " + powerShellQuote(exe)
+	}
+
+	var failed bool
+	for _, shell := range shells {
+		profile, err := runPowerShell(shell, "$PROFILE.CurrentUserCurrentHost")
+		if err != nil || profile == "" {
+			fmt.Printf("%s %s: failed to find its profile: %v\n", failMark(), shell, err)
+			failed = true
+			continue
+		}
+		added, err := addCompletionToProfile(profile, command)
+		switch {
+		case err != nil:
+			fmt.Printf("%s %s: %v\n", failMark(), shell, err)
+			failed = true
+		case added:
+			fmt.Printf("%s Registered completion in %s\n", okMark(), profile)
+		default:
+			fmt.Printf("- %s: already loads mcpr completion\n", profile)
+		}
+	}
+	if failed {
+		return fmt.Errorf("failed to set up every PowerShell")
+	}
+	fmt.Println("\nOpen a new PowerShell window to use it.")
+	return nil
+}
+
+// installedPowerShells returns the PowerShell editions found on PATH
+func installedPowerShells() []string {
+	var shells []string
+	for _, shell := range powerShells {
+		if _, err := lookPath(shell); err == nil {
+			shells = append(shells, shell)
+		}
+	}
+	return shells
+}
+
+// addCompletionToProfile appends the lines loading mcpr completion with
+// command to the PowerShell profile at path, reporting whether it did. A
+// profile that already loads it is left alone.
+func addCompletionToProfile(path, command string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, fmt.Errorf("failed to read profile: %w", err)
+	}
+	if bytes.Contains(data, []byte(completionMarker)) {
+		return false, nil
+	}
+
+	newline := "\n"
+	if bytes.Contains(data, []byte("\r\n")) {
+		newline = "\r\n"
+	}
+	var b bytes.Buffer
+	b.Write(data)
+	if len(data) > 0 {
+		if !bytes.HasSuffix(data, []byte("\n")) {
+			b.WriteString(newline)
+		}
+		b.WriteString(newline)
+	}
+	b.WriteString(completionMarker + newline)
+	b.WriteString(command + " completion powershell | Out-String | Invoke-Expression" + newline)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return false, fmt.Errorf("failed to create profile directory: %w", err)
+	}
+	if err := os.WriteFile(path, b.Bytes(), 0o644); err != nil {
+		return false, fmt.Errorf("failed to write profile: %w", err)
+	}
+	return true, nil
+}
+
+// powerShellQuote quotes s as a PowerShell string literal
+func powerShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,269 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/jrandolf/mcpr/config"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	shareIncludeSecrets bool
+	shareQR             bool
+	shareCopy           bool
+)
+
+var shareCmd = &cobra.Command{
+	Use:   "share [server]",
+	Short: "Print a link teammates can add a server from",
+	Long: `Encode a configured server as an mcpr:// link and print the command that
+adds it, ready to paste into a chat message.
+
+Secrets in env vars and headers are left out of the link: whoever adds it is
+asked for them instead. Values referencing variables, like ${GITHUB_TOKEN},
+are shared as they are.
+
+With --qr the link is also drawn as a QR code, and with --copy the command is
+put on the clipboard, for 'mcpr add clipboard' on the other end.
+
+Examples:
+  mcpr share github
+  mcpr share --qr github
+  mcpr share --include-secrets internal-api`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeServerName,
+	RunE:              runShare,
+}
+
+var linkName string
+
+var addLinkCmd = &cobra.Command{
+	Use:   "link [link]",
+	Short: "Add a server from a link made by mcpr share",
+	Long: `Add a server from an mcpr:// link made by 'mcpr share'. Secrets left out of
+the link are asked for; leave an env var empty to write it as a ${NAME}
+reference for clients that expand variables, or a header empty to drop it.
+
+Examples:
+  mcpr add link mcpr://add/eyJzZXJ2ZXIiOnsi...
+  mcpr add link --name gh mcpr://add/eyJzZXJ2ZXIiOnsi...`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAddLink,
+}
+
+var addClipboardCmd = &cobra.Command{
+	Use:   "clipboard",
+	Short: "Add a server from a link on the clipboard",
+	Long: `Add a server from an mcpr:// link made by 'mcpr share' that is on the
+system clipboard, such as one copied from a chat message or with
+'mcpr share --copy'. The link is validated before anything is added.
+
+Examples:
+  mcpr add clipboard
+  mcpr add clipboard --name gh`,
+	Args: cobra.NoArgs,
+	RunE: runAddClipboard,
+}
+
+func init() {
+	shareCmd.Flags().BoolVar(&shareIncludeSecrets, "include-secrets", false, "Put secrets in the link instead of asking for them")
+	shareCmd.Flags().BoolVar(&shareQR, "qr", false, "Also draw the link as a QR code")
+	shareCmd.Flags().BoolVar(&shareCopy, "copy", false, "Copy the command to the clipboard")
+
+	addLinkCmd.Flags().StringVarP(&linkName, "name", "n", "", "Server name (defaults to the shared name)")
+	addClipboardCmd.Flags().StringVarP(&linkName, "name", "n", "", "Server name (defaults to the shared name)")
+	addCmd.AddCommand(addLinkCmd)
+	addCmd.AddCommand(addClipboardCmd)
+}
+
+func runShare(cmd *cobra.Command, args []string) error {
+	server, err := loadServer(args[0])
+	if err != nil {
+		return err
+	}
+
+	shared := shareServer(server, shareIncludeSecrets)
+	link, err := config.EncodeLink(shared)
+	if err != nil {
+		return err
+	}
+	command := "mcpr add link " + link
+	fmt.Println(command)
+	for _, prompt := range shared.Prompts {
+		kind, name, _ := strings.Cut(prompt, ":")
+		fmt.Fprintf(os.Stderr, "The %s %s is left out and asked for when the link is added\n", kind, name)
+	}
+
+	if shareQR && plainOutput {
+		fmt.Fprintln(os.Stderr, "The QR code is left out of plain output")
+	} else if shareQR {
+		fmt.Println()
+		if err := printQR(os.Stdout, link); err != nil {
+			return err
+		}
+	}
+	if shareCopy {
+		if err := writeClipboard(command); err != nil {
+			return err
+		}
+		fmt.Fprintln(os.Stderr, "Copied to the clipboard")
+	}
+	return nil
+}
+
+// shareServer returns server as shared in a link. Literal secrets in env
+// vars and headers are replaced by prompts unless includeSecrets is set, and
+// the env file, a path on this machine, is left out.
+func shareServer(server config.MCPServer, includeSecrets bool) config.SharedServer {
+	if server.EnvFile != "" {
+		fmt.Fprintf(os.Stderr, "Warning: the env file of %q is not shared; its variables must be set up separately\n", server.Name)
+		server.EnvFile = ""
+	}
+
+	// Where the server came from is the sharer's business
+	server.Provenance = nil
+
+	shared := config.SharedServer{}
+	if !includeSecrets {
+		server.Env = maps.Clone(server.Env)
+		server.Headers = maps.Clone(server.Headers)
+		shared.Prompts = serverSecrets(server)
+		for _, prompt := range shared.Prompts {
+			setServerSecret(&server, prompt, "")
+		}
+	}
+	shared.Server = server
+	return shared
+}
+
+// serverSecrets returns the env vars and headers of server holding literal
+// secrets, as "env:NAME" or "header:NAME"
+func serverSecrets(server config.MCPServer) []string {
+	var secrets []string
+	for _, kind := range []string{"env", "header"} {
+		values := server.Env
+		if kind == "header" {
+			values = server.Headers
+		}
+		for _, name := range slices.Sorted(maps.Keys(values)) {
+			if isLiteralSecret(values[name]) && containsAny(strings.ToLower(name), secretNameHints) {
+				secrets = append(secrets, kind+":"+name)
+			}
+		}
+	}
+	return secrets
+}
+
+// serverSecret returns the value of a secret named as by serverSecrets
+func serverSecret(server config.MCPServer, secret string) string {
+	kind, name, _ := strings.Cut(secret, ":")
+	if kind == "header" {
+		return server.Headers[name]
+	}
+	return server.Env[name]
+}
+
+// setServerSecret sets a secret named as by serverSecrets. The server's
+// maps are written to, so callers clone them first if they are shared.
+func setServerSecret(server *config.MCPServer, secret, value string) {
+	kind, name, _ := strings.Cut(secret, ":")
+	if kind == "header" {
+		if server.Headers == nil {
+			server.Headers = make(map[string]string)
+		}
+		server.Headers[name] = value
+		return
+	}
+	if server.Env == nil {
+		server.Env = make(map[string]string)
+	}
+	server.Env[name] = value
+}
+
+func runAddLink(cmd *cobra.Command, args []string) error {
+	shared, err := config.DecodeLink(args[0])
+	if err != nil {
+		return err
+	}
+	return addSharedServer(cmd.Context(), shared)
+}
+
+func runAddClipboard(cmd *cobra.Command, args []string) error {
+	text, err := readClipboard()
+	if err != nil {
+		return err
+	}
+	shared, err := config.DecodeLink(text)
+	if err != nil {
+		return fmt.Errorf("the clipboard doesn't hold a valid server link: %w", err)
+	}
+	fmt.Printf("Found %s server %q on the clipboard\n", shared.Server.Type, shared.Server.Name)
+	return addSharedServer(cmd.Context(), shared)
+}
+
+// addSharedServer asks for the secrets left out of a shared server and adds
+// it under --name, if given
+func addSharedServer(ctx context.Context, shared config.SharedServer) error {
+	server := shared.Server
+	if linkName != "" {
+		server.Name = linkName
+	}
+	server.Provenance = &config.Provenance{Source: config.SourceLink}
+	if err := promptSecrets(os.Stdin, os.Stdout, &server, shared.Prompts); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	return saveNewServer(ctx, cfg, server, linkName != "")
+}
+
+// promptSecrets asks on in for each secret left out of a shared server. An
+// empty answer makes an env var a ${NAME} reference, and drops a header.
+func promptSecrets(in io.Reader, out io.Writer, server *config.MCPServer, prompts []string) error {
+	reader := bufio.NewReader(in)
+	for _, prompt := range prompts {
+		kind, name, _ := strings.Cut(prompt, ":")
+		label := "env var"
+		if kind == "header" {
+			label = "header"
+		}
+		fmt.Fprintf(out, "Value of %s %s for %q: ", label, name, server.Name)
+		answer, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		answer = strings.TrimSpace(answer)
+
+		switch kind {
+		case "env":
+			if answer == "" {
+				answer = "${" + name + "}"
+			}
+			if server.Env == nil {
+				server.Env = make(map[string]string)
+			}
+			server.Env[name] = answer
+		case "header":
+			if answer == "" {
+				delete(server.Headers, name)
+				continue
+			}
+			if server.Headers == nil {
+				server.Headers = make(map[string]string)
+			}
+			server.Headers[name] = answer
+		}
+	}
+	return nil
+}
@@ -0,0 +1,258 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/jrandolf/mcpr/clients"
+	"github.com/jrandolf/mcpr/config"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	simulateDir     string
+	simulateClients []string
+	simulateKeep    bool
+)
+
+var simulateCmd = &cobra.Command{
+	Use:   "simulate",
+	Short: "Run a full sync against a sandbox directory",
+	Long: `Sync all configured servers to every client inside a sandbox directory
+instead of your real home directory, and report what was written where.
+
+The sandbox contains a home/ directory used as the home directory of every
+client, and a project/ directory used for local syncs. Put existing client
+configs in a sandbox with --dir to see how mcpr merges into them. The checks
+of a real sync run too, so a sync that would be refused fails the simulation.
+Your real client configs are never touched.
+
+Examples:
+  # Simulate syncing to every client
+  mcpr simulate
+
+  # Simulate specific clients and keep the result for inspection
+  mcpr simulate --clients cursor,zed --keep
+
+  # Simulate against a prepared directory tree
+  mcpr simulate --dir ./sandbox`,
+	Args: cobra.NoArgs,
+	RunE: runSimulate,
+}
+
+func init() {
+	simulateCmd.Flags().StringVarP(&simulateDir, "dir", "d", "", "Sandbox directory (defaults to a temporary directory)")
+	simulateCmd.Flags().StringSliceVarP(&simulateClients, "clients", "c", nil, "Clients or @groups to simulate (comma-separated, defaults to all)")
+	_ = simulateCmd.RegisterFlagCompletionFunc("clients", completeList(func() []string {
+		return append(clientCompletions(), groupCompletions()...)
+	}))
+	simulateCmd.Flags().BoolVarP(&simulateKeep, "keep", "k", false, "Keep the temporary sandbox directory")
+}
+
+func runSimulate(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if len(cfg.ListServers()) == 0 {
+		return fmt.Errorf("no servers configured. Use 'mcpr add' to add a server first")
+	}
+
+	root := simulateDir
+	if root == "" {
+		root, err = os.MkdirTemp("", "mcpr-simulate-*")
+		if err != nil {
+			return fmt.Errorf("failed to create sandbox: %w", err)
+		}
+		if !simulateKeep {
+			defer os.RemoveAll(root)
+		}
+	}
+
+	names, err := cfg.ExpandClients(simulateClients)
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		names = clients.ListClientNames()
+		sort.Strings(names)
+	}
+
+	if err := simulate(cmd.Context(), os.Stdout, root, cfg, names); err != nil {
+		return err
+	}
+	if simulateDir != "" || simulateKeep {
+		fmt.Printf("\nSandbox: %s\n", root)
+	}
+	return nil
+}
+
+// simulate syncs the servers of cfg to the named clients with the home and
+// working directories pointed into root, and writes a report to w
+func simulate(ctx context.Context, w io.Writer, root string, cfg *config.Config, names []string) error {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+	home, project, err := prepareSandbox(root)
+	if err != nil {
+		return err
+	}
+
+	restore, err := enterSandbox(home, project)
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	total, failed := 0, 0
+	for _, name := range names {
+		client, err := clients.GetClient(name)
+		if err != nil {
+			return err
+		}
+
+		scopes := []bool{false}
+		if client.SupportsLocal {
+			scopes = append(scopes, true)
+		}
+		for _, local := range scopes {
+			total++
+			scope := "global"
+			if local {
+				scope = "local"
+			}
+
+			path, n, err := simulateSync(ctx, w, cfg, client, local)
+			if err == nil {
+				info, statErr := os.Stat(path)
+				size := int64(0)
+				if statErr == nil {
+					size = info.Size()
+				}
+				rel, relErr := filepath.Rel(root, path)
+				if relErr != nil {
+					rel = path
+				}
+				fmt.Fprintf(w, "%s %s (%s): %d server(s) %s %s (%d bytes)\n", okMark(), client.DisplayName, scope, n, arrow(), rel, size)
+				continue
+			}
+			failed++
+			fmt.Fprintf(w, "%s %s (%s): %v\n", failMark(), client.DisplayName, scope, err)
+		}
+	}
+
+	fmt.Fprintf(w, "\nSimulated %d sync(s), %d failed\n", total, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d simulated sync(s) failed", failed)
+	}
+	return nil
+}
+
+// simulateSync syncs the servers of cfg to the client the way 'mcpr client
+// sync' does, with the same checks before writing, but without resolving
+// conflicts or recording the sync. It returns the path written and how many
+// servers it holds; schema violations are reported to w.
+func simulateSync(ctx context.Context, w io.Writer, cfg *config.Config, client *clients.Client, local bool) (string, int, error) {
+	servers, err := selectServers(cfg, client.Name, nil, nil)
+	if err != nil {
+		return "", 0, err
+	}
+	staged, conflicts, unmanaged, err := stageSync(cfg, client, local, cfg.GetSyncedClient(client.Name, local) != nil, servers)
+	switch {
+	case err != nil:
+		return "", 0, err
+	case len(conflicts) > 0:
+		return "", 0, conflictError(client, local, conflicts)
+	case len(unmanaged) > 0:
+		return "", 0, unmanagedError(client, local, staged.Path, unmanaged)
+	}
+
+	var txn clients.Transaction
+	txn.Add(staged)
+	if err := txn.Commit(ctx); err != nil {
+		return "", 0, fmt.Errorf("failed to sync to %s: %w", client.DisplayName, err)
+	}
+	warnSchemaViolations(w, client, staged.Path, staged.Data)
+	return staged.Path, len(servers), nil
+}
+
+// prepareSandbox creates the home and project directories of a sandbox
+func prepareSandbox(root string) (string, string, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return "", "", err
+	}
+	home := filepath.Join(root, "home")
+	project := filepath.Join(root, "project")
+	for _, dir := range []string{home, project} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", "", fmt.Errorf("failed to create sandbox: %w", err)
+		}
+	}
+	return home, project, nil
+}
+
+// enterSandbox points the home directory, client-specific overrides and the
+// working directory into the sandbox, returning a function that restores them
+func enterSandbox(home, project string) (func(), error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	env := map[string]string{
+		"HOME":              home,
+		"USERPROFILE":       home,
+		"APPDATA":           filepath.Join(home, "AppData", "Roaming"),
+		"LOCALAPPDATA":      filepath.Join(home, "AppData", "Local"),
+		"CODEX_HOME":        "",
+		"CLAUDE_CONFIG_DIR": "",
+		"CURSOR_CONFIG_DIR": "",
+		"XDG_CONFIG_HOME":   "",
+		"XDG_STATE_HOME":    "",
+	}
+	// Path overrides would point outside the sandbox
+	for _, client := range clients.GetClients() {
+		env[client.EnvOverride(false)] = ""
+		env[client.EnvOverride(true)] = ""
+	}
+	// A project config around the sandbox mustn't draw local syncs out of it
+	previousProject := clients.SetProjectDir(project)
+	saved := make(map[string]*string, len(env))
+	for key, value := range env {
+		if old, ok := os.LookupEnv(key); ok {
+			saved[key] = &old
+		} else {
+			saved[key] = nil
+		}
+		if value == "" {
+			os.Unsetenv(key)
+		} else {
+			os.Setenv(key, value)
+		}
+	}
+
+	restore := func() {
+		for key, old := range saved {
+			if old == nil {
+				os.Unsetenv(key)
+			} else {
+				os.Setenv(key, *old)
+			}
+		}
+		os.Chdir(cwd)
+		clients.SetProjectDir(previousProject)
+	}
+
+	if err := os.Chdir(project); err != nil {
+		restore()
+		return nil, fmt.Errorf("failed to enter sandbox: %w", err)
+	}
+	return restore, nil
+}
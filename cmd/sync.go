@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/jrandolf/mcpr/config"
+
+	"github.com/spf13/cobra"
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync [server...]",
+	Short: "Push changes to servers to the clients that get them",
+	Long: `Push the changes made to the named servers to every synced client that
+gets them. Only the entries of the named servers are rewritten: other
+entries are left as they are, even if their servers have changes of their
+own, and clients whose entries for the named servers are already up to date
+aren't written. A server removed from mcpr is removed from the clients that
+still have it. The clients that are written are written together or not at
+all.
+
+Without servers, every synced client is resynced.
+
+Examples:
+  mcpr sync github
+  mcpr sync github filesystem
+  mcpr sync  # resync all`,
+	RunE:              runSync,
+	ValidArgsFunction: completeServerNames,
+}
+
+func runSync(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if len(args) == 0 {
+		return resyncAll(cmd.Context(), cfg)
+	}
+	return syncServersTo(cmd.Context(), os.Stdout, cfg, args)
+}
+
+// syncServersTo pushes the changes to the named servers to the synced
+// clients that get them, or still have them after they were removed,
+// writing progress to w
+func syncServersTo(ctx context.Context, w io.Writer, cfg *config.Config, names []string) error {
+	for _, name := range names {
+		if !cfg.HasServer(name) && !slices.ContainsFunc(cfg.GetSyncedClients(), func(sc config.SyncedClient) bool {
+			return hasAnyEntry(sc, []string{name})
+		}) {
+			return fmt.Errorf("server %q not found", name)
+		}
+	}
+
+	var errors []string
+	synced, total := 0, 0
+	err := resyncClients(ctx, cfg, names, nil, func(r syncResult) {
+		if r.Paused {
+			fmt.Fprintf(w, "- %s: paused\n", r.label())
+			return
+		}
+		total++
+		for _, name := range r.Missing {
+			errors = append(errors, fmt.Sprintf("%s: server %q not found", r.Name, name))
+		}
+		switch {
+		case r.Err != nil:
+			errors = append(errors, fmt.Sprintf("%s: %v", r.label(), r.Err))
+		case r.Skipped:
+			fmt.Fprintf(w, "- %s: up to date\n", r.label())
+		default:
+			fmt.Fprintf(w, "%s %s: %s %s %s\n", okMark(), r.label(), r.changes(), arrow(), r.Path)
+			synced++
+		}
+	})
+	if total == 0 && err == nil {
+		fmt.Fprintf(w, "No synced client gets %s.\n", strings.Join(quoteAll(names), ", "))
+		return nil
+	}
+
+	fmt.Fprintf(w, "\nSynced %d/%d client(s)\n", synced, total)
+	if len(errors) > 0 {
+		fmt.Fprintln(w, "\nErrors:")
+		for _, e := range errors {
+			fmt.Fprintf(w, "  - %s\n", e)
+		}
+		return fmt.Errorf("some clients failed to sync")
+	}
+	return err
+}
+
+// quoteAll returns names each in double quotes
+func quoteAll(names []string) []string {
+	quoted := slices.Clone(names)
+	for i, name := range quoted {
+		quoted[i] = fmt.Sprintf("%q", name)
+	}
+	return quoted
+}
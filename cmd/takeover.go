@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jrandolf/mcpr/clients"
+	"github.com/jrandolf/mcpr/config"
+)
+
+// How a sync takes over a client config holding server entries mcpr
+// doesn't manage, which it would otherwise drop
+const (
+	takeoverNone    = ""        // Refuse to sync
+	takeoverAdopt   = "adopt"   // Import the entries as servers, then sync
+	takeoverDiscard = "discard" // Sync, dropping the entries
+)
+
+var (
+	clientSyncForce   bool
+	clientSyncAdopt   bool
+	clientSyncDiscard bool
+)
+
+// syncTakeover returns how --force, --adopt and --discard take over client
+// configs
+func syncTakeover() (string, error) {
+	switch {
+	case clientSyncAdopt && clientSyncDiscard:
+		return "", errors.New("--adopt and --discard can't be used together")
+	case (clientSyncAdopt || clientSyncDiscard) && !clientSyncForce:
+		return "", errors.New("--adopt and --discard take over a client config only with --force")
+	case clientSyncForce && clientSyncAdopt:
+		return takeoverAdopt, nil
+	case clientSyncForce && clientSyncDiscard:
+		return takeoverDiscard, nil
+	case clientSyncForce:
+		return "", errors.New("--force needs --adopt to import the entries mcpr doesn't manage, or --discard to drop them")
+	}
+	return takeoverNone, nil
+}
+
+// unmanagedEntries returns the names of the server entries in the client
+// config at path that mcpr didn't write and that writing data would drop,
+// sorted. Entries are mcpr's when the newest snapshot of what it synced
+// there has them. A synced client without snapshots was synced before they
+// were kept, so its entries are all taken to be mcpr's.
+func unmanagedEntries(client *clients.Client, synced bool, path string, data []byte) []string {
+	written, ok := client.SnapshotEntries(path)
+	if !ok && synced {
+		return nil
+	}
+	after := client.ParseServerEntries(path, data)
+	var names []string
+	for name := range client.ServerEntries(path) {
+		_, mcprs := written[name]
+		_, kept := after[name]
+		if !mcprs && !kept {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// unmanagedError describes the unmanaged entries a sync of a client would
+// drop, and how to take the config over
+func unmanagedError(client *clients.Client, local bool, path string, names []string) error {
+	command := "mcpr client sync " + client.Name
+	if local {
+		command += " --local"
+	}
+	entries := "server entries"
+	if len(names) == 1 {
+		entries = "server entry"
+	}
+	return fmt.Errorf("%s holds %d %s mcpr doesn't manage (%s); run '%s --force --adopt' to import them, or '%s --force --discard' to drop them",
+		path, len(names), entries, strings.Join(names, ", "), command, command)
+}
+
+// adoptEntries imports the named server entries of the client config at
+// path into cfg, marked as imported from the client, so they are synced
+// from then on
+func adoptEntries(cfg *config.Config, client *clients.Client, path string, names []string) error {
+	entries := client.ServerEntries(path)
+	for _, name := range names {
+		server, err := clients.EntryServer(name, entries[name])
+		if err != nil {
+			return fmt.Errorf("failed to adopt %s: %w", name, err)
+		}
+		server.Provenance = &config.Provenance{
+			Source:  config.SourceImport,
+			Manager: client.Name,
+			From:    path,
+			Added:   time.Now().UTC().Truncate(time.Second),
+		}
+		if err := config.ValidateServer(server); err != nil {
+			return fmt.Errorf("failed to adopt %s: %w", name, err)
+		}
+		if err := cfg.AddServer(server); err != nil {
+			return fmt.Errorf("failed to adopt %s: %w", name, err)
+		}
+	}
+	return nil
+}
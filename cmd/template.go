@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// outputFuncs are available to --template, on top of the built-in ones
+var outputFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// parseOutputTemplate parses the --template of a command listing things,
+// returning nil if none was given
+func parseOutputTemplate(text string) (*template.Template, error) {
+	if text == "" {
+		return nil, nil
+	}
+	tmpl, err := template.New("output").Funcs(outputFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// writeTemplate writes tmpl executed for item on a line of its own
+func writeTemplate(w io.Writer, tmpl *template.Template, item any) error {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, item); err != nil {
+		return fmt.Errorf("failed to execute --template: %w", err)
+	}
+	b.WriteString("\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/jrandolf/mcpr/clients"
+	"github.com/jrandolf/mcpr/config"
+
+	"github.com/spf13/cobra"
+)
+
+var treeCmd = &cobra.Command{
+	Use:   "tree",
+	Short: "Show config layers and which servers reach which clients",
+	Long: `Show the config layers, from the machine-wide system config to the
+project config, with the servers each defines, and every synced client with
+the servers it gets, as a tree.
+
+Servers overridden by a higher layer, disabled, quarantined or missing are
+marked, so complex setups can be checked at a glance.
+
+Examples:
+  mcpr tree
+  mcpr tree --plain`,
+	Args: cobra.NoArgs,
+	RunE: runTree,
+}
+
+// treeNode is a line of a tree and the lines below it
+type treeNode struct {
+	label    string
+	children []treeNode
+}
+
+func runTree(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	writeTree(os.Stdout, configTree(cfg))
+	return nil
+}
+
+// configTree returns the layers of cfg and its synced clients as trees
+func configTree(cfg *config.Config) []treeNode {
+	layers := treeNode{label: "Config layers"}
+	all := cfg.Layers()
+	// Several project layers share a name, so they are told apart by path
+	effective := make(map[string]config.Layer)
+	for _, layer := range all {
+		for _, server := range layer.Servers {
+			effective[server.Name] = layer
+		}
+	}
+	for _, layer := range all {
+		node := treeNode{label: fmt.Sprintf("%s  %s", layer.Name, layer.Path)}
+		if !layer.Exists {
+			node.label += " (not found)"
+		}
+		for _, server := range layer.Servers {
+			label := server.Name
+			if top := effective[server.Name]; top.Path != layer.Path {
+				by := top.Name
+				if by == layer.Name {
+					by = top.Path
+				}
+				label += " (overridden by " + by + ")"
+			}
+			node.children = append(node.children, treeNode{label: label})
+		}
+		layers.children = append(layers.children, node)
+	}
+
+	synced := treeNode{label: "Synced clients"}
+	allServers := disableQuarantined(cfg, cfg.ListServers())
+	byName := make(map[string]config.MCPServer, len(allServers))
+	for _, server := range allServers {
+		byName[server.Name] = server
+	}
+	for _, sc := range cfg.GetSyncedClients() {
+		node := treeNode{label: sc.Name}
+		if client, err := clients.GetClient(sc.Name); err == nil {
+			node.label = client.DisplayName
+			if sc.Local {
+				node.label += " (local)"
+			}
+			if sc.Paused {
+				node.label += " (paused)"
+			}
+			if path, err := client.Path(sc.Local); err == nil {
+				node.label += "  " + path
+			}
+		}
+
+		servers, missing := syncedServers(sc, allServers, byName)
+		for _, server := range servers {
+			label := server.Name
+			switch {
+			case cfg.IsQuarantined(server.Name):
+				label += " (quarantined)"
+			case server.Disabled:
+				label += " (disabled)"
+			}
+			node.children = append(node.children, treeNode{label: label})
+		}
+		for _, name := range missing {
+			node.children = append(node.children, treeNode{label: name + " (missing)"})
+		}
+		synced.children = append(synced.children, node)
+	}
+	if len(synced.children) == 0 {
+		synced.children = []treeNode{{label: "none; use 'mcpr client sync <client-name>' to add one"}}
+	}
+	return []treeNode{layers, synced}
+}
+
+// writeTree writes roots and their descendants to w with branches drawn
+// between them, or only indented with --plain
+func writeTree(w io.Writer, roots []treeNode) {
+	for i, root := range roots {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintln(w, root.label)
+		writeTreeChildren(w, root.children, "")
+	}
+}
+
+// writeTreeChildren writes nodes below a line whose descendants start with
+// prefix
+func writeTreeChildren(w io.Writer, nodes []treeNode, prefix string) {
+	for i, node := range nodes {
+		last := i == len(nodes)-1
+		branch, indent := "├── ", "│   "
+		switch {
+		case plainOutput:
+			branch, indent = "  ", "  "
+		case last:
+			branch, indent = "└── ", "    "
+		}
+		fmt.Fprintln(w, strings.TrimRight(prefix+branch+node.label, " "))
+		writeTreeChildren(w, node.children, prefix+indent)
+	}
+}
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jrandolf/mcpr/config"
+	"github.com/jrandolf/mcpr/netutil"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// noUpdateCheckEnv turns off the check for newer releases when set
+const noUpdateCheckEnv = "MCPR_NO_UPDATE_CHECK"
+
+// updateCheckInterval is how long to wait between checks for newer releases
+const updateCheckInterval = 24 * time.Hour
+
+// updateCheckTimeout bounds how long a command's completion waits for the
+// check
+const updateCheckTimeout = 3 * time.Second
+
+// latestReleaseURL is where the latest release is looked up; a variable so
+// tests can override it
+var latestReleaseURL = "https://api.github.com/repos/jrandolf/mcpr/releases/latest"
+
+// updateCheck is a check for a newer release running alongside a command
+type updateCheck struct {
+	done    chan struct{}
+	latest  string // Version of the latest release
+	url     string // Page of the latest release
+	checked time.Time
+	err     error
+}
+
+// startUpdateCheck starts looking up the latest release in the background
+// while the command cmd runs, unless this is a development build, checks
+// are turned off by $MCPR_NO_UPDATE_CHECK or updates.check, or one was
+// done in the last day. It returns nil if no check was started.
+func startUpdateCheck(ctx context.Context, cfg *config.Config, cmd *cobra.Command) *updateCheck {
+	if version == "dev" || os.Getenv(noUpdateCheckEnv) != "" || !cfg.UpdateCheckEnabled() || !updateNoticeWanted(cmd) {
+		return nil
+	}
+	state, err := config.LoadState()
+	if err != nil || state.Updates != nil && time.Since(state.Updates.Checked) < updateCheckInterval {
+		return nil
+	}
+	client, err := netutil.NewClient(netOptions(cfg))
+	if err != nil {
+		return nil
+	}
+
+	u := &updateCheck{done: make(chan struct{}), checked: time.Now().UTC().Truncate(time.Second)}
+	go func() {
+		defer close(u.done)
+		ctx, cancel := context.WithTimeout(ctx, updateCheckTimeout)
+		defer cancel()
+		u.latest, u.url, u.err = latestRelease(ctx, client)
+	}()
+	return u
+}
+
+// updateNoticeWanted reports whether the notice may follow cmd: not when
+// stderr isn't a terminal, so scripts aren't disturbed, nor after shell
+// completion
+var updateNoticeWanted = func(cmd *cobra.Command) bool {
+	if !term.IsTerminal(int(os.Stderr.Fd())) {
+		return false
+	}
+	for c := cmd; c != nil; c = c.Parent() {
+		if c.Name() == cobra.ShellCompRequestCmd || c.Name() == cobra.ShellCompNoDescRequestCmd || c.Name() == "completion" {
+			return false
+		}
+	}
+	return true
+}
+
+// latestRelease returns the version and page of the latest mcpr release
+func latestRelease(ctx context.Context, client *http.Client) (string, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, latestReleaseURL, nil)
+	if err != nil {
+		return "", "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("User-Agent", "mcpr/"+version)
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("release lookup returned %s", resp.Status)
+	}
+	var release struct {
+		TagName string `json:"tag_name"`
+		HTMLURL string `json:"html_url"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return "", "", fmt.Errorf("failed to parse release: %w", err)
+	}
+	return release.TagName, release.HTMLURL, nil
+}
+
+// finish waits for the check, records it in the state so the next is a
+// day away, and writes a notice to w if the latest release is newer than
+// this build. Failed checks are recorded too, so an offline machine isn't
+// slowed down on every command. It does nothing if u is nil.
+func (u *updateCheck) finish(w io.Writer) {
+	if u == nil {
+		return
+	}
+	<-u.done
+
+	if state, err := config.LoadState(); err == nil {
+		state.Updates = &config.UpdateState{Checked: u.checked, Latest: u.latest}
+		_ = state.Save()
+	}
+	if u.err == nil && newerVersion(u.latest, version) {
+		fmt.Fprintf(w, "\nA new release of mcpr is available: %s %s %s\n%s\n", strings.TrimPrefix(version, "v"), arrow(), strings.TrimPrefix(u.latest, "v"), u.url)
+	}
+}
+
+// newerVersion reports whether release version a is newer than b. Both may
+// start with "v"; pre-release and build suffixes are ignored, and versions
+// that aren't dotted numbers are never newer.
+func newerVersion(a, b string) bool {
+	va, vb := versionNumbers(a), versionNumbers(b)
+	if va == nil || vb == nil {
+		return false
+	}
+	return slices.Compare(va, vb) > 0
+}
+
+// versionNumbers returns the major, minor and patch numbers of version, or
+// nil if it isn't a release version
+func versionNumbers(version string) []int {
+	version = strings.TrimPrefix(version, "v")
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+	parts := strings.Split(version, ".")
+	if len(parts) > 3 {
+		return nil
+	}
+	numbers := make([]int, 3)
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil
+		}
+		numbers[i] = n
+	}
+	return numbers
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// templatePattern matches ${VAR} placeholders and {{...}} templates, which
+// are expanded at sync time
+var templatePattern = regexp.MustCompile(`\$\{[^}]*\}|\{\{.*?\}\}`)
+
+// normalizeServerURL checks the URL of an http server and returns it with
+// the scheme and host lower-cased, a bare "/" path dropped and repeated
+// trailing slashes collapsed. A single trailing slash is kept, since some
+// servers only answer on it. URLs with placeholders are checked with the
+// placeholders filled in and kept as they are.
+func normalizeServerURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	// Digits stand in for placeholders, since they may be ports too
+	filled := templatePattern.ReplaceAllString(raw, "0")
+	if strings.ContainsFunc(filled, unicode.IsSpace) {
+		return "", fmt.Errorf("invalid URL %q: contains spaces", raw)
+	}
+	u, err := url.Parse(filled)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL %q: %w", raw, errors.Unwrap(err))
+	}
+	switch {
+	case u.Scheme == "" || u.Opaque != "":
+		return "", fmt.Errorf("invalid URL %q: expected http:// or https://, as in https://%s", raw, strings.TrimPrefix(raw, "//"))
+	case !strings.EqualFold(u.Scheme, "http") && !strings.EqualFold(u.Scheme, "https"):
+		return "", fmt.Errorf("invalid URL %q: scheme must be http or https, not %s", raw, u.Scheme)
+	case u.Host == "":
+		return "", fmt.Errorf("invalid URL %q: no host", raw)
+	}
+	if filled != raw {
+		return raw, nil
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	if u.Path == "/" {
+		u.Path, u.RawPath = "", ""
+	} else if strings.HasSuffix(u.Path, "//") {
+		u.Path = strings.TrimRight(u.Path, "/") + "/"
+		u.RawPath = ""
+	}
+	return u.String(), nil
+}
+
+// warnInsecureURL warns when an http server outside this machine is reached
+// without TLS
+func warnInsecureURL(raw string) {
+	u, err := url.Parse(templatePattern.ReplaceAllString(raw, "0"))
+	if err != nil || u.Scheme != "http" || isLoopback(u.Hostname()) {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Warning: %s isn't encrypted; requests and headers such as Authorization are sent in the clear\n", raw)
+}
+
+// isLoopback reports whether host is this machine
+func isLoopback(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
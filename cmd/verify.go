@@ -0,0 +1,278 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/jrandolf/mcpr/clients"
+	"github.com/jrandolf/mcpr/config"
+
+	"github.com/spf13/cobra"
+)
+
+var verifyLocal bool
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify [client-name|@group]...",
+	Short: "Check that client configs match the mcpr config and hold no secrets",
+	Long: `Check that client configs hold exactly what 'mcpr client sync' would
+write, and that the project config and local client configs don't contain
+secrets written out literally instead of referenced as ${VAR}.
+
+Synced clients and every local client config in the current directory (such
+as .mcp.json or .cursor/mcp.json) are verified. The command fails if any
+config drifted or leaks a secret, so it can guard commits; see 'mcpr hook
+install'. Name clients or client groups to verify only those.
+
+Examples:
+  mcpr verify
+  mcpr verify --local
+  mcpr verify @editors`,
+	RunE:              runVerify,
+	ValidArgsFunction: completeClientNames,
+}
+
+func init() {
+	verifyCmd.Flags().BoolVar(&verifyLocal, "local", false, "Only verify local client configs and the project config")
+}
+
+func runVerify(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	only, err := cfg.ExpandClients(args)
+	if err != nil {
+		return err
+	}
+	problems, err := verifyClients(os.Stdout, cfg, verifyLocal, only)
+	if err != nil {
+		return err
+	}
+	if problems > 0 {
+		return fmt.Errorf("%d problem(s) found", problems)
+	}
+	return nil
+}
+
+// verifyClients checks synced clients and local client configs for drift
+// and literal secrets, writing the results to w, and returns the number of
+// problems found. With localOnly, global client configs are skipped; with
+// only, clients not named in it are.
+func verifyClients(w io.Writer, cfg *config.Config, localOnly bool, only []string) (int, error) {
+	problems := 0
+
+	// A project config is committed along with the local client configs
+	if layers := cfg.Layers(); layers[len(layers)-1].Name == config.LayerProject {
+		n, err := verifySecrets(w, cfg.Path())
+		if err != nil {
+			return 0, err
+		}
+		problems += n
+	}
+
+	var targets []config.SyncedClient
+	for _, sc := range cfg.GetSyncedClients() {
+		if sc.Local || !localOnly {
+			targets = append(targets, sc)
+		}
+	}
+	names := clients.ListClientNames()
+	sort.Strings(names)
+	for _, name := range names {
+		client, _ := clients.GetClient(name)
+		if !client.SupportsLocal || cfg.GetSyncedClient(name, true) != nil {
+			continue
+		}
+		// Local configs synced on another machine have no sync record here
+		if path, err := client.Path(true); err == nil {
+			if _, err := os.Stat(path); err == nil {
+				targets = append(targets, config.SyncedClient{Name: name, Local: true})
+			}
+		}
+	}
+	if len(only) > 0 {
+		targets = slices.DeleteFunc(targets, func(sc config.SyncedClient) bool {
+			return !slices.Contains(only, sc.Name)
+		})
+	}
+	if len(targets) == 0 {
+		fmt.Fprintln(w, "No client configs to verify")
+		return problems, nil
+	}
+
+	allServers := disableQuarantined(cfg, cfg.ListServers())
+	byName := make(map[string]config.MCPServer, len(allServers))
+	for _, server := range allServers {
+		byName[server.Name] = server
+	}
+
+	for _, sc := range targets {
+		client, err := clients.GetClient(sc.Name)
+		if err != nil {
+			fmt.Fprintf(w, "%s %s: %v\n", failMark(), sc.Name, err)
+			problems++
+			continue
+		}
+		localStr := ""
+		if sc.Local {
+			localStr = " (local)"
+		}
+
+		servers, missing := syncedServers(sc, allServers, byName)
+		if len(missing) > 0 {
+			fmt.Fprintf(w, "%s %s%s: servers %s not found\n", failMark(), client.DisplayName, localStr, strings.Join(missing, ", "))
+			problems++
+			continue
+		}
+		servers, err = orderServers(sc.Name, servers)
+		if err != nil {
+			return 0, err
+		}
+
+		path, ok, err := client.Verify(servers, sc.Local)
+		if err != nil {
+			fmt.Fprintf(w, "%s %s%s: %v\n", failMark(), client.DisplayName, localStr, err)
+			problems++
+			continue
+		}
+		if ok {
+			fmt.Fprintf(w, "%s %s%s: %s\n", okMark(), client.DisplayName, localStr, path)
+		} else {
+			flag := ""
+			if sc.Local {
+				flag = " --local"
+			}
+			fmt.Fprintf(w, "%s %s%s: %s doesn't match the mcpr config; run 'mcpr client sync %s%s'\n", failMark(), client.DisplayName, localStr, path, sc.Name, flag)
+			problems++
+		}
+
+		n, err := verifySchema(w, client, path)
+		if err != nil {
+			return 0, err
+		}
+		problems += n
+
+		if sc.Local {
+			n, err := verifySecrets(w, path)
+			if err != nil {
+				return 0, err
+			}
+			problems += n
+		}
+	}
+	return problems, nil
+}
+
+// verifySchema reports where the client config at path doesn't match the
+// client's schema and returns how many violations there are
+func verifySchema(w io.Writer, client *clients.Client, path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	violations, err := client.CheckSchema(path, data)
+	if err != nil {
+		return 0, err
+	}
+	for _, v := range violations {
+		fmt.Fprintf(w, "%s %s: doesn't match the %s config schema at %s\n", failMark(), path, client.DisplayName, v)
+	}
+	return len(violations), nil
+}
+
+// verifySecrets reports each secret written out literally in the config
+// file at path and returns how many there are
+func verifySecrets(w io.Writer, path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var doc any
+	if err := config.Unmarshal(data, config.FormatForPath(path), &doc); err != nil {
+		return 0, nil
+	}
+
+	found := literalSecrets(doc, "")
+	for _, at := range found {
+		fmt.Fprintf(w, "%s %s: %s holds a literal secret; reference it as ${VAR} instead\n", failMark(), path, at)
+	}
+	return len(found), nil
+}
+
+// secretNameHints mark env vars and headers holding secrets
+var secretNameHints = slices.Concat(secretKeyHints, []string{"key", "auth"})
+
+// literalSecrets returns where in v secrets are written out literally
+// rather than referenced from the environment, as dotted key paths
+func literalSecrets(v any, at string) []string {
+	var found []string
+	switch v := v.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			key := strings.ToLower(k)
+			loc := joinKeyPath(at, k)
+			// Codex names the variables holding secrets, not the secrets
+			if strings.Contains(key, "env_var") || key == "env_http_headers" {
+				continue
+			}
+			if m, ok := v[k].(map[string]any); ok && slices.Contains(secretContainerKeys, key) {
+				names := make([]string, 0, len(m))
+				for name := range m {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+				for _, name := range names {
+					s, ok := m[name].(string)
+					if ok && isLiteralSecret(s) && containsAny(strings.ToLower(name), secretNameHints) {
+						found = append(found, joinKeyPath(loc, name))
+					}
+				}
+				continue
+			}
+			if s, ok := v[k].(string); ok && containsAny(key, secretKeyHints) {
+				if isLiteralSecret(s) {
+					found = append(found, loc)
+				}
+				continue
+			}
+			found = append(found, literalSecrets(v[k], loc)...)
+		}
+	case []any:
+		for i, val := range v {
+			found = append(found, literalSecrets(val, fmt.Sprintf("%s[%d]", at, i))...)
+		}
+	}
+	return found
+}
+
+// isLiteralSecret reports whether a secret value is written out rather than
+// referenced from the environment
+func isLiteralSecret(s string) bool {
+	return s != "" && s != redactedValue &&
+		!strings.Contains(s, "${") && !strings.HasPrefix(s, "$") && !strings.Contains(s, "{env:")
+}
+
+// joinKeyPath appends key to a dotted key path
+func joinKeyPath(at, key string) string {
+	if at == "" {
+		return key
+	}
+	return at + "." + key
+}
@@ -1,37 +1,86 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 )
 
 const configFileName = "mcpr.json"
 
 // MCPServer represents an MCP server configuration
 type MCPServer struct {
-	Name    string            `json:"name"`
-	Type    string            `json:"type"` // "stdio" or "http"
-	Command string            `json:"command,omitempty"`
-	Args    []string          `json:"args,omitempty"`
-	Env     map[string]string `json:"env,omitempty"`
-	URL     string            `json:"url,omitempty"`
-	Headers map[string]string `json:"headers,omitempty"`
+	Name        string                    `json:"name"`
+	Type        string                    `json:"type" jsonschema:"enum=stdio|http|socket"` // "stdio", "http" or "socket"
+	Command     string                    `json:"command,omitempty"`
+	Args        []string                  `json:"args,omitempty"`
+	Env         map[string]string         `json:"env,omitempty"`
+	EnvFile     string                    `json:"envFile,omitempty"` // .env file with more env vars, referenced by clients that support it
+	URL         string                    `json:"url,omitempty"`
+	Headers     map[string]string         `json:"headers,omitempty"`
+	Path        string                    `json:"path,omitempty"`        // Unix socket the server listens on (socket)
+	DependsOn   []string                  `json:"dependsOn,omitempty"`   // Servers that must be present alongside this one
+	Description string                    `json:"description,omitempty"` // What the server is for
+	DocsURL     string                    `json:"docsUrl,omitempty"`     // Where to read more about the server
+	NoShim      bool                      `json:"noShim,omitempty"`      // Don't wrap .cmd launchers like npx in "cmd /c" on Windows
+	Timeout     int                       `json:"timeout,omitempty"`     // Request timeout in seconds, for clients that support one
+	Trust       bool                      `json:"trust,omitempty"`       // Skip tool call confirmations, for clients that support it
+	AlwaysAllow []string                  `json:"alwaysAllow,omitempty"` // Tools to run without confirmation, for clients that support it
+	Disabled    bool                      `json:"disabled,omitempty"`    // Keep configured but turned off; left out of clients without a disabled flag
+	Sandbox     *Sandbox                  `json:"sandbox,omitempty"`     // Run the command in a sandbox with only these permissions (stdio)
+	Platforms   map[string]ServerOverride `json:"platforms,omitempty"`   // Overrides by operating system ("darwin", "linux", "windows"), applied at sync time
+	Locked      bool                      `json:"locked,omitempty"`      // Refuse to remove or replace the server without --unlock
+	Provenance  *Provenance               `json:"provenance,omitempty"`  // Where the server came from, recorded when it was added
+}
+
+// Sandbox is the permission profile of a sandboxed stdio server. System
+// directories are readable; everything else must be listed.
+type Sandbox struct {
+	ReadOnly  []string `json:"readOnly,omitempty"`  // Extra paths the server may read
+	ReadWrite []string `json:"readWrite,omitempty"` // Paths the server may read and write
+	Network   bool     `json:"network,omitempty"`   // Allow network access
 }
 
 // SyncedClient represents a client that has been synced
 type SyncedClient struct {
-	Name    string   `json:"name"`              // Client name (e.g., "claude-desktop")
-	Local   bool     `json:"local"`             // Whether synced to local config
-	Servers []string `json:"servers,omitempty"` // Specific servers synced (empty = all)
+	Name     string    `json:"name"`              // Client name (e.g., "claude-desktop")
+	Local    bool      `json:"local"`             // Whether synced to local config
+	Servers  []string  `json:"servers,omitempty"` // Specific servers synced (empty = all)
+	Exclude  []string  `json:"exclude,omitempty"` // Servers never synced to this client
+	LastSync time.Time `json:"lastSync,omitzero"` // When mcpr last wrote the client config
+	Hash     string    `json:"hash,omitempty"`    // SHA-256 of the client config as last written
+	Paused   bool      `json:"paused,omitempty"`  // Left out of resyncs until resumed
 }
 
-// Config holds all configured MCP servers
+// Config holds all configured MCP servers. Its methods are safe for
+// concurrent use; the exported fields must not be accessed directly while
+// other goroutines use the config.
 type Config struct {
-	Servers       []MCPServer    `json:"servers"`
-	SyncedClients []SyncedClient `json:"synced_clients,omitempty"`
-	path          string         // path where config was loaded from or will be saved to
+	Schema        string                     `json:"$schema,omitempty"` // JSON Schema reference for editors
+	Servers       []MCPServer                `json:"servers"`
+	SyncedClients []SyncedClient             `json:"-"`                      // Kept in the state file
+	Aliases       map[string]string          `json:"aliases,omitempty"`      // Command aliases (e.g., "s" -> "client sync")
+	ClientGroups  map[string][]string        `json:"clientGroups,omitempty"` // Named lists of clients, referenced as @name
+	FileMode      string                     `json:"fileMode,omitempty"`     // Octal mode for newly written config files, e.g. "0600"
+	Network       *Network                   `json:"network,omitempty"`      // Proxy, CA and timeout settings for network requests
+	Retry         *Retry                     `json:"retry,omitempty"`        // Retrying after transient failures
+	Updates       *Updates                   `json:"updates,omitempty"`      // Checking for newer mcpr releases
+	Machines      map[string]MachineOverride `json:"machines,omitempty"`     // Per-machine overrides, by hostname or $MCPR_MACHINE
+	Locked        bool                       `json:"locked,omitempty"`       // Refuse changes to the config without --unlock
+	Health        map[string]HealthRecord    `json:"-"`                      // Health check history by server name, kept in the state file
+	path          string                     // path where config was loaded from or will be saved to
+	layers        []Layer                    // lower-precedence layers merged below this config
+	raw           []byte                     // file contents as last read or written, for format-preserving saves
+	modTime       time.Time                  // modification time of the file as last read or written
+	unlocked      bool                       // locked servers and settings may be changed
+	mu            sync.RWMutex
 }
 
 // findConfigInParents searches for config file in current and parent directories
@@ -42,9 +91,8 @@ func findConfigInParents() (string, bool) {
 	}
 
 	for {
-		configPath := filepath.Join(dir, configFileName)
-		if _, err := os.Stat(configPath); err == nil {
-			return configPath, true
+		if path, ok := projectConfigIn(dir); ok {
+			return path, true
 		}
 
 		parent := filepath.Dir(dir)
@@ -57,19 +105,48 @@ func findConfigInParents() (string, bool) {
 	return "", false
 }
 
-// getGlobalConfigPath returns the global config path at ~/.config/mcpr/config.json
+// projectConfigIn returns the project config in dir, if there is one
+func projectConfigIn(dir string) (string, bool) {
+	for _, name := range configFileNames {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// ProjectDir returns the directory of the project config found in the
+// current directory or its parents, where local client configs belong
+func ProjectDir() (string, bool) {
+	path, found := findConfigInParents()
+	if !found {
+		return "", false
+	}
+	return filepath.Dir(path), true
+}
+
+// getGlobalConfigPath returns the global config path at ~/.config/mcpr/config.json,
+// or an existing config.yaml, config.yml or config.toml next to it
 func getGlobalConfigPath() (string, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return "", fmt.Errorf("failed to get home directory: %w", err)
 	}
-	return filepath.Join(home, ".config", "mcpr", "config.json"), nil
+	dir := filepath.Join(home, ".config", "mcpr")
+	for _, name := range globalConfigFileNames {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return filepath.Join(dir, globalConfigFileNames[0]), nil
 }
 
 // GetConfigPath returns the path to the mcpr config file
 // It searches in the following order:
-// 1. Current directory and parent directories for mcpr.json
-// 2. ~/.config/mcpr/config.json
+// 1. Current directory and parent directories for mcpr.json (or .yaml, .yml, .toml)
+// 2. ~/.config/mcpr/config.json (or config.yaml, config.yml, config.toml)
 func GetConfigPath() (string, error) {
 	// First check parent directories
 	if path, found := findConfigInParents(); found {
@@ -80,6 +157,32 @@ func GetConfigPath() (string, error) {
 	return getGlobalConfigPath()
 }
 
+// ExplainConfigPath returns the active config path together with a
+// human-readable explanation of how it was discovered
+func ExplainConfigPath() (string, string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	if path, found := findConfigInParents(); found {
+		if filepath.Dir(path) == cwd {
+			return path, fmt.Sprintf("found %s in the current directory", filepath.Base(path)), nil
+		}
+		return path, fmt.Sprintf("found %s in a parent of the current directory (%s)", filepath.Base(path), cwd), nil
+	}
+
+	path, err := getGlobalConfigPath()
+	if err != nil {
+		return "", "", err
+	}
+	reason := fmt.Sprintf("no %s in %s or its parents, using the global config", configFileName, cwd)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		reason += " (not created yet)"
+	}
+	return path, reason, nil
+}
+
 // GetWriteConfigPath returns the path where new config should be written
 // Prefers local directory if mcpr.json exists, otherwise uses global config
 func GetWriteConfigPath(preferLocal bool) (string, error) {
@@ -105,52 +208,119 @@ func Load() (*Config, error) {
 	if os.IsNotExist(err) {
 		// Return empty config, will be saved to global path
 		globalPath, _ := getGlobalConfigPath()
-		return &Config{Servers: []MCPServer{}, path: globalPath}, nil
+		cfg := &Config{Servers: []MCPServer{}, path: globalPath}
+		if err := cfg.loadState(nil, FormatJSON); err != nil {
+			return nil, err
+		}
+		// Without a config, modes are chosen by content and writes are
+		// retried by default again
+		if err := cfg.applyFileMode(); err != nil {
+			return nil, err
+		}
+		if err := cfg.applyRetry(); err != nil {
+			return nil, err
+		}
+		if err := cfg.loadLayers(); err != nil {
+			return nil, err
+		}
+		return cfg, nil
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config: %w", err)
 	}
 
 	var cfg Config
-	if err := json.Unmarshal(data, &cfg); err != nil {
-		return nil, fmt.Errorf("failed to parse config: %w", err)
+	if err := decodeConfig(data, FormatForPath(path), &cfg); err != nil {
+		return nil, newParseError(path, data, FormatForPath(path), err)
 	}
 	cfg.path = path
+	cfg.raw = data
+	cfg.modTime = fileModTime(path)
+	if err := cfg.loadState(data, FormatForPath(path)); err != nil {
+		return nil, err
+	}
+	if err := cfg.applyFileMode(); err != nil {
+		return nil, err
+	}
+	if err := cfg.applyRetry(); err != nil {
+		return nil, err
+	}
+	if err := cfg.loadLayers(); err != nil {
+		return nil, err
+	}
 
 	return &cfg, nil
 }
 
-// LoadFromPath reads the config from a specific path
+// LoadFromPath reads the config from a specific path, merged with the
+// system layer
 func LoadFromPath(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if os.IsNotExist(err) {
-		return &Config{Servers: []MCPServer{}, path: path}, nil
+		cfg := &Config{Servers: []MCPServer{}, path: path}
+		if err := cfg.loadState(nil, FormatJSON); err != nil {
+			return nil, err
+		}
+		// A config without fileMode chooses modes by content again
+		if err := cfg.applyFileMode(); err != nil {
+			return nil, err
+		}
+		if err := cfg.applyRetry(); err != nil {
+			return nil, err
+		}
+		if err := cfg.loadLayers(); err != nil {
+			return nil, err
+		}
+		return cfg, nil
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config: %w", err)
 	}
 
 	var cfg Config
-	if err := json.Unmarshal(data, &cfg); err != nil {
-		return nil, fmt.Errorf("failed to parse config: %w", err)
+	if err := decodeConfig(data, FormatForPath(path), &cfg); err != nil {
+		return nil, newParseError(path, data, FormatForPath(path), err)
 	}
 	cfg.path = path
+	cfg.raw = data
+	cfg.modTime = fileModTime(path)
+	if err := cfg.loadState(data, FormatForPath(path)); err != nil {
+		return nil, err
+	}
+	if err := cfg.applyFileMode(); err != nil {
+		return nil, err
+	}
+	if err := cfg.applyRetry(); err != nil {
+		return nil, err
+	}
+	if err := cfg.loadLayers(); err != nil {
+		return nil, err
+	}
 
 	return &cfg, nil
 }
 
 // Path returns the path where this config was loaded from or will be saved to
 func (c *Config) Path() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.path
 }
 
 // SetPath sets the path where this config will be saved
 func (c *Config) SetPath(path string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.path = path
 }
 
-// Save writes the config to disk
+// Save writes the config to disk in the format implied by its extension.
+// When a JSON config was read from a file, comments and formatting of
+// unchanged parts of the file are preserved.
 func (c *Config) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	if c.path == "" {
 		path, err := getGlobalConfigPath()
 		if err != nil {
@@ -159,6 +329,12 @@ func (c *Config) Save() error {
 		c.path = path
 	}
 
+	// State goes first, so a failure can't lose sync records migrated out
+	// of the config file
+	if err := c.saveState(true); err != nil {
+		return err
+	}
+
 	// Ensure directory exists
 	dir := filepath.Dir(c.path)
 	if err := os.MkdirAll(dir, 0755); err != nil {
@@ -170,15 +346,158 @@ func (c *Config) Save() error {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 
-	if err := os.WriteFile(c.path, data, 0644); err != nil {
+	format := FormatForPath(c.path)
+	if format == FormatJSON {
+		// Keep comments, key order and formatting of hand-edited files
+		if c.raw != nil {
+			data = PreserveFormatting(c.raw, data)
+		}
+	} else {
+		data, err = fromJSON(data, format)
+		if err != nil {
+			return fmt.Errorf("failed to marshal config: %w", err)
+		}
+	}
+
+	// The version being replaced parsed when it was read, so it can be
+	// returned to by 'mcpr config repair'. A failed backup doesn't stop the
+	// save.
+	if c.raw != nil && !bytes.Equal(c.raw, data) {
+		_ = keepBackup(c.path, c.raw)
+	}
+	if err := WriteFile(c.path, data, HasSecrets(c.Servers)); err != nil {
 		return fmt.Errorf("failed to write config: %w", err)
 	}
+	c.raw = data
+	c.modTime = fileModTime(c.path)
 
 	return nil
 }
 
+// WriteConfigFile replaces the config file at path with data that didn't
+// come from Save, such as a pulled or hand-edited config. As Save does, it
+// keeps what the file held as a backup for 'mcpr config repair' if it
+// parsed, and makes the file private if data holds secrets.
+func WriteConfigFile(path string, data []byte) error {
+	format := FormatForPath(path)
+	if old, err := os.ReadFile(path); err == nil && !bytes.Equal(old, data) {
+		var parsed Config
+		if decodeConfig(old, format, &parsed) == nil {
+			// A failed backup doesn't stop the write
+			_ = keepBackup(path, old)
+		}
+	}
+	var cfg Config
+	_ = decodeConfig(data, format, &cfg)
+	return WriteFile(path, data, HasSecrets(cfg.Servers))
+}
+
+// copySettings copies every field of from kept in the config file into c:
+// the exported fields other than those kept in the state file
+func (c *Config) copySettings(from *Config) {
+	dst, src := reflect.ValueOf(c).Elem(), reflect.ValueOf(from).Elem()
+	for i := range dst.NumField() {
+		field := dst.Type().Field(i)
+		if field.IsExported() && field.Tag.Get("json") != "-" {
+			dst.Field(i).Set(src.Field(i))
+		}
+	}
+}
+
+// MergeSettings copies the settings of from, the fields kept in the config
+// file other than its schema, servers and aliases, into c. Settings c has
+// already set to something else are kept unless force is set. It returns the
+// JSON names of the settings copied and of those kept.
+func (c *Config) MergeSettings(from *Config, force bool) (copied, kept []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	dst, src := reflect.ValueOf(c).Elem(), reflect.ValueOf(from).Elem()
+	for i := range dst.NumField() {
+		field := dst.Type().Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		switch {
+		case !field.IsExported() || name == "-" || name == "$schema" || name == "servers" || name == "aliases":
+			continue
+		case src.Field(i).IsZero() || reflect.DeepEqual(dst.Field(i).Interface(), src.Field(i).Interface()):
+			continue
+		case !dst.Field(i).IsZero() && !force:
+			kept = append(kept, name)
+			continue
+		}
+		dst.Field(i).Set(src.Field(i))
+		copied = append(copied, name)
+	}
+	return copied, kept
+}
+
+// Reload re-reads the config if its file changed on disk since it was last
+// read or written, and reports whether it did. A file with the same
+// modification time is assumed unchanged; otherwise its content is compared
+// with what was last read or written.
+func (c *Config) Reload() (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	info, err := os.Stat(c.path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read config: %w", err)
+	}
+	if c.raw != nil && info.ModTime().Equal(c.modTime) {
+		return false, nil
+	}
+
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read config: %w", err)
+	}
+	c.modTime = info.ModTime()
+	if c.raw != nil && bytes.Equal(data, c.raw) {
+		return false, nil
+	}
+
+	var fresh Config
+	if err := decodeConfig(data, FormatForPath(c.path), &fresh); err != nil {
+		return false, newParseError(c.path, data, FormatForPath(c.path), err)
+	}
+	c.copySettings(&fresh)
+	c.raw = data
+	if err := c.loadState(data, FormatForPath(c.path)); err != nil {
+		return false, err
+	}
+	if err := c.applyFileMode(); err != nil {
+		return false, err
+	}
+	if err := c.applyRetry(); err != nil {
+		return false, err
+	}
+	if err := c.loadLayers(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// fileModTime returns the modification time of a file, or the zero time if
+// it can't be read
+func fileModTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
 // AddServer adds a new MCP server to the config
 func (c *Config) AddServer(server MCPServer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.checkLocked(); err != nil {
+		return err
+	}
 	for _, s := range c.Servers {
 		if s.Name == server.Name {
 			return fmt.Errorf("server %q already exists", server.Name)
@@ -190,18 +509,54 @@ func (c *Config) AddServer(server MCPServer) error {
 
 // RemoveServer removes an MCP server from the config by name
 func (c *Config) RemoveServer(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.checkLocked(name); err != nil {
+		return err
+	}
 	for i, s := range c.Servers {
 		if s.Name == name {
 			c.Servers = append(c.Servers[:i], c.Servers[i+1:]...)
+			delete(c.Health, name)
 			return nil
 		}
 	}
+	for _, layer := range c.layers {
+		for _, s := range layer.Servers {
+			if s.Name == name {
+				return fmt.Errorf("server %q is provided by the %s config (%s) and cannot be removed here", name, layer.Name, layer.Path)
+			}
+		}
+	}
 	return fmt.Errorf("server %q not found", name)
 }
 
-// GetServer retrieves a server by name
+// ReplaceServer replaces the server of the same name where it is in the
+// config, keeping the order of servers, or adds it if the config has none.
+// A server of a lower layer is shadowed rather than replaced.
+func (c *Config) ReplaceServer(server MCPServer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.checkLocked(server.Name); err != nil {
+		return err
+	}
+	if i := slices.IndexFunc(c.Servers, func(s MCPServer) bool { return s.Name == server.Name }); i >= 0 {
+		c.Servers[i] = server
+		return nil
+	}
+	c.Servers = append(c.Servers, server)
+	return nil
+}
+
+// GetServer retrieves a server by name, including servers inherited from
+// lower config layers
 func (c *Config) GetServer(name string) (*MCPServer, error) {
-	for _, s := range c.Servers {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, s := range c.listServers() {
 		if s.Name == name {
 			return &s, nil
 		}
@@ -209,13 +564,36 @@ func (c *Config) GetServer(name string) (*MCPServer, error) {
 	return nil, fmt.Errorf("server %q not found", name)
 }
 
-// ListServers returns all configured servers
+// HasServer reports whether the config itself defines a server named name,
+// leaving out servers inherited from lower config layers
+func (c *Config) HasServer(name string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return slices.ContainsFunc(c.Servers, func(s MCPServer) bool { return s.Name == name })
+}
+
+// ListServers returns all configured servers: servers inherited from lower
+// config layers followed by the servers of this config
 func (c *Config) ListServers() []MCPServer {
-	return c.Servers
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.listServers()
+}
+
+// listServers is ListServers for callers holding the lock. The result is a
+// copy, so callers can't race with later changes to the config.
+func (c *Config) listServers() []MCPServer {
+	inherited := c.inheritedServers()
+	servers := make([]MCPServer, 0, len(inherited)+len(c.Servers))
+	servers = append(servers, inherited...)
+	return applyMachineOverrides(append(servers, c.Servers...), c.Machines)
 }
 
 // AddSyncedClient adds or updates a synced client record
 func (c *Config) AddSyncedClient(clientName string, local bool, servers []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	// Check if client already exists and update it
 	for i, sc := range c.SyncedClients {
 		if sc.Name == clientName && sc.Local == local {
@@ -231,8 +609,37 @@ func (c *Config) AddSyncedClient(clientName string, local bool, servers []string
 	})
 }
 
+// SetSyncedClientExclude sets the exclude list of an existing synced client record
+func (c *Config) SetSyncedClientExclude(clientName string, local bool, exclude []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, sc := range c.SyncedClients {
+		if sc.Name == clientName && sc.Local == local {
+			c.SyncedClients[i].Exclude = exclude
+			return
+		}
+	}
+}
+
+// SetSyncedClientPaused pauses or resumes an existing synced client record
+func (c *Config) SetSyncedClientPaused(clientName string, local bool, paused bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, sc := range c.SyncedClients {
+		if sc.Name == clientName && sc.Local == local {
+			c.SyncedClients[i].Paused = paused
+			return
+		}
+	}
+}
+
 // RemoveSyncedClient removes a synced client record
 func (c *Config) RemoveSyncedClient(clientName string, local bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	for i, sc := range c.SyncedClients {
 		if sc.Name == clientName && sc.Local == local {
 			c.SyncedClients = append(c.SyncedClients[:i], c.SyncedClients[i+1:]...)
@@ -243,11 +650,16 @@ func (c *Config) RemoveSyncedClient(clientName string, local bool) {
 
 // GetSyncedClients returns all synced client records
 func (c *Config) GetSyncedClients() []SyncedClient {
-	return c.SyncedClients
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return slices.Clone(c.SyncedClients)
 }
 
 // GetSyncedClient returns a specific synced client by name and local flag
 func (c *Config) GetSyncedClient(clientName string, local bool) *SyncedClient {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
 	for _, sc := range c.SyncedClients {
 		if sc.Name == clientName && sc.Local == local {
 			return &sc
@@ -255,3 +667,26 @@ func (c *Config) GetSyncedClient(clientName string, local bool) *SyncedClient {
 	}
 	return nil
 }
+
+// SetAlias adds or replaces a command alias
+func (c *Config) SetAlias(name, expansion string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.Aliases == nil {
+		c.Aliases = make(map[string]string)
+	}
+	c.Aliases[name] = expansion
+}
+
+// RemoveAlias removes a command alias by name
+func (c *Config) RemoveAlias(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.Aliases[name]; !ok {
+		return fmt.Errorf("alias %q not found", name)
+	}
+	delete(c.Aliases, name)
+	return nil
+}
@@ -1,9 +1,14 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestMCPServer(t *testing.T) {
@@ -884,3 +889,220 @@ func TestConfig_SyncedClients_SaveAndLoad(t *testing.T) {
 		t.Errorf("expected cursor Servers to be ['server1'], got %v", cursor.Servers)
 	}
 }
+
+func TestConfig_SetSyncedClientExclude(t *testing.T) {
+	cfg := &Config{}
+	cfg.AddSyncedClient("zed", false, nil)
+
+	cfg.SetSyncedClientExclude("zed", false, []string{"playwright"})
+
+	sc := cfg.GetSyncedClient("zed", false)
+	if sc == nil {
+		t.Fatal("expected to find zed")
+	}
+	if len(sc.Exclude) != 1 || sc.Exclude[0] != "playwright" {
+		t.Errorf("expected Exclude to be ['playwright'], got %v", sc.Exclude)
+	}
+
+	// Resetting clears the list
+	cfg.SetSyncedClientExclude("zed", false, nil)
+	if sc := cfg.GetSyncedClient("zed", false); sc.Exclude != nil {
+		t.Errorf("expected nil Exclude, got %v", sc.Exclude)
+	}
+}
+
+func TestConfig_SetSyncedClientExclude_NotFound(t *testing.T) {
+	cfg := &Config{}
+
+	// Should not panic or create a record
+	cfg.SetSyncedClientExclude("zed", false, []string{"playwright"})
+
+	if len(cfg.SyncedClients) != 0 {
+		t.Errorf("expected 0 synced clients, got %d", len(cfg.SyncedClients))
+	}
+}
+
+func TestConfig_SetSyncedClientPaused(t *testing.T) {
+	cfg := &Config{}
+	cfg.AddSyncedClient("cursor", false, []string{"fs"})
+	cfg.SetSyncedClientExclude("cursor", false, []string{"git"})
+
+	cfg.SetSyncedClientPaused("cursor", false, true)
+	sc := cfg.GetSyncedClient("cursor", false)
+	if sc == nil || !sc.Paused {
+		t.Fatalf("expected cursor to be paused, got %+v", sc)
+	}
+
+	// Pausing keeps the filters, and a resync of the record keeps it paused
+	cfg.AddSyncedClient("cursor", false, []string{"fs"})
+	if sc := cfg.GetSyncedClient("cursor", false); !sc.Paused || len(sc.Servers) != 1 || len(sc.Exclude) != 1 {
+		t.Errorf("expected the paused record to keep its filters, got %+v", sc)
+	}
+
+	cfg.SetSyncedClientPaused("cursor", false, false)
+	if sc := cfg.GetSyncedClient("cursor", false); sc.Paused {
+		t.Error("expected cursor to be resumed")
+	}
+	cfg.SetSyncedClientPaused("zed", false, true)
+	if len(cfg.SyncedClients) != 1 {
+		t.Errorf("expected no record to be created, got %+v", cfg.SyncedClients)
+	}
+}
+
+func TestConfig_Aliases(t *testing.T) {
+	cfg := &Config{}
+
+	cfg.SetAlias("s", "client sync")
+	if cfg.Aliases["s"] != "client sync" {
+		t.Errorf("expected alias 's' to be 'client sync', got %q", cfg.Aliases["s"])
+	}
+
+	if err := cfg.RemoveAlias("s"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := cfg.Aliases["s"]; ok {
+		t.Error("expected alias 's' to be removed")
+	}
+
+	if err := cfg.RemoveAlias("s"); err == nil {
+		t.Error("expected error removing nonexistent alias, got nil")
+	}
+}
+
+func TestExplainConfigPath_Local(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "mcpr-test-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	tempDir, err = filepath.EvalSymlinks(tempDir)
+	if err != nil {
+		t.Fatalf("failed to resolve symlinks: %v", err)
+	}
+
+	nestedDir := filepath.Join(tempDir, "sub")
+	if err := os.MkdirAll(nestedDir, 0755); err != nil {
+		t.Fatalf("failed to create nested dir: %v", err)
+	}
+	configPath := filepath.Join(tempDir, configFileName)
+	if err := os.WriteFile(configPath, []byte(`{"servers":[]}`), 0644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	if err := os.Chdir(nestedDir); err != nil {
+		t.Fatalf("failed to change directory: %v", err)
+	}
+
+	path, reason, err := ExplainConfigPath()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if path != configPath {
+		t.Errorf("expected path %q, got %q", configPath, path)
+	}
+	if !strings.Contains(reason, "parent") {
+		t.Errorf("expected reason to mention a parent directory, got %q", reason)
+	}
+}
+
+func TestConfig_Reload(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	os.WriteFile(configPath, []byte(`{"servers": [{"name": "a", "type": "stdio", "command": "a"}]}`), 0o644)
+
+	cfg, err := LoadFromPath(configPath)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	if changed, err := cfg.Reload(); err != nil || changed {
+		t.Fatalf("expected unchanged config, got changed=%v err=%v", changed, err)
+	}
+
+	os.WriteFile(configPath, []byte(`{"servers": [{"name": "b", "type": "stdio", "command": "b"}]}`), 0o644)
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(configPath, later, later)
+
+	changed, err := cfg.Reload()
+	if err != nil || !changed {
+		t.Fatalf("expected changed config, got changed=%v err=%v", changed, err)
+	}
+	if _, err := cfg.GetServer("b"); err != nil {
+		t.Errorf("expected reloaded server b: %v", err)
+	}
+
+	// Touching the file without changing it isn't a change
+	later = later.Add(time.Minute)
+	os.Chtimes(configPath, later, later)
+	if changed, err := cfg.Reload(); err != nil || changed {
+		t.Errorf("expected touched config to be unchanged, got changed=%v err=%v", changed, err)
+	}
+}
+
+func TestConfig_ReloadLocked(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	os.WriteFile(configPath, []byte(`{"servers": []}`), 0o644)
+	cfg, err := LoadFromPath(configPath)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	later := time.Now()
+	for _, locked := range []bool{true, false} {
+		os.WriteFile(configPath, []byte(fmt.Sprintf(`{"locked": %v, "servers": []}`, locked)), 0o644)
+		later = later.Add(time.Minute)
+		os.Chtimes(configPath, later, later)
+		if changed, err := cfg.Reload(); err != nil || !changed {
+			t.Fatalf("expected changed config, got changed=%v err=%v", changed, err)
+		}
+		if err := cfg.CheckLocked(); (err != nil) != locked {
+			t.Errorf("expected locked=%v after reloading, got %v", locked, err)
+		}
+	}
+}
+
+func TestConfig_MergeSettings(t *testing.T) {
+	cfg := &Config{FileMode: "0600", Aliases: map[string]string{"s": "client sync"}}
+	from := &Config{
+		Schema:   "schema.json",
+		Servers:  []MCPServer{{Name: "fs", Type: "stdio", Command: "npx"}},
+		Aliases:  map[string]string{"l": "list"},
+		FileMode: "0644",
+		Locked:   true,
+	}
+
+	copied, kept := cfg.MergeSettings(from, false)
+	if !slices.Equal(copied, []string{"locked"}) || !slices.Equal(kept, []string{"fileMode"}) {
+		t.Errorf("expected locked copied and fileMode kept, got %v and %v", copied, kept)
+	}
+	if cfg.Schema != "" || len(cfg.Servers) != 0 || len(cfg.Aliases) != 1 || cfg.FileMode != "0600" || !cfg.Locked {
+		t.Errorf("unexpected config after merging: %+v", cfg)
+	}
+
+	if copied, kept := cfg.MergeSettings(from, true); !slices.Equal(copied, []string{"fileMode"}) || kept != nil || cfg.FileMode != "0644" {
+		t.Errorf("expected fileMode to be replaced, got %v and %v", copied, kept)
+	}
+}
+
+func TestConfig_ConcurrentUse(t *testing.T) {
+	cfg := &Config{}
+	cfg.SetPath(filepath.Join(t.TempDir(), "config.json"))
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Go(func() {
+			name := fmt.Sprintf("server-%d", i)
+			cfg.AddServer(MCPServer{Name: name, Type: "stdio", Command: "npx"})
+			cfg.AddSyncedClient("cursor", false, nil)
+			cfg.ListServers()
+			cfg.GetServer(name)
+			cfg.Save()
+		})
+	}
+	wg.Wait()
+
+	if got := len(cfg.ListServers()); got != 8 {
+		t.Errorf("expected 8 servers, got %d", got)
+	}
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"bytes"
+	"regexp"
+)
+
+// Credential is a value that looks like a live credential
+type Credential struct {
+	Kind  string // What it looks like, e.g. "GitHub token"
+	Value string
+	Line  int // 1-based
+}
+
+// credentialPatterns match the formats of well-known credentials. They are
+// specific enough that placeholders and ${VAR} references don't match.
+var credentialPatterns = []struct {
+	kind string
+	re   *regexp.Regexp
+}{
+	{"AWS access key", regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"GitHub token", regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`)},
+	{"GitHub token", regexp.MustCompile(`\bgithub_pat_[A-Za-z0-9_]{40,}\b`)},
+	{"GitLab token", regexp.MustCompile(`\bglpat-[A-Za-z0-9_-]{20,}\b`)},
+	{"Slack token", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{"Stripe live key", regexp.MustCompile(`\b[rs]k_live_[A-Za-z0-9]{20,}\b`)},
+	{"Google API key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{"private key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`)},
+}
+
+// FindCredentials returns the values in data that look like live
+// credentials, such as AWS access keys and GitHub tokens, in order
+func FindCredentials(data []byte) []Credential {
+	var found []Credential
+	for i, line := range bytes.Split(data, []byte("\n")) {
+		for _, p := range credentialPatterns {
+			for _, match := range p.re.FindAll(line, -1) {
+				found = append(found, Credential{Kind: p.kind, Value: string(match), Line: i + 1})
+			}
+		}
+	}
+	return found
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SortByDependencies orders servers so that every server comes after the
+// servers it depends on. Servers without dependency constraints keep their
+// original relative order. Dependencies outside the given set are ignored.
+func SortByDependencies(servers []MCPServer) ([]MCPServer, error) {
+	index := make(map[string]int, len(servers))
+	for i, s := range servers {
+		index[s.Name] = i
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make([]int, len(servers))
+	sorted := make([]MCPServer, 0, len(servers))
+
+	var visit func(i int, path []string) error
+	visit = func(i int, path []string) error {
+		switch state[i] {
+		case done:
+			return nil
+		case visiting:
+			return fmt.Errorf("dependency cycle: %s", strings.Join(append(path, servers[i].Name), " -> "))
+		}
+		state[i] = visiting
+		for _, dep := range servers[i].DependsOn {
+			j, ok := index[dep]
+			if !ok {
+				continue
+			}
+			if err := visit(j, append(path, servers[i].Name)); err != nil {
+				return err
+			}
+		}
+		state[i] = done
+		sorted = append(sorted, servers[i])
+		return nil
+	}
+
+	for i := range servers {
+		if err := visit(i, nil); err != nil {
+			return nil, err
+		}
+	}
+	return sorted, nil
+}
+
+// MissingDependencies returns, for each server in the set, the dependencies
+// that are not part of the set
+func MissingDependencies(servers []MCPServer) map[string][]string {
+	present := make(map[string]bool, len(servers))
+	for _, s := range servers {
+		present[s.Name] = true
+	}
+
+	missing := make(map[string][]string)
+	for _, s := range servers {
+		for _, dep := range s.DependsOn {
+			if !present[dep] {
+				missing[s.Name] = append(missing[s.Name], dep)
+			}
+		}
+	}
+	return missing
+}
+
+// Dependents returns the names of servers that depend on the named server
+func (c *Config) Dependents(name string) []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var dependents []string
+	for _, s := range c.listServers() {
+		for _, dep := range s.DependsOn {
+			if dep == name {
+				dependents = append(dependents, s.Name)
+				break
+			}
+		}
+	}
+	return dependents
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func serverNames(servers []MCPServer) []string {
+	names := make([]string, len(servers))
+	for i, s := range servers {
+		names[i] = s.Name
+	}
+	return names
+}
+
+func TestSortByDependencies(t *testing.T) {
+	servers := []MCPServer{
+		{Name: "router", DependsOn: []string{"search", "files"}},
+		{Name: "files"},
+		{Name: "search", DependsOn: []string{"files"}},
+		{Name: "git"},
+	}
+
+	sorted, err := SortByDependencies(servers)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := strings.Join(serverNames(sorted), ",")
+	expected := "files,search,router,git"
+	if got != expected {
+		t.Errorf("expected order %q, got %q", expected, got)
+	}
+}
+
+func TestSortByDependencies_PreservesOrderWithoutDeps(t *testing.T) {
+	servers := []MCPServer{{Name: "c"}, {Name: "a"}, {Name: "b"}}
+
+	sorted, err := SortByDependencies(servers)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := strings.Join(serverNames(sorted), ",")
+	if got != "c,a,b" {
+		t.Errorf("expected order 'c,a,b', got %q", got)
+	}
+}
+
+func TestSortByDependencies_IgnoresMissing(t *testing.T) {
+	servers := []MCPServer{
+		{Name: "router", DependsOn: []string{"missing"}},
+	}
+
+	sorted, err := SortByDependencies(servers)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sorted) != 1 {
+		t.Errorf("expected 1 server, got %d", len(sorted))
+	}
+}
+
+func TestSortByDependencies_Cycle(t *testing.T) {
+	servers := []MCPServer{
+		{Name: "a", DependsOn: []string{"b"}},
+		{Name: "b", DependsOn: []string{"a"}},
+	}
+
+	_, err := SortByDependencies(servers)
+	if err == nil {
+		t.Fatal("expected error for dependency cycle, got nil")
+	}
+	if !strings.Contains(err.Error(), "a -> b -> a") {
+		t.Errorf("expected cycle path in error, got %q", err.Error())
+	}
+}
+
+func TestMissingDependencies(t *testing.T) {
+	servers := []MCPServer{
+		{Name: "router", DependsOn: []string{"search", "files"}},
+		{Name: "files"},
+	}
+
+	missing := MissingDependencies(servers)
+	if len(missing) != 1 {
+		t.Fatalf("expected 1 server with missing deps, got %d", len(missing))
+	}
+	if deps := missing["router"]; len(deps) != 1 || deps[0] != "search" {
+		t.Errorf("expected router to miss ['search'], got %v", deps)
+	}
+}
+
+func TestConfig_Dependents(t *testing.T) {
+	cfg := &Config{
+		Servers: []MCPServer{
+			{Name: "files"},
+			{Name: "search", DependsOn: []string{"files"}},
+			{Name: "router", DependsOn: []string{"search", "files"}},
+		},
+	}
+
+	dependents := cfg.Dependents("files")
+	if strings.Join(dependents, ",") != "search,router" {
+		t.Errorf("expected dependents 'search,router', got %v", dependents)
+	}
+
+	if got := cfg.Dependents("router"); len(got) != 0 {
+		t.Errorf("expected no dependents, got %v", got)
+	}
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"slices"
+)
+
+// Equal reports whether s and other define the same server. Args are
+// compared in order, since their order matters to the command; env vars,
+// headers and platform overrides are compared as maps, and dependencies,
+// tool lists and sandbox paths as sets. A missing list or map equals an
+// empty one. Provenance records where a server came from rather than what
+// it is, so it is ignored.
+func (s MCPServer) Equal(other MCPServer) bool {
+	return string(s.canonical()) == string(other.canonical())
+}
+
+// Hash returns a digest of the server that is the same for servers that
+// are Equal, to find duplicates among many
+func (s MCPServer) Hash() string {
+	sum := sha256.Sum256(s.canonical())
+	return hex.EncodeToString(sum[:])
+}
+
+// canonical returns the server in the form Equal compares. JSON writes map
+// keys sorted and leaves out empty fields, so only sets need sorting.
+func (s MCPServer) canonical() []byte {
+	s.Provenance = nil
+	s.DependsOn = sortedSet(s.DependsOn)
+	s.AlwaysAllow = sortedSet(s.AlwaysAllow)
+	if s.Sandbox != nil {
+		sandbox := *s.Sandbox
+		sandbox.ReadOnly = sortedSet(sandbox.ReadOnly)
+		sandbox.ReadWrite = sortedSet(sandbox.ReadWrite)
+		s.Sandbox = &sandbox
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		// Servers hold nothing JSON can't encode
+		panic(err)
+	}
+	return data
+}
+
+// sortedSet returns the distinct items of list, sorted
+func sortedSet(list []string) []string {
+	if len(list) == 0 {
+		return nil
+	}
+	return slices.Compact(slices.Sorted(slices.Values(list)))
+}
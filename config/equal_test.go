@@ -0,0 +1,54 @@
+package config
+
+import "testing"
+
+func TestMCPServer_Equal(t *testing.T) {
+	base := MCPServer{
+		Name:        "github",
+		Type:        "stdio",
+		Command:     "npx",
+		Args:        []string{"-y", "@modelcontextprotocol/server-github"},
+		Env:         map[string]string{"A": "1", "B": "2"},
+		DependsOn:   []string{"auth", "cache"},
+		AlwaysAllow: []string{"search", "read"},
+		Sandbox:     &Sandbox{ReadOnly: []string{"/a", "/b"}},
+	}
+
+	testCases := []struct {
+		name  string
+		edit  func(s *MCPServer)
+		equal bool
+	}{
+		{"identical", func(s *MCPServer) {}, true},
+		{"env in another order", func(s *MCPServer) { s.Env = map[string]string{"B": "2", "A": "1"} }, true},
+		{"sets in another order with duplicates", func(s *MCPServer) {
+			s.DependsOn = []string{"cache", "auth", "cache"}
+			s.AlwaysAllow = []string{"read", "search"}
+			s.Sandbox = &Sandbox{ReadOnly: []string{"/b", "/a"}}
+		}, true},
+		{"empty headers", func(s *MCPServer) { s.Headers = map[string]string{} }, true},
+		{"provenance", func(s *MCPServer) { s.Provenance = &Provenance{Source: SourceImport} }, true},
+		{"args in another order", func(s *MCPServer) { s.Args = []string{"@modelcontextprotocol/server-github", "-y"} }, false},
+		{"repeated arg", func(s *MCPServer) { s.Args = append(s.Args, "-y") }, false},
+		{"env value", func(s *MCPServer) { s.Env = map[string]string{"A": "1", "B": "3"} }, false},
+		{"name", func(s *MCPServer) { s.Name = "gh" }, false},
+		{"disabled", func(s *MCPServer) { s.Disabled = true }, false},
+		{"sandbox network", func(s *MCPServer) { s.Sandbox = &Sandbox{ReadOnly: []string{"/a", "/b"}, Network: true} }, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			other := base
+			tc.edit(&other)
+			if got := base.Equal(other); got != tc.equal {
+				t.Errorf("expected Equal to be %v, got %v", tc.equal, got)
+			}
+			if got := base.Hash() == other.Hash(); got != tc.equal {
+				t.Errorf("expected equal hashes to be %v, got %v", tc.equal, got)
+			}
+		})
+	}
+
+	if base.Sandbox.ReadOnly[0] != "/a" || base.DependsOn[0] != "auth" {
+		t.Error("expected comparing not to modify the server")
+	}
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Format is the serialization format of a config file
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+	FormatTOML Format = "toml"
+)
+
+// configFileNames are the project config names searched for, in order
+var configFileNames = []string{configFileName, "mcpr.yaml", "mcpr.yml", "mcpr.toml"}
+
+// globalConfigFileNames are the global config names searched for, in order
+var globalConfigFileNames = []string{"config.json", "config.yaml", "config.yml", "config.toml"}
+
+// FormatForPath returns the format implied by a config file's extension.
+// Unknown extensions are treated as JSON.
+func FormatForPath(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".toml":
+		return FormatTOML
+	default:
+		return FormatJSON
+	}
+}
+
+// Unmarshal decodes data in the given format into v. JSON may contain
+// comments and trailing commas.
+func Unmarshal(data []byte, format Format, v any) error {
+	return decodeConfig(data, format, v)
+}
+
+// Marshal encodes v in the given format, laid out like mcpr's own config files
+func Marshal(v any, format Format) ([]byte, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return fromJSON(data, format)
+}
+
+// decodeConfig decodes config data in the given format into v. Keys are the
+// same in every format, so YAML and TOML are converted to JSON and decoded
+// with the JSON struct tags.
+func decodeConfig(data []byte, format Format, v any) error {
+	std, err := toJSON(data, format)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(std, v)
+}
+
+// toJSON converts config data in the given format into standard JSON
+func toJSON(data []byte, format Format) ([]byte, error) {
+	switch format {
+	case FormatYAML:
+		var v any
+		if err := yaml.Unmarshal(data, &v); err != nil {
+			return nil, err
+		}
+		if v == nil {
+			v = map[string]any{}
+		}
+		return json.Marshal(v)
+	case FormatTOML:
+		var v map[string]any
+		if err := toml.Unmarshal(data, &v); err != nil {
+			return nil, err
+		}
+		return json.Marshal(v)
+	default:
+		// Most files are plain JSON; only JSONC needs the slower parser
+		if json.Valid(data) {
+			return data, nil
+		}
+		return standardizeJSONC(data)
+	}
+}
+
+// fromJSON converts standard JSON into the given format
+func fromJSON(data []byte, format Format) ([]byte, error) {
+	switch format {
+	case FormatYAML:
+		// Decoding JSON as a YAML node keeps the key order of the JSON
+		var node yaml.Node
+		if err := yaml.Unmarshal(data, &node); err != nil {
+			return nil, err
+		}
+		setBlockStyle(&node)
+
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(&node); err != nil {
+			return nil, err
+		}
+		if err := enc.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case FormatTOML:
+		return encodeTOML(data)
+	case FormatJSON:
+		return data, nil
+	default:
+		return nil, fmt.Errorf("unsupported config format: %s", format)
+	}
+}
+
+// yaml11Scalar matches strings that YAML 1.1 readers take for booleans or
+// base 60 numbers. The encoder only quotes strings that YAML 1.2 would read
+// as another type, so these keep their quotes.
+var yaml11Scalar = regexp.MustCompile(`^(?i:y|n|yes|no|on|off)$|^[-+]?[0-9][0-9_]*(:[0-5]?[0-9])+(\.[0-9_]*)?$`)
+
+// setBlockStyle switches a YAML node tree parsed from JSON to block style
+func setBlockStyle(node *yaml.Node) {
+	node.Style &^= yaml.FlowStyle
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!str" && !yaml11Scalar.MatchString(node.Value) {
+		node.Style &^= yaml.DoubleQuotedStyle
+	}
+	for _, child := range node.Content {
+		setBlockStyle(child)
+	}
+}
@@ -0,0 +1,290 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFormatForPath(t *testing.T) {
+	tests := map[string]Format{
+		"mcpr.json":        FormatJSON,
+		"mcpr.yaml":        FormatYAML,
+		"mcpr.yml":         FormatYAML,
+		"MCPR.YAML":        FormatYAML,
+		"mcpr.toml":        FormatTOML,
+		"config":           FormatJSON,
+		"/etc/mcpr/x.toml": FormatTOML,
+	}
+	for path, want := range tests {
+		if got := FormatForPath(path); got != want {
+			t.Errorf("FormatForPath(%q): expected %s, got %s", path, want, got)
+		}
+	}
+}
+
+func TestLoadFromPath_YAML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mcpr.yaml")
+	data := `# team servers
+servers:
+  - name: fs
+    type: stdio
+    command: npx
+    args: ["-y", "@modelcontextprotocol/server-filesystem"]
+    env:
+      DEBUG: "1"
+  - name: api
+    type: http
+    url: https://example.com/mcp
+aliases:
+  s: client sync
+`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.Servers) != 2 {
+		t.Fatalf("expected 2 servers, got %d", len(cfg.Servers))
+	}
+	if cfg.Servers[0].Command != "npx" || len(cfg.Servers[0].Args) != 2 {
+		t.Errorf("unexpected stdio server: %+v", cfg.Servers[0])
+	}
+	if cfg.Servers[0].Env["DEBUG"] != "1" {
+		t.Errorf("expected env DEBUG=1, got %q", cfg.Servers[0].Env["DEBUG"])
+	}
+	if cfg.Servers[1].URL != "https://example.com/mcp" {
+		t.Errorf("expected url https://example.com/mcp, got %q", cfg.Servers[1].URL)
+	}
+	if cfg.Aliases["s"] != "client sync" {
+		t.Errorf("expected alias s, got %q", cfg.Aliases["s"])
+	}
+}
+
+func TestLoadFromPath_TOML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mcpr.toml")
+	data := `[[servers]]
+name = "fs"
+type = "stdio"
+command = "npx"
+args = ["-y", "server"]
+
+[servers.env]
+DEBUG = "1"
+
+[[synced_clients]]
+name = "cursor"
+local = true
+`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.Servers) != 1 || cfg.Servers[0].Env["DEBUG"] != "1" {
+		t.Fatalf("unexpected servers: %+v", cfg.Servers)
+	}
+	if len(cfg.SyncedClients) != 1 || !cfg.SyncedClients[0].Local {
+		t.Errorf("unexpected synced clients: %+v", cfg.SyncedClients)
+	}
+}
+
+func TestConfig_SaveAndLoad_Formats(t *testing.T) {
+	for _, name := range []string{"mcpr.yaml", "mcpr.toml"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			cfg := &Config{path: path}
+			cfg.AddServer(MCPServer{Name: "fs", Type: "stdio", Command: "npx", Args: []string{"-y", "true"}})
+			cfg.AddServer(MCPServer{Name: "api", Type: "http", URL: "https://example.com", Headers: map[string]string{"Authorization": "Bearer x"}})
+			cfg.AddSyncedClient("cursor", false, nil)
+
+			if err := cfg.Save(); err != nil {
+				t.Fatalf("failed to save: %v", err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to read config: %v", err)
+			}
+			if strings.HasPrefix(strings.TrimSpace(string(data)), "{") {
+				t.Errorf("expected %s to be written as %s, got:\n%s", name, FormatForPath(name), data)
+			}
+
+			loaded, err := LoadFromPath(path)
+			if err != nil {
+				t.Fatalf("failed to load: %v", err)
+			}
+			if len(loaded.Servers) != 2 {
+				t.Fatalf("expected 2 servers, got %d", len(loaded.Servers))
+			}
+			if loaded.Servers[0].Args[1] != "true" {
+				t.Errorf("expected string arg \"true\", got %q", loaded.Servers[0].Args[1])
+			}
+			if loaded.Servers[1].Headers["Authorization"] != "Bearer x" {
+				t.Errorf("expected header to round-trip, got %+v", loaded.Servers[1].Headers)
+			}
+			if len(loaded.SyncedClients) != 1 {
+				t.Errorf("expected 1 synced client, got %d", len(loaded.SyncedClients))
+			}
+		})
+	}
+}
+
+func TestFindConfigInParents_YAML(t *testing.T) {
+	tempDir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to resolve symlinks: %v", err)
+	}
+	configPath := filepath.Join(tempDir, "mcpr.yml")
+	if err := os.WriteFile(configPath, []byte("servers: []\n"), 0644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("failed to change directory: %v", err)
+	}
+
+	foundPath, found := findConfigInParents()
+	if !found {
+		t.Fatal("expected to find mcpr.yml")
+	}
+	if foundPath != configPath {
+		t.Errorf("expected %s, got %s", configPath, foundPath)
+	}
+}
+
+func TestValidateFormat(t *testing.T) {
+	if err := ValidateFormat([]byte("servers:\n  - name: fs\n    type: stdio\n    command: npx\n"), FormatYAML); err != nil {
+		t.Errorf("expected valid YAML config, got %v", err)
+	}
+	if err := ValidateFormat([]byte("servers:\n  - name: fs\n    type: stdio\n"), FormatYAML); err == nil {
+		t.Error("expected error for stdio server without command")
+	}
+	if err := ValidateFormat([]byte("[[servers]]\nname = \"fs\"\ntype = \"sse\"\n"), FormatTOML); err == nil {
+		t.Error("expected error for unknown type")
+	}
+	if err := ValidateFormat([]byte("servers = ["), FormatTOML); err == nil {
+		t.Error("expected TOML syntax error")
+	}
+}
+
+func TestMarshalUnmarshal_RoundTrip(t *testing.T) {
+	doc := map[string]any{
+		"model":   "o3",
+		"servers": map[string]any{"fs": map[string]any{"command": "npx"}},
+	}
+
+	for _, format := range []Format{FormatJSON, FormatYAML, FormatTOML} {
+		data, err := Marshal(doc, format)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", format, err)
+		}
+
+		var got map[string]any
+		if err := Unmarshal(data, format, &got); err != nil {
+			t.Fatalf("%s: unexpected error: %v", format, err)
+		}
+		if !reflect.DeepEqual(got, doc) {
+			t.Errorf("%s: expected %v, got %v", format, doc, got)
+		}
+	}
+}
+
+func TestMarshal_TOMLText(t *testing.T) {
+	data := []byte(`{
+  "model": "o3",
+  "mcp_servers": {
+    "zeta": {"command": "npx", "args": ["-y", "server"], "startup_timeout_sec": 30, "env": {"Z": "1", "A": "x\"y"}},
+    "alpha": {"url": "http://localhost", "ratio": 0.5, "cap": 3.0, "nothing": null}
+  },
+  "profiles": [{"name": "a"}, {"name": "b"}],
+  "tools": ["x", {"inline": true}]
+}`)
+	want := `model = "o3"
+tools = ["x", {inline = true}]
+
+[mcp_servers]
+[mcp_servers.zeta]
+command = "npx"
+args = ["-y", "server"]
+startup_timeout_sec = 30
+[mcp_servers.zeta.env]
+Z = "1"
+A = "x\"y"
+[mcp_servers.alpha]
+url = "http://localhost"
+ratio = 0.5
+cap = 3.0
+
+[[profiles]]
+name = "a"
+
+[[profiles]]
+name = "b"
+`
+	got, err := fromJSON(data, FormatTOML)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(got) != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+
+	// Reading the output back and writing it again gives the same text
+	var doc map[string]any
+	if err := Unmarshal(got, FormatTOML, &doc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := doc["mcp_servers"].(map[string]any)["zeta"].(map[string]any)["startup_timeout_sec"]; n != float64(30) {
+		t.Errorf("expected the timeout to read back as 30, got %v", n)
+	}
+	again, err := Marshal(doc, FormatTOML)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var redoc map[string]any
+	if err := Unmarshal(again, FormatTOML, &redoc); err != nil || !reflect.DeepEqual(redoc, doc) {
+		t.Errorf("expected the document to survive a second round trip, got %v (%v)", redoc, err)
+	}
+	if strings.Contains(string(again), "30.0") {
+		t.Errorf("expected integers to stay integers:\n%s", again)
+	}
+}
+
+func TestMarshal_YAMLText(t *testing.T) {
+	data := []byte(`{"servers": {"zeta": {"command": "npx", "args": ["yes", "No", "on", "OFF", "y", "1:20", "true", "123", "plain"], "timeout": 30}, "alpha": {"url": "http://localhost"}}}`)
+	want := `servers:
+  zeta:
+    command: npx
+    args:
+      - "yes"
+      - "No"
+      - "on"
+      - "OFF"
+      - "y"
+      - "1:20"
+      - "true"
+      - "123"
+      - plain
+    timeout: 30
+  alpha:
+    url: http://localhost
+`
+	got, err := fromJSON(data, FormatYAML)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(got) != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+}
@@ -0,0 +1,114 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// configSeeds are configs the fuzz targets start from
+var configSeeds = []string{
+	`{"servers": []}`,
+	"{\n  // comment\n  \"servers\": [\n    {\"name\": \"a\", \"type\": \"stdio\", \"command\": \"npx\", \"args\": [\"-y\", \"pkg\"],},\n  ],\n}",
+	`{"servers": [{"name": "h", "type": "http", "url": "https://example.com/mcp", "headers": {"Authorization": "Bearer x"}}], "aliases": {"x": ["h"]}}`,
+	`{"servers": [{"name": "s", "type": "stdio", "command": "npx", "platforms": {"windows": {"command": "npx.cmd"}}}], "fileMode": "0600"}`,
+	`{"servers": [{"name": "a"}`,
+	"{\n\t\"servers\": {}\n}",
+	`/* unterminated`,
+	`{"servers": [/ 1]}`,
+	"",
+}
+
+func FuzzLoadFromPath(f *testing.F) {
+	for _, seed := range configSeeds {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		t.Setenv("XDG_STATE_HOME", t.TempDir())
+		path := filepath.Join(t.TempDir(), "config.json")
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg, err := LoadFromPath(path)
+		if err != nil {
+			var parseErr *ParseError
+			if errors.As(err, &parseErr) && parseErr.Error() == "" {
+				t.Error("expected a parse error to describe itself")
+			}
+			return
+		}
+
+		// What loaded must save and load again to the same servers
+		servers := len(cfg.Servers)
+		if err := cfg.Save(); err != nil {
+			t.Fatalf("failed to save a config that loaded: %v", err)
+		}
+		again, err := LoadFromPath(path)
+		if err != nil {
+			t.Fatalf("failed to load a saved config: %v", err)
+		}
+		if len(again.Servers) != servers {
+			t.Errorf("expected %d servers after saving, got %d", servers, len(again.Servers))
+		}
+	})
+}
+
+func FuzzStandardizeJSONC(f *testing.F) {
+	for _, seed := range configSeeds {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		std, err := standardizeJSONC(data)
+		if err != nil {
+			return
+		}
+		// Comments are blanked rather than removed, so offsets stay put
+		if len(std) != len(data) {
+			t.Errorf("expected length %d, got %d", len(data), len(std))
+		}
+		if !json.Valid(std) {
+			t.Errorf("expected standard JSON, got %q", std)
+		}
+
+		var v any
+		if err := json.Unmarshal(std, &v); err != nil {
+			t.Fatal(err)
+		}
+		updated, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if merged := PreserveFormatting(data, updated); !json.Valid(mustStandardize(t, merged)) {
+			t.Errorf("expected formatting to be preserved as valid JSONC, got %q", merged)
+		}
+	})
+}
+
+func FuzzRepairJSON(f *testing.F) {
+	for _, seed := range configSeeds {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		repaired, err := RepairJSON(data)
+		if err != nil {
+			return
+		}
+		if err := Validate(repaired); err != nil {
+			t.Errorf("expected a repaired config to be valid, got %v", err)
+		}
+	})
+}
+
+// mustStandardize converts JSONC to standard JSON, failing the test if it
+// doesn't parse
+func mustStandardize(t *testing.T, data []byte) []byte {
+	t.Helper()
+	std, err := standardizeJSONC(data)
+	if err != nil {
+		t.Fatalf("failed to parse %q: %v", data, err)
+	}
+	return std
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// GroupPrefix marks a client group in place of a client name, as in @editors
+const GroupPrefix = "@"
+
+// SetClientGroup adds or replaces a named group of clients
+func (c *Config) SetClientGroup(name string, clients []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.ClientGroups == nil {
+		c.ClientGroups = make(map[string][]string)
+	}
+	c.ClientGroups[name] = slices.Clone(clients)
+}
+
+// RemoveClientGroup removes a client group by name
+func (c *Config) RemoveClientGroup(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.ClientGroups[name]; !ok {
+		return fmt.Errorf("client group %q not found", name)
+	}
+	delete(c.ClientGroups, name)
+	return nil
+}
+
+// GetClientGroups returns a copy of the client groups
+func (c *Config) GetClientGroups() map[string][]string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	groups := make(map[string][]string, len(c.ClientGroups))
+	for name, clients := range c.ClientGroups {
+		groups[name] = slices.Clone(clients)
+	}
+	return groups
+}
+
+// ExpandClients replaces the @group references among names with the
+// clients of the groups, leaving out repeats
+func (c *Config) ExpandClients(names []string) ([]string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var expanded []string
+	for _, name := range names {
+		members := []string{name}
+		if group, ok := strings.CutPrefix(name, GroupPrefix); ok {
+			if members, ok = c.ClientGroups[group]; !ok {
+				groups := slices.Sorted(maps.Keys(c.ClientGroups))
+				if len(groups) == 0 {
+					return nil, fmt.Errorf("client group %q not found; none are defined", group)
+				}
+				return nil, fmt.Errorf("client group %q not found; groups: %s", group, strings.Join(groups, ", "))
+			}
+		}
+		for _, member := range members {
+			if !slices.Contains(expanded, member) {
+				expanded = append(expanded, member)
+			}
+		}
+	}
+	return expanded, nil
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestConfig_ExpandClients(t *testing.T) {
+	cfg := &Config{}
+	cfg.SetClientGroup("editors", []string{"cursor", "vscode", "zed"})
+	cfg.SetClientGroup("cli", []string{"claude-code", "gemini"})
+
+	got, err := cfg.ExpandClients([]string{"@editors", "claude-code", "@cli", "zed"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"cursor", "vscode", "zed", "claude-code", "gemini"}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	_, err = cfg.ExpandClients([]string{"@missing"})
+	if err == nil || !strings.Contains(err.Error(), "cli, editors") {
+		t.Errorf("expected an error listing the groups, got %v", err)
+	}
+}
+
+func TestConfig_ClientGroups_SaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	cfg, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.SetClientGroup("editors", []string{"cursor", "zed"})
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := loaded.GetClientGroups()["editors"]; !slices.Equal(got, []string{"cursor", "zed"}) {
+		t.Errorf("expected the group to be saved, got %v", got)
+	}
+
+	if err := loaded.RemoveClientGroup("editors"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := loaded.RemoveClientGroup("editors"); err == nil {
+		t.Error("expected an error removing a missing group")
+	}
+}
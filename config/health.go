@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// QuarantineThreshold is the number of consecutive failed health checks
+// after which a server is quarantined
+const QuarantineThreshold = 3
+
+// HealthRecord is the health check history of a server. Quarantined servers
+// are synced to clients disabled until they are unquarantined.
+type HealthRecord struct {
+	LastCheck   time.Time `json:"lastCheck"`
+	LastError   string    `json:"lastError,omitempty"`   // Error of the last check, if it failed
+	Failures    int       `json:"failures,omitempty"`    // Consecutive failed checks
+	Quarantined bool      `json:"quarantined,omitempty"` // Failed too often; synced disabled
+}
+
+// RecordHealthCheck records the outcome of a health check of a server, err
+// being nil if it passed. When quarantine is set, a server reaching
+// QuarantineThreshold consecutive failures is quarantined; the result
+// reports whether this check did so.
+func (c *Config) RecordHealthCheck(name string, err error, quarantine bool) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	record := c.Health[name]
+	record.LastCheck = time.Now().UTC().Truncate(time.Second)
+	if err == nil {
+		record.LastError = ""
+		record.Failures = 0
+	} else {
+		record.LastError = err.Error()
+		record.Failures++
+	}
+
+	quarantined := quarantine && !record.Quarantined && record.Failures >= QuarantineThreshold
+	if quarantined {
+		record.Quarantined = true
+	}
+
+	if c.Health == nil {
+		c.Health = make(map[string]HealthRecord)
+	}
+	c.Health[name] = record
+	return quarantined
+}
+
+// HealthOf returns the health check history of a server
+func (c *Config) HealthOf(name string) (HealthRecord, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	record, ok := c.Health[name]
+	return record, ok
+}
+
+// IsQuarantined reports whether a server is quarantined
+func (c *Config) IsQuarantined(name string) bool {
+	record, _ := c.HealthOf(name)
+	return record.Quarantined
+}
+
+// Unquarantine lifts the quarantine of a server and clears its failures
+func (c *Config) Unquarantine(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	record, ok := c.Health[name]
+	if !ok || !record.Quarantined {
+		return fmt.Errorf("server %q is not quarantined", name)
+	}
+	record.Quarantined = false
+	record.Failures = 0
+	c.Health[name] = record
+	return nil
+}
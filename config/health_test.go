@@ -0,0 +1,71 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRecordHealthCheck_Quarantine(t *testing.T) {
+	cfg := &Config{}
+	failure := errors.New("exit status 1")
+
+	for i := 1; i < QuarantineThreshold; i++ {
+		if cfg.RecordHealthCheck("fs", failure, true) {
+			t.Fatalf("quarantined after %d failures", i)
+		}
+	}
+	if !cfg.RecordHealthCheck("fs", failure, true) {
+		t.Fatal("expected server to be quarantined at the threshold")
+	}
+	if !cfg.IsQuarantined("fs") {
+		t.Error("expected IsQuarantined to report the quarantine")
+	}
+	if cfg.RecordHealthCheck("fs", failure, true) {
+		t.Error("expected an already quarantined server not to be reported again")
+	}
+
+	record, _ := cfg.HealthOf("fs")
+	if record.LastError != "exit status 1" || record.Failures != QuarantineThreshold+1 || record.LastCheck.IsZero() {
+		t.Errorf("unexpected record: %+v", record)
+	}
+
+	// Passing clears failures but the quarantine stays until lifted
+	cfg.RecordHealthCheck("fs", nil, true)
+	record, _ = cfg.HealthOf("fs")
+	if record.Failures != 0 || record.LastError != "" || !record.Quarantined {
+		t.Errorf("unexpected record after passing check: %+v", record)
+	}
+
+	if err := cfg.Unquarantine("fs"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.IsQuarantined("fs") {
+		t.Error("expected quarantine to be lifted")
+	}
+	if err := cfg.Unquarantine("fs"); err == nil {
+		t.Error("expected error unquarantining a server that isn't quarantined")
+	}
+}
+
+func TestRecordHealthCheck_NoQuarantine(t *testing.T) {
+	cfg := &Config{}
+	for range QuarantineThreshold + 1 {
+		if cfg.RecordHealthCheck("fs", errors.New("boom"), false) {
+			t.Fatal("expected no quarantine when disabled")
+		}
+	}
+	if cfg.IsQuarantined("fs") {
+		t.Error("expected server not to be quarantined")
+	}
+}
+
+func TestRemoveServer_ClearsHealth(t *testing.T) {
+	cfg := &Config{Servers: []MCPServer{{Name: "fs", Type: "stdio", Command: "npx"}}}
+	cfg.RecordHealthCheck("fs", errors.New("boom"), true)
+	if err := cfg.RemoveServer("fs"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := cfg.HealthOf("fs"); ok {
+		t.Error("expected health record to be removed with the server")
+	}
+}
@@ -0,0 +1,232 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/tailscale/hujson"
+)
+
+// standardizeJSONC converts JSON with comments and trailing commas into
+// standard JSON. Comments are blanked out rather than removed so byte
+// offsets, and therefore error positions, stay the same.
+func standardizeJSONC(data []byte) ([]byte, error) {
+	v, err := hujson.Parse(bytes.Clone(data))
+	if err != nil {
+		return nil, errors.New(strings.TrimPrefix(err.Error(), "hujson: "))
+	}
+	v.Standardize()
+	return v.Pack(), nil
+}
+
+// unmarshalJSONC decodes JSON that may contain comments and trailing commas
+func unmarshalJSONC(data []byte, v any) error {
+	std, err := standardizeJSONC(data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(std, v)
+}
+
+// PreserveFormatting re-expresses updated (standard JSON) using the comments,
+// key order and formatting of original wherever values are unchanged, so a
+// save only touches what actually changed. If original can't be parsed,
+// updated is returned as is.
+func PreserveFormatting(original, updated []byte) []byte {
+	if bytes.Equal(original, updated) {
+		return updated
+	}
+
+	oldV, err := hujson.Parse(bytes.Clone(original))
+	if err != nil {
+		return updated
+	}
+	newV, err := hujson.Parse(bytes.Clone(updated))
+	if err != nil {
+		return updated
+	}
+
+	mergeValue(&oldV, &newV)
+	return oldV.Pack()
+}
+
+// mergeValue updates dst in place to hold the value of src, keeping dst's
+// comments and formatting for everything that didn't change
+func mergeValue(dst, src *hujson.Value) {
+	if equalValues(*dst, *src) {
+		return
+	}
+
+	switch oldV := dst.Value.(type) {
+	case *hujson.Object:
+		if newV, ok := src.Value.(*hujson.Object); ok && len(oldV.Members) > 0 {
+			mergeObject(oldV, newV)
+			return
+		}
+	case *hujson.Array:
+		if newV, ok := src.Value.(*hujson.Array); ok && len(oldV.Elements) > 0 {
+			mergeArray(oldV, newV)
+			return
+		}
+	}
+
+	// Scalars, type changes and previously empty composites are replaced
+	// wholesale, keeping the comments around the value
+	dst.Value = src.Value
+}
+
+// mergeObject merges object members, keeping the original member order and
+// appending new members at the end
+func mergeObject(dst, src *hujson.Object) {
+	trailingComma := dst.Members[len(dst.Members)-1].Value.AfterExtra != nil
+	singleLine := !bytes.Contains(dst.Members[0].Name.BeforeExtra, []byte("\n"))
+
+	srcIndex := make(map[string]int, len(src.Members))
+	for i, m := range src.Members {
+		srcIndex[memberName(m)] = i
+	}
+
+	members := make([]hujson.ObjectMember, 0, len(src.Members))
+	used := make(map[string]bool, len(src.Members))
+	for _, m := range dst.Members {
+		name := memberName(m)
+		i, ok := srcIndex[name]
+		if !ok {
+			continue
+		}
+		mergeValue(&m.Value, &src.Members[i].Value)
+		members = append(members, m)
+		used[name] = true
+	}
+	for _, m := range src.Members {
+		if used[memberName(m)] {
+			continue
+		}
+		if singleLine {
+			m.Name.BeforeExtra = hujson.Extra(" ")
+		}
+		members = append(members, m)
+	}
+
+	dst.Members = members
+	if len(members) > 0 {
+		fixTrailingComma(&members[len(members)-1].Value, &dst.AfterExtra, trailingComma)
+	}
+}
+
+// mergeArray merges array elements in src order. Arrays of named objects
+// (such as servers) are matched by name, other arrays by position.
+func mergeArray(dst, src *hujson.Array) {
+	trailingComma := dst.Elements[len(dst.Elements)-1].AfterExtra != nil
+
+	byName := namedElements(dst.Elements) != nil && namedElements(src.Elements) != nil
+	oldByName := namedElements(dst.Elements)
+
+	elements := make([]hujson.Value, 0, len(src.Elements))
+	for i, e := range src.Elements {
+		var old *hujson.Value
+		if byName {
+			if j, ok := oldByName[elementName(e)]; ok {
+				old = &dst.Elements[j]
+			}
+		} else if i < len(dst.Elements) {
+			old = &dst.Elements[i]
+		}
+
+		if old == nil {
+			elements = append(elements, e)
+			continue
+		}
+		merged := old.Clone()
+		merged.AfterExtra = nil
+		mergeValue(&merged, &e)
+		elements = append(elements, merged)
+	}
+
+	dst.Elements = elements
+	if len(elements) > 0 {
+		fixTrailingComma(&elements[len(elements)-1], &dst.AfterExtra, trailingComma)
+	}
+}
+
+// fixTrailingComma makes the last value of a composite carry a trailing
+// comma only if the original composite had one
+func fixTrailingComma(last *hujson.Value, closingExtra *hujson.Extra, want bool) {
+	switch {
+	case want && last.AfterExtra == nil:
+		last.AfterExtra = hujson.Extra{}
+	case !want && last.AfterExtra != nil:
+		*closingExtra = append(last.AfterExtra, *closingExtra...)
+		last.AfterExtra = nil
+	}
+}
+
+// namedElements indexes array elements by their "name" member, or returns
+// nil if any element isn't an object with a unique string name
+func namedElements(elements []hujson.Value) map[string]int {
+	index := make(map[string]int, len(elements))
+	for i, e := range elements {
+		name := elementName(e)
+		if name == "" {
+			return nil
+		}
+		if _, dup := index[name]; dup {
+			return nil
+		}
+		index[name] = i
+	}
+	return index
+}
+
+// elementName returns the "name" member of an object value, or ""
+func elementName(v hujson.Value) string {
+	obj, ok := v.Value.(*hujson.Object)
+	if !ok {
+		return ""
+	}
+	for _, m := range obj.Members {
+		if memberName(m) == "name" {
+			if lit, ok := m.Value.Value.(hujson.Literal); ok && lit.Kind() == '"' {
+				return lit.String()
+			}
+		}
+	}
+	return ""
+}
+
+// memberName returns the decoded name of an object member
+func memberName(m hujson.ObjectMember) string {
+	if lit, ok := m.Name.Value.(hujson.Literal); ok {
+		return lit.String()
+	}
+	return ""
+}
+
+// equalValues reports whether two values decode to the same JSON data
+func equalValues(a, b hujson.Value) bool {
+	// Values written by the same encoder are usually byte for byte equal,
+	// which is much cheaper to check than decoding both
+	packedA, packedB := trimmedPack(a), trimmedPack(b)
+	if bytes.Equal(packedA, packedB) {
+		return true
+	}
+
+	var av, bv any
+	if err := unmarshalJSONC(packedA, &av); err != nil {
+		return false
+	}
+	if err := unmarshalJSONC(packedB, &bv); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(av, bv)
+}
+
+// trimmedPack serializes a value without its surrounding comments
+func trimmedPack(v hujson.Value) []byte {
+	v.BeforeExtra = nil
+	v.AfterExtra = nil
+	return v.Pack()
+}
@@ -0,0 +1,182 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStandardizeJSONC_KeepsOffsets(t *testing.T) {
+	data := []byte("{\n  // comment\n  \"a\": 1,\n}")
+
+	std, err := standardizeJSONC(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(std) != len(data) {
+		t.Errorf("expected length %d, got %d", len(data), len(std))
+	}
+	if strings.Contains(string(std), "//") {
+		t.Errorf("expected comment to be removed, got %q", std)
+	}
+
+	// Input must not be modified
+	if !strings.Contains(string(data), "// comment") {
+		t.Error("expected input to be left untouched")
+	}
+}
+
+func TestStandardizeJSONC_SyntaxError(t *testing.T) {
+	_, err := standardizeJSONC([]byte("{\n  \"a\" 1\n}"))
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected error to mention line 2, got %q", err.Error())
+	}
+}
+
+func TestPreserveFormatting_Unchanged(t *testing.T) {
+	original := []byte(`{
+  // team servers
+  "servers": [
+    {"name": "fs", "type": "stdio", "command": "npx"} // inline
+  ]
+}
+`)
+	updated := []byte(`{
+  "servers": [
+    {
+      "name": "fs",
+      "type": "stdio",
+      "command": "npx"
+    }
+  ]
+}`)
+
+	got := PreserveFormatting(original, updated)
+	if string(got) != string(original) {
+		t.Errorf("expected unchanged config to be preserved byte for byte, got:\n%s", got)
+	}
+}
+
+func TestPreserveFormatting_InvalidOriginal(t *testing.T) {
+	updated := []byte(`{"servers": []}`)
+
+	got := PreserveFormatting([]byte("{not json"), updated)
+	if string(got) != string(updated) {
+		t.Errorf("expected updated content, got %s", got)
+	}
+}
+
+func TestConfig_Save_PreservesCommentsAndOrder(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "mcpr-test-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	configPath := filepath.Join(tempDir, "config.json")
+	original := `{
+  // Aliases come first in this file
+  "aliases": {"s": "client sync"},
+  "servers": [
+    // Filesystem access for the whole team
+    {
+      "command": "npx",
+      "name": "filesystem",
+      "type": "stdio"
+    },
+    /* remove me */
+    {
+      "name": "old",
+      "type": "stdio",
+      "command": "old-cmd"
+    }
+  ]
+}
+`
+	if err := os.WriteFile(configPath, []byte(original), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := LoadFromPath(configPath)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	if err := cfg.RemoveServer("old"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := cfg.AddServer(MCPServer{Name: "git", Type: "stdio", Command: "git-mcp"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+	saved := string(data)
+
+	for _, expected := range []string{
+		"// Aliases come first in this file",
+		"// Filesystem access for the whole team",
+		`"aliases": {"s": "client sync"}`,
+		`"command": "npx",
+      "name": "filesystem",`,
+		`"name": "git"`,
+	} {
+		if !strings.Contains(saved, expected) {
+			t.Errorf("expected saved config to contain %q, got:\n%s", expected, saved)
+		}
+	}
+	if strings.Contains(saved, `"old"`) {
+		t.Errorf("expected removed server to be gone, got:\n%s", saved)
+	}
+	if strings.Index(saved, "aliases") > strings.Index(saved, "servers") {
+		t.Errorf("expected original key order to be kept, got:\n%s", saved)
+	}
+
+	// The result must still load
+	reloaded, err := LoadFromPath(configPath)
+	if err != nil {
+		t.Fatalf("failed to reload config: %v\n%s", err, saved)
+	}
+	if len(reloaded.Servers) != 2 {
+		t.Errorf("expected 2 servers, got %d", len(reloaded.Servers))
+	}
+}
+
+func TestConfig_Save_PreservesTrailingCommas(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "mcpr-test-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	configPath := filepath.Join(tempDir, "config.json")
+	original := "{\n  \"servers\": [\n    {\"name\": \"a\", \"type\": \"stdio\", \"command\": \"a\"},\n  ],\n}\n"
+	if err := os.WriteFile(configPath, []byte(original), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := LoadFromPath(configPath)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	cfg.AddServer(MCPServer{Name: "b", Type: "stdio", Command: "b"})
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+
+	reloaded, err := LoadFromPath(configPath)
+	if err != nil {
+		t.Fatalf("failed to reload config: %v", err)
+	}
+	if len(reloaded.Servers) != 2 {
+		t.Errorf("expected 2 servers, got %d", len(reloaded.Servers))
+	}
+}
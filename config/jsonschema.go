@@ -0,0 +1,263 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// SchemaViolations checks doc, as decoded from JSON, against a JSON Schema
+// and returns what doesn't match, each as "path: problem". It is a shallow
+// check of the keywords that catch a malformed config: $ref to the schema's
+// own definitions, type, enum, const, properties, required,
+// additionalProperties, items, minItems, minLength, allOf, anyOf and oneOf.
+// Other keywords are ignored.
+func SchemaViolations(schema []byte, doc any) ([]string, error) {
+	var root any
+	if err := json.Unmarshal(schema, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+	v := &schemaValidator{root: root}
+	v.check(root, doc, "", 0)
+	return v.violations, nil
+}
+
+// maxSchemaDepth bounds $ref chains, so recursive schemas can't loop
+const maxSchemaDepth = 64
+
+// schemaValidator collects the violations of one document
+type schemaValidator struct {
+	root       any
+	violations []string
+}
+
+// fail records a violation at the dotted key path at
+func (v *schemaValidator) fail(at, format string, args ...any) {
+	if at == "" {
+		at = "(root)"
+	}
+	v.violations = append(v.violations, at+": "+fmt.Sprintf(format, args...))
+}
+
+// valid reports whether doc matches schema, without recording violations
+func (v *schemaValidator) valid(schema, doc any, depth int) bool {
+	sub := &schemaValidator{root: v.root}
+	sub.check(schema, doc, "", depth)
+	return len(sub.violations) == 0
+}
+
+// check records where doc, found at at, doesn't match schema
+func (v *schemaValidator) check(schema, doc any, at string, depth int) {
+	if depth > maxSchemaDepth {
+		return
+	}
+	s, ok := schema.(map[string]any)
+	if !ok {
+		// true matches anything, false nothing
+		if b, ok := schema.(bool); ok && !b {
+			v.fail(at, "not allowed")
+		}
+		return
+	}
+
+	if ref, ok := s["$ref"].(string); ok {
+		target, err := v.resolve(ref)
+		if err != nil {
+			v.fail(at, "%v", err)
+			return
+		}
+		v.check(target, doc, at, depth+1)
+	}
+
+	if t, ok := s["type"]; ok && !matchesType(t, doc) {
+		v.fail(at, "expected %s, got %s", describeTypes(t), jsonType(doc))
+		return
+	}
+	if enum, ok := s["enum"].([]any); ok && !slices.ContainsFunc(enum, func(e any) bool { return reflect.DeepEqual(e, doc) }) {
+		v.fail(at, "%s is not one of %s", describeValue(doc), describeValues(enum))
+	}
+	if c, ok := s["const"]; ok && !reflect.DeepEqual(c, doc) {
+		v.fail(at, "expected %s, got %s", describeValue(c), describeValue(doc))
+	}
+
+	for _, sub := range schemaList(s["allOf"]) {
+		v.check(sub, doc, at, depth+1)
+	}
+	if anyOf := schemaList(s["anyOf"]); len(anyOf) > 0 && !slices.ContainsFunc(anyOf, func(sub any) bool { return v.valid(sub, doc, depth+1) }) {
+		v.fail(at, "doesn't match any of the allowed shapes")
+	}
+	if oneOf := schemaList(s["oneOf"]); len(oneOf) > 0 {
+		matched := 0
+		for _, sub := range oneOf {
+			if v.valid(sub, doc, depth+1) {
+				matched++
+			}
+		}
+		if matched != 1 {
+			v.fail(at, "matches %d of the allowed shapes instead of exactly one", matched)
+		}
+	}
+
+	switch doc := doc.(type) {
+	case map[string]any:
+		v.checkObject(s, doc, at, depth)
+	case []any:
+		if min, ok := s["minItems"].(float64); ok && float64(len(doc)) < min {
+			v.fail(at, "expected at least %v items, got %d", min, len(doc))
+		}
+		if items, ok := s["items"]; ok {
+			for i, item := range doc {
+				v.check(items, item, fmt.Sprintf("%s[%d]", at, i), depth+1)
+			}
+		}
+	case string:
+		if min, ok := s["minLength"].(float64); ok && float64(len([]rune(doc))) < min {
+			v.fail(at, "expected at least %v characters", min)
+		}
+	}
+}
+
+// checkObject records where the object doc doesn't match schema s
+func (v *schemaValidator) checkObject(s map[string]any, doc map[string]any, at string, depth int) {
+	for _, name := range schemaStrings(s["required"]) {
+		if _, ok := doc[name]; !ok {
+			v.fail(at, "missing required %q", name)
+		}
+	}
+	properties, _ := s["properties"].(map[string]any)
+	additional, hasAdditional := s["additionalProperties"]
+	keys := make([]string, 0, len(doc))
+	for key := range doc {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		loc := key
+		if at != "" {
+			loc = at + "." + key
+		}
+		if prop, ok := properties[key]; ok {
+			v.check(prop, doc[key], loc, depth+1)
+			continue
+		}
+		if !hasAdditional {
+			continue
+		}
+		if b, ok := additional.(bool); ok {
+			if !b {
+				v.fail(loc, "unknown property")
+			}
+			continue
+		}
+		v.check(additional, doc[key], loc, depth+1)
+	}
+}
+
+// resolve returns the part of the schema a local $ref such as
+// "#/$defs/server" points to
+func (v *schemaValidator) resolve(ref string) (any, error) {
+	pointer, ok := strings.CutPrefix(ref, "#")
+	if !ok {
+		return nil, fmt.Errorf("unsupported schema reference %q", ref)
+	}
+	node := v.root
+	for _, part := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		if part == "" {
+			continue
+		}
+		part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+		m, ok := node.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("schema reference %q not found", ref)
+		}
+		if node, ok = m[part]; !ok {
+			return nil, fmt.Errorf("schema reference %q not found", ref)
+		}
+	}
+	return node, nil
+}
+
+// matchesType reports whether doc is of the JSON Schema type t, a name or a
+// list of names
+func matchesType(t, doc any) bool {
+	names := schemaStrings(t)
+	if name, ok := t.(string); ok {
+		names = []string{name}
+	}
+	actual := jsonType(doc)
+	for _, name := range names {
+		if name == actual || (name == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonType returns the JSON Schema type name of a decoded JSON value
+func jsonType(doc any) string {
+	switch doc := doc.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if doc == math.Trunc(doc) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", doc)
+	}
+}
+
+// describeTypes describes the JSON Schema type t for a message
+func describeTypes(t any) string {
+	if name, ok := t.(string); ok {
+		return name
+	}
+	return strings.Join(schemaStrings(t), " or ")
+}
+
+// describeValue describes a decoded JSON value for a message
+func describeValue(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// describeValues describes a list of allowed values for a message
+func describeValues(values []any) string {
+	described := make([]string, len(values))
+	for i, value := range values {
+		described[i] = describeValue(value)
+	}
+	return strings.Join(described, ", ")
+}
+
+// schemaList returns the subschemas of a keyword such as anyOf
+func schemaList(v any) []any {
+	list, _ := v.([]any)
+	return list
+}
+
+// schemaStrings returns the strings of a keyword such as required
+func schemaStrings(v any) []string {
+	var result []string
+	for _, item := range schemaList(v) {
+		if s, ok := item.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
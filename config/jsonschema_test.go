@@ -0,0 +1,75 @@
+package config
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestSchemaViolations(t *testing.T) {
+	schema := []byte(`{
+  "type": "object",
+  "properties": {
+    "servers": {
+      "type": "object",
+      "additionalProperties": {"$ref": "#/$defs/server"}
+    }
+  },
+  "$defs": {
+    "server": {
+      "type": "object",
+      "anyOf": [{"required": ["command"]}, {"required": ["url"]}],
+      "properties": {
+        "type": {"enum": ["stdio", "http"]},
+        "command": {"type": "string", "minLength": 1},
+        "args": {"type": "array", "items": {"type": "string"}},
+        "url": {"type": "string"}
+      },
+      "additionalProperties": false
+    }
+  }
+}`)
+
+	tests := []struct {
+		name string
+		doc  string
+		want []string
+	}{
+		{"valid", `{"servers": {"fs": {"command": "npx", "args": ["-y"]}, "api": {"type": "http", "url": "https://example.com"}}, "other": 1}`, nil},
+		{"wrong root type", `[]`, []string{"(root): expected object, got array"}},
+		{"bad item", `{"servers": {"fs": {"command": "npx", "args": ["-y", 1]}}}`, []string{"servers.fs.args[1]: expected string, got integer"}},
+		{"unknown property", `{"servers": {"fs": {"command": "npx", "cwd": "/"}}}`, []string{"servers.fs.cwd: unknown property"}},
+		{"no command or url", `{"servers": {"fs": {"args": []}}}`, []string{"servers.fs: doesn't match any of the allowed shapes"}},
+		{"enum", `{"servers": {"fs": {"type": "sse", "url": "u"}}}`, []string{`servers.fs.type: "sse" is not one of "stdio", "http"`}},
+		{"empty command", `{"servers": {"fs": {"command": ""}}}`, []string{"servers.fs.command: expected at least 1 characters"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var doc any
+			if err := json.Unmarshal([]byte(tt.doc), &doc); err != nil {
+				t.Fatal(err)
+			}
+			got, err := SchemaViolations(schema, doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSchemaViolations_OwnSchema(t *testing.T) {
+	var doc any
+	if err := json.Unmarshal([]byte(`{"servers": [{"name": "fs", "type": "stdio", "command": "npx"}]}`), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := SchemaViolations(Schema(), doc); err != nil || len(got) != 0 {
+		t.Errorf("expected a valid config to match mcpr's schema, got %q, %v", got, err)
+	}
+	doc.(map[string]any)["servers"] = map[string]any{}
+	if got, _ := SchemaViolations(Schema(), doc); !slices.Equal(got, []string{"servers: expected array, got object"}) {
+		t.Errorf("unexpected violations %q", got)
+	}
+}
@@ -0,0 +1,202 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+)
+
+// Layer names, from lowest to highest precedence
+const (
+	LayerSystem  = "system"
+	LayerUser    = "user"
+	LayerProject = "project"
+)
+
+// Layer is a config file contributing servers to the merged configuration
+type Layer struct {
+	Name    string      // LayerSystem, LayerUser or LayerProject
+	Path    string      // File the layer was read from
+	Exists  bool        // Whether the file exists
+	Servers []MCPServer // Servers defined in the layer
+}
+
+// getSystemConfigPath is a variable for testing
+var getSystemConfigPath = getSystemConfigPathImpl
+
+// getSystemConfigPathImpl returns the machine-wide config path managed by admins
+func getSystemConfigPathImpl() (string, error) {
+	if runtime.GOOS == "windows" {
+		programData := os.Getenv("ProgramData")
+		if programData == "" {
+			programData = `C:\ProgramData`
+		}
+		return filepath.Join(programData, "mcpr", "config.json"), nil
+	}
+	return filepath.Join(string(filepath.Separator), "etc", "mcpr", "config.json"), nil
+}
+
+// GetSystemConfigPath returns the path of the system-wide config layer
+func GetSystemConfigPath() (string, error) {
+	return getSystemConfigPath()
+}
+
+// loadLayers reads the layers merged below the config at c.path: the
+// system config and, for a project config, the project configs in its
+// parent directories, farthest first
+func (c *Config) loadLayers() error {
+	systemPath, err := getSystemConfigPath()
+	if err != nil {
+		return err
+	}
+
+	c.layers = nil
+	if sameFile(systemPath, c.path) {
+		return nil
+	}
+
+	layer, err := readLayer(LayerSystem, systemPath)
+	if err != nil {
+		return err
+	}
+	c.layers = append(c.layers, layer)
+
+	for _, path := range parentProjectConfigs(c.path) {
+		layer, err := readLayer(LayerProject, path)
+		if err != nil {
+			return err
+		}
+		c.layers = append(c.layers, layer)
+	}
+	return nil
+}
+
+// parentProjectConfigs returns the project configs in the parent
+// directories of the project config at path, farthest first, so a
+// subpackage of a monorepo supplements the config at the repo root. It
+// returns none if path isn't a project config.
+func parentProjectConfigs(path string) []string {
+	if !slices.Contains(configFileNames, filepath.Base(path)) {
+		return nil
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil
+	}
+
+	var found []string
+	dir := filepath.Dir(abs)
+	for {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+		if path, ok := projectConfigIn(dir); ok {
+			found = append([]string{path}, found...)
+		}
+	}
+	return found
+}
+
+// readLayer reads the servers of a single config layer
+func readLayer(name, path string) (Layer, error) {
+	layer := Layer{Name: name, Path: path}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return layer, nil
+	}
+	if err != nil {
+		return layer, fmt.Errorf("failed to read %s config: %w", name, err)
+	}
+
+	var cfg Config
+	if err := decodeConfig(data, FormatForPath(path), &cfg); err != nil {
+		return layer, fmt.Errorf("failed to parse %s config %s: %w", name, path, err)
+	}
+	layer.Exists = true
+	layer.Servers = applyMachineOverrides(cfg.Servers, cfg.Machines)
+	return layer, nil
+}
+
+// Layers returns every config layer from lowest to highest precedence,
+// ending with the active config itself
+func (c *Config) Layers() []Layer {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.allLayers()
+}
+
+// allLayers is Layers for callers holding the lock
+func (c *Config) allLayers() []Layer {
+	layers := make([]Layer, 0, len(c.layers)+1)
+	layers = append(layers, c.layers...)
+
+	name := LayerProject
+	if globalPath, err := getGlobalConfigPath(); err == nil && sameFile(globalPath, c.path) {
+		name = LayerUser
+	}
+	_, statErr := os.Stat(c.path)
+	layers = append(layers, Layer{
+		Name:    name,
+		Path:    c.path,
+		Exists:  statErr == nil,
+		Servers: c.Servers,
+	})
+	return layers
+}
+
+// ServerLayer returns the name of the layer providing the effective
+// definition of a server, or "" if no layer defines it
+func (c *Config) ServerLayer(name string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	layers := c.allLayers()
+	for i := len(layers) - 1; i >= 0; i-- {
+		for _, s := range layers[i].Servers {
+			if s.Name == name {
+				return layers[i].Name
+			}
+		}
+	}
+	return ""
+}
+
+// inheritedServers returns servers from lower layers that aren't overridden
+// by the active config, lowest layer first
+func (c *Config) inheritedServers() []MCPServer {
+	seen := make(map[string]bool, len(c.Servers))
+	for _, s := range c.Servers {
+		seen[s.Name] = true
+	}
+
+	var inherited []MCPServer
+	for i := len(c.layers) - 1; i >= 0; i-- {
+		var layerServers []MCPServer
+		for _, s := range c.layers[i].Servers {
+			if !seen[s.Name] {
+				seen[s.Name] = true
+				layerServers = append(layerServers, s)
+			}
+		}
+		inherited = append(layerServers, inherited...)
+	}
+	return inherited
+}
+
+// sameFile reports whether two paths refer to the same location
+func sameFile(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return absA == absB
+}
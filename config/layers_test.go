@@ -0,0 +1,212 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// withSystemConfig points the system layer at a temporary file for the test
+func withSystemConfig(t *testing.T, content string) string {
+	t.Helper()
+	tempDir := t.TempDir()
+	systemPath := filepath.Join(tempDir, "system.json")
+	if content != "" {
+		if err := os.WriteFile(systemPath, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write system config: %v", err)
+		}
+	}
+
+	original := getSystemConfigPath
+	getSystemConfigPath = func() (string, error) { return systemPath, nil }
+	t.Cleanup(func() { getSystemConfigPath = original })
+	return systemPath
+}
+
+func TestGetSystemConfigPathImpl(t *testing.T) {
+	path, err := getSystemConfigPathImpl()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasSuffix(path, filepath.Join("mcpr", "config.json")) {
+		t.Errorf("expected path ending in mcpr/config.json, got %q", path)
+	}
+}
+
+func TestLoadFromPath_MergesSystemLayer(t *testing.T) {
+	withSystemConfig(t, `{"servers":[
+		{"name":"org-proxy","type":"http","url":"https://proxy.example.com/mcp"},
+		{"name":"shared","type":"stdio","command":"system-cmd"}
+	]}`)
+
+	userPath := filepath.Join(t.TempDir(), "config.json")
+	err := os.WriteFile(userPath, []byte(`{"servers":[{"name":"shared","type":"stdio","command":"user-cmd"}]}`), 0644)
+	if err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := LoadFromPath(userPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	servers := cfg.ListServers()
+	if len(servers) != 2 {
+		t.Fatalf("expected 2 effective servers, got %d", len(servers))
+	}
+	if servers[0].Name != "org-proxy" {
+		t.Errorf("expected inherited 'org-proxy' first, got %q", servers[0].Name)
+	}
+
+	shared, err := cfg.GetServer("shared")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if shared.Command != "user-cmd" {
+		t.Errorf("expected user layer to win, got command %q", shared.Command)
+	}
+
+	if _, err := cfg.GetServer("org-proxy"); err != nil {
+		t.Errorf("expected inherited server to be found: %v", err)
+	}
+
+	// Inherited servers are never written back to the active config
+	if len(cfg.Servers) != 1 {
+		t.Errorf("expected 1 own server, got %d", len(cfg.Servers))
+	}
+}
+
+func TestConfig_RemoveServer_Inherited(t *testing.T) {
+	withSystemConfig(t, `{"servers":[{"name":"org-proxy","type":"http","url":"https://proxy.example.com/mcp"}]}`)
+
+	cfg, err := LoadFromPath(filepath.Join(t.TempDir(), "config.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err = cfg.RemoveServer("org-proxy")
+	if err == nil {
+		t.Fatal("expected error removing inherited server, got nil")
+	}
+	if !strings.Contains(err.Error(), "system") {
+		t.Errorf("expected error to mention the system layer, got %q", err.Error())
+	}
+}
+
+func TestConfig_Layers(t *testing.T) {
+	systemPath := withSystemConfig(t, `{"servers":[{"name":"org-proxy","type":"http","url":"https://proxy.example.com/mcp"}]}`)
+
+	projectPath := filepath.Join(t.TempDir(), configFileName)
+	cfg, err := LoadFromPath(projectPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cfg.AddServer(MCPServer{Name: "local", Type: "stdio", Command: "local-cmd"})
+
+	layers := cfg.Layers()
+	if len(layers) != 2 {
+		t.Fatalf("expected 2 layers, got %d", len(layers))
+	}
+	if layers[0].Name != LayerSystem || layers[0].Path != systemPath || !layers[0].Exists {
+		t.Errorf("unexpected system layer: %+v", layers[0])
+	}
+	if layers[1].Name != LayerProject || layers[1].Path != projectPath || layers[1].Exists {
+		t.Errorf("unexpected project layer: %+v", layers[1])
+	}
+
+	if got := cfg.ServerLayer("org-proxy"); got != LayerSystem {
+		t.Errorf("expected 'org-proxy' from system layer, got %q", got)
+	}
+	if got := cfg.ServerLayer("local"); got != LayerProject {
+		t.Errorf("expected 'local' from project layer, got %q", got)
+	}
+	if got := cfg.ServerLayer("missing"); got != "" {
+		t.Errorf("expected no layer for 'missing', got %q", got)
+	}
+}
+
+func TestLoadFromPath_SystemLayerNotFound(t *testing.T) {
+	withSystemConfig(t, "")
+
+	cfg, err := LoadFromPath(filepath.Join(t.TempDir(), "config.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	layers := cfg.Layers()
+	if layers[0].Exists {
+		t.Error("expected missing system layer to be reported as not existing")
+	}
+	if len(cfg.ListServers()) != 0 {
+		t.Errorf("expected no servers, got %d", len(cfg.ListServers()))
+	}
+}
+
+func TestLoadFromPath_InvalidSystemLayer(t *testing.T) {
+	withSystemConfig(t, `{invalid`)
+
+	_, err := LoadFromPath(filepath.Join(t.TempDir(), "config.json"))
+	if err == nil {
+		t.Error("expected error for invalid system config, got nil")
+	}
+}
+
+func TestLoadFromPath_MergesParentProjectConfigs(t *testing.T) {
+	withSystemConfig(t, "")
+
+	root := t.TempDir()
+	pkg := filepath.Join(root, "packages", "web")
+	if err := os.MkdirAll(pkg, 0755); err != nil {
+		t.Fatal(err)
+	}
+	rootPath := filepath.Join(root, configFileName)
+	err := os.WriteFile(rootPath, []byte(`{"servers":[
+		{"name":"git","type":"stdio","command":"root-git"},
+		{"name":"shared","type":"stdio","command":"root-cmd"}
+	]}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkgPath := filepath.Join(pkg, configFileName)
+	err = os.WriteFile(pkgPath, []byte(`{"servers":[{"name":"shared","type":"stdio","command":"pkg-cmd"}]}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFromPath(pkgPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	layers := cfg.Layers()
+	if len(layers) != 3 {
+		t.Fatalf("expected system, root and package layers, got %d", len(layers))
+	}
+	if layers[1].Name != LayerProject || layers[1].Path != rootPath {
+		t.Errorf("unexpected root layer: %+v", layers[1])
+	}
+
+	shared, err := cfg.GetServer("shared")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if shared.Command != "pkg-cmd" {
+		t.Errorf("expected the nearest config to win, got command %q", shared.Command)
+	}
+	if _, err := cfg.GetServer("git"); err != nil {
+		t.Errorf("expected 'git' from the root config: %v", err)
+	}
+	if len(cfg.Servers) != 1 {
+		t.Errorf("expected 1 own server, got %d", len(cfg.Servers))
+	}
+
+	// The root config itself has no parents to merge
+	cfg, err = LoadFromPath(rootPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.Layers()) != 2 {
+		t.Errorf("expected system and root layers, got %d", len(cfg.Layers()))
+	}
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrLocked is returned for changes to a locked config or server
+var ErrLocked = errors.New("locked")
+
+// Unlock lets the config's locked servers and settings be changed
+func (c *Config) Unlock() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.unlocked = true
+}
+
+// CheckLocked returns an error wrapping ErrLocked if the config, or any of
+// the servers named, is locked and the config wasn't unlocked. Commands
+// changing fields directly, like aliases, check it first.
+func (c *Config) CheckLocked(names ...string) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.checkLocked(names...)
+}
+
+// checkLocked is CheckLocked with c.mu held
+func (c *Config) checkLocked(names ...string) error {
+	if c.unlocked {
+		return nil
+	}
+	if c.Locked {
+		return fmt.Errorf("%s is %w", c.path, ErrLocked)
+	}
+	for _, s := range c.Servers {
+		if s.Locked && slices.Contains(names, s.Name) {
+			return fmt.Errorf("server %q is %w", s.Name, ErrLocked)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocked(t *testing.T) {
+	cfg, err := LoadFromPath(filepath.Join(t.TempDir(), "mcpr.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.AddServer(MCPServer{Name: "org", Type: "stdio", Command: "npx", Locked: true})
+	cfg.AddServer(MCPServer{Name: "mine", Type: "stdio", Command: "npx"})
+
+	// Locked servers stay, others can go
+	if err := cfg.RemoveServer("org"); !errors.Is(err, ErrLocked) {
+		t.Errorf("expected removing a locked server to fail, got %v", err)
+	}
+	if err := cfg.RemoveServer("mine"); err != nil {
+		t.Errorf("expected an unlocked server to be removed, got %v", err)
+	}
+
+	// A locked config takes no changes
+	cfg.Locked = true
+	if err := cfg.AddServer(MCPServer{Name: "new", Type: "stdio", Command: "npx"}); !errors.Is(err, ErrLocked) {
+		t.Errorf("expected adding to a locked config to fail, got %v", err)
+	}
+	if err := cfg.CheckLocked(); !errors.Is(err, ErrLocked) {
+		t.Errorf("expected the config to be locked, got %v", err)
+	}
+
+	cfg.Unlock()
+	if err := cfg.RemoveServer("org"); err != nil {
+		t.Errorf("expected an unlocked config to change, got %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"maps"
+	"os"
+	"slices"
+	"strings"
+)
+
+// MachineEnv names this machine for machine overrides, in addition to its
+// hostname
+const MachineEnv = "MCPR_MACHINE"
+
+// hostname is a variable for testing
+var hostname = os.Hostname
+
+// MachineOverride adjusts servers on the machines it is keyed by, so one
+// config shared between computers fits each of them
+type MachineOverride struct {
+	Servers map[string]ServerOverride `json:"servers,omitempty"` // By server name
+}
+
+// ServerOverride replaces the fields it sets in the server of the same
+// name. Env vars and headers are merged into the server's.
+type ServerOverride struct {
+	Command  string            `json:"command,omitempty"`
+	Args     []string          `json:"args,omitempty"`
+	Env      map[string]string `json:"env,omitempty"`
+	EnvFile  string            `json:"envFile,omitempty"`
+	URL      string            `json:"url,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	Path     string            `json:"path,omitempty"`
+	Disabled *bool             `json:"disabled,omitempty"`
+}
+
+// MachineNames returns the names machine overrides match this machine by,
+// from least to most specific: its hostname without the domain, its full
+// hostname and $MCPR_MACHINE
+func MachineNames() []string {
+	var names []string
+	if host, err := hostname(); err == nil && host != "" {
+		short, _, _ := strings.Cut(host, ".")
+		names = append(names, short)
+		if host != short {
+			names = append(names, host)
+		}
+	}
+	if name := os.Getenv(MachineEnv); name != "" {
+		names = append(names, name)
+	}
+	return names
+}
+
+// MatchingMachines returns the keys of machines matching this machine, in
+// the order their overrides apply. Keys are matched case-insensitively.
+func MatchingMachines(machines map[string]MachineOverride) []string {
+	var keys []string
+	for _, name := range MachineNames() {
+		for _, key := range slices.Sorted(maps.Keys(machines)) {
+			if strings.EqualFold(key, name) && !slices.Contains(keys, key) {
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+// applyMachineOverrides returns copies of servers with the overrides for
+// this machine applied
+func applyMachineOverrides(servers []MCPServer, machines map[string]MachineOverride) []MCPServer {
+	keys := MatchingMachines(machines)
+	if len(keys) == 0 {
+		return servers
+	}
+
+	applied := make([]MCPServer, len(servers))
+	for i, server := range servers {
+		for _, key := range keys {
+			if o, ok := machines[key].Servers[server.Name]; ok {
+				server = o.Apply(server)
+			}
+		}
+		applied[i] = server
+	}
+	return applied
+}
+
+// Apply returns server with the override's fields
+func (o ServerOverride) Apply(server MCPServer) MCPServer {
+	if o.Command != "" {
+		server.Command = o.Command
+	}
+	if o.Args != nil {
+		server.Args = slices.Clone(o.Args)
+	}
+	if len(o.Env) > 0 {
+		server.Env = maps.Clone(server.Env)
+		if server.Env == nil {
+			server.Env = make(map[string]string, len(o.Env))
+		}
+		maps.Copy(server.Env, o.Env)
+	}
+	if o.EnvFile != "" {
+		server.EnvFile = o.EnvFile
+	}
+	if o.URL != "" {
+		server.URL = o.URL
+	}
+	if len(o.Headers) > 0 {
+		server.Headers = maps.Clone(server.Headers)
+		if server.Headers == nil {
+			server.Headers = make(map[string]string, len(o.Headers))
+		}
+		maps.Copy(server.Headers, o.Headers)
+	}
+	if o.Path != "" {
+		server.Path = o.Path
+	}
+	if o.Disabled != nil {
+		server.Disabled = *o.Disabled
+	}
+	return server
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// withHostname makes the machine's hostname host for the test
+func withHostname(t *testing.T, host string) {
+	t.Helper()
+	original := hostname
+	hostname = func() (string, error) { return host, nil }
+	t.Cleanup(func() { hostname = original })
+}
+
+func TestMachineNames(t *testing.T) {
+	withHostname(t, "laptop.example.com")
+	t.Setenv(MachineEnv, "")
+	if got, want := MachineNames(), []string{"laptop", "laptop.example.com"}; !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	t.Setenv(MachineEnv, "work")
+	if got, want := MachineNames(), []string{"laptop", "laptop.example.com", "work"}; !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestLoadFromPath_AppliesMachineOverrides(t *testing.T) {
+	withHostname(t, "Laptop.local")
+	t.Setenv(MachineEnv, "work")
+	withSystemConfig(t, `{
+		"servers": [{"name": "org", "type": "http", "url": "https://org.example.com/mcp"}],
+		"machines": {"laptop": {"servers": {"org": {"headers": {"X-Team": "ops"}}}}}
+	}`)
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	err := os.WriteFile(path, []byte(`{
+		"servers": [
+			{"name": "fs", "type": "stdio", "command": "mcp-fs", "args": ["/home/me"], "env": {"A": "1", "B": "2"}},
+			{"name": "notes", "type": "stdio", "command": "notes"}
+		],
+		"machines": {
+			"LAPTOP": {"servers": {"fs": {"args": ["/Users/me"], "env": {"B": "laptop"}}}},
+			"work": {"servers": {"fs": {"env": {"B": "work"}}, "notes": {"disabled": true}}},
+			"desktop": {"servers": {"fs": {"command": "other"}}}
+		}
+	}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fs, err := cfg.GetServer("fs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fs.Command != "mcp-fs" || !slices.Equal(fs.Args, []string{"/Users/me"}) {
+		t.Errorf("expected the laptop args, got %s %v", fs.Command, fs.Args)
+	}
+	// $MCPR_MACHINE is more specific than the hostname, so it applies last
+	if fs.Env["A"] != "1" || fs.Env["B"] != "work" {
+		t.Errorf("expected merged env, got %v", fs.Env)
+	}
+	if notes, _ := cfg.GetServer("notes"); !notes.Disabled {
+		t.Error("expected notes to be disabled on this machine")
+	}
+	if org, _ := cfg.GetServer("org"); org.Headers["X-Team"] != "ops" {
+		t.Errorf("expected the system layer's override, got %v", org.Headers)
+	}
+
+	// The config keeps the shared definitions, so saving writes no overrides
+	if cfg.Servers[0].Env["B"] != "2" || cfg.Servers[0].Args[0] != "/home/me" || cfg.Servers[1].Disabled {
+		t.Errorf("expected overrides to leave the config untouched, got %+v", cfg.Servers)
+	}
+}
+
+func TestConfig_ReloadMachineOverrides(t *testing.T) {
+	withHostname(t, "laptop")
+	t.Setenv(MachineEnv, "")
+	path := filepath.Join(t.TempDir(), "config.json")
+	os.WriteFile(path, []byte(`{
+		"servers": [{"name": "fs", "type": "stdio", "command": "mcp-fs", "args": ["/home/me"]}],
+		"machines": {"laptop": {"servers": {"fs": {"args": ["/Users/me"]}}}}
+	}`), 0644)
+	cfg, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The override is removed on disk
+	os.WriteFile(path, []byte(`{"servers": [{"name": "fs", "type": "stdio", "command": "mcp-fs", "args": ["/home/me"]}]}`), 0644)
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(path, later, later)
+	if changed, err := cfg.Reload(); err != nil || !changed {
+		t.Fatalf("expected the config to be reloaded, got %v, %v", changed, err)
+	}
+	if fs, _ := cfg.GetServer("fs"); !slices.Equal(fs.Args, []string{"/home/me"}) {
+		t.Errorf("expected the removed override to no longer apply, got %v", fs.Args)
+	}
+}
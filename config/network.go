@@ -0,0 +1,83 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Network configures the HTTP requests mcpr makes: to http servers, remotes
+// and registries
+type Network struct {
+	Proxy    string `json:"proxy,omitempty"`    // Proxy URL; HTTPS_PROXY, HTTP_PROXY and NO_PROXY are honored when unset
+	NoProxy  string `json:"noProxy,omitempty"`  // Comma-separated hosts, domains or CIDRs reached without the proxy
+	CABundle string `json:"caBundle,omitempty"` // PEM file of CA certificates to trust besides the system ones, relative to the config
+	Timeout  int    `json:"timeout,omitempty"`  // Seconds to connect, including the TLS handshake
+}
+
+// NetworkSettings returns the network settings in effect: those of the
+// system config, overridden field by field by the global config and then
+// the active config, so a proxy set once applies in every project
+func (c *Config) NetworkSettings() Network {
+	c.mu.RLock()
+	path, own := c.path, c.Network
+	c.mu.RUnlock()
+
+	var settings Network
+	for _, p := range outerConfigPaths(path) {
+		if cfg := readConfig(p); cfg != nil {
+			settings.merge(cfg.Network, p)
+		}
+	}
+	settings.merge(own, path)
+	return settings
+}
+
+// outerConfigPaths returns the paths of the system and global configs,
+// leaving out the config at path
+func outerConfigPaths(path string) []string {
+	var paths []string
+	if systemPath, err := getSystemConfigPath(); err == nil && !sameFile(systemPath, path) {
+		paths = append(paths, systemPath)
+	}
+	if globalPath, err := getGlobalConfigPath(); err == nil && !sameFile(globalPath, path) {
+		paths = append(paths, globalPath)
+	}
+	return paths
+}
+
+// merge replaces the fields of n that other, read from the config at path,
+// sets
+func (n *Network) merge(other *Network, path string) {
+	if other == nil {
+		return
+	}
+	if other.Proxy != "" {
+		n.Proxy = other.Proxy
+	}
+	if other.NoProxy != "" {
+		n.NoProxy = other.NoProxy
+	}
+	if other.CABundle != "" {
+		n.CABundle = other.CABundle
+		if !filepath.IsAbs(n.CABundle) {
+			n.CABundle = filepath.Join(filepath.Dir(path), n.CABundle)
+		}
+	}
+	if other.Timeout != 0 {
+		n.Timeout = other.Timeout
+	}
+}
+
+// readConfig returns the settings of the config at path, without its
+// layers or state, or nil if it can't be read
+func readConfig(path string) *Config {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var cfg Config
+	if err := decodeConfig(data, FormatForPath(path), &cfg); err != nil {
+		return nil
+	}
+	return &cfg
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfig_NetworkSettings(t *testing.T) {
+	withSystemConfig(t, `{"servers":[],"network":{"proxy":"http://proxy.corp:3128","noProxy":"corp","timeout":5}}`)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	globalDir := filepath.Join(home, ".config", "mcpr")
+	if err := os.MkdirAll(globalDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	err := os.WriteFile(filepath.Join(globalDir, "config.json"), []byte(`{"servers":[],"network":{"caBundle":"corp-ca.pem","timeout":10}}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	project := filepath.Join(t.TempDir(), configFileName)
+	os.WriteFile(project, []byte(`{"servers":[],"network":{"noProxy":"localhost"}}`), 0644)
+	cfg, err := LoadFromPath(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := Network{
+		Proxy:    "http://proxy.corp:3128",
+		NoProxy:  "localhost",
+		CABundle: filepath.Join(globalDir, "corp-ca.pem"),
+		Timeout:  10,
+	}
+	if got := cfg.NetworkSettings(); got != want {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
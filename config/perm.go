@@ -0,0 +1,164 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strconv"
+	"sync/atomic"
+)
+
+// Modes for newly written config files
+const (
+	DefaultFileMode os.FileMode = 0o644
+	SecretFileMode  os.FileMode = 0o600
+)
+
+// fileModeOverride is the mode for new files set by the config loaded last,
+// or 0 to choose by content. It is shared with client config writes, which
+// don't see the Config, so it is atomic rather than guarded by Config.mu.
+var fileModeOverride atomic.Uint32
+
+// ParseFileMode parses an octal file mode such as "0600"
+func ParseFileMode(s string) (os.FileMode, error) {
+	mode, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || mode > 0o777 {
+		return 0, fmt.Errorf("invalid file mode %q (expected octal permissions like \"0600\")", s)
+	}
+	return os.FileMode(mode), nil
+}
+
+// applyFileMode makes the config's fileMode the mode for new files, or
+// lets modes be chosen by content again if it has none
+func (c *Config) applyFileMode() error {
+	var mode os.FileMode
+	if c.FileMode != "" {
+		var err error
+		if mode, err = ParseFileMode(c.FileMode); err != nil {
+			return err
+		}
+	}
+	fileModeOverride.Store(uint32(mode))
+	return nil
+}
+
+// HasSecrets reports whether any server carries env vars or headers, which
+// commonly hold API keys and tokens
+func HasSecrets(servers []MCPServer) bool {
+	for _, s := range servers {
+		if len(s.Env) > 0 || len(s.Headers) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteFile writes a config file. New files get the configured fileMode, or
+// 0600 when they hold secrets and 0644 otherwise. Existing files keep their
+// permissions; a warning is printed when one holding secrets is readable by
+// other users. The file is replaced atomically, see AtomicWriteFile, and
+// keeps its owner.
+func WriteFile(path string, data []byte, secret bool) error {
+	if info, err := os.Stat(path); err == nil {
+		perm := info.Mode().Perm()
+		if secret && perm&0o077 != 0 && runtime.GOOS != "windows" {
+			fmt.Fprintf(os.Stderr, "Warning: %s contains secrets but is readable by other users (mode %04o); run 'chmod 600 %s'\n", path, perm, path)
+		}
+		return AtomicWriteFile(path, data, perm)
+	}
+
+	mode := DefaultFileMode
+	if override := os.FileMode(fileModeOverride.Load()); override != 0 {
+		mode = override
+	} else if secret {
+		mode = SecretFileMode
+	}
+	return AtomicWriteFile(path, data, mode)
+}
+
+// AtomicWriteFile writes data to a temporary file next to path, flushes it
+// to disk and renames it over path, so a crash or a kill leaves either what
+// the file held before or all of data, never part of it. A symlink at path
+// is followed, replacing the file it points to rather than the link. The
+// new file gets the owner of the old one; a file whose owner can't be kept
+// that way, because the caller may not give files away, is rewritten in
+// place instead.
+func AtomicWriteFile(path string, data []byte, perm os.FileMode) error {
+	if target, err := filepath.EvalSymlinks(path); err == nil {
+		path = target
+	}
+	return RetryWrite(func() error { return replaceFile(path, data, perm) })
+}
+
+// replaceFile is one attempt of AtomicWriteFile
+func replaceFile(path string, data []byte, perm os.FileMode) (err error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(tmp.Name())
+		}
+	}()
+	if _, err = tmp.Write(data); err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if err = os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	// The renamed file is owned by whoever wrote it, so it is given the
+	// owner of the file it replaces. Where that isn't allowed, such as for a
+	// group-writable file of another user, the file is rewritten in place
+	// instead so it keeps its owner.
+	if uid, gid, ok := fileOwner(path); ok {
+		if tmpUID, tmpGID, _ := fileOwner(tmp.Name()); (tmpUID != uid || tmpGID != gid) && os.Chown(tmp.Name(), uid, gid) != nil {
+			os.Remove(tmp.Name())
+			return writeInPlace(path, data)
+		}
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// writeInPlace truncates the file at path and writes data to it, keeping
+// its owner, permissions and extended attributes
+func writeInPlace(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_TRUNC, 0)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(data); err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// fileOwner returns the uid and gid owning the file at path, where the
+// platform has them. They are read from the stat result by name, which Unix
+// stat results have and Windows ones don't, so no build tags are needed.
+func fileOwner(path string) (uid, gid int, ok bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, 0, false
+	}
+	v := reflect.Indirect(reflect.ValueOf(info.Sys()))
+	if v.Kind() != reflect.Struct {
+		return 0, 0, false
+	}
+	u, g := v.FieldByName("Uid"), v.FieldByName("Gid")
+	if !u.IsValid() || !g.IsValid() || !u.CanUint() || !g.CanUint() {
+		return 0, 0, false
+	}
+	return int(u.Uint()), int(g.Uint()), true
+}
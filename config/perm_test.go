@@ -0,0 +1,227 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseFileMode(t *testing.T) {
+	mode, err := ParseFileMode("0600")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mode != 0o600 {
+		t.Errorf("expected mode 0600, got %04o", mode)
+	}
+
+	for _, s := range []string{"rw-------", "0800", "01777"} {
+		if _, err := ParseFileMode(s); err == nil {
+			t.Errorf("expected error for %q", s)
+		}
+	}
+}
+
+func TestHasSecrets(t *testing.T) {
+	if HasSecrets([]MCPServer{{Name: "fs", Command: "npx"}}) {
+		t.Error("expected no secrets without env or headers")
+	}
+	if !HasSecrets([]MCPServer{{Name: "fs", Command: "npx"}, {Name: "api", Headers: map[string]string{"Authorization": "x"}}}) {
+		t.Error("expected headers to count as secrets")
+	}
+}
+
+func TestWriteFile_Modes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file permissions are not enforced on Windows")
+	}
+	dir := t.TempDir()
+
+	plain := filepath.Join(dir, "plain.json")
+	if err := WriteFile(plain, []byte("{}"), false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	secret := filepath.Join(dir, "secret.json")
+	if err := WriteFile(secret, []byte("{}"), true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if info, _ := os.Stat(secret); info.Mode().Perm() != SecretFileMode {
+		t.Errorf("expected new secret file to be %04o, got %04o", SecretFileMode, info.Mode().Perm())
+	}
+	if info, _ := os.Stat(plain); info.Mode().Perm()&0o600 != 0o600 {
+		t.Errorf("expected plain file to be readable and writable by owner, got %04o", info.Mode().Perm())
+	}
+}
+
+func TestWriteFile_PreservesExistingMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file permissions are not enforced on Windows")
+	}
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte("{}"), 0o640); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := os.Chmod(path, 0o640); err != nil {
+		t.Fatalf("failed to chmod: %v", err)
+	}
+
+	if err := WriteFile(path, []byte(`{"a":1}`), true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	info, _ := os.Stat(path)
+	if info.Mode().Perm() != 0o640 {
+		t.Errorf("expected existing mode 0640 to be kept, got %04o", info.Mode().Perm())
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != `{"a":1}` {
+		t.Errorf("expected content to be rewritten, got %q", data)
+	}
+}
+
+func TestWriteFile_Atomic(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "dotfiles", "config.json")
+	os.MkdirAll(filepath.Dir(target), 0o755)
+	os.WriteFile(target, []byte("{}"), 0o644)
+	link := filepath.Join(dir, "config.json")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	if err := WriteFile(link, []byte(`{"a":1}`), false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The file the link points to is replaced, not the link
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("expected %s to stay a symlink", link)
+	}
+	if data, _ := os.ReadFile(target); string(data) != `{"a":1}` {
+		t.Errorf("expected the link target to be rewritten, got %q", data)
+	}
+	// No temporary files are left behind
+	for _, d := range []string{dir, filepath.Dir(target)} {
+		entries, _ := os.ReadDir(d)
+		for _, e := range entries {
+			if strings.HasSuffix(e.Name(), ".tmp") {
+				t.Errorf("temporary file %s left behind", e.Name())
+			}
+		}
+	}
+}
+
+func TestWriteFile_KeepsOwner(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	os.WriteFile(path, []byte("{}"), 0o640)
+	os.Chmod(path, 0o640)
+	uid, gid, ok := fileOwner(path)
+	if runtime.GOOS == "windows" {
+		if ok {
+			t.Error("expected no owner on windows")
+		}
+	} else if !ok || uid != os.Getuid() {
+		t.Fatalf("expected the file to be owned by %d, got %d (%v)", os.Getuid(), uid, ok)
+	}
+	if os.Getuid() == 0 {
+		// Root may give the file away, as a config of another user
+		uid, gid = 4242, 4242
+		if err := os.Chown(path, uid, gid); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := WriteFile(path, []byte(`{"a":1}`), true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info, _ := os.Stat(path); runtime.GOOS != "windows" && info.Mode().Perm() != 0o640 {
+		t.Errorf("expected mode 0640 to be kept, got %04o", info.Mode().Perm())
+	}
+	if gotUID, gotGID, _ := fileOwner(path); gotUID != uid || gotGID != gid {
+		t.Errorf("expected owner %d:%d to be kept, got %d:%d", uid, gid, gotUID, gotGID)
+	}
+
+	// Files whose owner can't be kept are rewritten in place
+	before, _ := os.Stat(path)
+	if err := writeInPlace(path, []byte(`{"b":2}`)); err != nil {
+		t.Fatal(err)
+	}
+	after, _ := os.Stat(path)
+	if data, _ := os.ReadFile(path); string(data) != `{"b":2}` || !os.SameFile(before, after) {
+		t.Errorf("expected the same file to be rewritten, got %q", data)
+	}
+}
+
+func TestLoadFromPath_FileMode(t *testing.T) {
+	defer fileModeOverride.Store(0)
+
+	path := filepath.Join(t.TempDir(), "mcpr.json")
+	if err := os.WriteFile(path, []byte(`{"fileMode": "0640", "servers": []}`), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	cfg, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := fileModeOverride.Load(); got != 0o640 {
+		t.Errorf("expected file mode override 0640, got %04o", got)
+	}
+
+	// Removing fileMode and reloading chooses modes by content again
+	if err := os.WriteFile(path, []byte(`{"servers": []}`), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(path, later, later)
+	if _, err := cfg.Reload(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := fileModeOverride.Load(); got != 0 {
+		t.Errorf("expected no file mode override after reloading, got %04o", got)
+	}
+
+	// As does loading another config without it
+	fileModeOverride.Store(0o640)
+	if _, err := LoadFromPath(filepath.Join(t.TempDir(), "mcpr.json")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := fileModeOverride.Load(); got != 0 {
+		t.Errorf("expected no file mode override for a config without fileMode, got %04o", got)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"fileMode": "640x", "servers": []}`), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if _, err := LoadFromPath(path); err == nil {
+		t.Error("expected error for invalid fileMode")
+	}
+}
+
+func TestLoad_ResetsFileMode(t *testing.T) {
+	defer fileModeOverride.Store(0)
+	withSystemConfig(t, "")
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Chdir(t.TempDir())
+
+	path := filepath.Join(t.TempDir(), "mcpr.json")
+	os.WriteFile(path, []byte(`{"fileMode": "0640", "servers": []}`), 0o644)
+	if _, err := LoadFromPath(path); err != nil {
+		t.Fatal(err)
+	}
+	if got := fileModeOverride.Load(); got != 0o640 {
+		t.Fatalf("expected file mode override 0640, got %04o", got)
+	}
+
+	// Finding no config at all chooses modes by content again
+	if _, err := Load(); err != nil {
+		t.Fatal(err)
+	}
+	if got := fileModeOverride.Load(); got != 0 {
+		t.Errorf("expected no file mode override without a config, got %04o", got)
+	}
+}
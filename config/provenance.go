@@ -0,0 +1,62 @@
+package config
+
+import (
+	"strings"
+	"time"
+)
+
+// Where servers come from
+const (
+	SourceManual   = "manual"    // mcpr add stdio, http or socket
+	SourcePackage  = "package"   // mcpr add node or python
+	SourceImport   = "import"    // mcpr import, from another manager's client config
+	SourceBundle   = "bundle"    // mcpr bundle import
+	SourceLink     = "link"      // mcpr add link or clipboard
+	SourceScaffold = "scaffold"  // mcpr new server
+	SourceMCPServe = "mcp-serve" // The add_server tool of mcpr mcp-serve
+)
+
+// Provenance records where a server came from, so it can be traced and
+// refreshed from there
+type Provenance struct {
+	Source   string    `json:"source" jsonschema:"enum=manual|package|import|bundle|link|scaffold|mcp-serve"`
+	Registry string    `json:"registry,omitempty"` // Package registry: "npm" or "pypi"
+	Package  string    `json:"package,omitempty"`  // Package name, e.g. "@modelcontextprotocol/server-memory"
+	Version  string    `json:"version,omitempty"`  // Pinned package version; empty for the latest
+	Manager  string    `json:"manager,omitempty"`  // Manager imported from, e.g. "smithery"
+	From     string    `json:"from,omitempty"`     // File the server came from: a client config, bundle or project directory
+	Added    time.Time `json:"added,omitzero"`
+}
+
+// String describes the provenance in a few words, e.g. "npm package
+// @modelcontextprotocol/server-memory@2025.4.25" or "imported from smithery
+// (~/.cursor/mcp.json)"
+func (p Provenance) String() string {
+	var b strings.Builder
+	switch p.Source {
+	case SourcePackage:
+		b.WriteString(p.Registry + " package " + p.Package)
+		if p.Version != "" {
+			b.WriteString("@" + p.Version)
+		}
+	case SourceImport:
+		b.WriteString("imported from " + p.Manager)
+	case SourceBundle:
+		b.WriteString("bundle")
+	case SourceLink:
+		b.WriteString("shared link")
+	case SourceScaffold:
+		b.WriteString("scaffolded")
+	case SourceMCPServe:
+		b.WriteString("added through mcp-serve")
+	default:
+		b.WriteString(p.Source)
+	}
+	if p.From != "" {
+		b.WriteString(" (" + p.From + ")")
+	}
+	if !p.Added.IsZero() {
+		b.WriteString(", added " + p.Added.Local().Format("2006-01-02"))
+	}
+	return b.String()
+}
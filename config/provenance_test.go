@@ -0,0 +1,20 @@
+package config
+
+import "testing"
+
+func TestProvenanceString(t *testing.T) {
+	tests := []struct {
+		p    Provenance
+		want string
+	}{
+		{Provenance{Source: SourcePackage, Registry: "npm", Package: "@scope/server", Version: "1.2.0"}, "npm package @scope/server@1.2.0"},
+		{Provenance{Source: SourcePackage, Registry: "pypi", Package: "mcp-server-fetch"}, "pypi package mcp-server-fetch"},
+		{Provenance{Source: SourceImport, Manager: "smithery", From: "/home/me/.cursor/mcp.json"}, "imported from smithery (/home/me/.cursor/mcp.json)"},
+		{Provenance{Source: SourceManual}, "manual"},
+	}
+	for _, tt := range tests {
+		if got := tt.p.String(); got != tt.want {
+			t.Errorf("expected %q, got %q", tt.want, got)
+		}
+	}
+}
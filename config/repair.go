@@ -0,0 +1,337 @@
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// backupLimit is the number of earlier versions kept per config file
+const backupLimit = 5
+
+// ParseError is returned when a config file doesn't parse. For JSON it
+// carries the line and column of the syntax error and the lines around it.
+type ParseError struct {
+	Path    string
+	Line    int    // 1-based; 0 if unknown
+	Column  int    // 1-based; 0 if unknown
+	Snippet string // The offending line and the one before, with a caret under the column
+	Err     error
+}
+
+func (e *ParseError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("failed to parse config %s: %v", e.Path, e.Err)
+	}
+	return fmt.Sprintf("failed to parse config %s: line %d, column %d: %v\n%s", e.Path, e.Line, e.Column, e.Err, e.Snippet)
+}
+
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// newParseError describes err, returned while decoding the config data read
+// from path, as a ParseError. JSON errors are located in data; the JSONC
+// parser already reports a position, which is taken from its message.
+func newParseError(path string, data []byte, format Format, err error) error {
+	e := &ParseError{Path: path, Err: err}
+	if format != FormatJSON {
+		return e
+	}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		e.Line, e.Column = lineColumn(data, syntaxErr.Offset)
+	case errors.As(err, &typeErr):
+		e.Line, e.Column = lineColumn(data, typeErr.Offset)
+	default:
+		msg := err.Error()
+		if n, _ := fmt.Sscanf(msg, "line %d, column %d:", &e.Line, &e.Column); n == 2 {
+			e.Err = errors.New(strings.TrimSpace(msg[strings.Index(msg, ":")+1:]))
+		} else {
+			e.Line, e.Column = 0, 0
+		}
+	}
+	if e.Line > 0 {
+		e.Snippet = snippet(data, e.Line, e.Column)
+	}
+	return e
+}
+
+// snippet returns line of data and the one before it, numbered, with a
+// caret under column
+func snippet(data []byte, line, column int) string {
+	lines := strings.Split(string(data), "\n")
+	if line > len(lines) {
+		line = len(lines)
+	}
+	width := len(fmt.Sprint(line))
+	var b strings.Builder
+	for n := max(line-1, 1); n <= line; n++ {
+		fmt.Fprintf(&b, "  %*d | %s\n", width, n, strings.TrimRight(lines[n-1], "\r"))
+	}
+	// Tabs are kept so the caret lines up however wide they are shown
+	text := lines[line-1]
+	indent := []rune(text[:min(max(column-1, 0), len(text))])
+	for i, r := range indent {
+		if r != '\t' {
+			indent[i] = ' '
+		}
+	}
+	fmt.Fprintf(&b, "  %*s | %s^", width, "", string(indent))
+	return b.String()
+}
+
+// backupDir returns the directory holding earlier versions of the config at
+// path. Configs of different projects are kept apart by keying on the path.
+func backupDir(path string) (string, error) {
+	state, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	sum := sha256.Sum256([]byte(path))
+	return filepath.Join(state, "backups", hex.EncodeToString(sum[:6])), nil
+}
+
+// keepBackup keeps data, a version of the config at path that parsed, so
+// 'mcpr config repair' can return to it. Only the newest backupLimit are
+// kept.
+func keepBackup(path string, data []byte) error {
+	dir, err := backupDir(path)
+	if err != nil {
+		return err
+	}
+	backups, err := listBackups(dir)
+	if err != nil {
+		return err
+	}
+	if len(backups) > 0 {
+		if latest, err := os.ReadFile(backups[len(backups)-1]); err == nil && bytes.Equal(latest, data) {
+			return nil
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	// Configs may hold secrets, so backups are private
+	name := time.Now().UTC().Format("20060102T150405.000000000Z")
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0o600); err != nil {
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+
+	backups = append(backups, filepath.Join(dir, name))
+	for len(backups) > backupLimit {
+		os.Remove(backups[0])
+		backups = backups[1:]
+	}
+	return nil
+}
+
+// listBackups returns the backup files in dir, oldest first
+func listBackups(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backups: %w", err)
+	}
+	var backups []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			backups = append(backups, filepath.Join(dir, entry.Name()))
+		}
+	}
+	// Names are UTC timestamps, so lexical order is chronological
+	slices.Sort(backups)
+	return backups, nil
+}
+
+// Backup is an earlier version of a config file
+type Backup struct {
+	Path  string
+	Saved time.Time
+}
+
+// Backups returns the earlier versions kept of the config at path, newest
+// first. A version is kept each time mcpr saves over it.
+func Backups(path string) ([]Backup, error) {
+	dir, err := backupDir(path)
+	if err != nil {
+		return nil, err
+	}
+	files, err := listBackups(dir)
+	if err != nil {
+		return nil, err
+	}
+	var backups []Backup
+	for _, file := range slices.Backward(files) {
+		saved, err := time.Parse("20060102T150405.000000000Z", filepath.Base(file))
+		if err != nil {
+			continue
+		}
+		backups = append(backups, Backup{Path: file, Saved: saved})
+	}
+	return backups, nil
+}
+
+// RepairJSON attempts a lenient parse of a JSON config that doesn't parse:
+// strings, objects and arrays left open by a truncated file are closed,
+// keys left without a value get null, missing commas between values are
+// inserted, stray closing brackets are dropped, and anything after the
+// top-level value is cut off. It returns the repaired config, or an error
+// if it still isn't valid.
+func RepairJSON(data []byte) ([]byte, error) {
+	var out []byte
+	var stack []byte // Closing brackets of the open objects and arrays
+	inString, escaped, isKey := false, false, false
+	ended := false     // Whether the last token ended a value
+	valueEnd := 0      // Where in out the last value ended
+	expectKey := false // Whether the next string is an object key
+	needValue := false // Whether a key or ':' awaits its value
+
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		if inString {
+			out = append(out, c)
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+				if isKey {
+					needValue = true
+				} else {
+					ended, valueEnd = true, len(out)
+				}
+			}
+			continue
+		}
+
+		switch {
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			end := bytes.IndexByte(data[i:], '\n')
+			if end < 0 {
+				end = len(data) - i
+			}
+			out = append(out, data[i:i+end]...)
+			i += end - 1
+			continue
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			end := bytes.Index(data[i+2:], []byte("*/"))
+			if end < 0 {
+				// An unterminated comment runs to the end of the file
+				i = len(data)
+				continue
+			}
+			out = append(out, data[i:i+end+4]...)
+			i += end + 3
+			continue
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			out = append(out, c)
+			continue
+		}
+
+		if len(stack) == 0 && ended {
+			// Anything after the top-level value is dropped
+			break
+		}
+		switch c {
+		case '}', ']':
+			if len(stack) == 0 || stack[len(stack)-1] != c {
+				continue
+			}
+			out = closeValue(out, isKey && needValue, needValue)
+			stack = stack[:len(stack)-1]
+			out = append(out, c)
+			ended, expectKey, needValue, isKey = true, false, false, false
+			valueEnd = len(out)
+		case ',':
+			if !ended {
+				continue
+			}
+			out = append(out, c)
+			ended = false
+			expectKey = stack[len(stack)-1] == '}'
+		case ':':
+			out = append(out, c)
+			ended, isKey = false, false
+		default:
+			if ended && len(stack) > 0 {
+				// The comma goes right after the value, before any line break
+				out = slices.Insert(out, valueEnd, ',')
+				expectKey = stack[len(stack)-1] == '}'
+			}
+			isKey = expectKey && c == '"'
+			ended, expectKey, needValue = false, false, false
+			switch c {
+			case '{':
+				stack = append(stack, '}')
+				out = append(out, c)
+				expectKey = true
+			case '[':
+				stack = append(stack, ']')
+				out = append(out, c)
+			case '"':
+				inString = true
+				out = append(out, c)
+			default:
+				// Numbers and literals run until a delimiter
+				end := i + 1
+				for end < len(data) && !bytes.ContainsRune([]byte(" \t\r\n,:{}[]\"/"), rune(data[end])) {
+					end++
+				}
+				out = append(out, data[i:end]...)
+				i = end - 1
+				ended, valueEnd = true, len(out)
+			}
+		}
+	}
+
+	if inString {
+		if escaped {
+			out = out[:len(out)-1]
+		}
+		out = append(out, '"')
+		if isKey {
+			needValue = true
+		}
+	}
+	if needValue {
+		out = closeValue(out, isKey, true)
+	}
+	for _, closing := range slices.Backward(stack) {
+		out = append(out, closing)
+	}
+
+	if err := ValidateFormat(out, FormatJSON); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// closeValue completes an object member cut short after its key, or after
+// its ':' if colon is false, with a null value if missing is true
+func closeValue(out []byte, colon, missing bool) []byte {
+	if colon {
+		out = append(out, ':')
+	}
+	if missing {
+		out = append(out, "null"...)
+	}
+	return out
+}
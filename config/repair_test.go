@@ -0,0 +1,182 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestLoadFromPath_ParseError(t *testing.T) {
+	testCases := []struct {
+		name    string
+		data    string
+		line    int
+		column  int
+		snippet string
+	}{
+		{
+			name:    "missing comma",
+			data:    "{\n  \"servers\": [\n    {\"name\": \"a\"}\n    {\"name\": \"b\"}\n  ]\n}\n",
+			line:    4,
+			column:  5,
+			snippet: "  3 |     {\"name\": \"a\"}\n  4 |     {\"name\": \"b\"}\n    |     ^",
+		},
+		{
+			name:    "wrong type",
+			data:    "{\n\t\"servers\": {}\n}\n",
+			line:    2,
+			column:  14,
+			snippet: "  1 | {\n  2 | \t\"servers\": {}\n    | \t            ^",
+		},
+		{
+			name:    "first line",
+			data:    "nope",
+			line:    1,
+			column:  1,
+			snippet: "  1 | nope\n    | ^",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.json")
+			if err := os.WriteFile(path, []byte(tc.data), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadFromPath(path)
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("expected a ParseError, got %v", err)
+			}
+			if parseErr.Path != path || parseErr.Line != tc.line || parseErr.Column != tc.column {
+				t.Errorf("expected %s:%d:%d, got %s:%d:%d", path, tc.line, tc.column, parseErr.Path, parseErr.Line, parseErr.Column)
+			}
+			if parseErr.Snippet != tc.snippet {
+				t.Errorf("expected snippet\n%s\ngot\n%s", tc.snippet, parseErr.Snippet)
+			}
+			if !strings.Contains(err.Error(), parseErr.Snippet) {
+				t.Errorf("expected the error to show the snippet, got %q", err)
+			}
+		})
+	}
+}
+
+func TestRepairJSON(t *testing.T) {
+	testCases := []struct {
+		name    string
+		data    string
+		servers []string
+	}{
+		{"truncated in a string", `{"servers": [{"name": "a", "type": "stdio", "command": "npx", "description": "Fil`, []string{"a"}},
+		{"truncated after a key", `{"servers": [{"name": "a", "type": "stdio", "command": "npx"}], "fileMode`, []string{"a"}},
+		{"truncated after a colon", `{"servers": [{"name": "a", "type": "stdio", "command": "npx"}], "fileMode":`, []string{"a"}},
+		{"missing commas", "{\"servers\": [\n{\"name\": \"a\" \"type\": \"stdio\" \"command\": \"npx\"}\n{\"name\": \"b\", \"type\": \"http\", \"url\": \"https://example.com\"}\n]}", []string{"a", "b"}},
+		{"stray bracket and trailing garbage", `{"servers": [{"name": "a", "type": "stdio", "command": "npx"}]]} }garbage`, []string{"a"}},
+		{"comments", "// servers\n{\"servers\": [/* one */ {\"name\": \"a\", \"type\": \"stdio\", \"command\": \"npx\"},", []string{"a"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repaired, err := RepairJSON([]byte(tc.data))
+			if err != nil {
+				t.Fatalf("failed to repair: %v", err)
+			}
+			var cfg Config
+			if err := decodeConfig(repaired, FormatJSON, &cfg); err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, s := range cfg.Servers {
+				names = append(names, s.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(tc.servers, ",") {
+				t.Errorf("expected servers %v, got %v from %s", tc.servers, names, repaired)
+			}
+		})
+	}
+
+	if _, err := RepairJSON([]byte(`{"servers": [{"name": "a", "type": "stdio"}]}`)); err == nil {
+		t.Error("expected an error when the repaired config isn't valid")
+	}
+}
+
+func TestSave_KeepsBackups(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "config.json")
+
+	cfg, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if backups, _ := Backups(path); len(backups) != 0 {
+		t.Fatalf("expected no backup of a new config, got %v", backups)
+	}
+
+	for i := range backupLimit + 2 {
+		server := MCPServer{Name: "s" + string(rune('a'+i)), Type: "stdio", Command: "npx"}
+		if err := cfg.AddServer(server); err != nil {
+			t.Fatal(err)
+		}
+		if err := cfg.Save(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	backups, err := Backups(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != backupLimit {
+		t.Fatalf("expected %d backups, got %d", backupLimit, len(backups))
+	}
+	newest, err := os.ReadFile(backups[0].Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var prev Config
+	if err := decodeConfig(newest, FormatJSON, &prev); err != nil {
+		t.Fatal(err)
+	}
+	if len(prev.Servers) != backupLimit+1 {
+		t.Errorf("expected the newest backup to be the version before the last save, got %d servers", len(prev.Servers))
+	}
+	if backups[0].Saved.Before(backups[1].Saved) {
+		t.Error("expected backups newest first")
+	}
+}
+
+func TestWriteConfigFile(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "config.json")
+	before := `{"servers": []}`
+	os.WriteFile(path, []byte(before), 0o644)
+	os.Chmod(path, 0o644)
+
+	secret := `{"servers": [{"name": "gh", "type": "stdio", "command": "gh-mcp", "env": {"TOKEN": "x"}}]}`
+	if err := WriteConfigFile(path, []byte(secret)); err != nil {
+		t.Fatal(err)
+	}
+	backups, err := Backups(path)
+	if err != nil || len(backups) != 1 {
+		t.Fatalf("expected a backup of the replaced config, got %v, %v", backups, err)
+	}
+	if data, _ := os.ReadFile(backups[0].Path); string(data) != before {
+		t.Errorf("expected the backup to hold the replaced config, got %s", data)
+	}
+
+	// A new file holding secrets is private
+	fresh := filepath.Join(t.TempDir(), "config.json")
+	if err := WriteConfigFile(fresh, []byte(secret)); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := os.Stat(fresh); runtime.GOOS != "windows" && info.Mode().Perm() != SecretFileMode {
+		t.Errorf("expected mode %04o, got %04o", SecretFileMode, info.Mode().Perm())
+	}
+	if backups, _ := Backups(fresh); len(backups) != 0 {
+		t.Errorf("expected no backup of a new config, got %v", backups)
+	}
+}
@@ -0,0 +1,125 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/jrandolf/mcpr/retry"
+)
+
+// RetryPolicy says how often and how patiently an operation is retried;
+// unset fields keep the value they would have otherwise
+type RetryPolicy struct {
+	Attempts int    `json:"attempts,omitempty"` // Tries in all, including the first; 1 disables retrying
+	Delay    string `json:"delay,omitempty"`    // Wait before the first retry, doubled before each further one, e.g. "200ms"
+	MaxDelay string `json:"maxDelay,omitempty"` // Longest wait between attempts, e.g. "5s"
+}
+
+// Retry configures retrying after transient failures, for every operation
+// and per operation
+type Retry struct {
+	Attempts int          `json:"attempts,omitempty"` // Tries in all, including the first; 1 disables retrying
+	Delay    string       `json:"delay,omitempty"`    // Wait before the first retry, doubled before each further one, e.g. "200ms"
+	MaxDelay string       `json:"maxDelay,omitempty"` // Longest wait between attempts, e.g. "5s"
+	Write    *RetryPolicy `json:"write,omitempty"`    // Writing config files another program holds locked
+	Remote   *RetryPolicy `json:"remote,omitempty"`   // Requests to a remote failing with a network error or 429 or 5xx
+}
+
+// Operations with their own retry policy
+const (
+	RetryWriteOp  = "write"
+	RetryRemoteOp = "remote"
+)
+
+// writeRetry is the policy for writing config files set by the config
+// loaded last, or nil for the defaults. It is shared with client config
+// writes, which don't see the Config, so it is atomic rather than guarded by
+// Config.mu.
+var writeRetry atomic.Pointer[retry.Policy]
+
+// RetryPolicy returns the retry policy for op: the defaults, overridden by
+// the config's retry settings and then those for op
+func (c *Config) RetryPolicy(op string) (retry.Policy, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.Retry.policyFor(op)
+}
+
+// policyFor returns the retry policy for op with the settings of r, which
+// may be nil
+func (r *Retry) policyFor(op string) (retry.Policy, error) {
+	policy := retry.Default
+	if r == nil {
+		return policy, nil
+	}
+	if err := r.policy().apply(&policy); err != nil {
+		return retry.Policy{}, fmt.Errorf("retry: %w", err)
+	}
+	var override *RetryPolicy
+	switch op {
+	case RetryWriteOp:
+		override = r.Write
+	case RetryRemoteOp:
+		override = r.Remote
+	}
+	if override != nil {
+		if err := override.apply(&policy); err != nil {
+			return retry.Policy{}, fmt.Errorf("retry.%s: %w", op, err)
+		}
+	}
+	return policy, nil
+}
+
+// policy returns the settings of r for every operation
+func (r *Retry) policy() *RetryPolicy {
+	return &RetryPolicy{Attempts: r.Attempts, Delay: r.Delay, MaxDelay: r.MaxDelay}
+}
+
+// apply overrides the fields of policy that p sets
+func (p *RetryPolicy) apply(policy *retry.Policy) error {
+	if p.Attempts < 0 {
+		return fmt.Errorf("invalid attempts %d (expected 1 or more)", p.Attempts)
+	}
+	if p.Attempts > 0 {
+		policy.Attempts = p.Attempts
+	}
+	for _, f := range []struct {
+		name  string
+		value string
+		dst   *time.Duration
+	}{{"delay", p.Delay, &policy.Delay}, {"maxDelay", p.MaxDelay, &policy.MaxDelay}} {
+		if f.value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(f.value)
+		if err != nil || d < 0 {
+			return fmt.Errorf("invalid %s %q (expected a duration like \"500ms\" or \"2s\")", f.name, f.value)
+		}
+		*f.dst = d
+	}
+	return nil
+}
+
+// applyRetry makes the config's retry settings for writes the policy for
+// writing config files, or the defaults again if it has none
+func (c *Config) applyRetry() error {
+	policy, err := c.Retry.policyFor(RetryWriteOp)
+	if err != nil {
+		return err
+	}
+	writeRetry.Store(&policy)
+	return nil
+}
+
+// RetryWrite runs write, the writing of a config file, again while it
+// fails because another program holds the file, such as an editor or a
+// virus scanner on Windows
+func RetryWrite(write func() error) error {
+	policy := retry.Default
+	if p := writeRetry.Load(); p != nil {
+		policy = *p
+	}
+	return policy.Do(context.Background(), retry.IsBusy, write)
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jrandolf/mcpr/retry"
+)
+
+func TestConfig_RetryPolicy(t *testing.T) {
+	cfg := &Config{Retry: &Retry{
+		Attempts: 6,
+		MaxDelay: "10s",
+		Remote:   &RetryPolicy{Delay: "1s"},
+		Write:    &RetryPolicy{Attempts: 1},
+	}}
+
+	testCases := []struct {
+		op   string
+		want retry.Policy
+	}{
+		{RetryRemoteOp, retry.Policy{Attempts: 6, Delay: time.Second, MaxDelay: 10 * time.Second}},
+		{RetryWriteOp, retry.Policy{Attempts: 1, Delay: retry.Default.Delay, MaxDelay: 10 * time.Second}},
+	}
+	for _, tc := range testCases {
+		got, err := cfg.RetryPolicy(tc.op)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tc.want {
+			t.Errorf("%s: expected %+v, got %+v", tc.op, tc.want, got)
+		}
+	}
+
+	if got, _ := (&Config{}).RetryPolicy(RetryRemoteOp); got != retry.Default {
+		t.Errorf("expected the default policy without retry settings, got %+v", got)
+	}
+	if _, err := (&Config{Retry: &Retry{Attempts: -1}}).RetryPolicy(RetryWriteOp); err == nil {
+		t.Error("expected an error for negative attempts")
+	}
+}
+
+func TestLoad_ResetsWriteRetry(t *testing.T) {
+	defer writeRetry.Store(nil)
+	withSystemConfig(t, "")
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Chdir(t.TempDir())
+
+	dir := t.TempDir()
+	withRetry := filepath.Join(dir, "retry.json")
+	os.WriteFile(withRetry, []byte(`{"servers": [], "retry": {"write": {"attempts": 5}}}`), 0o644)
+
+	for name, load := range map[string]func() (*Config, error){
+		"LoadFromPath": func() (*Config, error) { return LoadFromPath(filepath.Join(dir, "missing.json")) },
+		"Load":         Load,
+	} {
+		if _, err := LoadFromPath(withRetry); err != nil {
+			t.Fatal(err)
+		}
+		if got := writeRetry.Load(); got == nil || got.Attempts != 5 {
+			t.Fatalf("expected 5 write attempts, got %+v", got)
+		}
+		// A missing config retries writes by default again
+		if _, err := load(); err != nil {
+			t.Fatal(err)
+		}
+		if got := writeRetry.Load(); got == nil || *got != retry.Default {
+			t.Errorf("%s: expected the default policy after a missing config, got %+v", name, got)
+		}
+	}
+}
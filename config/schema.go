@@ -0,0 +1,115 @@
+package config
+
+import (
+	_ "embed"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+//go:generate go test -run TestSchemaUpToDate -update
+
+// SchemaURL is where the published JSON Schema for mcpr configs lives
+const SchemaURL = "https://raw.githubusercontent.com/jrandolf/mcpr/main/config/schema.json"
+
+//go:embed schema.json
+var schemaJSON []byte
+
+// Schema returns the JSON Schema describing mcpr config files
+func Schema() []byte {
+	return schemaJSON
+}
+
+// generateSchema builds the JSON Schema from the Config struct definitions
+func generateSchema() ([]byte, error) {
+	root := schemaFor(reflect.TypeOf(Config{}))
+	root["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	root["$id"] = SchemaURL
+	root["title"] = "mcpr configuration"
+
+	data, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// timeType is encoded by encoding/json as an RFC 3339 string
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaFor returns the schema of a Go type as encoded by encoding/json
+func schemaFor(t reflect.Type) map[string]any {
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaFor(t.Elem())
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": schemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaFor(t.Elem())}
+	case reflect.Struct:
+		return structSchema(t)
+	default:
+		return map[string]any{}
+	}
+}
+
+// structSchema returns the object schema of a struct's JSON fields
+func structSchema(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	var required []string
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		prop := schemaFor(field.Type)
+		for _, directive := range strings.Split(field.Tag.Get("jsonschema"), ",") {
+			if values, ok := strings.CutPrefix(directive, "enum="); ok {
+				prop["enum"] = strings.Split(values, "|")
+			}
+		}
+		properties[name] = prop
+
+		// Fields left out when empty or zero may be missing
+		optional := slices.ContainsFunc(strings.Split(opts, ","), func(opt string) bool {
+			return opt == "omitempty" || opt == "omitzero"
+		})
+		if !optional {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
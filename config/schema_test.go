@@ -0,0 +1,126 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "regenerate schema.json")
+
+func TestSchemaUpToDate(t *testing.T) {
+	generated, err := generateSchema()
+	if err != nil {
+		t.Fatalf("failed to generate schema: %v", err)
+	}
+
+	if *update {
+		if err := os.WriteFile("schema.json", generated, 0644); err != nil {
+			t.Fatalf("failed to write schema: %v", err)
+		}
+		return
+	}
+
+	if !bytes.Equal(generated, Schema()) {
+		t.Error("schema.json is out of date with the config structs; run 'go generate ./config'")
+	}
+}
+
+func TestSchema_Structure(t *testing.T) {
+	var schema map[string]any
+	if err := json.Unmarshal(Schema(), &schema); err != nil {
+		t.Fatalf("failed to parse schema: %v", err)
+	}
+
+	if schema["$id"] != SchemaURL {
+		t.Errorf("expected $id %q, got %v", SchemaURL, schema["$id"])
+	}
+
+	properties := schema["properties"].(map[string]any)
+	for _, key := range []string{"$schema", "servers", "aliases"} {
+		if _, ok := properties[key]; !ok {
+			t.Errorf("expected property %q in schema", key)
+		}
+	}
+
+	servers := properties["servers"].(map[string]any)
+	server := servers["items"].(map[string]any)
+	serverProps := server["properties"].(map[string]any)
+
+	typeProp := serverProps["type"].(map[string]any)
+	enum, ok := typeProp["enum"].([]any)
+	if !ok || len(enum) != 3 || enum[0] != "stdio" || enum[1] != "http" || enum[2] != "socket" {
+		t.Errorf("expected type enum [stdio http socket], got %v", typeProp["enum"])
+	}
+
+	required := server["required"].([]any)
+	if len(required) != 2 || required[0] != "name" || required[1] != "type" {
+		t.Errorf("expected required [name type], got %v", required)
+	}
+}
+
+func TestConfig_SaveWithSchema(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "mcpr-test-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	cfg := &Config{Schema: SchemaURL, Servers: []MCPServer{}}
+	cfg.SetPath(tempDir + "/config.json")
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+
+	data, err := os.ReadFile(cfg.Path())
+	if err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+	if !bytes.Contains(data, []byte(`"$schema": "`+SchemaURL+`"`)) {
+		t.Errorf("expected saved config to contain $schema, got %s", data)
+	}
+}
+
+func TestSchema_AcceptsSavedConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mcpr.json")
+	cfg, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Schema = SchemaURL
+	server := MCPServer{
+		Name:    "memory",
+		Type:    "stdio",
+		Command: "npx",
+		Args:    []string{"-y", "@modelcontextprotocol/server-memory"},
+		Provenance: &Provenance{
+			Source:   SourcePackage,
+			Registry: "npm",
+			Package:  "@modelcontextprotocol/server-memory",
+			Added:    time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC),
+		},
+	}
+	if err := cfg.AddServer(server); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	violations, err := SchemaViolations(Schema(), doc)
+	if err != nil || len(violations) != 0 {
+		t.Errorf("expected the saved config to match the schema, got %q, %v:\n%s", violations, err, data)
+	}
+}
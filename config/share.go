@@ -0,0 +1,63 @@
+package config
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// LinkPrefix starts every shared server link
+const LinkPrefix = "mcpr://add/"
+
+// SharedServer is a server definition shared as a link. Secrets are left
+// out of the server and listed in Prompts instead, to be asked for when the
+// link is added.
+type SharedServer struct {
+	Server  MCPServer `json:"server"`
+	Prompts []string  `json:"prompts,omitempty"` // "env:NAME" or "header:NAME"
+}
+
+// EncodeLink encodes a shared server as an mcpr:// link
+func EncodeLink(shared SharedServer) (string, error) {
+	data, err := json.Marshal(shared)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode server: %w", err)
+	}
+	return LinkPrefix + base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodeLink decodes and validates an mcpr:// link. Surrounding whitespace
+// and a leading "mcpr add link" command, as pasted from a chat message, are
+// ignored.
+func DecodeLink(link string) (SharedServer, error) {
+	link = strings.TrimSpace(link)
+	link = strings.TrimSpace(strings.TrimPrefix(link, "mcpr add link"))
+	encoded, ok := strings.CutPrefix(link, LinkPrefix)
+	if !ok {
+		return SharedServer{}, fmt.Errorf("not an mcpr link: expected it to start with %s", LinkPrefix)
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(encoded, "="))
+	if err != nil {
+		return SharedServer{}, fmt.Errorf("invalid mcpr link: %w", err)
+	}
+	var shared SharedServer
+	if err := json.Unmarshal(data, &shared); err != nil {
+		return SharedServer{}, fmt.Errorf("invalid mcpr link: %w", err)
+	}
+
+	if shared.Server.Name == "" {
+		return SharedServer{}, fmt.Errorf("invalid mcpr link: server name is missing")
+	}
+	if err := ValidateServer(shared.Server); err != nil {
+		return SharedServer{}, fmt.Errorf("invalid mcpr link: %w", err)
+	}
+	for _, prompt := range shared.Prompts {
+		kind, name, _ := strings.Cut(prompt, ":")
+		if (kind != "env" && kind != "header") || name == "" {
+			return SharedServer{}, fmt.Errorf("invalid mcpr link: unknown prompt %q", prompt)
+		}
+	}
+	return shared, nil
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEncodeDecodeLink(t *testing.T) {
+	shared := SharedServer{
+		Server: MCPServer{
+			Name:    "github",
+			Type:    "stdio",
+			Command: "npx",
+			Args:    []string{"-y", "@modelcontextprotocol/server-github"},
+			Env:     map[string]string{"GITHUB_TOKEN": ""},
+		},
+		Prompts: []string{"env:GITHUB_TOKEN"},
+	}
+	link, err := EncodeLink(shared)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(link, LinkPrefix) || strings.ContainsAny(link, " '\"&") {
+		t.Errorf("expected a shell-safe mcpr link, got %q", link)
+	}
+
+	for _, input := range []string{link, "  " + link + "\n", "mcpr add link " + link} {
+		decoded, err := DecodeLink(input)
+		if err != nil {
+			t.Fatalf("unexpected error for %q: %v", input, err)
+		}
+		if !reflect.DeepEqual(decoded, shared) {
+			t.Errorf("expected %+v, got %+v", shared, decoded)
+		}
+	}
+}
+
+func TestDecodeLink_Invalid(t *testing.T) {
+	invalid := func(shared SharedServer) string {
+		link, _ := EncodeLink(shared)
+		return link
+	}
+	tests := map[string]string{
+		"https://example.com":      "not an mcpr link",
+		LinkPrefix + "!!!":         "invalid mcpr link",
+		LinkPrefix + "bm90LWpzb24": "invalid mcpr link",
+		invalid(SharedServer{Server: MCPServer{Type: "stdio", Command: "x"}}):                                    "name is missing",
+		invalid(SharedServer{Server: MCPServer{Name: "x", Type: "stdio"}}):                                       "command is required",
+		invalid(SharedServer{Server: MCPServer{Name: "x", Type: "stdio", Command: "x"}, Prompts: []string{"a"}}): "unknown prompt",
+	}
+	for link, want := range tests {
+		if _, err := DecodeLink(link); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error containing %q for %s, got %v", want, link, err)
+		}
+	}
+}
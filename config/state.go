@@ -0,0 +1,224 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// StateDir returns the directory where mcpr keeps state that isn't
+// configuration, such as snapshots of client configs: $XDG_STATE_HOME/mcpr,
+// or ~/.local/state/mcpr when it isn't set
+func StateDir() (string, error) {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "mcpr"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".local", "state", "mcpr"), nil
+}
+
+// State is what mcpr records about its own actions: which clients each
+// config was synced to and how its servers fared in health checks. It is
+// kept in the state directory rather than in config files, so project
+// configs can be committed without machine-specific entries.
+type State struct {
+	Configs map[string]*ConfigState `json:"configs,omitempty"` // By absolute config path
+	Remote  *RemoteState            `json:"remote,omitempty"`
+	Updates *UpdateState            `json:"updates,omitempty"`
+}
+
+// UpdateState records the last check for a newer mcpr release, so it is
+// done at most once a day
+type UpdateState struct {
+	Checked time.Time `json:"checked"`
+	Latest  string    `json:"latest,omitempty"` // Version of the latest release found
+}
+
+// RemoteState records the last push or pull of the global config, to tell
+// which side changed since
+type RemoteState struct {
+	URL    string    `json:"url"`
+	ETag   string    `json:"etag,omitempty"` // Of the remote copy
+	Hash   string    `json:"hash,omitempty"` // SHA-256 of the local config
+	Synced time.Time `json:"synced,omitzero"`
+}
+
+// ConfigState is the state belonging to one config file
+type ConfigState struct {
+	LastSaved     time.Time               `json:"lastSaved,omitzero"` // When mcpr last wrote the config
+	SyncedClients []SyncedClient          `json:"syncedClients,omitempty"`
+	Health        map[string]HealthRecord `json:"health,omitempty"` // By server name
+}
+
+// LastSync returns when any client was last synced from the config
+func (s *ConfigState) LastSync() time.Time {
+	var last time.Time
+	for _, sc := range s.SyncedClients {
+		if sc.LastSync.After(last) {
+			last = sc.LastSync
+		}
+	}
+	return last
+}
+
+// legacyState is the state configs kept inline before the state file existed
+type legacyState struct {
+	SyncedClients []SyncedClient          `json:"synced_clients"`
+	Health        map[string]HealthRecord `json:"health"`
+}
+
+// StatePath returns the path of the state file
+func StatePath() (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "state.json"), nil
+}
+
+// LoadState reads the state file, returning empty state if there is none
+func LoadState() (*State, error) {
+	path, err := StatePath()
+	if err != nil {
+		return nil, err
+	}
+	state := &State{Configs: make(map[string]*ConfigState)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state: %w", err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse state %s: %w", path, err)
+	}
+	if state.Configs == nil {
+		state.Configs = make(map[string]*ConfigState)
+	}
+	return state, nil
+}
+
+// Save writes the state file
+func (s *State) Save() error {
+	path, err := StatePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %w", err)
+	}
+	if err := RetryWrite(func() error { return os.WriteFile(path, append(data, '\n'), 0o600) }); err != nil {
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+	return nil
+}
+
+// Prune drops the state of configs whose files no longer exist and returns
+// their paths
+func (s *State) Prune() []string {
+	var pruned []string
+	for path := range s.Configs {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			delete(s.Configs, path)
+			pruned = append(pruned, path)
+		}
+	}
+	slices.Sort(pruned)
+	return pruned
+}
+
+// stateKey returns the key of a config path in the state file
+func stateKey(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// loadState reads the config's sync records and health history from the
+// state file. Configs written before the state file existed kept them
+// inline in data; those are used while the state file has no entry for the
+// config, and are dropped from the config file when it is next saved.
+func (c *Config) loadState(data []byte, format Format) error {
+	state, err := LoadState()
+	if err != nil {
+		return err
+	}
+	if entry, ok := state.Configs[stateKey(c.path)]; ok {
+		c.SyncedClients = entry.SyncedClients
+		c.Health = entry.Health
+		return nil
+	}
+
+	var legacy legacyState
+	if len(data) > 0 {
+		if err := decodeConfig(data, format, &legacy); err != nil {
+			return fmt.Errorf("failed to parse config: %w", err)
+		}
+	}
+	c.SyncedClients = legacy.SyncedClients
+	c.Health = legacy.Health
+	return nil
+}
+
+// SaveState writes the config's sync records and health history to the
+// state file, leaving the entries of other configs alone
+func (c *Config) SaveState() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.saveState(false)
+}
+
+// saveState is SaveState for callers holding the lock. Saving the config
+// itself also records it as saved, so every config mcpr wrote is tracked.
+func (c *Config) saveState(saved bool) error {
+	if c.path == "" {
+		return nil
+	}
+	state, err := LoadState()
+	if err != nil {
+		return err
+	}
+
+	key := stateKey(c.path)
+	entry, ok := state.Configs[key]
+	if !ok {
+		if !saved && len(c.SyncedClients) == 0 && len(c.Health) == 0 {
+			return nil
+		}
+		entry = &ConfigState{}
+		state.Configs[key] = entry
+	}
+	entry.SyncedClients = c.SyncedClients
+	entry.Health = c.Health
+	if saved {
+		entry.LastSaved = time.Now().UTC().Truncate(time.Second)
+	}
+	return state.Save()
+}
+
+// RecordSync notes when a synced client was last written and the hash of
+// what was written
+func (c *Config) RecordSync(clientName string, local bool, hash string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, sc := range c.SyncedClients {
+		if sc.Name == clientName && sc.Local == local {
+			c.SyncedClients[i].LastSync = time.Now().UTC().Truncate(time.Second)
+			c.SyncedClients[i].Hash = hash
+			return
+		}
+	}
+}
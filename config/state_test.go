@@ -0,0 +1,155 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	// Keep sync records and health history saved by tests out of the real
+	// state dir
+	state, err := os.MkdirTemp("", "mcpr-state")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("XDG_STATE_HOME", state)
+	code := m.Run()
+	os.RemoveAll(state)
+	os.Exit(code)
+}
+
+func TestStateDir(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "/tmp/state")
+	if dir, err := StateDir(); err != nil || dir != filepath.Join("/tmp/state", "mcpr") {
+		t.Errorf("expected $XDG_STATE_HOME/mcpr, got %q, %v", dir, err)
+	}
+
+	home := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", "")
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	if dir, err := StateDir(); err != nil || dir != filepath.Join(home, ".local", "state", "mcpr") {
+		t.Errorf("expected ~/.local/state/mcpr, got %q, %v", dir, err)
+	}
+}
+
+func TestConfig_StateFile(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	dir := t.TempDir()
+	path := filepath.Join(dir, "mcpr.json")
+
+	cfg, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.AddServer(MCPServer{Name: "a", Type: "stdio", Command: "a"})
+	cfg.AddSyncedClient("cursor", true, nil)
+	cfg.RecordSync("cursor", true, "abc")
+	cfg.RecordHealthCheck("a", nil, true)
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := os.ReadFile(path)
+	for _, key := range []string{"synced_clients", "syncedClients", "health"} {
+		if strings.Contains(string(data), key) {
+			t.Errorf("expected %q to stay out of the config file, got:\n%s", key, data)
+		}
+	}
+
+	reloaded, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := reloaded.GetSyncedClient("cursor", true)
+	if sc == nil || sc.Hash != "abc" || sc.LastSync.IsZero() {
+		t.Errorf("expected sync record from the state file, got %+v", sc)
+	}
+	if _, ok := reloaded.HealthOf("a"); !ok {
+		t.Error("expected health record from the state file")
+	}
+
+	// Another config's state is kept apart
+	other, err := LoadFromPath(filepath.Join(t.TempDir(), "mcpr.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(other.GetSyncedClients()) != 0 {
+		t.Errorf("expected no synced clients for another config, got %v", other.GetSyncedClients())
+	}
+}
+
+func TestConfig_StateFile_MigratesInlineState(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "mcpr.json")
+	legacy := `{
+  "servers": [{"name": "a", "type": "stdio", "command": "a"}],
+  "synced_clients": [{"name": "cursor", "local": true}],
+  "health": {"a": {"lastCheck": "2025-01-01T00:00:00Z", "failures": 3, "quarantined": true}}
+}
+`
+	if err := os.WriteFile(path, []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.GetSyncedClient("cursor", true) == nil || !cfg.IsQuarantined("a") {
+		t.Fatalf("expected inline state to be read, got %v %v", cfg.GetSyncedClients(), cfg.Health)
+	}
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "synced_clients") || strings.Contains(string(data), "health") {
+		t.Errorf("expected inline state to be dropped on save, got:\n%s", data)
+	}
+	state, err := LoadState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := state.Configs[stateKey(path)]
+	if entry == nil || len(entry.SyncedClients) != 1 || !entry.Health["a"].Quarantined {
+		t.Errorf("expected inline state in the state file, got %+v", entry)
+	}
+}
+
+func TestState_Prune(t *testing.T) {
+	kept := filepath.Join(t.TempDir(), "mcpr.json")
+	os.WriteFile(kept, []byte(`{"servers": []}`), 0644)
+	gone := filepath.Join(t.TempDir(), "gone", "mcpr.json")
+	state := &State{Configs: map[string]*ConfigState{kept: {}, gone: {}}}
+
+	pruned := state.Prune()
+	if len(pruned) != 1 || pruned[0] != gone {
+		t.Errorf("expected %s to be pruned, got %v", gone, pruned)
+	}
+	if _, ok := state.Configs[kept]; !ok || len(state.Configs) != 1 {
+		t.Errorf("expected only %s to be kept, got %v", kept, state.Configs)
+	}
+}
+
+func TestConfig_Save_RecordsConfig(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "mcpr.json")
+	cfg, err := LoadFromPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := LoadState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry := state.Configs[stateKey(path)]; entry == nil || entry.LastSaved.IsZero() {
+		t.Errorf("expected saved config to be recorded, got %+v", entry)
+	}
+}
@@ -0,0 +1,211 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// bareKey matches TOML keys that need no quotes
+var bareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// encodeTOML writes JSON as TOML. Unlike encoding a decoded map, it keeps
+// the key order of the JSON and writes its integers as integers. Like the
+// TOML encoder, it writes null values in tables as nothing.
+func encodeTOML(data []byte) ([]byte, error) {
+	// Decoding JSON as a YAML node keeps the key order and the number types
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if len(node.Content) == 0 {
+		return buf.Bytes(), nil
+	}
+	root := node.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("TOML documents must be tables")
+	}
+	if err := writeTOMLTable(&buf, nil, root); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeTOMLTable writes the values of a mapping under the table at path,
+// values first and subtables after them as TOML requires
+func writeTOMLTable(buf *bytes.Buffer, path []string, m *yaml.Node) error {
+	var tables []int
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		key, value := m.Content[i].Value, m.Content[i+1]
+		if isTOMLTable(value) || isTOMLArrayOfTables(value) {
+			tables = append(tables, i)
+			continue
+		}
+		if value.Tag == "!!null" {
+			continue
+		}
+		buf.WriteString(tomlKey(key) + " = ")
+		if err := writeTOMLValue(buf, value); err != nil {
+			return fmt.Errorf("%s: %w", strings.Join(append(path, key), "."), err)
+		}
+		buf.WriteByte('\n')
+	}
+
+	for _, i := range tables {
+		key, value := m.Content[i].Value, m.Content[i+1]
+		sub := append(append([]string(nil), path...), key)
+		if isTOMLTable(value) {
+			// Top-level tables are set apart by a blank line
+			if len(sub) == 1 && buf.Len() > 0 {
+				buf.WriteByte('\n')
+			}
+			buf.WriteString("[" + tomlPath(sub) + "]\n")
+			if err := writeTOMLTable(buf, sub, value); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, elem := range value.Content {
+			if buf.Len() > 0 {
+				buf.WriteByte('\n')
+			}
+			buf.WriteString("[[" + tomlPath(sub) + "]]\n")
+			if err := writeTOMLTable(buf, sub, elem); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeTOMLValue writes a value inline: scalars, arrays and inline tables
+func writeTOMLValue(buf *bytes.Buffer, node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.MappingNode:
+		buf.WriteByte('{')
+		first := true
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i+1].Tag == "!!null" {
+				continue
+			}
+			if !first {
+				buf.WriteString(", ")
+			}
+			first = false
+			buf.WriteString(tomlKey(node.Content[i].Value) + " = ")
+			if err := writeTOMLValue(buf, node.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case yaml.SequenceNode:
+		buf.WriteByte('[')
+		for i, elem := range node.Content {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			if err := writeTOMLValue(buf, elem); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case yaml.ScalarNode:
+		switch node.Tag {
+		case "!!str":
+			buf.WriteString(tomlString(node.Value))
+		case "!!bool", "!!int":
+			buf.WriteString(node.Value)
+		case "!!float":
+			f, err := strconv.ParseFloat(node.Value, 64)
+			if err != nil || math.IsInf(f, 0) || math.IsNaN(f) {
+				return fmt.Errorf("invalid number %s", node.Value)
+			}
+			s := strconv.FormatFloat(f, 'f', -1, 64)
+			if !strings.Contains(s, ".") {
+				s += ".0"
+			}
+			buf.WriteString(s)
+		case "!!null":
+			return fmt.Errorf("TOML has no null value")
+		default:
+			return fmt.Errorf("unsupported value %s", node.Value)
+		}
+	default:
+		return fmt.Errorf("unsupported value")
+	}
+	return nil
+}
+
+// isTOMLTable reports whether a value is written as a table
+func isTOMLTable(node *yaml.Node) bool {
+	return node.Kind == yaml.MappingNode
+}
+
+// isTOMLArrayOfTables reports whether a value is a non-empty array of
+// tables, written as [[key]] sections
+func isTOMLArrayOfTables(node *yaml.Node) bool {
+	if node.Kind != yaml.SequenceNode || len(node.Content) == 0 {
+		return false
+	}
+	for _, elem := range node.Content {
+		if elem.Kind != yaml.MappingNode {
+			return false
+		}
+	}
+	return true
+}
+
+// tomlPath joins the keys of a table header
+func tomlPath(keys []string) string {
+	quoted := make([]string, len(keys))
+	for i, key := range keys {
+		quoted[i] = tomlKey(key)
+	}
+	return strings.Join(quoted, ".")
+}
+
+// tomlKey quotes a key unless it is a bare key
+func tomlKey(key string) string {
+	if bareKey.MatchString(key) {
+		return key
+	}
+	return tomlString(key)
+}
+
+// tomlString quotes a string as a TOML basic string
+func tomlString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
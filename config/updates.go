@@ -0,0 +1,26 @@
+package config
+
+// Updates configures the check for newer mcpr releases
+type Updates struct {
+	Check *bool `json:"check,omitempty"` // Check once a day and print a notice; true when unset
+}
+
+// UpdateCheckEnabled reports whether mcpr may check for newer releases:
+// unless updates.check is false in the active config, or in the global or
+// system config and not set back to true by a config above it
+func (c *Config) UpdateCheckEnabled() bool {
+	c.mu.RLock()
+	path, own := c.path, c.Updates
+	c.mu.RUnlock()
+
+	enabled := true
+	for _, p := range outerConfigPaths(path) {
+		if cfg := readConfig(p); cfg != nil && cfg.Updates != nil && cfg.Updates.Check != nil {
+			enabled = *cfg.Updates.Check
+		}
+	}
+	if own != nil && own.Check != nil {
+		enabled = *own.Check
+	}
+	return enabled
+}
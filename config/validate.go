@@ -0,0 +1,135 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// Validate checks that data is a well-formed mcpr config. Comments and
+// trailing commas are allowed. Syntax errors are reported with their line
+// and column.
+func Validate(data []byte) error {
+	return ValidateFormat(data, FormatJSON)
+}
+
+// ValidateFormat checks that data is a well-formed mcpr config in the given
+// format
+func ValidateFormat(data []byte, format Format) error {
+	std, err := toJSON(data, format)
+	if err != nil {
+		return err
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(std, &cfg); err != nil {
+		if format == FormatJSON {
+			return locateJSONError(std, err)
+		}
+		return err
+	}
+
+	if cfg.FileMode != "" {
+		if _, err := ParseFileMode(cfg.FileMode); err != nil {
+			return err
+		}
+	}
+	if cfg.Retry != nil {
+		for _, op := range []string{RetryWriteOp, RetryRemoteOp} {
+			if _, err := cfg.Retry.policyFor(op); err != nil {
+				return err
+			}
+		}
+	}
+
+	seen := make(map[string]bool, len(cfg.Servers))
+	for i, s := range cfg.Servers {
+		if s.Name == "" {
+			return fmt.Errorf("server #%d: name is required", i+1)
+		}
+		if seen[s.Name] {
+			return fmt.Errorf("server %q: duplicate name", s.Name)
+		}
+		seen[s.Name] = true
+
+		if err := ValidateServer(s); err != nil {
+			return err
+		}
+	}
+
+	if _, err := SortByDependencies(cfg.Servers); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ValidateServer checks the fields of a single server definition
+func ValidateServer(s MCPServer) error {
+	if s.Timeout < 0 {
+		return fmt.Errorf("server %q: timeout must not be negative", s.Name)
+	}
+
+	switch s.Type {
+	case "stdio":
+		if s.Command == "" {
+			return fmt.Errorf("server %q: command is required for stdio servers", s.Name)
+		}
+	case "http":
+		if s.URL == "" {
+			return fmt.Errorf("server %q: url is required for http servers", s.Name)
+		}
+	case "socket":
+		if s.Path == "" {
+			return fmt.Errorf("server %q: path is required for socket servers", s.Name)
+		}
+	default:
+		return fmt.Errorf("server %q: unknown type %q (expected \"stdio\", \"http\" or \"socket\")", s.Name, s.Type)
+	}
+	if s.Sandbox != nil && s.Type != "stdio" {
+		return fmt.Errorf("server %q: sandbox is only supported for stdio servers", s.Name)
+	}
+	for _, os := range slices.Sorted(maps.Keys(s.Platforms)) {
+		if !slices.Contains(knownOS, os) {
+			return fmt.Errorf("server %q: unknown platform %q (expected an operating system such as \"darwin\", \"linux\" or \"windows\")", s.Name, os)
+		}
+	}
+	return nil
+}
+
+// knownOS are the operating systems platform overrides can be keyed by
+var knownOS = []string{"aix", "android", "darwin", "dragonfly", "freebsd", "illumos", "ios", "linux", "netbsd", "openbsd", "plan9", "solaris", "windows"}
+
+// locateJSONError annotates JSON decoding errors with a line and column
+func locateJSONError(data []byte, err error) error {
+	var offset int64
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		offset = syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		offset = typeErr.Offset
+	default:
+		return err
+	}
+
+	line, col := lineColumn(data, offset)
+	return fmt.Errorf("line %d, column %d: %w", line, col, err)
+}
+
+// lineColumn converts a byte offset into 1-based line and column numbers
+func lineColumn(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	col := int(offset) - bytes.LastIndexByte(before, '\n')
+	if col < 1 {
+		col = 1
+	}
+	return line, col
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate_Valid(t *testing.T) {
+	data := []byte(`{
+  "servers": [
+    {"name": "fs", "type": "stdio", "command": "npx"},
+    {"name": "api", "type": "http", "url": "https://example.com/mcp"}
+  ]
+}`)
+
+	if err := Validate(data); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestValidate_SyntaxErrorLocation(t *testing.T) {
+	data := []byte("{\n  \"servers\": [\n    {\"name\" \"fs\"}\n  ]\n}")
+
+	err := Validate(data)
+	if err == nil {
+		t.Fatal("expected error for invalid JSON, got nil")
+	}
+	if !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("expected error to mention line 3, got %q", err.Error())
+	}
+}
+
+func TestValidate_CommentsAndTrailingCommas(t *testing.T) {
+	data := []byte(`{
+  // Servers shared with the team
+  "servers": [
+    {"name": "fs", "type": "stdio", "command": "npx",}, /* local only */
+  ],
+}`)
+
+	if err := Validate(data); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestValidate_TypeErrorLocation(t *testing.T) {
+	data := []byte("{\n  \"servers\": {}\n}")
+
+	err := Validate(data)
+	if err == nil {
+		t.Fatal("expected error for wrong type, got nil")
+	}
+	if !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected error to mention line 2, got %q", err.Error())
+	}
+}
+
+func TestValidate_SemanticErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		data     string
+		contains string
+	}{
+		{"missing name", `{"servers":[{"type":"stdio","command":"x"}]}`, "name is required"},
+		{"duplicate", `{"servers":[{"name":"a","type":"stdio","command":"x"},{"name":"a","type":"stdio","command":"y"}]}`, "duplicate"},
+		{"unknown type", `{"servers":[{"name":"a","type":"grpc"}]}`, "unknown type"},
+		{"stdio without command", `{"servers":[{"name":"a","type":"stdio"}]}`, "command is required"},
+		{"http without url", `{"servers":[{"name":"a","type":"http"}]}`, "url is required"},
+		{"socket without path", `{"servers":[{"name":"a","type":"socket"}]}`, "path is required"},
+		{"sandboxed http", `{"servers":[{"name":"a","type":"http","url":"https://x","sandbox":{}}]}`, "only supported for stdio"},
+		{"unknown platform", `{"servers":[{"name":"a","type":"stdio","command":"x","platforms":{"macos":{"command":"y"}}}]}`, "unknown platform"},
+		{"cycle", `{"servers":[{"name":"a","type":"stdio","command":"x","dependsOn":["a"]}]}`, "cycle"},
+		{"bad retry delay", `{"servers":[],"retry":{"remote":{"delay":"soon"}}}`, "retry.remote: invalid delay"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Validate([]byte(tc.data))
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tc.contains) {
+				t.Errorf("expected error containing %q, got %q", tc.contains, err.Error())
+			}
+		})
+	}
+}
+
+func TestLineColumn(t *testing.T) {
+	data := []byte("ab\ncd\nef")
+
+	testCases := []struct {
+		offset int64
+		line   int
+		col    int
+	}{
+		{0, 1, 1},
+		{1, 1, 2},
+		{4, 2, 2},
+		{7, 3, 2},
+		{100, 3, 3},
+	}
+
+	for _, tc := range testCases {
+		line, col := lineColumn(data, tc.offset)
+		if line != tc.line || col != tc.col {
+			t.Errorf("offset %d: expected %d:%d, got %d:%d", tc.offset, tc.line, tc.col, line, col)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Client is a minimal MCP client. It sends one request at a time over a
+// transport and answers the few requests servers send to clients.
+type Client struct {
+	transport Transport
+	info      Implementation
+	nextID    int64
+}
+
+// NewClient creates a client that talks over transport and identifies
+// itself with name and version
+func NewClient(transport Transport, name, version string) *Client {
+	return &Client{
+		transport: transport,
+		info:      Implementation{Name: name, Version: version},
+	}
+}
+
+// message is any JSON-RPC message received from a server
+type message struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *Error          `json:"error,omitempty"`
+}
+
+// Initialize performs the initialize handshake, which must come before any
+// other request
+func (c *Client) Initialize(ctx context.Context) (*InitializeResult, error) {
+	params := map[string]any{
+		"protocolVersion": ProtocolVersion,
+		"capabilities":    map[string]any{},
+		"clientInfo":      c.info,
+	}
+	var result InitializeResult
+	if err := c.Request(ctx, "initialize", params, &result); err != nil {
+		return nil, err
+	}
+	if err := c.Notify(ctx, "notifications/initialized", nil); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Request sends a request and decodes its result into result, which may be
+// nil to discard it. JSON-RPC errors are returned as *Error.
+func (c *Client) Request(ctx context.Context, method string, params, result any) error {
+	c.nextID++
+	id := json.RawMessage(strconv.FormatInt(c.nextID, 10))
+	if err := c.send(ctx, &Request{JSONRPC: "2.0", ID: id, Method: method}, params); err != nil {
+		return fmt.Errorf("failed to send %s: %w", method, err)
+	}
+
+	for {
+		data, err := c.transport.Receive(ctx)
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("server closed the connection before answering %s", method)
+		} else if err != nil {
+			return err
+		}
+
+		var msg message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			// Servers sometimes log to stdout; skip anything that isn't JSON-RPC
+			continue
+		}
+		if msg.Method != "" {
+			if len(msg.ID) > 0 {
+				if err := c.answer(ctx, &msg); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if !bytes.Equal(msg.ID, id) {
+			continue
+		}
+
+		if msg.Error != nil {
+			return msg.Error
+		}
+		if result != nil && len(msg.Result) > 0 {
+			if err := json.Unmarshal(msg.Result, result); err != nil {
+				return fmt.Errorf("invalid %s result: %w", method, err)
+			}
+		}
+		return nil
+	}
+}
+
+// Notify sends a notification, which gets no response
+func (c *Client) Notify(ctx context.Context, method string, params any) error {
+	return c.send(ctx, &Request{JSONRPC: "2.0", Method: method}, params)
+}
+
+func (c *Client) send(ctx context.Context, req *Request, params any) error {
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		req.Params = data
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	return c.transport.Send(ctx, data)
+}
+
+// answer replies to a request the server sent. Only ping is supported, as
+// the client declares no capabilities.
+func (c *Client) answer(ctx context.Context, msg *message) error {
+	resp := &Response{JSONRPC: "2.0", ID: msg.ID}
+	if msg.Method == "ping" {
+		resp.Result = map[string]any{}
+	} else {
+		resp.Error = &Error{Code: CodeMethodNotFound, Message: fmt.Sprintf("method not found: %s", msg.Method)}
+	}
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	return c.transport.Send(ctx, data)
+}
+
+// ListTools returns every tool the server exposes, following pagination
+func (c *Client) ListTools(ctx context.Context) ([]Tool, error) {
+	return listAll[Tool](ctx, c, "tools/list", "tools")
+}
+
+// ListResources returns every resource the server exposes, following
+// pagination
+func (c *Client) ListResources(ctx context.Context) ([]Resource, error) {
+	return listAll[Resource](ctx, c, "resources/list", "resources")
+}
+
+// ListPrompts returns every prompt the server exposes, following pagination
+func (c *Client) ListPrompts(ctx context.Context) ([]Prompt, error) {
+	return listAll[Prompt](ctx, c, "prompts/list", "prompts")
+}
+
+// listAll requests every page of a paginated list method and collects the
+// items stored under key
+func listAll[T any](ctx context.Context, c *Client, method, key string) ([]T, error) {
+	var items []T
+	var cursor string
+	for {
+		var page map[string]json.RawMessage
+		if err := c.Request(ctx, method, cursorParams(cursor), &page); err != nil {
+			return nil, err
+		}
+
+		var batch []T
+		if raw, ok := page[key]; ok {
+			if err := json.Unmarshal(raw, &batch); err != nil {
+				return nil, fmt.Errorf("invalid %s result: %w", method, err)
+			}
+		}
+		items = append(items, batch...)
+
+		var next string
+		if raw, ok := page["nextCursor"]; ok {
+			json.Unmarshal(raw, &next)
+		}
+		if next == "" {
+			return items, nil
+		}
+		cursor = next
+	}
+}
+
+// cursorParams returns the params of a list request for one page
+func cursorParams(cursor string) any {
+	if cursor == "" {
+		return nil
+	}
+	return map[string]string{"cursor": cursor}
+}
+
+// CallTool calls a tool with JSON arguments, which may be nil
+func (c *Client) CallTool(ctx context.Context, name string, args json.RawMessage) (*ToolResult, error) {
+	var result ToolResult
+	if err := c.Request(ctx, "tools/call", &CallToolParams{Name: name, Arguments: args}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ReadResource returns the contents of the resource at uri
+func (c *Client) ReadResource(ctx context.Context, uri string) ([]ResourceContents, error) {
+	var result struct {
+		Contents []ResourceContents `json:"contents"`
+	}
+	if err := c.Request(ctx, "resources/read", map[string]string{"uri": uri}, &result); err != nil {
+		return nil, err
+	}
+	return result.Contents, nil
+}
+
+// GetPrompt renders the prompt called name with string arguments
+func (c *Client) GetPrompt(ctx context.Context, name string, args map[string]string) (*PromptResult, error) {
+	params := map[string]any{"name": name}
+	if len(args) > 0 {
+		params["arguments"] = args
+	}
+	var result PromptResult
+	if err := c.Request(ctx, "prompts/get", params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Close closes the transport
+func (c *Client) Close() error {
+	return c.transport.Close()
+}
@@ -0,0 +1,131 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// newTestClient returns an initialized client connected to newTestServer
+func newTestClient(t *testing.T) *Client {
+	t.Helper()
+	clientR, serverW := io.Pipe()
+	serverR, clientW := io.Pipe()
+	go func() {
+		newTestServer().Serve(serverR, serverW)
+		serverW.Close()
+	}()
+
+	client := NewClient(NewStreamTransport(clientR, clientW, clientW.Close), "mcpr", "test")
+	t.Cleanup(func() { client.Close() })
+	if _, err := client.Initialize(context.Background()); err != nil {
+		t.Fatalf("initialize failed: %v", err)
+	}
+	return client
+}
+
+func TestClient_Initialize(t *testing.T) {
+	clientR, serverW := io.Pipe()
+	serverR, clientW := io.Pipe()
+	go newTestServer().Serve(serverR, serverW)
+
+	client := NewClient(NewStreamTransport(clientR, clientW, clientW.Close), "mcpr", "test")
+	defer client.Close()
+
+	result, err := client.Initialize(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.ServerInfo.Name != "test" || result.ProtocolVersion != ProtocolVersion {
+		t.Errorf("unexpected initialize result: %+v", result)
+	}
+}
+
+func TestClient_ListAndCallTools(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	tools, err := client.ListTools(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tools) != 2 || tools[0].Name != "echo" {
+		t.Errorf("unexpected tools: %+v", tools)
+	}
+
+	result, err := client.CallTool(ctx, "echo", json.RawMessage(`{"text":"hi"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError || len(result.Content) != 1 || result.Content[0].Text != "hi" {
+		t.Errorf("unexpected echo result: %+v", result)
+	}
+
+	result, err = client.CallTool(ctx, "fail", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.IsError {
+		t.Error("expected a tool error result")
+	}
+
+	_, err = client.CallTool(ctx, "missing", nil)
+	var rpcErr *Error
+	if !errors.As(err, &rpcErr) || rpcErr.Code != CodeInvalidParams {
+		t.Errorf("expected invalid params error, got %v", err)
+	}
+}
+
+func TestClient_AnswersServerRequests(t *testing.T) {
+	clientR, serverW := io.Pipe()
+	serverR, clientW := io.Pipe()
+
+	// A server that logs a line, pings the client and then answers
+	pong := make(chan string, 1)
+	go func() {
+		scanner := bufio.NewScanner(serverR)
+		scanner.Scan()
+		io.WriteString(serverW, "starting up\n")
+		io.WriteString(serverW, `{"jsonrpc":"2.0","id":"s1","method":"ping"}`+"\n")
+		scanner.Scan()
+		pong <- scanner.Text()
+		io.WriteString(serverW, `{"jsonrpc":"2.0","id":1,"result":{"ok":true}}`+"\n")
+	}()
+
+	client := NewClient(NewStreamTransport(clientR, clientW, clientW.Close), "mcpr", "test")
+	defer client.Close()
+
+	var result struct{ OK bool }
+	if err := client.Request(context.Background(), "custom", nil, &result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.OK {
+		t.Error("expected the result to be decoded")
+	}
+	if reply := <-pong; !strings.Contains(reply, `"id":"s1"`) || !strings.Contains(reply, `"result":{}`) {
+		t.Errorf("unexpected ping reply: %s", reply)
+	}
+}
+
+func TestClient_ServerExits(t *testing.T) {
+	client := NewClient(NewStreamTransport(strings.NewReader(""), io.Discard, nil), "mcpr", "test")
+	if _, err := client.Initialize(context.Background()); err == nil || !strings.Contains(err.Error(), "closed the connection") {
+		t.Errorf("expected closed connection error, got %v", err)
+	}
+}
+
+func TestClient_Canceled(t *testing.T) {
+	clientR, _ := io.Pipe()
+	_, clientW := io.Pipe()
+	client := NewClient(NewStreamTransport(clientR, clientW, nil), "mcpr", "test")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.ListTools(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
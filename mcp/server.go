@@ -0,0 +1,131 @@
+package mcp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ToolHandler executes a tool call. The returned text becomes the tool's
+// result; a returned error is reported to the caller as a tool error.
+type ToolHandler func(args json.RawMessage) (string, error)
+
+// Server is a minimal MCP server exposing tools over a stream
+type Server struct {
+	info     Implementation
+	tools    []Tool
+	handlers map[string]ToolHandler
+}
+
+// NewServer creates a server that identifies itself with name and version
+func NewServer(name, version string) *Server {
+	return &Server{
+		info:     Implementation{Name: name, Version: version},
+		handlers: make(map[string]ToolHandler),
+	}
+}
+
+// AddTool registers a tool and its handler
+func (s *Server) AddTool(tool Tool, handler ToolHandler) {
+	if tool.InputSchema == nil {
+		tool.InputSchema = map[string]any{"type": "object"}
+	}
+	s.tools = append(s.tools, tool)
+	s.handlers[tool.Name] = handler
+}
+
+// Tools returns the registered tools
+func (s *Server) Tools() []Tool {
+	return s.tools
+}
+
+// Serve reads newline-delimited JSON-RPC messages from r and writes
+// responses to w until r is exhausted
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	enc := json.NewEncoder(w)
+	write := func(resp *Response) error {
+		return enc.Encode(resp)
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var req Request
+		if err := json.Unmarshal(line, &req); err != nil {
+			if err := write(&Response{
+				JSONRPC: "2.0",
+				ID:      json.RawMessage("null"),
+				Error:   &Error{Code: CodeParseError, Message: "parse error"},
+			}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		resp := s.Handle(&req)
+		if resp == nil {
+			continue
+		}
+		if err := write(resp); err != nil {
+			return fmt.Errorf("failed to write response: %w", err)
+		}
+	}
+	return scanner.Err()
+}
+
+// Handle processes a single request. It returns nil for notifications.
+func (s *Server) Handle(req *Request) *Response {
+	result, rpcErr := s.dispatch(req)
+	if req.IsNotification() {
+		return nil
+	}
+
+	resp := &Response{JSONRPC: "2.0", ID: req.ID}
+	if rpcErr != nil {
+		resp.Error = rpcErr
+	} else {
+		resp.Result = result
+	}
+	return resp
+}
+
+func (s *Server) dispatch(req *Request) (any, *Error) {
+	switch req.Method {
+	case "initialize":
+		return &InitializeResult{
+			ProtocolVersion: ProtocolVersion,
+			Capabilities:    map[string]any{"tools": map[string]any{}},
+			ServerInfo:      s.info,
+		}, nil
+	case "ping":
+		return map[string]any{}, nil
+	case "tools/list":
+		return map[string]any{"tools": s.tools}, nil
+	case "tools/call":
+		var params CallToolParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &Error{Code: CodeInvalidParams, Message: "invalid params: " + err.Error()}
+		}
+		handler, ok := s.handlers[params.Name]
+		if !ok {
+			return nil, &Error{Code: CodeInvalidParams, Message: fmt.Sprintf("unknown tool: %s", params.Name)}
+		}
+		text, err := handler(params.Arguments)
+		if err != nil {
+			return &ToolResult{Content: []Content{{Type: "text", Text: err.Error()}}, IsError: true}, nil
+		}
+		return &ToolResult{Content: []Content{{Type: "text", Text: text}}}, nil
+	default:
+		if strings.HasPrefix(req.Method, "notifications/") {
+			return nil, nil
+		}
+		return nil, &Error{Code: CodeMethodNotFound, Message: fmt.Sprintf("method not found: %s", req.Method)}
+	}
+}
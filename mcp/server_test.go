@@ -0,0 +1,140 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func newTestServer() *Server {
+	s := NewServer("test", "1.0.0")
+	s.AddTool(Tool{Name: "echo", Description: "Echo the input"}, func(args json.RawMessage) (string, error) {
+		var in struct {
+			Text string `json:"text"`
+		}
+		if err := json.Unmarshal(args, &in); err != nil {
+			return "", err
+		}
+		return in.Text, nil
+	})
+	s.AddTool(Tool{Name: "fail"}, func(args json.RawMessage) (string, error) {
+		return "", fmt.Errorf("boom")
+	})
+	return s
+}
+
+func serve(t *testing.T, s *Server, input string) []Response {
+	t.Helper()
+	var out bytes.Buffer
+	if err := s.Serve(strings.NewReader(input), &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var responses []Response
+	dec := json.NewDecoder(&out)
+	for dec.More() {
+		var resp Response
+		if err := dec.Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		responses = append(responses, resp)
+	}
+	return responses
+}
+
+func TestServer_Initialize(t *testing.T) {
+	responses := serve(t, newTestServer(), `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}
+{"jsonrpc":"2.0","method":"notifications/initialized"}
+`)
+
+	// Notifications get no response
+	if len(responses) != 1 {
+		t.Fatalf("expected 1 response, got %d", len(responses))
+	}
+
+	result, _ := json.Marshal(responses[0].Result)
+	var init InitializeResult
+	if err := json.Unmarshal(result, &init); err != nil {
+		t.Fatalf("failed to parse result: %v", err)
+	}
+	if init.ProtocolVersion != ProtocolVersion {
+		t.Errorf("expected protocol version %q, got %q", ProtocolVersion, init.ProtocolVersion)
+	}
+	if init.ServerInfo.Name != "test" {
+		t.Errorf("expected server name 'test', got %q", init.ServerInfo.Name)
+	}
+}
+
+func TestServer_ToolsList(t *testing.T) {
+	responses := serve(t, newTestServer(), `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`+"\n")
+	if len(responses) != 1 {
+		t.Fatalf("expected 1 response, got %d", len(responses))
+	}
+
+	result, _ := json.Marshal(responses[0].Result)
+	var list struct {
+		Tools []Tool `json:"tools"`
+	}
+	if err := json.Unmarshal(result, &list); err != nil {
+		t.Fatalf("failed to parse result: %v", err)
+	}
+	if len(list.Tools) != 2 {
+		t.Fatalf("expected 2 tools, got %d", len(list.Tools))
+	}
+	if list.Tools[0].InputSchema["type"] != "object" {
+		t.Errorf("expected default input schema, got %v", list.Tools[0].InputSchema)
+	}
+}
+
+func TestServer_ToolsCall(t *testing.T) {
+	responses := serve(t, newTestServer(), `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"echo","arguments":{"text":"hello"}}}
+{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"fail"}}
+{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"missing"}}
+`)
+	if len(responses) != 3 {
+		t.Fatalf("expected 3 responses, got %d", len(responses))
+	}
+
+	var ok ToolResult
+	result, _ := json.Marshal(responses[0].Result)
+	json.Unmarshal(result, &ok)
+	if ok.IsError || len(ok.Content) != 1 || ok.Content[0].Text != "hello" {
+		t.Errorf("expected echo result 'hello', got %+v", ok)
+	}
+
+	var failed ToolResult
+	result, _ = json.Marshal(responses[1].Result)
+	json.Unmarshal(result, &failed)
+	if !failed.IsError || failed.Content[0].Text != "boom" {
+		t.Errorf("expected tool error 'boom', got %+v", failed)
+	}
+
+	if responses[2].Error == nil || responses[2].Error.Code != CodeInvalidParams {
+		t.Errorf("expected invalid params error for unknown tool, got %+v", responses[2].Error)
+	}
+}
+
+func TestServer_MethodNotFound(t *testing.T) {
+	responses := serve(t, newTestServer(), `{"jsonrpc":"2.0","id":"a","method":"resources/list"}`+"\n")
+	if len(responses) != 1 {
+		t.Fatalf("expected 1 response, got %d", len(responses))
+	}
+	if responses[0].Error == nil || responses[0].Error.Code != CodeMethodNotFound {
+		t.Errorf("expected method not found error, got %+v", responses[0].Error)
+	}
+	if string(responses[0].ID) != `"a"` {
+		t.Errorf("expected id \"a\", got %s", responses[0].ID)
+	}
+}
+
+func TestServer_ParseError(t *testing.T) {
+	responses := serve(t, newTestServer(), "not json\n")
+	if len(responses) != 1 {
+		t.Fatalf("expected 1 response, got %d", len(responses))
+	}
+	if responses[0].Error == nil || responses[0].Error.Code != CodeParseError {
+		t.Errorf("expected parse error, got %+v", responses[0].Error)
+	}
+}
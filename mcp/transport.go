@@ -0,0 +1,289 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Transport carries JSON-RPC messages between a client and a server
+type Transport interface {
+	// Send delivers one message to the server
+	Send(ctx context.Context, msg []byte) error
+	// Receive returns the next message from the server, or io.EOF once the
+	// server has nothing more to say
+	Receive(ctx context.Context) ([]byte, error)
+	Close() error
+}
+
+// streamTransport exchanges newline-delimited messages over a byte stream
+type streamTransport struct {
+	w      io.Writer
+	closer func() error
+	lines  chan []byte
+	done   chan struct{}
+	err    error // read error, set before lines is closed
+}
+
+// NewStreamTransport exchanges newline-delimited JSON messages by writing
+// to w and reading from r. closer, if not nil, is called by Close to
+// release the stream.
+func NewStreamTransport(r io.Reader, w io.Writer, closer func() error) Transport {
+	t := &streamTransport{
+		w:      w,
+		closer: closer,
+		lines:  make(chan []byte),
+		done:   make(chan struct{}),
+	}
+	go t.read(r)
+	return t
+}
+
+func (t *streamTransport) read(r io.Reader) {
+	defer close(t.lines)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		select {
+		case t.lines <- bytes.Clone(line):
+		case <-t.done:
+			return
+		}
+	}
+	t.err = scanner.Err()
+}
+
+func (t *streamTransport) Send(ctx context.Context, msg []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	_, err := t.w.Write(append(msg, '\n'))
+	return err
+}
+
+func (t *streamTransport) Receive(ctx context.Context) ([]byte, error) {
+	select {
+	case line, ok := <-t.lines:
+		if !ok {
+			if t.err != nil {
+				return nil, t.err
+			}
+			return nil, io.EOF
+		}
+		return line, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (t *streamTransport) Close() error {
+	select {
+	case <-t.done:
+		return nil
+	default:
+		close(t.done)
+	}
+	if t.closer != nil {
+		return t.closer()
+	}
+	return nil
+}
+
+// commandExitTimeout is how long a closed server process gets to exit
+// before it is killed
+const commandExitTimeout = 5 * time.Second
+
+// NewCommandTransport starts cmd and talks to it over its stdin and stdout.
+// Closing the transport closes stdin and waits for the process to exit,
+// killing it if it doesn't exit in time.
+func NewCommandTransport(cmd *exec.Cmd) (Transport, error) {
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start %s: %w", cmd.Path, err)
+	}
+
+	return NewStreamTransport(stdout, stdin, func() error {
+		stdin.Close()
+		exited := make(chan struct{})
+		go func() {
+			cmd.Wait()
+			close(exited)
+		}()
+		select {
+		case <-exited:
+		case <-time.After(commandExitTimeout):
+			cmd.Process.Kill()
+			<-exited
+		}
+		return nil
+	}), nil
+}
+
+// httpTransport speaks MCP's streamable HTTP transport: every message is
+// POSTed to the server, which answers with JSON or an event stream
+type httpTransport struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+	session string
+	pending [][]byte
+}
+
+// NewHTTPTransport talks to the server at url over streamable HTTP, sending
+// headers with every request
+func NewHTTPTransport(url string, headers map[string]string) Transport {
+	return NewHTTPClientTransport(url, headers, &http.Client{})
+}
+
+// NewHTTPClientTransport is NewHTTPTransport making its requests with
+// client, such as one trusting extra CAs
+func NewHTTPClientTransport(url string, headers map[string]string, client *http.Client) Transport {
+	return &httpTransport{url: url, headers: headers, client: client}
+}
+
+// HTTPStatusError is returned when an HTTP server answers a message with an
+// error status
+type HTTPStatusError struct {
+	StatusCode int
+	Status     string // e.g. "401 Unauthorized"
+	Body       string // Start of the response body, trimmed
+}
+
+func (e *HTTPStatusError) Error() string {
+	if e.Body != "" {
+		return fmt.Sprintf("server returned %s: %s", e.Status, e.Body)
+	}
+	return fmt.Sprintf("server returned %s", e.Status)
+}
+
+func (t *httpTransport) newRequest(ctx context.Context, method string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, t.url, body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("MCP-Protocol-Version", ProtocolVersion)
+	if t.session != "" {
+		req.Header.Set("Mcp-Session-Id", t.session)
+	}
+	return req, nil
+}
+
+func (t *httpTransport) Send(ctx context.Context, msg []byte) error {
+	req, err := t.newRequest(ctx, http.MethodPost, bytes.NewReader(msg))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if session := resp.Header.Get("Mcp-Session-Id"); session != "" {
+		t.session = session
+	}
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &HTTPStatusError{StatusCode: resp.StatusCode, Status: resp.Status, Body: strings.TrimSpace(string(body))}
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch mediaType {
+	case "text/event-stream":
+		return t.readEvents(resp.Body)
+	case "application/json":
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		if data = bytes.TrimSpace(data); len(data) > 0 {
+			t.pending = append(t.pending, data)
+		}
+	}
+	return nil
+}
+
+// readEvents queues the data of every event in an event stream until the
+// server closes it
+func (t *httpTransport) readEvents(r io.Reader) error {
+	var data []string
+	dispatch := func() {
+		if len(data) > 0 {
+			t.pending = append(t.pending, []byte(strings.Join(data, "\n")))
+			data = nil
+		}
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			dispatch()
+			continue
+		}
+		if value, ok := strings.CutPrefix(line, "data:"); ok {
+			data = append(data, strings.TrimPrefix(value, " "))
+		}
+	}
+	dispatch()
+	return scanner.Err()
+}
+
+func (t *httpTransport) Receive(ctx context.Context) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(t.pending) == 0 {
+		return nil, io.EOF
+	}
+	msg := t.pending[0]
+	t.pending = t.pending[1:]
+	return msg, nil
+}
+
+// Close ends the server session, if the server started one
+func (t *httpTransport) Close() error {
+	if t.session == "" {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, err := t.newRequest(ctx, http.MethodDelete, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	t.session = ""
+	return nil
+}
@@ -0,0 +1,113 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestHTTPTransport(t *testing.T) {
+	var sessions []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sessions = append(sessions, r.Method+" "+r.Header.Get("Mcp-Session-Id"))
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		switch {
+		case r.Method == http.MethodDelete:
+		case strings.Contains(string(body), `"initialize"`):
+			w.Header().Set("Mcp-Session-Id", "abc")
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `{"jsonrpc":"2.0","id":1,"result":{"protocolVersion":"2025-06-18","capabilities":{},"serverInfo":{"name":"remote","version":"1"}}}`)
+		case strings.Contains(string(body), `"notifications/`):
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			w.Header().Set("Content-Type", "text/event-stream")
+			io.WriteString(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/progress\"}\n\n")
+			io.WriteString(w, "data: {\"jsonrpc\":\"2.0\",\"id\":2,\n")
+			io.WriteString(w, "data: \"result\":{\"tools\":[{\"name\":\"search\",\"inputSchema\":{}}]}}\n\n")
+		}
+	}))
+	defer srv.Close()
+
+	client := NewClient(NewHTTPTransport(srv.URL, map[string]string{"Authorization": "Bearer token"}), "mcpr", "test")
+	ctx := context.Background()
+
+	result, err := client.Initialize(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.ServerInfo.Name != "remote" {
+		t.Errorf("unexpected server info: %+v", result.ServerInfo)
+	}
+
+	tools, err := client.ListTools(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tools) != 1 || tools[0].Name != "search" {
+		t.Errorf("unexpected tools: %+v", tools)
+	}
+
+	if err := client.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"POST ", "POST abc", "POST abc", "DELETE abc"}
+	if strings.Join(sessions, ",") != strings.Join(want, ",") {
+		t.Errorf("expected requests %v, got %v", want, sessions)
+	}
+}
+
+func TestHTTPTransport_ErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no such endpoint", http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	client := NewClient(NewHTTPTransport(srv.URL, nil), "mcpr", "test")
+	_, err := client.Initialize(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "no such endpoint") {
+		t.Errorf("expected the server's error, got %v", err)
+	}
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Errorf("expected an HTTPStatusError with status 404, got %v", err)
+	}
+}
+
+func TestCommandTransport(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses cat")
+	}
+	if _, err := exec.LookPath("cat"); err != nil {
+		t.Skip("cat not available")
+	}
+
+	transport, err := NewCommandTransport(exec.Command("cat"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := context.Background()
+	if err := transport.Send(ctx, []byte(`{"jsonrpc":"2.0","method":"x"}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	msg, err := transport.Receive(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(msg) != `{"jsonrpc":"2.0","method":"x"}` {
+		t.Errorf("expected the message echoed back, got %s", msg)
+	}
+	if err := transport.Close(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package netutil
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBypassProxy(t *testing.T) {
+	tests := []struct {
+		host    string
+		noProxy string
+		want    bool
+	}{
+		{"example.com", "", false},
+		{"example.com", "*", true},
+		{"example.com", "example.com", true},
+		{"api.example.com", "example.com", true},
+		{"api.example.com", ".example.com", true},
+		{"notexample.com", "example.com", false},
+		{"internal", "localhost, internal:8080", true},
+		{"10.1.2.3", "10.0.0.0/8", true},
+		{"192.168.1.1", "10.0.0.0/8", false},
+	}
+	for _, tt := range tests {
+		if got := bypassProxy(tt.host, tt.noProxy); got != tt.want {
+			t.Errorf("bypassProxy(%q, %q) = %v, expected %v", tt.host, tt.noProxy, got, tt.want)
+		}
+	}
+}
+
+func TestNewClient_Proxy(t *testing.T) {
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+	}))
+	defer proxy.Close()
+
+	client, err := NewClient(Options{Proxy: proxy.URL, NoProxy: "direct.invalid"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp, err := client.Get("http://registry.example.com/pkg")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if len(proxied) != 1 || proxied[0] != "http://registry.example.com/pkg" {
+		t.Errorf("expected the request to go through the proxy, got %v", proxied)
+	}
+
+	if _, err := NewClient(Options{Proxy: "not a url"}); err == nil {
+		t.Error("expected an error for an invalid proxy URL")
+	}
+}
+
+func TestNewClient_CABundle(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	client, err := NewClient(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Get(srv.URL); err == nil {
+		t.Error("expected the test certificate not to be trusted without the bundle")
+	}
+
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	os.WriteFile(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0644)
+	client, err = NewClient(Options{CABundle: bundle})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("expected the bundle to be trusted, got %v", err)
+	}
+	resp.Body.Close()
+
+	empty := filepath.Join(t.TempDir(), "empty.pem")
+	os.WriteFile(empty, []byte("nothing"), 0644)
+	if _, err := NewClient(Options{CABundle: empty}); err == nil || !strings.Contains(err.Error(), "no certificates") {
+		t.Errorf("expected an error for a bundle without certificates, got %v", err)
+	}
+}
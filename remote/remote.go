@@ -0,0 +1,210 @@
+// Package remote stores the global mcpr config on a remote backend, so
+// several machines can share it: S3 and S3-compatible services, Google
+// Cloud Storage and WebDAV servers.
+package remote
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jrandolf/mcpr/config"
+	"github.com/jrandolf/mcpr/retry"
+)
+
+// ErrNotFound is returned by Backend.Get when the remote holds no config
+var ErrNotFound = errors.New("no config on the remote")
+
+// ErrChanged is returned by Backend.Put when the remote copy is no longer
+// the one the put was based on
+var ErrChanged = errors.New("the remote copy changed")
+
+// NoConfig is the ETag to put with when the remote held no config, so the
+// put fails if another machine pushed one since
+const NoConfig = "-"
+
+// Settings configure the remote. Credentials missing here are read from the
+// environment when connecting.
+type Settings struct {
+	URL       string `json:"url"`                 // s3://bucket/key, gs://bucket/key or an http(s) WebDAV URL
+	Username  string `json:"username,omitempty"`  // WebDAV
+	Password  string `json:"password,omitempty"`  // WebDAV; $MCPR_REMOTE_PASSWORD
+	AccessKey string `json:"accessKey,omitempty"` // S3 or GCS HMAC key; $AWS_ACCESS_KEY_ID
+	SecretKey string `json:"secretKey,omitempty"` // S3 or GCS HMAC secret; $AWS_SECRET_ACCESS_KEY
+	Region    string `json:"region,omitempty"`    // S3; $AWS_REGION, or us-east-1
+	Endpoint  string `json:"endpoint,omitempty"`  // S3-compatible services such as MinIO or R2
+
+	Client *http.Client `json:"-"` // Makes the requests; http.DefaultClient if nil
+	Retry  retry.Policy `json:"-"` // Retries network failures and transient statuses; retry.Default if unset
+}
+
+// Object is the remote copy of the config
+type Object struct {
+	Data []byte
+	ETag string // Changes whenever the remote copy does
+}
+
+// Backend stores the config remotely
+type Backend interface {
+	// Get returns the remote copy, or ErrNotFound
+	Get(ctx context.Context) (Object, error)
+	// Put replaces the remote copy and returns its new ETag. Unless ifMatch
+	// is empty, the put fails with ErrChanged if the remote copy no longer
+	// has that ETag, or holds a config when it is NoConfig.
+	Put(ctx context.Context, data []byte, ifMatch string) (string, error)
+}
+
+// SettingsPath returns the path of the remote settings, next to the global
+// config. They may hold credentials, so they aren't part of it.
+func SettingsPath() (string, error) {
+	path, err := config.GetWriteConfigPath(false)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), "remote.json"), nil
+}
+
+// LoadSettings reads the remote settings, returning nil if no remote is
+// configured
+func LoadSettings() (*Settings, error) {
+	path, err := SettingsPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read remote settings: %w", err)
+	}
+	var s Settings
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse remote settings %s: %w", path, err)
+	}
+	return &s, nil
+}
+
+// Save writes the remote settings, readable only by the user
+func (s *Settings) Save() error {
+	path, err := SettingsPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal remote settings: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write remote settings: %w", err)
+	}
+	return os.Chmod(path, 0o600)
+}
+
+// RemoveSettings deletes the remote settings
+func RemoveSettings() error {
+	path, err := SettingsPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove remote settings: %w", err)
+	}
+	return nil
+}
+
+// Validate checks the remote URL, without connecting
+func (s Settings) Validate() error {
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		return fmt.Errorf("invalid remote URL: %w", err)
+	}
+	switch u.Scheme {
+	case "s3", "gs":
+		if u.Host == "" {
+			return fmt.Errorf("invalid remote URL %q: expected %s://bucket/key", s.URL, u.Scheme)
+		}
+	case "http", "https":
+		if u.Host == "" {
+			return fmt.Errorf("invalid remote URL %q: host is missing", s.URL)
+		}
+	default:
+		return fmt.Errorf("unsupported remote URL %q: expected s3://, gs://, http:// or https://", s.URL)
+	}
+	return nil
+}
+
+// New returns the backend for settings. A URL ending in "/" names a
+// directory, and the config is stored in it as name.
+func New(s Settings, name string) (Backend, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	u, _ := url.Parse(s.URL)
+	if strings.HasSuffix(u.Path, "/") || u.Path == "" {
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/" + name
+	}
+
+	if u.Scheme == "s3" || u.Scheme == "gs" {
+		return newS3Backend(s, u)
+	}
+	password := s.Password
+	if password == "" {
+		password = os.Getenv("MCPR_REMOTE_PASSWORD")
+	}
+	return &webDAVBackend{url: u.String(), username: s.Username, password: password, client: cmp.Or(s.Client, http.DefaultClient), retry: cmp.Or(s.Retry, retry.Default)}, nil
+}
+
+// send sends the requests newRequest builds until one is answered with
+// anything but a transient failure, or policy gives up
+func send(ctx context.Context, client *http.Client, policy retry.Policy, newRequest func() (*http.Request, error)) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		req, err := newRequest()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		resp, err := client.Do(req)
+		transient := retry.IsTransientNetwork(err) || err == nil && retry.IsTransientStatus(resp.StatusCode)
+		if !transient || !policy.Next(ctx, attempt) {
+			if err != nil {
+				return nil, fmt.Errorf("failed to reach the remote: %w", err)
+			}
+			return resp, nil
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+	}
+}
+
+// preconditionHeader returns the headers that make a put fail unless the
+// remote copy still has the ETag ifMatch
+func preconditionHeader(ifMatch string) http.Header {
+	header := http.Header{}
+	switch {
+	case ifMatch == NoConfig:
+		header.Set("If-None-Match", "*")
+	case ifMatch != "" && !strings.HasPrefix(ifMatch, "sha256:"):
+		// Content hashes aren't ETags the server knows, so they can't be matched
+		header.Set("If-Match", ifMatch)
+	}
+	return header
+}
+
+// contentETag stands in for the ETag of servers that don't send one
+func contentETag(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,191 @@
+package remote
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jrandolf/mcpr/retry"
+)
+
+// s3Backend stores the config as an object in S3, an S3-compatible service
+// or Google Cloud Storage, whose XML API accepts S3 requests signed with
+// HMAC keys
+type s3Backend struct {
+	url          string
+	region       string
+	accessKey    string
+	secretKey    string
+	sessionToken string
+	client       *http.Client
+	retry        retry.Policy
+	now          func() time.Time
+}
+
+func newS3Backend(s Settings, u *url.URL) (*s3Backend, error) {
+	bucket, key := u.Host, strings.TrimPrefix(u.Path, "/")
+	b := &s3Backend{
+		region:       s.Region,
+		accessKey:    s.AccessKey,
+		secretKey:    s.SecretKey,
+		sessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+		client:       cmp.Or(s.Client, http.DefaultClient),
+		retry:        cmp.Or(s.Retry, retry.Default),
+		now:          time.Now,
+	}
+	if b.accessKey == "" {
+		b.accessKey = os.Getenv("AWS_ACCESS_KEY_ID")
+	}
+	if b.secretKey == "" {
+		b.secretKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+	}
+	if b.accessKey == "" || b.secretKey == "" {
+		return nil, fmt.Errorf("no credentials for %s: set an access key and secret key, or AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY", s.URL)
+	}
+
+	if b.region == "" {
+		b.region = cmp.Or(os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION"), "us-east-1")
+	}
+
+	object := (&url.URL{Path: "/" + key}).EscapedPath()
+	switch {
+	case u.Scheme == "gs":
+		b.region = "auto"
+		b.url = "https://storage.googleapis.com/" + bucket + object
+	case s.Endpoint != "":
+		b.url = strings.TrimSuffix(s.Endpoint, "/") + "/" + bucket + object
+	default:
+		b.url = "https://" + bucket + ".s3." + b.region + ".amazonaws.com" + object
+	}
+	return b, nil
+}
+
+func (b *s3Backend) do(ctx context.Context, method string, body []byte, header http.Header) (*http.Response, error) {
+	sum := sha256.Sum256(body)
+	return send(ctx, b.client, b.retry, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, method, b.url, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		signV4(req, hex.EncodeToString(sum[:]), b.accessKey, b.secretKey, b.sessionToken, b.region, b.now())
+		return req, nil
+	})
+}
+
+func (b *s3Backend) Get(ctx context.Context) (Object, error) {
+	resp, err := b.do(ctx, http.MethodGet, nil, nil)
+	if err != nil {
+		return Object{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return Object{}, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Object{}, s3Error(http.MethodGet, b.url, resp)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Object{}, fmt.Errorf("failed to read %s: %w", b.url, err)
+	}
+	return Object{Data: data, ETag: resp.Header.Get("ETag")}, nil
+}
+
+func (b *s3Backend) Put(ctx context.Context, data []byte, ifMatch string) (string, error) {
+	resp, err := b.do(ctx, http.MethodPut, data, preconditionHeader(ifMatch))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusPreconditionFailed {
+		return "", fmt.Errorf("%s %s: %w", http.MethodPut, b.url, ErrChanged)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", s3Error(http.MethodPut, b.url, resp)
+	}
+	return resp.Header.Get("ETag"), nil
+}
+
+// s3Error describes an S3 error response by its code and message
+func s3Error(method, url string, resp *http.Response) error {
+	var body struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if xml.Unmarshal(data, &body) == nil && body.Code != "" {
+		return fmt.Errorf("%s %s: %s: %s", method, url, body.Code, body.Message)
+	}
+	return fmt.Errorf("%s %s: %s", method, url, resp.Status)
+}
+
+// signV4 signs req for S3 with AWS Signature Version 4. Every header set on
+// req is signed, along with the host.
+func signV4(req *http.Request, payloadHash, accessKey, secretKey, sessionToken, region string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", sessionToken)
+	}
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	headers := map[string]string{"host": host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := []byte("AWS4" + secretKey)
+	for _, part := range []string{date, region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+accessKey+"/"+scope+", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,110 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/jrandolf/mcpr/retry"
+)
+
+// webDAVBackend stores the config as a file on a WebDAV server
+type webDAVBackend struct {
+	url      string
+	username string
+	password string
+	client   *http.Client
+	retry    retry.Policy
+}
+
+func (b *webDAVBackend) do(ctx context.Context, method, url string, body []byte, header http.Header) (*http.Response, error) {
+	return send(ctx, b.client, b.retry, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		if b.username != "" || b.password != "" {
+			req.SetBasicAuth(b.username, b.password)
+		}
+		return req, nil
+	})
+}
+
+func (b *webDAVBackend) Get(ctx context.Context) (Object, error) {
+	resp, err := b.do(ctx, http.MethodGet, b.url, nil, nil)
+	if err != nil {
+		return Object{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return Object{}, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Object{}, statusError(http.MethodGet, b.url, resp)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Object{}, fmt.Errorf("failed to read %s: %w", b.url, err)
+	}
+	etag := resp.Header.Get("ETag")
+	if etag == "" {
+		etag = contentETag(data)
+	}
+	return Object{Data: data, ETag: etag}, nil
+}
+
+func (b *webDAVBackend) Put(ctx context.Context, data []byte, ifMatch string) (string, error) {
+	header := preconditionHeader(ifMatch)
+	resp, err := b.do(ctx, http.MethodPut, b.url, data, header)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	// The parent collection is created on first push
+	if resp.StatusCode == http.StatusConflict {
+		parent := b.url[:strings.LastIndex(b.url, "/")+1]
+		mkcol, err := b.do(ctx, "MKCOL", parent, nil, nil)
+		if err != nil {
+			return "", err
+		}
+		mkcol.Body.Close()
+		if resp, err = b.do(ctx, http.MethodPut, b.url, data, header); err != nil {
+			return "", err
+		}
+		resp.Body.Close()
+	}
+	if resp.StatusCode == http.StatusPreconditionFailed {
+		return "", fmt.Errorf("%s %s: %w", http.MethodPut, b.url, ErrChanged)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", statusError(http.MethodPut, b.url, resp)
+	}
+
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		return etag, nil
+	}
+	// Not every server returns the ETag of what was written
+	head, err := b.do(ctx, http.MethodHead, b.url, nil, nil)
+	if err == nil {
+		head.Body.Close()
+		if etag := head.Header.Get("ETag"); etag != "" {
+			return etag, nil
+		}
+	}
+	return contentETag(data), nil
+}
+
+// statusError describes an unexpected response
+func statusError(method, url string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if msg := strings.TrimSpace(string(body)); msg != "" {
+		return fmt.Errorf("%s %s: %s: %s", method, url, resp.Status, msg)
+	}
+	return fmt.Errorf("%s %s: %s", method, url, resp.Status)
+}
@@ -0,0 +1,118 @@
+// Package retry runs operations again after transient failures, such as a
+// file locked by another program or a server answering 503, waiting longer
+// after each attempt.
+package retry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"runtime"
+	"syscall"
+	"time"
+)
+
+// Policy says how often and how patiently an operation is retried
+type Policy struct {
+	Attempts int           // Tries in all, including the first; 1 disables retrying
+	Delay    time.Duration // Wait before the first retry, doubled before each further one
+	MaxDelay time.Duration // Longest wait between attempts
+}
+
+// Default is the policy of operations the config doesn't set one for
+var Default = Policy{Attempts: 4, Delay: 200 * time.Millisecond, MaxDelay: 5 * time.Second}
+
+// disabled makes every policy try once, for --no-retry
+var disabled bool
+
+// Disable turns retrying off for every policy
+func Disable() {
+	disabled = true
+}
+
+// sleep waits for d or until ctx is done; a variable so tests don't wait
+var sleep = func(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Next reports whether another attempt follows the failed attempt (counting
+// from 1), after waiting out the backoff. It returns false without waiting
+// once the attempts are used up, and false if ctx is done while waiting.
+func (p Policy) Next(ctx context.Context, attempt int) bool {
+	if disabled || attempt >= p.Attempts {
+		return false
+	}
+	return sleep(ctx, p.backoff(attempt)) == nil
+}
+
+// backoff returns the wait after the failed attempt
+func (p Policy) backoff(attempt int) time.Duration {
+	delay := p.Delay
+	for i := 1; i < attempt && (p.MaxDelay <= 0 || delay < p.MaxDelay); i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+// Do runs op until it succeeds, fails with an error transient rejects, or
+// the attempts run out, and returns its last error
+func (p Policy) Do(ctx context.Context, transient func(error) bool, op func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := op()
+		if err == nil || !transient(err) || !p.Next(ctx, attempt) {
+			return err
+		}
+	}
+}
+
+// IsBusy reports whether err is a file being in use by another program,
+// which lets go after a moment: EBUSY and ETXTBSY, or on Windows a sharing
+// or lock violation, or access denied while a virus scanner or indexer holds
+// the file open
+func IsBusy(err error) bool {
+	var errno syscall.Errno
+	if !errors.As(err, &errno) {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		// ERROR_ACCESS_DENIED, ERROR_SHARING_VIOLATION, ERROR_LOCK_VIOLATION
+		return errno == 5 || errno == 32 || errno == 33
+	}
+	return errno == syscall.EBUSY || errno == syscall.ETXTBSY
+}
+
+// IsTransientNetwork reports whether err is a network failure that may not
+// happen again: a timeout, or a connection refused, reset or cut short
+func IsTransientNetwork(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return dnsErr.IsTemporary
+	}
+	return errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// IsTransientStatus reports whether an HTTP status is worth retrying: too
+// many requests, or a server error other than not implemented
+func IsTransientStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= 500 && code != http.StatusNotImplemented
+}
@@ -0,0 +1,94 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// noSleep records the waits instead of waiting
+func noSleep(t *testing.T) *[]time.Duration {
+	t.Helper()
+	var waits []time.Duration
+	original := sleep
+	sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return ctx.Err()
+	}
+	t.Cleanup(func() { sleep = original })
+	return &waits
+}
+
+func TestPolicy_Do(t *testing.T) {
+	waits := noSleep(t)
+	p := Policy{Attempts: 4, Delay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+	busy := &os.PathError{Op: "open", Path: "x", Err: syscall.EBUSY}
+
+	calls := 0
+	err := p.Do(context.Background(), func(error) bool { return true }, func() error {
+		calls++
+		if calls < 4 {
+			return busy
+		}
+		return nil
+	})
+	if err != nil || calls != 4 {
+		t.Fatalf("expected success on the fourth attempt, got %v after %d", err, calls)
+	}
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond}
+	if fmt.Sprint(*waits) != fmt.Sprint(want) {
+		t.Errorf("expected waits %v, got %v", want, *waits)
+	}
+
+	// Attempts run out, and permanent errors aren't retried
+	calls = 0
+	err = p.Do(context.Background(), func(error) bool { return true }, func() error { calls++; return busy })
+	if !errors.Is(err, syscall.EBUSY) || calls != 4 {
+		t.Errorf("expected the last error after 4 attempts, got %v after %d", err, calls)
+	}
+	calls = 0
+	p.Do(context.Background(), func(error) bool { return false }, func() error { calls++; return busy })
+	if calls != 1 {
+		t.Errorf("expected a permanent error to be tried once, got %d", calls)
+	}
+}
+
+func TestDisable(t *testing.T) {
+	noSleep(t)
+	t.Cleanup(func() { disabled = false })
+	Disable()
+
+	calls := 0
+	Default.Do(context.Background(), func(error) bool { return true }, func() error { calls++; return io.EOF })
+	if calls != 1 {
+		t.Errorf("expected a single attempt with retrying disabled, got %d", calls)
+	}
+}
+
+func TestIsBusy(t *testing.T) {
+	if !IsBusy(&os.PathError{Op: "open", Path: "x", Err: syscall.EBUSY}) {
+		t.Error("expected EBUSY to be busy")
+	}
+	if IsBusy(os.ErrNotExist) || IsBusy(errors.New("other")) {
+		t.Error("expected other errors not to be busy")
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	if !IsTransientNetwork(fmt.Errorf("dial: %w", syscall.ECONNREFUSED)) || !IsTransientNetwork(io.ErrUnexpectedEOF) {
+		t.Error("expected refused and cut connections to be transient")
+	}
+	if IsTransientNetwork(context.Canceled) || IsTransientNetwork(errors.New("x509: unknown authority")) {
+		t.Error("expected cancellation and certificate errors not to be transient")
+	}
+	for code, want := range map[int]bool{200: false, 404: false, 429: true, 500: true, 501: false, 503: true} {
+		if got := IsTransientStatus(code); got != want {
+			t.Errorf("IsTransientStatus(%d) = %v, expected %v", code, got, want)
+		}
+	}
+}